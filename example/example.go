@@ -7,13 +7,16 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
-	"github.com/golang-jwt/jwt/v4"
-	"github.com/matelang/jwt-go-aws-kms/v2/jwtkms"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
 )
 
 const keyID = "aa2f90bf-f09f-42b7-b4f3-2083bd00f9ad"
 
 func main() {
+	// jwt.ParseWithClaims below needs the KMS signing methods registered with golang-jwt
+	jwtkms.MustRegister()
+
 	awsCfg, err := config.LoadDefaultConfig(context.Background(),
 		config.WithRegion("eu-central-1"))
 	if err != nil {
@@ -21,13 +24,13 @@ func main() {
 	}
 
 	now := time.Now()
-	jwtToken := jwt.NewWithClaims(jwtkms.SigningMethodECDSA256, &jwt.StandardClaims{
-		Audience:  "api.example.com",
-		ExpiresAt: now.Add(1 * time.Hour * 24).Unix(),
-		Id:        "1234-5678",
-		IssuedAt:  now.Unix(),
+	jwtToken := jwt.NewWithClaims(jwtkms.SigningMethodECDSA256, &jwt.RegisteredClaims{
+		Audience:  jwt.ClaimStrings{"api.example.com"},
+		ExpiresAt: jwt.NewNumericDate(now.Add(1 * time.Hour * 24)),
+		ID:        "1234-5678",
+		IssuedAt:  jwt.NewNumericDate(now),
 		Issuer:    "sso.example.com",
-		NotBefore: now.Unix(),
+		NotBefore: jwt.NewNumericDate(now),
 		Subject:   "john.doe@example.com",
 	})
 
@@ -0,0 +1,75 @@
+package mockkms
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// The mock does not distinguish key usages, RSA keys both sign and encrypt.
+var oaepHashAlgorithms = map[types.EncryptionAlgorithmSpec]crypto.Hash{
+	types.EncryptionAlgorithmSpecRsaesOaepSha1:   crypto.SHA1,
+	types.EncryptionAlgorithmSpecRsaesOaepSha256: crypto.SHA256,
+}
+
+func (k *MockKMS) getEncryptionKey(id string, algorithm types.EncryptionAlgorithmSpec) (*rsa.PrivateKey, crypto.Hash, error) {
+	key, err := k.getKey(id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, 0, &types.InvalidKeyUsageException{Message: aws.String("key is not an encryption key")}
+	}
+
+	hash, ok := oaepHashAlgorithms[algorithm]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown encryption algorithm: %v", algorithm)
+	}
+
+	return rsaKey, hash, nil
+}
+
+func (k *MockKMS) Encrypt(_ context.Context, in *kms.EncryptInput, _ ...func(*kms.Options)) (*kms.EncryptOutput, error) {
+	key, hash, err := k.getEncryptionKey(*in.KeyId, in.EncryptionAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := rsa.EncryptOAEP(hash.New(), rand.Reader, &key.PublicKey, in.Plaintext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting: %w", err)
+	}
+
+	return &kms.EncryptOutput{
+		CiphertextBlob:      ciphertext,
+		EncryptionAlgorithm: in.EncryptionAlgorithm,
+		KeyId:               aws.String(KeyARN(strings.TrimPrefix(*in.KeyId, ARNPrefix))),
+	}, nil
+}
+
+func (k *MockKMS) Decrypt(_ context.Context, in *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	key, hash, err := k.getEncryptionKey(aws.ToString(in.KeyId), in.EncryptionAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := rsa.DecryptOAEP(hash.New(), nil, key, in.CiphertextBlob, nil)
+	if err != nil {
+		return nil, &types.InvalidCiphertextException{Message: aws.String("ciphertext is invalid")}
+	}
+
+	return &kms.DecryptOutput{
+		Plaintext:           plaintext,
+		EncryptionAlgorithm: in.EncryptionAlgorithm,
+		KeyId:               aws.String(KeyARN(strings.TrimPrefix(aws.ToString(in.KeyId), ARNPrefix))),
+	}, nil
+}
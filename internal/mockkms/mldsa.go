@@ -0,0 +1,74 @@
+//go:build go1.27
+
+package mockkms
+
+import (
+	"crypto"
+	"crypto/mldsa"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+var keyTypeMLDSAParameters = map[KeyType]mldsa.Parameters{
+	KeyTypeMLDSA44: mldsa.MLDSA44(),
+	KeyTypeMLDSA65: mldsa.MLDSA65(),
+	KeyTypeMLDSA87: mldsa.MLDSA87(),
+}
+
+type mldsaKey struct {
+	*mldsa.PrivateKey
+}
+
+func generateMLDSAKey(kt KeyType) (signingKey, error) {
+	pk, err := mldsa.GenerateKey(keyTypeMLDSAParameters[kt])
+	if err != nil {
+		return nil, fmt.Errorf("generating key: %w", err)
+	}
+	return mldsaKey{pk}, nil
+}
+
+func (k mldsaKey) sign(in *kms.SignInput) (*kms.SignOutput, error) {
+	if in.SigningAlgorithm != types.SigningAlgorithmSpecMlDsaShake256 {
+		return nil, fmt.Errorf("unknown signing algorithm: %v", in.SigningAlgorithm)
+	}
+
+	var opts crypto.SignerOpts
+	switch in.MessageType {
+	case types.MessageTypeRaw:
+		opts = &mldsa.Options{}
+
+	case types.MessageTypeExternalMu:
+		opts = crypto.MLDSAMu
+
+	default:
+		return nil, fmt.Errorf("unsupported message type: %v", in.MessageType)
+	}
+
+	sig, err := k.Sign(nil, in.Message, opts)
+	if err != nil {
+		return nil, fmt.Errorf("signing message: %w", err)
+	}
+
+	return &kms.SignOutput{
+		Signature: sig,
+	}, nil
+}
+
+func (k mldsaKey) verify(in *kms.VerifyInput) (*kms.VerifyOutput, error) {
+	if in.MessageType != types.MessageTypeRaw {
+		return nil, fmt.Errorf("unsupported message type: %v", in.MessageType)
+	}
+
+	err := mldsa.Verify(k.PublicKey(), in.Message, in.Signature, nil)
+
+	return &kms.VerifyOutput{
+		SignatureValid: err == nil,
+	}, nil
+}
+
+func (k mldsaKey) marshalPublicKey() ([]byte, error) {
+	return x509.MarshalPKIXPublicKey(k.PublicKey())
+}
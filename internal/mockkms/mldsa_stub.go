@@ -0,0 +1,9 @@
+//go:build !go1.27
+
+package mockkms
+
+import "errors"
+
+func generateMLDSAKey(KeyType) (signingKey, error) {
+	return nil, errors.New("ML-DSA keys require go1.27")
+}
@@ -0,0 +1,689 @@
+// Package mockkms provides a partial implementation of AWS' KMS interface
+// sufficient to satisfy the KMSClient interface.
+//
+// It is internal to the module rather than to jwtkms so the tests of the
+// adapter packages, like jwtkmsjose, can use it too.
+package mockkms
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/aws/smithy-go"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/google/uuid"
+)
+
+// KeyType describes the type of a key.
+type KeyType int
+
+const (
+	KeyTypeECCNISTP256 KeyType = iota
+	KeyTypeECCNISTP384
+	KeyTypeECCNISTP521
+	KeyTypeECCSECGP256K1
+	KeyTypeRSA2048
+	KeyTypeHMAC256
+	KeyTypeHMAC384
+	KeyTypeHMAC512
+	KeyTypeMLDSA44
+	KeyTypeMLDSA65
+	KeyTypeMLDSA87
+	KeyTypeSM2
+)
+
+// MockKMS implements the KMSClient interface backed by in-memory storage. It
+// is safe for concurrent use.
+type MockKMS struct {
+	mu       sync.Mutex
+	keys     map[string]interface{}
+	keyTypes map[string]KeyType
+	disabled map[string]bool
+	states   map[string]types.KeyState
+}
+
+// NewMockKMS constructs a new MockKMS instance.
+func NewMockKMS() *MockKMS {
+	return &MockKMS{
+		keys:     make(map[string]interface{}),
+		keyTypes: make(map[string]KeyType),
+		disabled: make(map[string]bool),
+		states:   make(map[string]types.KeyState),
+	}
+}
+
+// DisableKey makes every subsequent operation on the key fail with a
+// DisabledException, like a disabled KMS key does.
+func (k *MockKMS) DisableKey(id string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.disabled[strings.TrimPrefix(id, ARNPrefix)] = true
+}
+
+// SetKeyState puts the key into state, e.g. PendingDeletion. Operations on
+// keys in a state other than Enabled fail with a KMSInvalidStateException,
+// like they do in KMS.
+func (k *MockKMS) SetKeyState(id string, state types.KeyState) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.states[strings.TrimPrefix(id, ARNPrefix)] = state
+}
+
+// DescribeKey reports the key ARN, state, spec and algorithms of a key.
+func (k *MockKMS) DescribeKey(_ context.Context, in *kms.DescribeKeyInput, _ ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	id := strings.TrimPrefix(*in.KeyId, ARNPrefix)
+	if _, ok := k.keys[id]; !ok {
+		return nil, &types.NotFoundException{Message: aws.String(fmt.Sprintf("no such key: %v", id))}
+	}
+
+	state := types.KeyStateEnabled
+	if s, ok := k.states[id]; ok {
+		state = s
+	}
+	if k.disabled[id] {
+		state = types.KeyStateDisabled
+	}
+
+	kt := k.keyTypes[id]
+	metadata := &types.KeyMetadata{
+		KeyId:             aws.String(id),
+		Arn:               aws.String(KeyARN(id)),
+		KeyState:          state,
+		KeySpec:           keyTypeKeySpecs[kt],
+		KeyUsage:          types.KeyUsageTypeSignVerify,
+		SigningAlgorithms: keyTypeSigningAlgorithms[kt],
+		Enabled:           state == types.KeyStateEnabled,
+	}
+
+	if algorithm, ok := keyTypeHMACAlgorithms[kt]; ok {
+		metadata.KeyUsage = types.KeyUsageTypeGenerateVerifyMac
+		metadata.MacAlgorithms = []types.MacAlgorithmSpec{algorithm}
+	}
+
+	return &kms.DescribeKeyOutput{KeyMetadata: metadata}, nil
+}
+
+// ARNPrefix is the prefix of the key ARNs reported by MockKMS. Keys can be
+// referred to by their KeyId or their ARN.
+const ARNPrefix = "arn:aws:kms:us-east-1:111122223333:key/"
+
+// KeyARN returns the ARN MockKMS reports for the key id.
+func KeyARN(id string) string {
+	return ARNPrefix + id
+}
+
+// GenerateKey generates a key of the type described by kt and returns the
+// KeyId which can be used by subsequent calls to refer to the generated key.
+func (k *MockKMS) GenerateKey(kt KeyType) (string, error) {
+	var err error
+	var key interface{}
+	switch kt {
+	case KeyTypeECCNISTP256, KeyTypeECCNISTP384, KeyTypeECCNISTP521:
+		key, err = generateECCKey(kt)
+
+	case KeyTypeECCSECGP256K1:
+		key, err = generateSECGKey()
+
+	case KeyTypeRSA2048:
+		key, err = generateRSAKey(kt)
+
+	case KeyTypeHMAC256, KeyTypeHMAC384, KeyTypeHMAC512:
+		key, err = generateHMACKey(kt)
+
+	case KeyTypeMLDSA44, KeyTypeMLDSA65, KeyTypeMLDSA87:
+		key, err = generateMLDSAKey(kt)
+
+	case KeyTypeSM2:
+		key, err = generateSM2Key()
+
+	default:
+		return "", fmt.Errorf("unknown key type: %v", kt)
+	}
+	if err != nil {
+		return "", fmt.Errorf("generating key: %w", err)
+	}
+
+	id := uuid.NewString()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[id] = key
+	k.keyTypes[id] = kt
+
+	return id, nil
+}
+
+var keyTypeECCCurves = map[KeyType]elliptic.Curve{
+	KeyTypeECCNISTP256: elliptic.P256(),
+	KeyTypeECCNISTP384: elliptic.P384(),
+	KeyTypeECCNISTP521: elliptic.P521(),
+}
+
+func generateECCKey(kt KeyType) (*ecdsa.PrivateKey, error) {
+	pk, err := ecdsa.GenerateKey(keyTypeECCCurves[kt], rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating key: %w", err)
+	}
+	return pk, nil
+}
+
+func generateSECGKey() (*ecdsa.PrivateKey, error) {
+	pk, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("generating key: %w", err)
+	}
+	return pk.ToECDSA(), nil
+}
+
+var keyTypeRSABits = map[KeyType]int{
+	KeyTypeRSA2048: 2048,
+}
+
+func generateRSAKey(kt KeyType) (*rsa.PrivateKey, error) {
+	pk, err := rsa.GenerateKey(rand.Reader, keyTypeRSABits[kt])
+	if err != nil {
+		return nil, fmt.Errorf("generating key: %v", err)
+	}
+	return pk, nil
+}
+
+// signingKey is implemented by key types whose support depends on the Go
+// version the package is built with.
+type signingKey interface {
+	sign(in *kms.SignInput) (*kms.SignOutput, error)
+	verify(in *kms.VerifyInput) (*kms.VerifyOutput, error)
+	marshalPublicKey() ([]byte, error)
+}
+
+// hmacKey is a symmetric key usable with GenerateMac and VerifyMac.
+type hmacKey struct {
+	algorithm types.MacAlgorithmSpec
+	hash      crypto.Hash
+	secret    []byte
+}
+
+var keyTypeHMACAlgorithms = map[KeyType]types.MacAlgorithmSpec{
+	KeyTypeHMAC256: types.MacAlgorithmSpecHmacSha256,
+	KeyTypeHMAC384: types.MacAlgorithmSpecHmacSha384,
+	KeyTypeHMAC512: types.MacAlgorithmSpecHmacSha512,
+}
+
+var macHashAlgorithms = map[types.MacAlgorithmSpec]crypto.Hash{
+	types.MacAlgorithmSpecHmacSha256: crypto.SHA256,
+	types.MacAlgorithmSpecHmacSha384: crypto.SHA384,
+	types.MacAlgorithmSpecHmacSha512: crypto.SHA512,
+}
+
+func generateHMACKey(kt KeyType) (*hmacKey, error) {
+	algorithm := keyTypeHMACAlgorithms[kt]
+	hash := macHashAlgorithms[algorithm]
+
+	secret := make([]byte, hash.Size())
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("generating key: %w", err)
+	}
+	return &hmacKey{algorithm: algorithm, hash: hash, secret: secret}, nil
+}
+
+func (k *MockKMS) getKey(id string) (interface{}, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	id = strings.TrimPrefix(id, ARNPrefix)
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, &types.NotFoundException{Message: aws.String(fmt.Sprintf("no such key: %v", id))}
+	}
+	if k.disabled[id] {
+		return nil, &types.DisabledException{Message: aws.String(fmt.Sprintf("key %v is disabled", id))}
+	}
+	if state, ok := k.states[id]; ok && state != types.KeyStateEnabled {
+		return nil, &types.KMSInvalidStateException{Message: aws.String(fmt.Sprintf("key %v is %v", id, state))}
+	}
+	return key, nil
+}
+
+func (k *MockKMS) Sign(_ context.Context, in *kms.SignInput, _ ...func(*kms.Options)) (*kms.SignOutput, error) {
+	key, err := k.getKey(*in.KeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	if aws.ToBool(in.DryRun) {
+		return nil, k.dryRun(*in.KeyId, in.SigningAlgorithm)
+	}
+
+	if _, ok := key.(signingKey); !ok && in.MessageType != types.MessageTypeDigest {
+		if in.MessageType != types.MessageTypeRaw {
+			return nil, fmt.Errorf("unsupported message type: %v", in.MessageType)
+		}
+
+		if in, err = digestRawMessage(in); err != nil {
+			return nil, err
+		}
+	}
+
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		return signECSDA(key, in)
+
+	case *rsa.PrivateKey:
+		return signRSAorPSS(key, in)
+
+	case signingKey:
+		return key.sign(in)
+
+	case *hmacKey:
+		return nil, &types.InvalidKeyUsageException{Message: aws.String("key is not a signing key")}
+
+	default:
+		panic("unreachable")
+	}
+}
+
+var ecdsaSigningAlgorithms = map[types.SigningAlgorithmSpec]bool{
+	types.SigningAlgorithmSpecEcdsaSha256: true,
+	types.SigningAlgorithmSpecEcdsaSha384: true,
+	types.SigningAlgorithmSpecEcdsaSha512: true,
+}
+
+// digestRawMessage returns a copy of in with its RAW message replaced by the digest KMS signs for it.
+func digestRawMessage(in *kms.SignInput) (*kms.SignInput, error) {
+	if len(in.Message) > 4096 {
+		return nil, errors.New("raw message is larger than 4096 bytes")
+	}
+
+	var hash crypto.Hash
+	switch in.SigningAlgorithm {
+	case types.SigningAlgorithmSpecEcdsaSha256:
+		hash = crypto.SHA256
+	case types.SigningAlgorithmSpecEcdsaSha384:
+		hash = crypto.SHA384
+	case types.SigningAlgorithmSpecEcdsaSha512:
+		hash = crypto.SHA512
+	default:
+		var ok bool
+		if hash, ok = rsaHashAlgorithms[in.SigningAlgorithm]; !ok {
+			if hash, ok = pssHashAlgorithms[in.SigningAlgorithm]; !ok {
+				return nil, validationError(in.SigningAlgorithm)
+			}
+		}
+	}
+
+	hasher := hash.New()
+	hasher.Write(in.Message)
+
+	digested := *in
+	digested.Message = hasher.Sum(nil)
+	digested.MessageType = types.MessageTypeDigest
+
+	return &digested, nil
+}
+
+func signECSDA(key *ecdsa.PrivateKey, in *kms.SignInput) (*kms.SignOutput, error) {
+	if !ecdsaSigningAlgorithms[in.SigningAlgorithm] {
+		return nil, validationError(in.SigningAlgorithm)
+	}
+
+	sig, err := key.Sign(rand.Reader, in.Message, nil)
+	if err != nil {
+		return nil, fmt.Errorf("signing message: %w", err)
+	}
+
+	return &kms.SignOutput{
+		Signature: sig,
+	}, nil
+}
+
+// validationError is the error KMS answers Sign calls with a signing algorithm
+// the key does not support with.
+func validationError(algorithm types.SigningAlgorithmSpec) error {
+	return &smithy.GenericAPIError{
+		Code:    "ValidationException",
+		Message: fmt.Sprintf("signing algorithm %v is not valid for the key", algorithm),
+	}
+}
+
+var rsaHashAlgorithms = map[types.SigningAlgorithmSpec]crypto.Hash{
+	types.SigningAlgorithmSpecRsassaPkcs1V15Sha256: crypto.SHA256,
+	types.SigningAlgorithmSpecRsassaPkcs1V15Sha384: crypto.SHA384,
+	types.SigningAlgorithmSpecRsassaPkcs1V15Sha512: crypto.SHA512,
+}
+
+var pssHashAlgorithms = map[types.SigningAlgorithmSpec]crypto.Hash{
+	types.SigningAlgorithmSpecRsassaPssSha256: crypto.SHA256,
+	types.SigningAlgorithmSpecRsassaPssSha384: crypto.SHA384,
+	types.SigningAlgorithmSpecRsassaPssSha512: crypto.SHA512,
+}
+
+func signRSAorPSS(key *rsa.PrivateKey, in *kms.SignInput) (*kms.SignOutput, error) {
+	// test if the algorithm is PSS, else use rss
+	_, ok := pssHashAlgorithms[in.SigningAlgorithm]
+	if ok {
+		return signPSS(key, in)
+	}
+
+	return signRSA(key, in)
+}
+
+func signRSA(key *rsa.PrivateKey, in *kms.SignInput) (*kms.SignOutput, error) {
+	hash, ok := rsaHashAlgorithms[in.SigningAlgorithm]
+	if !ok {
+		return nil, validationError(in.SigningAlgorithm)
+	}
+
+	// PS 512 expect message to be hashed
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, hash, in.Message)
+	if err != nil {
+		return nil, fmt.Errorf("signing message: %w", err)
+	}
+
+	return &kms.SignOutput{
+		Signature: sig,
+	}, nil
+}
+
+func signPSS(key *rsa.PrivateKey, in *kms.SignInput) (*kms.SignOutput, error) {
+	hash, ok := pssHashAlgorithms[in.SigningAlgorithm]
+	if !ok {
+		return nil, validationError(in.SigningAlgorithm)
+	}
+
+	// KMS uses a salt as long as the digest
+	sig, err := rsa.SignPSS(rand.Reader, key, hash, in.Message, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	if err != nil {
+		return nil, fmt.Errorf("signing message: %w", err)
+	}
+
+	return &kms.SignOutput{
+		Signature: sig,
+	}, nil
+}
+
+// Verify verifies a signature. Like KMS it fails with a
+// KMSInvalidSignatureException instead of reporting an invalid signature in
+// the output.
+func (k *MockKMS) Verify(ctx context.Context, in *kms.VerifyInput, optFns ...func(*kms.Options)) (*kms.VerifyOutput, error) {
+	out, err := k.verify(in)
+	if err != nil {
+		return nil, err
+	}
+	if !out.SignatureValid {
+		return nil, &types.KMSInvalidSignatureException{Message: aws.String("invalid signature")}
+	}
+	return out, nil
+}
+
+func (k *MockKMS) verify(in *kms.VerifyInput) (*kms.VerifyOutput, error) {
+	key, err := k.getKey(*in.KeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	if aws.ToBool(in.DryRun) {
+		return nil, k.dryRun(*in.KeyId, in.SigningAlgorithm)
+	}
+
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		return &kms.VerifyOutput{
+			SignatureValid: ecdsa.VerifyASN1(&key.PublicKey, in.Message, in.Signature),
+		}, nil
+
+	case *rsa.PrivateKey:
+		return verifyRSAorPSS(key, in)
+
+	case signingKey:
+		return key.verify(in)
+
+	case *hmacKey:
+		return nil, &types.InvalidKeyUsageException{Message: aws.String("key is not a signing key")}
+
+	default:
+		panic("unreachable")
+	}
+}
+
+func verifyRSAorPSS(key *rsa.PrivateKey, in *kms.VerifyInput) (*kms.VerifyOutput, error) {
+	// test if the algorithm is PSS, else use rss
+	_, ok := pssHashAlgorithms[in.SigningAlgorithm]
+	if ok {
+		return verifyPSS(key, in)
+	}
+
+	return verifyRSA(key, in)
+}
+
+func verifyRSA(key *rsa.PrivateKey, in *kms.VerifyInput) (*kms.VerifyOutput, error) {
+	hash, ok := rsaHashAlgorithms[in.SigningAlgorithm]
+	if !ok {
+		return nil, validationError(in.SigningAlgorithm)
+	}
+
+	err := rsa.VerifyPKCS1v15(&key.PublicKey, hash, in.Message, in.Signature)
+
+	return &kms.VerifyOutput{
+		SignatureValid: err == nil,
+	}, nil
+}
+
+func verifyPSS(key *rsa.PrivateKey, in *kms.VerifyInput) (*kms.VerifyOutput, error) {
+	hash, ok := pssHashAlgorithms[in.SigningAlgorithm]
+	if !ok {
+		return nil, validationError(in.SigningAlgorithm)
+	}
+
+	err := rsa.VerifyPSS(&key.PublicKey, hash, in.Message, in.Signature, &rsa.PSSOptions{})
+
+	return &kms.VerifyOutput{
+		SignatureValid: err == nil,
+	}, nil
+}
+
+func (k *MockKMS) GetPublicKey(_ context.Context, in *kms.GetPublicKeyInput, _ ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error) {
+	key, err := k.getKey(*in.KeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	var m []byte
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		if key.Curve == secp256k1.S256() {
+			m, err = marshalSECGPublicKey(key)
+		} else {
+			m, err = x509.MarshalPKIXPublicKey(&key.PublicKey)
+		}
+
+	case *rsa.PrivateKey:
+		m, err = x509.MarshalPKIXPublicKey(&key.PublicKey)
+
+	case signingKey:
+		m, err = key.marshalPublicKey()
+
+	case *hmacKey:
+		return nil, &types.UnsupportedOperationException{Message: aws.String("key is symmetric")}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("marshalling public key: %w", err)
+	}
+
+	id := strings.TrimPrefix(*in.KeyId, ARNPrefix)
+
+	k.mu.Lock()
+	kt := k.keyTypes[id]
+	k.mu.Unlock()
+
+	return &kms.GetPublicKeyOutput{
+		KeyId:             aws.String(KeyARN(id)),
+		KeySpec:           keyTypeKeySpecs[kt],
+		KeyUsage:          types.KeyUsageTypeSignVerify,
+		PublicKey:         m,
+		SigningAlgorithms: keyTypeSigningAlgorithms[kt],
+	}, nil
+}
+
+// dryRun returns the error of a dry run signing or verifying with the key id and
+// algorithm: a DryRunOperationException if the request would have succeeded,
+// like KMS does.
+func (k *MockKMS) dryRun(id string, algorithm types.SigningAlgorithmSpec) error {
+	k.mu.Lock()
+	kt := k.keyTypes[strings.TrimPrefix(id, ARNPrefix)]
+	k.mu.Unlock()
+
+	if !slices.Contains(keyTypeSigningAlgorithms[kt], algorithm) {
+		return &types.InvalidKeyUsageException{Message: aws.String(fmt.Sprintf("key does not support %v", algorithm))}
+	}
+
+	return &types.DryRunOperationException{Message: aws.String("request would have succeeded")}
+}
+
+var keyTypeKeySpecs = map[KeyType]types.KeySpec{
+	KeyTypeECCNISTP256:   types.KeySpecEccNistP256,
+	KeyTypeECCNISTP384:   types.KeySpecEccNistP384,
+	KeyTypeECCNISTP521:   types.KeySpecEccNistP521,
+	KeyTypeECCSECGP256K1: types.KeySpecEccSecgP256k1,
+	KeyTypeRSA2048:       types.KeySpecRsa2048,
+	KeyTypeHMAC256:       types.KeySpecHmac256,
+	KeyTypeHMAC384:       types.KeySpecHmac384,
+	KeyTypeHMAC512:       types.KeySpecHmac512,
+	KeyTypeMLDSA44:       types.KeySpecMlDsa44,
+	KeyTypeMLDSA65:       types.KeySpecMlDsa65,
+	KeyTypeMLDSA87:       types.KeySpecMlDsa87,
+	KeyTypeSM2:           types.KeySpecSm2,
+}
+
+var keyTypeSigningAlgorithms = map[KeyType][]types.SigningAlgorithmSpec{
+	KeyTypeECCNISTP256:   {types.SigningAlgorithmSpecEcdsaSha256},
+	KeyTypeECCNISTP384:   {types.SigningAlgorithmSpecEcdsaSha384},
+	KeyTypeECCNISTP521:   {types.SigningAlgorithmSpecEcdsaSha512},
+	KeyTypeECCSECGP256K1: {types.SigningAlgorithmSpecEcdsaSha256},
+	KeyTypeRSA2048: {
+		types.SigningAlgorithmSpecRsassaPkcs1V15Sha256,
+		types.SigningAlgorithmSpecRsassaPkcs1V15Sha384,
+		types.SigningAlgorithmSpecRsassaPkcs1V15Sha512,
+		types.SigningAlgorithmSpecRsassaPssSha256,
+		types.SigningAlgorithmSpecRsassaPssSha384,
+		types.SigningAlgorithmSpecRsassaPssSha512,
+	},
+	KeyTypeMLDSA44: {types.SigningAlgorithmSpecMlDsaShake256},
+	KeyTypeMLDSA65: {types.SigningAlgorithmSpecMlDsaShake256},
+	KeyTypeMLDSA87: {types.SigningAlgorithmSpecMlDsaShake256},
+	KeyTypeSM2:     {types.SigningAlgorithmSpecSm2dsa},
+}
+
+func (k *MockKMS) getHMACKey(id string, algorithm types.MacAlgorithmSpec) (*hmacKey, error) {
+	key, err := k.getKey(id)
+	if err != nil {
+		return nil, err
+	}
+
+	hk, ok := key.(*hmacKey)
+	if !ok {
+		return nil, &types.InvalidKeyUsageException{Message: aws.String("key is not an HMAC key")}
+	}
+
+	if hk.algorithm != algorithm {
+		return nil, &types.InvalidKeyUsageException{Message: aws.String(fmt.Sprintf("key does not support %v", algorithm))}
+	}
+
+	return hk, nil
+}
+
+func (k *MockKMS) GenerateMac(_ context.Context, in *kms.GenerateMacInput, _ ...func(*kms.Options)) (*kms.GenerateMacOutput, error) {
+	key, err := k.getHMACKey(*in.KeyId, in.MacAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if aws.ToBool(in.DryRun) {
+		return nil, &types.DryRunOperationException{Message: aws.String("request would have succeeded")}
+	}
+
+	mac := hmac.New(key.hash.New, key.secret)
+	mac.Write(in.Message) //nolint:errcheck
+
+	return &kms.GenerateMacOutput{
+		KeyId:        in.KeyId,
+		Mac:          mac.Sum(nil),
+		MacAlgorithm: in.MacAlgorithm,
+	}, nil
+}
+
+func (k *MockKMS) VerifyMac(_ context.Context, in *kms.VerifyMacInput, _ ...func(*kms.Options)) (*kms.VerifyMacOutput, error) {
+	key, err := k.getHMACKey(*in.KeyId, in.MacAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if aws.ToBool(in.DryRun) {
+		return nil, &types.DryRunOperationException{Message: aws.String("request would have succeeded")}
+	}
+
+	mac := hmac.New(key.hash.New, key.secret)
+	mac.Write(in.Message) //nolint:errcheck
+
+	// KMS reports a mismatching MAC as an error rather than MacValid=false
+	if !hmac.Equal(mac.Sum(nil), in.Mac) {
+		return nil, &types.KMSInvalidMacException{Message: aws.String("invalid mac")}
+	}
+
+	return &kms.VerifyMacOutput{
+		KeyId:        in.KeyId,
+		MacAlgorithm: in.MacAlgorithm,
+		MacValid:     true,
+	}, nil
+}
+
+var (
+	oidPublicKeyECDSA  = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidNamedCurveP256K = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// marshalSECGPublicKey marshals a secp256k1 public key by hand, since x509
+// does not support the curve.
+func marshalSECGPublicKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	var x, y secp256k1.FieldVal
+	x.SetByteSlice(key.X.Bytes())
+	y.SetByteSlice(key.Y.Bytes())
+
+	params, err := asn1.Marshal(oidNamedCurveP256K)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling curve: %w", err)
+	}
+
+	point := secp256k1.NewPublicKey(&x, &y).SerializeUncompressed()
+	m, err := asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeyECDSA,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		PublicKey: asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling public key: %w", err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,60 @@
+package mockkms
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/emmansun/gmsm/sm2"
+	"github.com/emmansun/gmsm/smx509"
+)
+
+type sm2Key struct {
+	*sm2.PrivateKey
+}
+
+func generateSM2Key() (signingKey, error) {
+	pk, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating key: %w", err)
+	}
+	return sm2Key{pk}, nil
+}
+
+func (k sm2Key) sign(in *kms.SignInput) (*kms.SignOutput, error) {
+	if in.SigningAlgorithm != types.SigningAlgorithmSpecSm2dsa {
+		return nil, fmt.Errorf("unknown signing algorithm: %v", in.SigningAlgorithm)
+	}
+
+	if in.MessageType != types.MessageTypeDigest {
+		return nil, fmt.Errorf("unsupported message type: %v", in.MessageType)
+	}
+
+	sig, err := sm2.SignASN1(rand.Reader, k.PrivateKey, in.Message, nil)
+	if err != nil {
+		return nil, fmt.Errorf("signing message: %w", err)
+	}
+
+	return &kms.SignOutput{
+		Signature: sig,
+	}, nil
+}
+
+func (k sm2Key) verify(in *kms.VerifyInput) (*kms.VerifyOutput, error) {
+	if in.SigningAlgorithm != types.SigningAlgorithmSpecSm2dsa {
+		return nil, fmt.Errorf("unknown signing algorithm: %v", in.SigningAlgorithm)
+	}
+
+	if in.MessageType != types.MessageTypeDigest {
+		return nil, fmt.Errorf("unsupported message type: %v", in.MessageType)
+	}
+
+	return &kms.VerifyOutput{
+		SignatureValid: sm2.VerifyASN1(&k.PublicKey, in.Message, in.Signature),
+	}, nil
+}
+
+func (k sm2Key) marshalPublicKey() ([]byte, error) {
+	return smx509.MarshalPKIXPublicKey(&k.PublicKey)
+}
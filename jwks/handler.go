@@ -0,0 +1,175 @@
+package jwks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+// WellKnownPath is the conventional path a JWK Set is published at.
+const WellKnownPath = "/.well-known/jwks.json"
+
+const (
+	defaultCacheControl    = "public, max-age=300"
+	defaultRefreshInterval = 5 * time.Minute
+)
+
+// Handler is an http.Handler serving the JWK Set of a list of KMS keys.
+//
+// The set is generated when the Handler is created and regenerated in the background every refresh interval,
+// a failed refresh keeps serving the last good set.
+type Handler struct {
+	cfg             *jwtkms.Config
+	keys            []Key
+	cacheControl    string
+	refreshInterval time.Duration
+	errorHandler    func(error)
+
+	mu   sync.RWMutex
+	body []byte
+}
+
+// HandlerOption configures a Handler.
+type HandlerOption func(*Handler)
+
+// WithCacheControl sets the Cache-Control header of the responses, "public, max-age=300" by default.
+// An empty value omits the header.
+func WithCacheControl(cacheControl string) HandlerOption {
+	return func(h *Handler) {
+		h.cacheControl = cacheControl
+	}
+}
+
+// WithRefreshInterval sets how often the JWK Set is regenerated from KMS, 5 minutes by default.
+// Zero or a negative value disables the background refresh.
+func WithRefreshInterval(interval time.Duration) HandlerOption {
+	return func(h *Handler) {
+		h.refreshInterval = interval
+	}
+}
+
+// WithErrorHandler sets a function called with the error of every failed background refresh.
+func WithErrorHandler(f func(error)) HandlerOption {
+	return func(h *Handler) {
+		h.errorHandler = f
+	}
+}
+
+// NewHandler generates the JWK Set of keys and returns a Handler serving it.
+// The background refresh runs until ctx is done.
+//
+//	h, err := jwks.NewHandler(ctx, kmsClient, []jwks.Key{{ID: "alias/signing-key"}})
+//	http.Handle(jwks.WellKnownPath, h)
+func NewHandler(ctx context.Context, client jwtkms.KMSClient, keys []Key, opts ...HandlerOption) (*Handler, error) {
+	return NewConfigHandler(ctx, jwtkms.NewConfig(client, ""), keys, opts...)
+}
+
+// NewConfigHandler is like NewHandler but fetches the public keys through cfg, see GenerateWithConfig.
+func NewConfigHandler(ctx context.Context, cfg *jwtkms.Config, keys []Key, opts ...HandlerOption) (*Handler, error) {
+	h := &Handler{
+		cfg:             cfg,
+		keys:            keys,
+		cacheControl:    defaultCacheControl,
+		refreshInterval: defaultRefreshInterval,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if err := h.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	if h.refreshInterval > 0 {
+		go h.refreshLoop(ctx)
+	}
+
+	return h, nil
+}
+
+// Refresh regenerates the JWK Set from KMS, fetching the public keys again instead of serving them from the cache of
+// the Config, see Config.RefreshPublicKeys. If a key fails to refresh, the Handler keeps serving the last good set and
+// the Config its cached keys.
+func (h *Handler) Refresh(ctx context.Context) error {
+	keys := make([]Key, len(h.keys))
+	keyIDs := make([]string, len(h.keys))
+	for i, key := range h.keys {
+		if key.ID == "" {
+			key.ID = h.cfg.KeyID()
+		}
+
+		keys[i], keyIDs[i] = key, key.ID
+	}
+
+	pubs, err := h.cfg.RefreshPublicKeys(ctx, keyIDs...)
+	if err != nil {
+		return fmt.Errorf("refreshing jwk set: %w", err)
+	}
+
+	set := &Set{Keys: make([]jwtkms.JWK, 0, len(keys))}
+	for i, key := range keys {
+		jwk, err := newJWK(key, pubs[i])
+		if err != nil {
+			return fmt.Errorf("generating jwk for %s: %w", key.ID, err)
+		}
+
+		set.Keys = append(set.Keys, *jwk)
+	}
+
+	body, err := json.Marshal(set)
+	if err != nil {
+		return fmt.Errorf("marshalling jwk set: %w", err)
+	}
+
+	h.mu.Lock()
+	h.body = body
+	h.mu.Unlock()
+
+	return nil
+}
+
+func (h *Handler) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(h.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := h.Refresh(ctx); err != nil && h.errorHandler != nil && ctx.Err() == nil {
+				h.errorHandler(err)
+			}
+		}
+	}
+}
+
+// ServeHTTP writes the JWK Set for GET and HEAD requests.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.mu.RLock()
+	body := h.body
+	h.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/jwk-set+json")
+	if h.cacheControl != "" {
+		w.Header().Set("Cache-Control", h.cacheControl)
+	}
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,156 @@
+package jwks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/smithy-go"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+type countingKMS struct {
+	*mockkms.MockKMS
+	getPublicKeyCalls atomic.Int32
+}
+
+func (c *countingKMS) GetPublicKey(ctx context.Context, in *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error) {
+	c.getPublicKeyCalls.Add(1)
+	return c.MockKMS.GetPublicKey(ctx, in, optFns...)
+}
+
+func TestHandler(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	h, err := NewHandler(context.Background(), client, []Key{{ID: id, KID: "key-1"}},
+		WithCacheControl("public, max-age=60"), WithRefreshInterval(0))
+	if err != nil {
+		t.Fatalf("Error creating handler: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, WellKnownPath, nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if got := rec.Header().Get("Content-Type"); got != "application/jwk-set+json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	if got := rec.Header().Get("Cache-Control"); got != "public, max-age=60" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+
+	var set Set
+	if err := json.Unmarshal(rec.Body.Bytes(), &set); err != nil {
+		t.Fatalf("Error unmarshalling JWK Set: %v", err)
+	}
+
+	if len(set.Keys) != 1 || set.Keys[0].KeyID != "key-1" {
+		t.Errorf("unexpected JWK Set %+v", set)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, WellKnownPath, nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandlerRefresh(t *testing.T) {
+	client := &countingKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := NewHandler(ctx, client, []Key{{ID: id}}, WithRefreshInterval(10*time.Millisecond)); err != nil {
+		t.Fatalf("Error creating handler: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for client.getPublicKeyCalls.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("JWK Set was not refreshed, %d GetPublicKey calls", client.getPublicKeyCalls.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestNewHandlerError(t *testing.T) {
+	if _, err := NewHandler(context.Background(), mockkms.NewMockKMS(), []Key{{ID: "missing"}}); err == nil {
+		t.Errorf("expected error for unknown key")
+	}
+}
+
+// failingKMS fails GetPublicKey while fail is set.
+type failingKMS struct {
+	countingKMS
+	fail atomic.Bool
+}
+
+func (c *failingKMS) GetPublicKey(ctx context.Context, in *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error) {
+	if c.fail.Load() {
+		c.getPublicKeyCalls.Add(1)
+		return nil, &smithy.GenericAPIError{Code: "KMSInternalException", Message: "internal error"}
+	}
+
+	return c.countingKMS.GetPublicKey(ctx, in, optFns...)
+}
+
+func TestHandlerRefreshError(t *testing.T) {
+	client := &failingKMS{countingKMS: countingKMS{MockKMS: mockkms.NewMockKMS()}}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := jwtkms.NewConfig(client, id)
+	h, err := NewConfigHandler(context.Background(), cfg, []Key{{KID: "key-1"}}, WithRefreshInterval(0))
+	if err != nil {
+		t.Fatalf("Error creating handler: %v", err)
+	}
+
+	client.fail.Store(true)
+	if err := h.Refresh(context.Background()); err == nil {
+		t.Fatalf("expected error refreshing while KMS fails")
+	}
+
+	// the Config keeps its cached key and the handler the last good set
+	calls := client.getPublicKeyCalls.Load()
+	if _, err := cfg.PublicKey(context.Background(), id); err != nil {
+		t.Errorf("Error getting cached public key: %v", err)
+	}
+
+	if n := client.getPublicKeyCalls.Load(); n != calls {
+		t.Errorf("GetPublicKey calls = %d, want %d", n, calls)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, WellKnownPath, nil))
+
+	var set Set
+	if err := json.Unmarshal(rec.Body.Bytes(), &set); err != nil {
+		t.Fatalf("Error unmarshalling JWK Set: %v", err)
+	}
+
+	if len(set.Keys) != 1 || set.Keys[0].KeyID != "key-1" {
+		t.Errorf("unexpected JWK Set %+v", set)
+	}
+}
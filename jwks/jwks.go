@@ -0,0 +1,105 @@
+// Package jwks builds RFC 7517 JWK Sets from the public keys of KMS asymmetric keys, so relying parties can verify
+// tokens signed with jwtkms without access to AWS.
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+// Set is a JWK Set, RFC 7517 section 5.
+type Set struct {
+	Keys []jwtkms.JWK `json:"keys"`
+}
+
+// Key identifies a KMS key to include in a Set.
+type Key struct {
+	// ID is the key id, key ARN, alias name or alias ARN passed to GetPublicKey, the key id of the Config when empty.
+	ID string
+
+	// KID is the kid of the JWK, the key ARN returned by KMS when empty, or ID for public keys provided to the Config
+	// without one.
+	KID string
+
+	// Thumbprint uses the RFC 7638 SHA-256 thumbprint of the key as kid when KID is empty, matching the kid of
+	// tokens signed with a Config created with WithThumbprintKeyIDHeader.
+	Thumbprint bool
+
+	// Alg is the alg of the JWK. When empty it is derived from the key spec and signing algorithms of keys supporting
+	// a single alg, see jwtkms.AlgorithmsForKeySpec, and omitted for RSA keys, since those can be used with both the
+	// RS and PS families.
+	Alg string
+}
+
+// Generate fetches the public key of every key with client and returns them as a JWK Set, in the order given.
+func Generate(ctx context.Context, client jwtkms.KMSClient, keys ...Key) (*Set, error) {
+	return GenerateWithConfig(ctx, jwtkms.NewConfig(client, ""), keys...)
+}
+
+// GenerateWithConfig returns the public key of every key as a JWK Set, in the order given. The public keys are taken
+// from cfg, see Config.PublicKey: from its cache, or fetched with the retries, circuit breaker, call timeout and
+// error mapping of cfg.
+func GenerateWithConfig(ctx context.Context, cfg *jwtkms.Config, keys ...Key) (*Set, error) {
+	set := &Set{Keys: make([]jwtkms.JWK, 0, len(keys))}
+
+	for _, key := range keys {
+		if key.ID == "" {
+			key.ID = cfg.KeyID()
+		}
+
+		jwk, err := generateJWK(ctx, cfg, key)
+		if err != nil {
+			return nil, fmt.Errorf("generating jwk for %s: %w", key.ID, err)
+		}
+
+		set.Keys = append(set.Keys, *jwk)
+	}
+
+	return set, nil
+}
+
+func generateJWK(ctx context.Context, cfg *jwtkms.Config, key Key) (*jwtkms.JWK, error) {
+	cached, err := cfg.PublicKey(ctx, key.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newJWK(key, cached)
+}
+
+// newJWK returns the JWK of key with its public key cached.
+func newJWK(key Key, cached *jwtkms.CachedPublicKey) (*jwtkms.JWK, error) {
+	jwk, err := jwtkms.NewJWK(cached.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	jwk.Use = "sig"
+
+	switch {
+	case key.KID != "":
+		jwk.KeyID = key.KID
+	case key.Thumbprint:
+		sum, err := jwk.Thumbprint(crypto.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("computing thumbprint: %w", err)
+		}
+
+		jwk.KeyID = base64.RawURLEncoding.EncodeToString(sum)
+	case cached.KeyARN != "":
+		jwk.KeyID = cached.KeyARN
+	default:
+		jwk.KeyID = key.ID
+	}
+
+	jwk.Algorithm = key.Alg
+	if algs := jwtkms.AlgorithmsForKeySpec(cached.KeySpec, cached.SigningAlgorithms); jwk.Algorithm == "" && len(algs) == 1 {
+		jwk.Algorithm = algs[0]
+	}
+
+	return jwk, nil
+}
@@ -0,0 +1,183 @@
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		key     Key
+		wantKty string
+		wantCrv string
+		wantAlg string
+	}{
+		{name: "P256", keyType: mockkms.KeyTypeECCNISTP256, wantKty: "EC", wantCrv: "P-256", wantAlg: "ES256"},
+		{name: "P384", keyType: mockkms.KeyTypeECCNISTP384, wantKty: "EC", wantCrv: "P-384", wantAlg: "ES384"},
+		{name: "P521", keyType: mockkms.KeyTypeECCNISTP521, wantKty: "EC", wantCrv: "P-521", wantAlg: "ES512"},
+		{name: "SECP256K1", keyType: mockkms.KeyTypeECCSECGP256K1, wantKty: "EC", wantCrv: "secp256k1", wantAlg: "ES256K"},
+		{name: "RSA", keyType: mockkms.KeyTypeRSA2048, wantKty: "RSA"},
+		{name: "RSA with alg", keyType: mockkms.KeyTypeRSA2048, key: Key{KID: "rsa-1", Alg: "PS256"}, wantKty: "RSA", wantAlg: "PS256"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kms := mockkms.NewMockKMS()
+			id, err := kms.GenerateKey(test.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			key := test.key
+			key.ID = id
+
+			set, err := Generate(context.Background(), kms, key)
+			if err != nil {
+				t.Fatalf("Error generating JWK Set: %v", err)
+			}
+
+			if len(set.Keys) != 1 {
+				t.Fatalf("len(Keys) = %d, want 1", len(set.Keys))
+			}
+
+			jwk := set.Keys[0]
+
+			wantKID := key.KID
+			if wantKID == "" {
+				wantKID = mockkms.KeyARN(id)
+			}
+
+			if jwk.KeyType != test.wantKty || jwk.Curve != test.wantCrv || jwk.Algorithm != test.wantAlg ||
+				jwk.KeyID != wantKID || jwk.Use != "sig" {
+				t.Errorf("got kty=%q crv=%q alg=%q kid=%q use=%q", jwk.KeyType, jwk.Curve, jwk.Algorithm, jwk.KeyID, jwk.Use)
+			}
+
+			pub, err := jwk.PublicKey()
+			if err != nil {
+				t.Fatalf("Error decoding JWK: %v", err)
+			}
+
+			signer, err := jwtkms.NewKMSSigner(jwtkms.NewKMSConfig(kms, id, false))
+			if err != nil {
+				t.Fatalf("Error creating signer: %v", err)
+			}
+
+			if !pub.(interface{ Equal(x crypto.PublicKey) bool }).Equal(signer.Public()) {
+				t.Errorf("decoded public key does not match KMS public key")
+			}
+		})
+	}
+}
+
+func TestGenerateJSON(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	ecID, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	rsaID, err := kms.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	set, err := Generate(context.Background(), kms, Key{ID: ecID, KID: "ec"}, Key{ID: rsaID, KID: "rsa", Alg: "RS256"})
+	if err != nil {
+		t.Fatalf("Error generating JWK Set: %v", err)
+	}
+
+	b, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("Error marshalling JWK Set: %v", err)
+	}
+
+	var decoded Set
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Error unmarshalling JWK Set: %v", err)
+	}
+
+	ecPub, err := decoded.Keys[0].PublicKey()
+	if err != nil {
+		t.Fatalf("Error decoding JWK: %v", err)
+	}
+
+	if _, ok := ecPub.(*ecdsa.PublicKey); !ok {
+		t.Errorf("Keys[0] is %T, want *ecdsa.PublicKey", ecPub)
+	}
+
+	rsaPub, err := decoded.Keys[1].PublicKey()
+	if err != nil {
+		t.Fatalf("Error decoding JWK: %v", err)
+	}
+
+	if _, ok := rsaPub.(*rsa.PublicKey); !ok {
+		t.Errorf("Keys[1] is %T, want *rsa.PublicKey", rsaPub)
+	}
+}
+
+func TestGenerateThumbprintKID(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP384)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	set, err := Generate(context.Background(), kms, Key{ID: id, Thumbprint: true})
+	if err != nil {
+		t.Fatalf("Error generating JWK Set: %v", err)
+	}
+
+	signed, err := jwtkms.NewKMSConfig(kms, id, false).WithThumbprintKeyIDHeader().SignedString(jwt.New(jwtkms.SigningMethodECDSA384))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	token, _, err := jwt.NewParser().ParseUnverified(signed, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("Error parsing token: %v", err)
+	}
+
+	if kid := token.Header["kid"]; kid != set.Keys[0].KeyID {
+		t.Errorf("token kid = %v, JWK kid = %s", kid, set.Keys[0].KeyID)
+	}
+}
+
+func TestGenerateWithConfig(t *testing.T) {
+	client := &countingKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := jwtkms.NewConfig(client, id)
+
+	// the key without ID is the key of the Config, fetched once and then served from its cache
+	for i := 0; i < 2; i++ {
+		set, err := GenerateWithConfig(context.Background(), cfg, Key{KID: "signing-key"})
+		if err != nil {
+			t.Fatalf("Error generating JWK Set: %v", err)
+		}
+
+		if len(set.Keys) != 1 || set.Keys[0].KeyID != "signing-key" || set.Keys[0].Algorithm != "ES256" {
+			t.Errorf("unexpected JWK Set %+v", set)
+		}
+	}
+
+	if n := client.getPublicKeyCalls.Load(); n != 1 {
+		t.Errorf("GetPublicKey calls = %d, want 1", n)
+	}
+
+	if _, err := GenerateWithConfig(context.Background(), cfg, Key{ID: "missing"}); !errors.Is(err, jwtkms.ErrKeyNotFound) {
+		t.Errorf("err = %v, want %v", err, jwtkms.ErrKeyNotFound)
+	}
+}
@@ -0,0 +1,50 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// SupportedAlgorithms returns the sorted JOSE algs of the signing methods of this package the KMS key keyID signs
+// with, e.g. for the alg of a JWK or to pick a signing method, using ctx for the KMS calls. The algs are mapped from
+// the key spec and signing algorithms of the public key, provided or fetched with GetPublicKey and cached like for
+// verification; HMAC keys, which have none, are inspected with DescribeKey and support the HS* alg of their size.
+func (c *Config) SupportedAlgorithms(ctx context.Context, keyID string) ([]string, error) {
+	cfg := c.WithContext(ctx).WithKeyID(keyID)
+
+	algs, err := cfg.keyAlgorithms()
+
+	var unsupported *types.UnsupportedOperationException
+	if errors.As(err, &unsupported) {
+		method, err := cfg.macSigningMethod()
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{method.Alg()}, nil
+	}
+
+	return algs, err
+}
+
+// AlgorithmsForKeySpec returns the sorted JOSE algs of the signing methods of this package a KMS key of keySpec
+// signing with algorithms supports, e.g. ES256K for an ECC_SECG_P256K1 key with ECDSA_SHA_256. An empty keySpec
+// matches every key spec.
+func AlgorithmsForKeySpec(keySpec types.KeySpec, algorithms []types.SigningAlgorithmSpec) []string {
+	var algs []string
+	for _, alg := range Algorithms() {
+		m, ok := GetSigningMethod(alg).(kmsSigningMethod)
+		if !ok || m.kmsSigningAlgorithm() == "" || keySpec != "" && !m.supportsKeySpec(keySpec) {
+			continue
+		}
+
+		if slices.Contains(algorithms, m.kmsSigningAlgorithm()) {
+			algs = append(algs, alg)
+		}
+	}
+
+	return algs
+}
@@ -0,0 +1,77 @@
+package jwtkms
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestSupportedAlgorithms(t *testing.T) {
+	client := mockkms.NewMockKMS()
+
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		want    []string
+	}{
+		{name: "P-256", keyType: mockkms.KeyTypeECCNISTP256, want: []string{"ES256"}},
+		{name: "P-521", keyType: mockkms.KeyTypeECCNISTP521, want: []string{"ES512"}},
+		{name: "secp256k1", keyType: mockkms.KeyTypeECCSECGP256K1, want: []string{"ES256K"}},
+		{name: "RSA", keyType: mockkms.KeyTypeRSA2048, want: []string{"PS256", "PS384", "PS512", "RS256", "RS384", "RS512"}},
+		{name: "SM2", keyType: mockkms.KeyTypeSM2, want: []string{"SM2"}},
+		{name: "HMAC", keyType: mockkms.KeyTypeHMAC512, want: []string{"HS512"}},
+	}
+
+	cfg := NewConfig(client, "unused")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := client.GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			algs, err := cfg.SupportedAlgorithms(context.Background(), id)
+			if err != nil {
+				t.Fatalf("Error getting supported algorithms: %v", err)
+			}
+
+			if !slices.Equal(algs, tt.want) {
+				t.Errorf("algs = %v, want %v", algs, tt.want)
+			}
+		})
+	}
+
+	if _, err := cfg.SupportedAlgorithms(context.Background(), "unknown"); err == nil {
+		t.Errorf("expected error for an unknown key")
+	}
+}
+
+func TestAlgorithmsForKeySpec(t *testing.T) {
+	tests := []struct {
+		name       string
+		keySpec    types.KeySpec
+		algorithms []types.SigningAlgorithmSpec
+		want       []string
+	}{
+		{name: "P-384", keySpec: types.KeySpecEccNistP384, algorithms: []types.SigningAlgorithmSpec{types.SigningAlgorithmSpecEcdsaSha384}, want: []string{"ES384"}},
+		{
+			name: "RSA with PSS only", keySpec: types.KeySpecRsa4096,
+			algorithms: []types.SigningAlgorithmSpec{types.SigningAlgorithmSpecRsassaPssSha256, types.SigningAlgorithmSpecRsassaPssSha512},
+			want:       []string{"PS256", "PS512"},
+		},
+		{name: "unknown key spec", algorithms: []types.SigningAlgorithmSpec{types.SigningAlgorithmSpecEcdsaSha256}, want: []string{"ES256", "ES256K"}},
+		{name: "encryption key", keySpec: types.KeySpecRsa2048},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if algs := AlgorithmsForKeySpec(tt.keySpec, tt.algorithms); !slices.Equal(algs, tt.want) {
+				t.Errorf("algs = %v, want %v", algs, tt.want)
+			}
+		})
+	}
+}
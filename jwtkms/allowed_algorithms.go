@@ -0,0 +1,30 @@
+package jwtkms
+
+import (
+	"fmt"
+	"slices"
+)
+
+// WithAllowedAlgorithms restricts the tokens the Config verifies to the algs, failing others with
+// ErrAlgorithmNotAllowed, see Config.WithAllowedAlgorithms.
+func WithAllowedAlgorithms(algs ...string) Option {
+	return func(c *Config) {
+		c.allowedAlgorithms = slices.Clone(algs)
+	}
+}
+
+// WithAllowedAlgorithms returns a copy of Config verifying only tokens with one of the algs, so a Config shared
+// between signing methods can not be used to verify a token claiming another alg of the same key, e.g. RS256 instead
+// of PS256. Other tokens fail with ErrAlgorithmNotAllowed before any KMS call. Without algs any alg is verified.
+func (c *Config) WithAllowedAlgorithms(algs ...string) *Config {
+	return c.with(WithAllowedAlgorithms(algs...))
+}
+
+// checkAllowedAlgorithm fails if the alg of m is not allowed by the Config.
+func (c *Config) checkAllowedAlgorithm(m kmsSigningMethod) error {
+	if len(c.allowedAlgorithms) == 0 || slices.Contains(c.allowedAlgorithms, m.Alg()) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s, allowed are %v", ErrAlgorithmNotAllowed, m.Alg(), c.allowedAlgorithms)
+}
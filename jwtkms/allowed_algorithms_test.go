@@ -0,0 +1,62 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestWithAllowedAlgorithms(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id)
+
+	signed := map[string]string{}
+	for _, method := range []jwt.SigningMethod{SigningMethodRS256, SigningMethodPS256} {
+		if signed[method.Alg()], err = cfg.SignContext(ctx, jwt.New(method)); err != nil {
+			t.Fatalf("Error signing token: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		cfg     *Config
+		alg     string
+		wantErr bool
+	}{
+		{name: "allowed", cfg: cfg.WithAllowedAlgorithms("PS256"), alg: "PS256"},
+		{name: "substituted", cfg: cfg.WithAllowedAlgorithms("PS256"), alg: "RS256", wantErr: true},
+		{name: "option", cfg: NewConfig(client, id, WithAllowedAlgorithms("RS256")), alg: "PS256", wantErr: true},
+		{name: "verify with KMS", cfg: cfg.WithAllowedAlgorithms("PS256").WithVerifyMode(VerifyWithKMS), alg: "RS256", wantErr: true},
+		{name: "any", cfg: cfg, alg: "RS256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.cfg.VerifyContext(ctx, signed[tt.alg], jwt.MapClaims{})
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("Error verifying token: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrAlgorithmNotAllowed) {
+				t.Errorf("err = %v, want %v", err, ErrAlgorithmNotAllowed)
+			}
+		})
+	}
+
+	// ParseWithConfig defaults to the algorithms allowed by the Config
+	if _, err := ParseWithConfig[jwt.MapClaims](ctx, signed["RS256"], cfg.WithAllowedAlgorithms("PS256")); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Errorf("err = %v, want %v", err, jwt.ErrTokenSignatureInvalid)
+	}
+}
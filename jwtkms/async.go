@@ -0,0 +1,107 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrAsyncSignerClosed is returned by Submit after the AsyncSigner was closed.
+var ErrAsyncSignerClosed = errors.New("async signer is closed")
+
+// AsyncSigner signs tokens in a pool of workers, so request handlers can enqueue token minting and collect the
+// signed token later instead of blocking on the KMS round trip.
+type AsyncSigner struct {
+	cfg  *Config
+	jobs chan asyncJob
+	done chan struct{}
+	wg   sync.WaitGroup
+
+	mu        sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+}
+
+type asyncJob struct {
+	ctx    context.Context
+	token  *jwt.Token
+	result chan SignResult
+}
+
+// NewAsyncSigner starts workers goroutines signing tokens with cfg, buffering up to queueSize submitted tokens.
+// Workers default to 1 and queueSize to 0 when not positive.
+func NewAsyncSigner(cfg *Config, workers, queueSize int) *AsyncSigner {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	s := &AsyncSigner{
+		cfg:  cfg,
+		jobs: make(chan asyncJob, queueSize),
+		done: make(chan struct{}),
+	}
+
+	s.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go s.work()
+	}
+
+	return s
+}
+
+func (s *AsyncSigner) work() {
+	defer s.wg.Done()
+
+	for job := range s.jobs {
+		var result SignResult
+		if err := job.ctx.Err(); err != nil {
+			result.Err = err
+		} else {
+			result.SignedString, result.Err = s.cfg.SignContext(job.ctx, job.token)
+		}
+
+		job.result <- result
+	}
+}
+
+// Submit enqueues token for signing with ctx and returns the channel its SignResult is delivered on. It blocks while
+// the queue is full, until ctx is done or the AsyncSigner is closed.
+func (s *AsyncSigner) Submit(ctx context.Context, token *jwt.Token) (<-chan SignResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return nil, ErrAsyncSignerClosed
+	}
+
+	job := asyncJob{ctx: ctx, token: token, result: make(chan SignResult, 1)}
+
+	select {
+	case s.jobs <- job:
+		return job.result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.done:
+		return nil, ErrAsyncSignerClosed
+	}
+}
+
+// Close stops accepting tokens and waits for the workers to sign the tokens already queued.
+func (s *AsyncSigner) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+
+		s.mu.Lock()
+		s.closed = true
+		close(s.jobs)
+		s.mu.Unlock()
+	})
+
+	s.wg.Wait()
+}
@@ -0,0 +1,74 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestAsyncSigner(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewKMSConfig(kms, id, false)
+	signer := NewAsyncSigner(cfg, 4, 8)
+
+	var results []<-chan SignResult
+	for i := 0; i < 16; i++ {
+		result, err := signer.Submit(context.Background(), jwt.NewWithClaims(SigningMethodECDSA256, jwt.MapClaims{"n": i}))
+		if err != nil {
+			t.Fatalf("Error submitting token: %v", err)
+		}
+		results = append(results, result)
+	}
+
+	for i, result := range results {
+		r := <-result
+		if r.Err != nil {
+			t.Fatalf("Error signing token %d: %v", i, r.Err)
+		}
+
+		if _, err := cfg.VerifyContext(context.Background(), r.SignedString, jwt.MapClaims{}); err != nil {
+			t.Errorf("Error verifying token %d: %v", i, err)
+		}
+	}
+
+	signer.Close()
+	signer.Close()
+
+	if _, err := signer.Submit(context.Background(), jwt.New(SigningMethodECDSA256)); !errors.Is(err, ErrAsyncSignerClosed) {
+		t.Errorf("error = %v, want %v", err, ErrAsyncSignerClosed)
+	}
+}
+
+func TestAsyncSignerQueueFull(t *testing.T) {
+	client, id := newBlockingKMS(t)
+	signer := NewAsyncSigner(NewKMSConfig(client, id, false), 1, 0)
+
+	first, err := signer.Submit(context.Background(), jwt.New(SigningMethodECDSA256))
+	if err != nil {
+		t.Fatalf("Error submitting token: %v", err)
+	}
+
+	// the only worker is busy and there is no queue, so Submit blocks until its context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := signer.Submit(ctx, jwt.New(SigningMethodECDSA256)); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want %v", err, context.Canceled)
+	}
+
+	close(client.unblock)
+
+	if r := <-first; r.Err != nil {
+		t.Errorf("Error signing token: %v", r.Err)
+	}
+
+	signer.Close()
+}
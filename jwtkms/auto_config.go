@@ -0,0 +1,111 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// WithSigningMethod binds the Config to method, a signing method of this package: Config.SigningMethod returns it,
+// so Builders and SignedString without a method sign with it instead of the default of the key spec.
+func WithSigningMethod(method jwt.SigningMethod) Option {
+	return func(c *Config) {
+		c.signingMethod = method
+	}
+}
+
+// WithSigningMethod returns a copy of the Config bound to method, see WithSigningMethod.
+func (c *Config) WithSigningMethod(method jwt.SigningMethod) *Config {
+	return c.with(WithSigningMethod(method))
+}
+
+// NewAutoConfig creates a Config like NewConfig bound to the signing method of the KMS key, see WithSigningMethod,
+// so the signing method can not be misconfigured. The method is selected from the key spec and signing algorithms
+// GetPublicKey reports, the default of the key spec if the key supports it, e.g. RS256 for RSA keys or PS256 for RSA
+// keys allowing only RSASSA-PSS. HMAC keys, which have no public key, are inspected with DescribeKey, so their client
+// has to implement KMSDescribeKeyClient.
+func NewAutoConfig(client KMSClient, keyID string, opts ...Option) (*Config, error) {
+	cfg := NewConfig(client, keyID, opts...)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	method, err := cfg.autoSigningMethod()
+	if err != nil {
+		return nil, fmt.Errorf("selecting signing method of key %q: %w", keyID, err)
+	}
+
+	return cfg.WithSigningMethod(method), nil
+}
+
+// autoSigningMethod selects the signing method of the KMS key for NewAutoConfig.
+func (c *Config) autoSigningMethod() (jwt.SigningMethod, error) {
+	key, err := getCachedPublicKey(c)
+
+	var unsupported *types.UnsupportedOperationException
+	if errors.As(err, &unsupported) {
+		return c.macSigningMethod()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if method, ok := SigningMethodForKeySpec(key.KeySpec); ok {
+		if m, ok := method.(kmsSigningMethod); ok && checkSigningAlgorithm(c.kmsKeyID, key, m) == nil {
+			return method, nil
+		}
+	}
+
+	algorithms := AlgorithmsForKeySpec(key.KeySpec, key.SigningAlgorithms)
+	if len(algorithms) == 0 {
+		return nil, &ConfigError{
+			Field: "KeyID",
+			Err:   fmt.Errorf("%w: %s key supports no signing method of this package", ErrIncompatibleKeySpec, key.KeySpec),
+		}
+	}
+
+	return GetSigningMethod(algorithms[0]), nil
+}
+
+// macSigningMethod selects the HS* signing method of an HMAC key from its key spec reported by DescribeKey.
+func (c *Config) macSigningMethod() (jwt.SigningMethod, error) {
+	client, ok := c.kmsClient.(KMSDescribeKeyClient)
+	if !ok {
+		return nil, &ConfigError{
+			Field: "KMSClient",
+			Err:   errors.New("kms client of an HMAC key does not implement KMSDescribeKeyClient"),
+		}
+	}
+
+	out, err := invoke(c, OperationDescribeKey, func(ctx context.Context) (*kms.DescribeKeyOutput, error) {
+		return client.DescribeKey(ctx, &kms.DescribeKeyInput{
+			KeyId:       aws.String(c.kmsKeyID),
+			GrantTokens: c.grantTokens,
+		}, c.kmsOptions()...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing key: %w", err)
+	}
+
+	var keySpec types.KeySpec
+	if out.KeyMetadata != nil {
+		keySpec = out.KeyMetadata.KeySpec
+	}
+
+	if method, ok := SigningMethodForKeySpec(keySpec); ok {
+		if _, ok := method.(*HMACSigningMethod); ok {
+			return method, nil
+		}
+	}
+
+	return nil, &ConfigError{
+		Field: "KeyID",
+		Err:   fmt.Errorf("%w: %s key supports no signing method of this package", ErrIncompatibleKeySpec, keySpec),
+	}
+}
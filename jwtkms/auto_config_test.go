@@ -0,0 +1,125 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// pssOnlyKMS reports only the RSASSA-PSS signing algorithms of RSA keys.
+type pssOnlyKMS struct {
+	*mockkms.MockKMS
+}
+
+func (c *pssOnlyKMS) GetPublicKey(ctx context.Context, in *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error) {
+	out, err := c.MockKMS.GetPublicKey(ctx, in, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	var algorithms []types.SigningAlgorithmSpec
+	for _, algorithm := range out.SigningAlgorithms {
+		if strings.HasPrefix(string(algorithm), "RSASSA_PSS") {
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+
+	out.SigningAlgorithms = algorithms
+
+	return out, nil
+}
+
+func TestNewAutoConfig(t *testing.T) {
+	registered(t)
+
+	client := mockkms.NewMockKMS()
+
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		client  func(*mockkms.MockKMS) KMSClient
+		want    jwt.SigningMethod
+	}{
+		{name: "P-256", keyType: mockkms.KeyTypeECCNISTP256, want: SigningMethodECDSA256},
+		{name: "P-384", keyType: mockkms.KeyTypeECCNISTP384, want: SigningMethodECDSA384},
+		{name: "secp256k1", keyType: mockkms.KeyTypeECCSECGP256K1, want: SigningMethodES256K},
+		{name: "RSA", keyType: mockkms.KeyTypeRSA2048, want: SigningMethodRS256},
+		{
+			name: "RSA with PSS only", keyType: mockkms.KeyTypeRSA2048, want: SigningMethodPS256,
+			client: func(m *mockkms.MockKMS) KMSClient { return &pssOnlyKMS{m} },
+		},
+		{name: "HMAC", keyType: mockkms.KeyTypeHMAC384, want: SigningMethodHS384},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := client.GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			var c KMSClient = client
+			if tt.client != nil {
+				c = tt.client(client)
+			}
+
+			cfg, err := NewAutoConfig(c, id)
+			if err != nil {
+				t.Fatalf("Error creating config: %v", err)
+			}
+
+			method, err := cfg.SigningMethod()
+			if err != nil {
+				t.Fatalf("Error getting signing method: %v", err)
+			}
+
+			if method != tt.want {
+				t.Fatalf("method = %s, want %s", method.Alg(), tt.want.Alg())
+			}
+
+			signed, err := NewBuilder(cfg).Sign(context.Background())
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			token, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil })
+			if err != nil {
+				t.Fatalf("Error verifying token: %v", err)
+			}
+
+			if token.Method != tt.want {
+				t.Errorf("alg = %s, want %s", token.Method.Alg(), tt.want.Alg())
+			}
+		})
+	}
+}
+
+func TestNewAutoConfigErrors(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	hmacKey, err := client.GenerateKey(mockkms.KeyTypeHMAC256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	if _, err := NewAutoConfig(client, "unknown"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("err = %v, want %v", err, ErrKeyNotFound)
+	}
+
+	if _, err := NewAutoConfig(client, ""); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidConfig)
+	}
+
+	if _, err := NewAutoConfig(noDescribeKeyKMS{client}, hmacKey); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidConfig)
+	}
+
+	if err := NewConfig(client, hmacKey, WithSigningMethod(jwt.SigningMethodHS256)).Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidConfig)
+	}
+}
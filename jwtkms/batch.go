@@ -0,0 +1,53 @@
+package jwtkms
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const defaultBatchParallelism = 10
+
+// SignResult is the outcome of signing one token with SignBatch or an AsyncSigner.
+type SignResult struct {
+	// SignedString is the signed token, empty if Err is set.
+	SignedString string
+	Err          error
+}
+
+// SignBatch signs tokens like SignContext with at most parallelism concurrent KMS calls, 10 when zero or negative.
+// The results are in the order of tokens; a failed token does not stop the others. Combine it with a SignLimiter to
+// keep large batches under the KMS request quota.
+func (c *Config) SignBatch(ctx context.Context, tokens []*jwt.Token, parallelism int) []SignResult {
+	if parallelism <= 0 {
+		parallelism = defaultBatchParallelism
+	}
+
+	cfg := c.WithContext(ctx)
+	results := make([]SignResult, len(tokens))
+	sem := make(chan struct{}, parallelism)
+
+	var wg sync.WaitGroup
+	for i, token := range tokens {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := ctx.Err(); err != nil {
+				results[i].Err = err
+				return
+			}
+
+			results[i].SignedString, results[i].Err = cfg.SignedString(token)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
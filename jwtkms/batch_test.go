@@ -0,0 +1,73 @@
+package jwtkms
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestSignBatch(t *testing.T) {
+	client, id := newBlockingKMS(t)
+	close(client.unblock)
+
+	cfg := NewKMSConfig(client, id, false)
+
+	tokens := make([]*jwt.Token, 20)
+	for i := range tokens {
+		tokens[i] = jwt.NewWithClaims(SigningMethodECDSA256, jwt.MapClaims{"sub": fmt.Sprint(i)})
+	}
+
+	// a token with a method that does not match the key fails on its own
+	tokens[5] = jwt.New(SigningMethodRS256)
+
+	results := cfg.SignBatch(context.Background(), tokens, 3)
+	if len(results) != len(tokens) {
+		t.Fatalf("len(results) = %d, want %d", len(results), len(tokens))
+	}
+
+	for i, result := range results {
+		if i == 5 {
+			if result.Err == nil {
+				t.Errorf("expected error signing token 5")
+			}
+			continue
+		}
+
+		if result.Err != nil {
+			t.Errorf("Error signing token %d: %v", i, result.Err)
+			continue
+		}
+
+		var claims jwt.MapClaims
+		if _, err := cfg.VerifyContext(context.Background(), result.SignedString, &claims); err != nil {
+			t.Errorf("Error verifying token %d: %v", i, err)
+		}
+
+		if claims["sub"] != fmt.Sprint(i) {
+			t.Errorf("token %d has sub %v", i, claims["sub"])
+		}
+	}
+
+	if n := client.maxInFlight.Load(); n > 3 {
+		t.Errorf("max concurrent Sign calls = %d, want at most 3", n)
+	}
+}
+
+func TestSignBatchCanceled(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := NewKMSConfig(kms, id, false).SignBatch(ctx, []*jwt.Token{jwt.New(SigningMethodECDSA256)}, 0)
+	if results[0].Err == nil {
+		t.Errorf("expected error signing with a canceled context")
+	}
+}
@@ -0,0 +1,109 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultBearerTokenLifetime      = 5 * time.Minute
+	defaultBearerTokenRefreshBefore = 30 * time.Second
+)
+
+// BearerTokenSettings configures the tokens of a BearerTokenSource.
+type BearerTokenSettings struct {
+	// Issuer, Subject and Audience are the iss, sub and aud claims of the tokens, omitted when empty.
+	Issuer   string
+	Subject  string
+	Audience []string
+
+	// Lifetime is the lifetime of the tokens, five minutes when zero.
+	Lifetime time.Duration
+
+	// RefreshBefore is how long before their expiry tokens are replaced, 30 seconds when zero.
+	RefreshBefore time.Duration
+
+	// Claims holds additional claims of the tokens.
+	Claims map[string]interface{}
+}
+
+// BearerTokenSource signs bearer tokens with a KMS key for service-to-service authentication without a central token
+// service, see Transport. A token is reused until shortly before it expires, and signed with the context of the
+// caller that needs a new one.
+//
+// A BearerTokenSource is safe for concurrent use.
+type BearerTokenSource struct {
+	cfg      *Config
+	method   jwt.SigningMethod
+	settings BearerTokenSettings
+	now      func() time.Time
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// NewBearerTokenSource creates a BearerTokenSource signing its tokens with cfg and method.
+func NewBearerTokenSource(cfg *Config, method jwt.SigningMethod, settings BearerTokenSettings) *BearerTokenSource {
+	if settings.Lifetime <= 0 {
+		settings.Lifetime = defaultBearerTokenLifetime
+	}
+
+	if settings.RefreshBefore <= 0 {
+		settings.RefreshBefore = defaultBearerTokenRefreshBefore
+	}
+
+	return &BearerTokenSource{cfg: cfg, method: method, settings: settings, now: time.Now}
+}
+
+// Token returns the cached token, signing a new one with a random jti, iat and exp if it is about to expire.
+func (s *BearerTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.token != "" && now.Before(s.expiry.Add(-s.settings.RefreshBefore)) {
+		return s.token, nil
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", fmt.Errorf("generating jti: %w", err)
+	}
+
+	claims := jwt.MapClaims{}
+	maps.Copy(claims, s.settings.Claims)
+
+	expiry := now.Add(s.settings.Lifetime)
+	claims["jti"] = base64.RawURLEncoding.EncodeToString(jti)
+	claims["iat"] = now.Unix()
+	claims["exp"] = expiry.Unix()
+
+	if s.settings.Issuer != "" {
+		claims["iss"] = s.settings.Issuer
+	}
+
+	if s.settings.Subject != "" {
+		claims["sub"] = s.settings.Subject
+	}
+
+	if len(s.settings.Audience) > 0 {
+		claims["aud"] = s.settings.Audience
+	}
+
+	token, err := s.cfg.SignContext(ctx, jwt.NewWithClaims(s.method, claims))
+	if err != nil {
+		return "", err
+	}
+
+	s.token, s.expiry = token, expiry
+
+	return token, nil
+}
@@ -0,0 +1,77 @@
+package jwtkms
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// signCountingKMS counts the Sign calls.
+type signCountingKMS struct {
+	*mockkms.MockKMS
+	calls atomic.Int32
+}
+
+func (c *signCountingKMS) Sign(ctx context.Context, in *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error) {
+	c.calls.Add(1)
+	return c.MockKMS.Sign(ctx, in, optFns...)
+}
+
+func TestBearerTokenSource(t *testing.T) {
+	client := &signCountingKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id)
+
+	source := NewBearerTokenSource(cfg, SigningMethodECDSA256, BearerTokenSettings{
+		Issuer:   "service-a",
+		Subject:  "service-a",
+		Audience: []string{"service-b"},
+		Lifetime: time.Minute,
+		Claims:   map[string]interface{}{"scope": "read"},
+	})
+
+	now := time.Now()
+	source.now = func() time.Time { return now }
+
+	token := func() string {
+		t.Helper()
+
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Error signing bearer token: %v", err)
+		}
+
+		return token
+	}
+
+	first := token()
+
+	claims := jwt.MapClaims{}
+	if _, err := cfg.VerifyContext(context.Background(), first, claims,
+		jwt.WithIssuer("service-a"), jwt.WithSubject("service-a"), jwt.WithAudience("service-b"), jwt.WithExpirationRequired()); err != nil {
+		t.Fatalf("Error verifying bearer token: %v", err)
+	}
+
+	if claims["scope"] != "read" || claims["jti"] == nil {
+		t.Errorf("claims = %v, want scope and jti", claims)
+	}
+
+	if token() != first || client.calls.Load() != 1 {
+		t.Errorf("Sign calls = %d, want 1, the token is cached", client.calls.Load())
+	}
+
+	// within RefreshBefore of the expiry the token is replaced
+	now = now.Add(45 * time.Second)
+	if token() == first || client.calls.Load() != 2 {
+		t.Errorf("Sign calls = %d, want 2", client.calls.Load())
+	}
+}
@@ -0,0 +1,182 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// ErrCircuitOpen is returned without calling KMS while a CircuitBreaker is open.
+var ErrCircuitOpen = errors.New("kms circuit breaker is open")
+
+// CircuitBreakerSettings configures a CircuitBreaker.
+type CircuitBreakerSettings struct {
+	// FailureThreshold is the number of consecutive failed KMS calls that opens the breaker, 5 when zero.
+	FailureThreshold int
+
+	// OpenTimeout is how long the breaker stays open before letting a trial call through, 30s when zero.
+	OpenTimeout time.Duration
+}
+
+const (
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerOpenTimeout      = 30 * time.Second
+)
+
+// CircuitBreaker makes KMS calls fail fast with ErrCircuitOpen during a KMS outage, instead of piling up callers
+// waiting on timeouts.
+//
+// It opens after FailureThreshold consecutive failures and, once OpenTimeout passed, lets a single trial call
+// through: the breaker closes if it succeeds and opens again if not. Errors that say nothing about the health of KMS,
+// like invalid signatures, unknown keys, denied access or canceled contexts, do not count as failures.
+//
+// A CircuitBreaker is safe for concurrent use and is usually shared by all Configs using the same KMS endpoint.
+type CircuitBreaker struct {
+	threshold   int
+	openTimeout time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker.
+func NewCircuitBreaker(settings CircuitBreakerSettings) *CircuitBreaker {
+	b := &CircuitBreaker{
+		threshold:   settings.FailureThreshold,
+		openTimeout: settings.OpenTimeout,
+		now:         time.Now,
+	}
+
+	if b.threshold <= 0 {
+		b.threshold = defaultBreakerFailureThreshold
+	}
+
+	if b.openTimeout <= 0 {
+		b.openTimeout = defaultBreakerOpenTimeout
+	}
+
+	return b
+}
+
+// WithCircuitBreaker guards the KMS calls of the Config with breaker.
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(c *Config) {
+		c.circuitBreaker = breaker
+	}
+}
+
+// WithLocalVerifyFallback makes a Config that verifies with KMS verify locally with the cached public key while its
+// circuit breaker is open.
+func WithLocalVerifyFallback() Option {
+	return func(c *Config) {
+		c.localVerifyFallback = true
+	}
+}
+
+// Open reports whether the breaker currently rejects calls.
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.failures >= b.threshold && (b.trial || b.now().Sub(b.openedAt) < b.openTimeout)
+}
+
+// allow reports whether a call may go through, marking it as the trial call of a half-open breaker.
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+
+	if b.trial || b.now().Sub(b.openedAt) < b.openTimeout {
+		return false
+	}
+
+	b.trial = true
+
+	return true
+}
+
+// record updates the breaker with the result of a call allowed through, made with the context ctx of the caller.
+func (b *CircuitBreaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+
+	if !isKMSFailure(ctx, err) {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = b.now()
+	}
+}
+
+// isKMSFailure reports whether err of a call made with the context ctx of the caller indicates KMS is unavailable: a
+// server error, a timeout or a transport error. Other errors are problems with the request, even if KMS rejects many of
+// them, e.g. throttling, a missing key, an invalid signature or an operation the key does not support. Once ctx is done
+// no error is a failure, the caller canceling or running out of time says nothing about KMS.
+func isKMSFailure(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrCallTimeout) {
+		return true
+	}
+
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode() >= http.StatusInternalServerError
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorFault() == smithy.FaultServer
+	}
+
+	var sendErr *smithyhttp.RequestSendError
+	var netErr net.Error
+
+	return errors.As(err, &sendErr) || errors.As(err, &netErr)
+}
+
+// breakerCall runs call through the circuit breaker of c, if any.
+func breakerCall[T any](c *Config, call func() (T, error)) (T, error) {
+	if c.circuitBreaker == nil {
+		return call()
+	}
+
+	if !c.circuitBreaker.allow() {
+		var zero T
+		return zero, ErrCircuitOpen
+	}
+
+	out, err := call()
+	c.circuitBreaker.record(c.ctx, err)
+
+	return out, err
+}
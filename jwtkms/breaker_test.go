@@ -0,0 +1,223 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+var errUnavailable error = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+// unavailableKMS fails Sign and Verify while down is set.
+type unavailableKMS struct {
+	*mockkms.MockKMS
+	down  atomic.Bool
+	calls atomic.Int32
+}
+
+func (c *unavailableKMS) Sign(ctx context.Context, in *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error) {
+	c.calls.Add(1)
+	if c.down.Load() {
+		return nil, errUnavailable
+	}
+
+	return c.MockKMS.Sign(ctx, in, optFns...)
+}
+
+func (c *unavailableKMS) Verify(ctx context.Context, in *kms.VerifyInput, optFns ...func(*kms.Options)) (*kms.VerifyOutput, error) {
+	c.calls.Add(1)
+	if c.down.Load() {
+		return nil, errUnavailable
+	}
+
+	return c.MockKMS.Verify(ctx, in, optFns...)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	client := &unavailableKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	now := time.Now()
+	breaker := NewCircuitBreaker(CircuitBreakerSettings{FailureThreshold: 2, OpenTimeout: time.Minute})
+	breaker.now = func() time.Time { return now }
+
+	cfg := NewConfig(client, id, WithCircuitBreaker(breaker))
+	sign := func() error {
+		_, err := jwt.New(SigningMethodECDSA256).SignedString(cfg)
+		return err
+	}
+
+	client.down.Store(true)
+	for i := 0; i < 2; i++ {
+		if err := sign(); !errors.Is(err, errUnavailable) {
+			t.Fatalf("error = %v, want %v", err, errUnavailable)
+		}
+	}
+
+	if !breaker.Open() {
+		t.Fatalf("breaker is not open after 2 failures")
+	}
+
+	if err := sign(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("error = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	if calls := client.calls.Load(); calls != 2 {
+		t.Errorf("KMS called %d times, want 2", calls)
+	}
+
+	// the trial call after the open timeout fails and opens the breaker again
+	now = now.Add(time.Minute)
+	if err := sign(); !errors.Is(err, errUnavailable) {
+		t.Errorf("error = %v, want %v", err, errUnavailable)
+	}
+
+	if err := sign(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("error = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	// a successful trial call closes it
+	client.down.Store(false)
+	now = now.Add(time.Minute)
+	if err := sign(); err != nil {
+		t.Errorf("Error signing token: %v", err)
+	}
+
+	if breaker.Open() {
+		t.Errorf("breaker is still open after a successful call")
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	breaker := NewCircuitBreaker(CircuitBreakerSettings{FailureThreshold: 1})
+
+	_, err := jwt.New(SigningMethodECDSA256).SignedString(NewConfig(kms, "missing", WithCircuitBreaker(breaker)))
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("error = %v, want %v", err, ErrKeyNotFound)
+	}
+
+	if breaker.Open() {
+		t.Errorf("breaker opened on a client error")
+	}
+
+	// HMAC keys have no public key, KMS rejects every GetPublicKey call of e.g. a KeyRegistry routing by key spec
+	id, err := kms.GenerateKey(mockkms.KeyTypeHMAC256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(kms, id, WithCircuitBreaker(breaker))
+	for i := 0; i < 3; i++ {
+		if _, err := getCachedPublicKey(cfg.WithKeyID(id)); err == nil {
+			t.Fatalf("expected error getting the public key of an HMAC key")
+		}
+	}
+
+	if breaker.Open() {
+		t.Errorf("breaker opened on UnsupportedOperationException")
+	}
+}
+
+func TestIsKMSFailure(t *testing.T) {
+	// responseError is an error of the SDK for a KMS response with status
+	responseError := func(status int, err error) error {
+		return &awshttp.ResponseError{ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+			Err:      err,
+		}}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		done bool
+		want bool
+	}{
+		{name: "none"},
+		{name: "internal error", err: &types.KMSInternalException{}, want: true},
+		{name: "server fault", err: &smithy.GenericAPIError{Code: "InternalFailure", Fault: smithy.FaultServer}, want: true},
+		{name: "5xx", err: responseError(http.StatusServiceUnavailable, &smithy.GenericAPIError{Code: "Unknown"}), want: true},
+		{name: "dependency timeout", err: mapKMSError(&types.DependencyTimeoutException{}), want: true},
+		{name: "call timeout", err: fmt.Errorf("%w: %w", ErrCallTimeout, context.DeadlineExceeded), want: true},
+		{name: "deadline", err: context.DeadlineExceeded, want: true},
+		{name: "transport", err: &smithyhttp.RequestSendError{Err: errors.New("connection reset")}, want: true},
+		{name: "network", err: errUnavailable, want: true},
+		{name: "throttled", err: mapKMSError(&types.LimitExceededException{})},
+		{name: "throttled response", err: responseError(http.StatusBadRequest, &smithy.GenericAPIError{Code: "ThrottlingException"})},
+		{name: "validation", err: &smithy.GenericAPIError{Code: "ValidationException", Fault: smithy.FaultClient}},
+		{name: "invalid key usage", err: &types.InvalidKeyUsageException{}},
+		{name: "unsupported operation", err: &types.UnsupportedOperationException{}},
+		{name: "key not found", err: mapKMSError(&types.NotFoundException{})},
+		{name: "invalid signature", err: ErrInvalidSignature},
+		{name: "key spec mismatch", err: &ConfigError{Field: "KeyID", Err: &KeySpecMismatchError{}}},
+		{name: "canceled", err: context.Canceled},
+		{name: "caller deadline", err: context.DeadlineExceeded, done: true},
+		{name: "caller deadline canceled request", err: &smithy.CanceledError{Err: context.DeadlineExceeded}, done: true},
+		{name: "caller deadline transport", err: &smithyhttp.RequestSendError{Err: context.DeadlineExceeded}, done: true},
+		{name: "caller canceled", err: &smithy.CanceledError{Err: context.Canceled}, done: true},
+	}
+
+	done, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.done {
+				ctx = done
+			}
+
+			if got := isKMSFailure(ctx, tt.err); got != tt.want {
+				t.Errorf("isKMSFailure(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalVerifyFallback(t *testing.T) {
+	client := &unavailableKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	breaker := NewCircuitBreaker(CircuitBreakerSettings{FailureThreshold: 1})
+	cfg := NewConfig(client, id, WithVerifyWithKMS(true), WithCircuitBreaker(breaker), WithLocalVerifyFallback())
+
+	signed, err := jwt.New(SigningMethodRS256).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	// cache the public key while KMS is up
+	if _, err := cfg.WithVerifyMode(VerifyLocally).VerifyContext(context.Background(), signed, jwt.MapClaims{}); err != nil {
+		t.Fatalf("Error verifying token locally: %v", err)
+	}
+
+	client.down.Store(true)
+
+	if _, err := cfg.VerifyContext(context.Background(), signed, jwt.MapClaims{}); !errors.Is(err, errUnavailable) {
+		t.Fatalf("error = %v, want %v", err, errUnavailable)
+	}
+
+	if _, err := cfg.VerifyContext(context.Background(), signed, jwt.MapClaims{}); err != nil {
+		t.Errorf("Error verifying token with open breaker: %v", err)
+	}
+}
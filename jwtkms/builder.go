@@ -0,0 +1,147 @@
+package jwtkms
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SignedString signs claims with cfg and method, jwt.MapClaims{} when claims is nil. A nil method is resolved from
+// the key spec of the KMS key, see Config.SigningMethod.
+func SignedString(cfg *Config, method jwt.SigningMethod, claims jwt.Claims) (string, error) {
+	return NewBuilder(cfg).WithMethod(method).WithClaims(claims).Sign(cfg.ctx)
+}
+
+// SigningMethod returns the default signing method of the key spec of the KMS key: ES256, ES384 and ES512 for the
+// NIST curves, ES256K, RS256 for RSA keys, SM2 and ML-DSA. The key spec is taken from the public key, so HMAC keys,
+// which have none, and unknown key specs fail with an ErrInvalidConfig error. A Config bound to a signing method, see
+// WithSigningMethod and NewAutoConfig, returns that method.
+func (c *Config) SigningMethod() (jwt.SigningMethod, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	if c.signingMethod != nil {
+		return c.signingMethod, nil
+	}
+
+	cached, err := getCachedPublicKey(c)
+	if err != nil {
+		return nil, err
+	}
+
+	method, ok := SigningMethodForKeySpec(cached.KeySpec)
+	if !ok {
+		return nil, &ConfigError{Field: "SigningMethod", Err: fmt.Errorf("no default signing method for key spec %q", cached.KeySpec)}
+	}
+
+	return method, nil
+}
+
+// SigningMethodForKeySpec returns the default signing method of keys of keySpec, see Config.SigningMethod. HMAC key
+// specs map to the HS* method of their size.
+func SigningMethodForKeySpec(keySpec types.KeySpec) (jwt.SigningMethod, bool) {
+	switch keySpec {
+	case types.KeySpecEccNistP256:
+		return SigningMethodECDSA256, true
+	case types.KeySpecEccNistP384:
+		return SigningMethodECDSA384, true
+	case types.KeySpecEccNistP521:
+		return SigningMethodECDSA512, true
+	case types.KeySpecEccSecgP256k1:
+		return SigningMethodES256K, true
+	case types.KeySpecRsa2048, types.KeySpecRsa3072, types.KeySpecRsa4096:
+		return SigningMethodRS256, true
+	case types.KeySpecSm2:
+		return SigningMethodSM2, true
+	case types.KeySpecHmac256:
+		return SigningMethodHS256, true
+	case types.KeySpecHmac384:
+		return SigningMethodHS384, true
+	case types.KeySpecHmac512:
+		return SigningMethodHS512, true
+	}
+
+	return mldsaSigningMethod(keySpec)
+}
+
+// Builder builds and signs tokens with a Config. A Builder is immutable: the With* methods return modified copies, so
+// a Builder can serve as a template of tokens.
+type Builder struct {
+	cfg    *Config
+	method jwt.SigningMethod
+	header map[string]interface{}
+
+	// Headers of WithProtectedHeaders, checked for reserved headers by Sign
+	protectedHeaders map[string]interface{}
+
+	claims jwt.Claims
+}
+
+// NewBuilder creates a Builder signing with cfg and the signing method of its key, see Config.SigningMethod.
+func NewBuilder(cfg *Config) Builder {
+	return Builder{cfg: cfg}
+}
+
+// WithMethod returns a copy of the Builder signing with method, the default of the key when nil.
+func (b Builder) WithMethod(method jwt.SigningMethod) Builder {
+	b.method = method
+	return b
+}
+
+// WithHeader returns a copy of the Builder setting the header name to value. The alg header can not be set.
+func (b Builder) WithHeader(name string, value interface{}) Builder {
+	b.header = maps.Clone(b.header)
+	if b.header == nil {
+		b.header = make(map[string]interface{})
+	}
+
+	b.header[name] = value
+
+	return b
+}
+
+// WithType returns a copy of the Builder setting the typ header to typ, e.g. TokenTypeAccessToken.
+func (b Builder) WithType(typ string) Builder {
+	return b.WithHeader("typ", typ)
+}
+
+// WithClaims returns a copy of the Builder signing claims, jwt.MapClaims{} when nil.
+func (b Builder) WithClaims(claims jwt.Claims) Builder {
+	b.claims = claims
+	return b
+}
+
+// Sign signs the token, using ctx for the KMS calls.
+func (b Builder) Sign(ctx context.Context) (string, error) {
+	cfg := b.cfg.WithContext(ctx)
+
+	method := b.method
+	if method == nil {
+		var err error
+		if method, err = cfg.SigningMethod(); err != nil {
+			return "", fmt.Errorf("resolving signing method: %w", err)
+		}
+	}
+
+	claims := b.claims
+	if claims == nil {
+		claims = jwt.MapClaims{}
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	for name, value := range b.header {
+		if name != "alg" {
+			token.Header[name] = value
+		}
+	}
+
+	if err := setProtectedHeaders(token, b.protectedHeaders); err != nil {
+		return "", err
+	}
+
+	return cfg.SignedString(token)
+}
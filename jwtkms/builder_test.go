@@ -0,0 +1,109 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestSignedString(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		method  jwt.SigningMethod
+		wantAlg string
+	}{
+		{name: "P-256", keyType: mockkms.KeyTypeECCNISTP256, wantAlg: "ES256"},
+		{name: "P-384", keyType: mockkms.KeyTypeECCNISTP384, wantAlg: "ES384"},
+		{name: "P-521", keyType: mockkms.KeyTypeECCNISTP521, wantAlg: "ES512"},
+		{name: "secp256k1", keyType: mockkms.KeyTypeECCSECGP256K1, wantAlg: "ES256K"},
+		{name: "RSA", keyType: mockkms.KeyTypeRSA2048, wantAlg: "RS256"},
+		{name: "SM2", keyType: mockkms.KeyTypeSM2, wantAlg: "SM2"},
+		{name: "explicit method", keyType: mockkms.KeyTypeRSA2048, method: SigningMethodPS384, wantAlg: "PS384"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := mockkms.NewMockKMS()
+			id, err := client.GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			cfg := NewConfig(client, id)
+
+			signed, err := SignedString(cfg, tt.method, jwt.MapClaims{"sub": "user"})
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			token, err := cfg.VerifyContext(context.Background(), signed, jwt.MapClaims{})
+			if err != nil {
+				t.Fatalf("Error verifying token: %v", err)
+			}
+
+			if token.Method.Alg() != tt.wantAlg {
+				t.Errorf("alg = %s, want %s", token.Method.Alg(), tt.wantAlg)
+			}
+
+			if sub, _ := token.Claims.GetSubject(); sub != "user" {
+				t.Errorf("sub = %q, want %q", sub, "user")
+			}
+		})
+	}
+}
+
+func TestSigningMethodHMAC(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeHMAC256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	// HMAC keys have no public key to take the key spec from
+	if _, err := SignedString(NewConfig(client, id), nil, nil); err == nil {
+		t.Errorf("expected error resolving the signing method of an HMAC key")
+	}
+
+	if _, err := SignedString(NewConfig(client, id), SigningMethodHS256, nil); err != nil {
+		t.Errorf("Error signing token: %v", err)
+	}
+
+	if _, err := NewConfig(client, "").SigningMethod(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidConfig)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id)
+
+	template := NewBuilder(cfg).WithHeader("typ", "at+jwt")
+	withKID := template.WithHeader("kid", "key-1").WithHeader("alg", "none")
+
+	signed, err := withKID.WithClaims(jwt.MapClaims{"sub": "user"}).Sign(context.Background())
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	token, err := cfg.VerifyContext(context.Background(), signed, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	if token.Header["typ"] != "at+jwt" || token.Header["kid"] != "key-1" || token.Header["alg"] != "ES256" {
+		t.Errorf("header = %v", token.Header)
+	}
+
+	if _, ok := template.header["kid"]; ok {
+		t.Errorf("WithHeader modified the Builder it was called on")
+	}
+}
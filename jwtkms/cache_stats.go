@@ -0,0 +1,124 @@
+package jwtkms
+
+import "sync"
+
+// CacheStats are the statistics of a public key cache, for sizing it and detecting churn driven by key rotation.
+type CacheStats struct {
+	// Hits and Misses count the public key lookups of the Configs sharing the cache, served from it or not. Expired
+	// public keys are misses.
+	Hits   uint64
+	Misses uint64
+
+	// Evictions counts the public keys evicted to stay under the maximum number of entries.
+	Evictions uint64
+
+	// Entries is the number of cached public keys.
+	Entries int
+
+	// Keys are the statistics of every key id looked up or evicted, until it is invalidated.
+	Keys map[string]KeyCacheStats
+}
+
+// KeyCacheStats are the statistics of the public key of a key id in a public key cache.
+type KeyCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// PublicKeyCacheStatsReporter is implemented by PublicKeyCaches reporting their number of entries and evictions,
+// like the in-memory cache. Hits and misses are counted by the Configs.
+type PublicKeyCacheStatsReporter interface {
+	CacheStats() CacheStats
+}
+
+// CacheMetricsRecorder is implemented by MetricsRecorders also receiving the CacheStats of the public key cache of the
+// Configs they are set on, reported after every public key fetched from KMS, when the entries change.
+type CacheMetricsRecorder interface {
+	OnCacheStats(CacheStats)
+}
+
+// CacheStats returns the statistics of the public key cache of the Config. Entries and evictions are zero if the
+// cache does not report them.
+func (c *Config) CacheStats() CacheStats {
+	var stats CacheStats
+	if reporter, ok := c.publicKeyCache().(PublicKeyCacheStatsReporter); ok {
+		stats = reporter.CacheStats()
+	}
+
+	if stats.Keys == nil {
+		stats.Keys = make(map[string]KeyCacheStats)
+	}
+
+	if c.cacheLookups != nil {
+		c.cacheLookups.addTo(&stats)
+	}
+
+	return stats
+}
+
+// recordCacheStats reports the statistics of the public key cache to the metrics recorder, if it takes them.
+func (c *Config) recordCacheStats() {
+	if recorder, ok := c.metrics.(CacheMetricsRecorder); ok {
+		recorder.OnCacheStats(c.CacheStats())
+	}
+}
+
+// countCacheLookup counts a public key lookup of the Config served from the cache or not.
+func (c *Config) countCacheLookup(hit bool) {
+	if c.cacheLookups != nil {
+		c.cacheLookups.count(c.kmsKeyID, hit)
+	}
+}
+
+// lookupCounter counts the hits and misses of a public key cache, by key id.
+type lookupCounter struct {
+	mutex  sync.Mutex
+	hits   uint64
+	misses uint64
+	keys   map[string]KeyCacheStats
+}
+
+func newLookupCounter() *lookupCounter {
+	return &lookupCounter{keys: make(map[string]KeyCacheStats)}
+}
+
+func (l *lookupCounter) count(keyID string, hit bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	keyStats := l.keys[keyID]
+
+	if hit {
+		l.hits++
+		keyStats.Hits++
+	} else {
+		l.misses++
+		keyStats.Misses++
+	}
+
+	l.keys[keyID] = keyStats
+}
+
+func (l *lookupCounter) delete(keyID string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.keys, keyID)
+}
+
+// addTo adds the counted hits and misses to stats.
+func (l *lookupCounter) addTo(stats *CacheStats) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	stats.Hits += l.hits
+	stats.Misses += l.misses
+
+	for keyID, counted := range l.keys {
+		keyStats := stats.Keys[keyID]
+		keyStats.Hits += counted.Hits
+		keyStats.Misses += counted.Misses
+		stats.Keys[keyID] = keyStats
+	}
+}
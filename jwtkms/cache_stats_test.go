@@ -0,0 +1,114 @@
+package jwtkms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+type cacheStatsRecorder struct {
+	nopMetricsRecorder
+	stats []CacheStats
+}
+
+func (r *cacheStatsRecorder) OnCacheStats(stats CacheStats) {
+	r.stats = append(r.stats, stats)
+}
+
+type nopMetricsRecorder struct{}
+
+func (nopMetricsRecorder) OnSign(SignMetrics) {}
+
+func (nopMetricsRecorder) OnVerify(VerifyMetrics) {}
+
+func (nopMetricsRecorder) OnGetPublicKey(GetPublicKeyMetrics) {}
+
+func TestCacheStats(t *testing.T) {
+	registered(t)
+
+	client := mockkms.NewMockKMS()
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	recorder := &cacheStatsRecorder{}
+	cache := NewPublicKeyCache(PublicKeyCacheSettings{MaxEntries: 1})
+	cfg := NewConfig(client, ids[0], WithPublicKeyCache(cache), WithMetricsRecorder(recorder))
+
+	verify := func(id string) {
+		t.Helper()
+
+		signed, err := jwt.New(SigningMethodECDSA256).SignedString(cfg.WithKeyID(id))
+		if err != nil {
+			t.Fatalf("Error signing token: %v", err)
+		}
+
+		if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg.WithKeyID(id), nil }); err != nil {
+			t.Fatalf("Error verifying token: %v", err)
+		}
+	}
+
+	// signing fetches the key's metadata on first use: miss and hit, hit, miss evicting ids[0] and hit, then signing
+	// with the remembered metadata of ids[0] and a miss evicting ids[1]
+	verify(ids[0])
+	verify(ids[0])
+	verify(ids[1])
+	verify(ids[0])
+
+	stats := cfg.CacheStats()
+	if stats.Hits != 3 || stats.Misses != 3 || stats.Evictions != 2 || stats.Entries != 1 {
+		t.Errorf("stats = %+v, want 3 hits, 3 misses, 2 evictions and 1 entry", stats)
+	}
+
+	if got := stats.Keys[ids[0]]; got != (KeyCacheStats{Hits: 2, Misses: 2, Evictions: 1}) {
+		t.Errorf("stats of %s = %+v", ids[0], got)
+	}
+
+	if got := stats.Keys[ids[1]]; got != (KeyCacheStats{Hits: 1, Misses: 1, Evictions: 1}) {
+		t.Errorf("stats of %s = %+v", ids[1], got)
+	}
+
+	if len(recorder.stats) != 3 || recorder.stats[2].Evictions != 2 {
+		t.Errorf("recorded stats = %+v, want 3 with 2 evictions last", recorder.stats)
+	}
+
+	cfg.Invalidate(ids[0])
+
+	if _, ok := cfg.CacheStats().Keys[ids[0]]; ok {
+		t.Errorf("stats of %s kept after Invalidate", ids[0])
+	}
+}
+
+func TestCacheStatsExpired(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id, WithPublicKeyTTL(time.Nanosecond))
+
+	for i := 0; i < 2; i++ {
+		if err := cfg.PreloadKeys(context.Background()); err != nil {
+			t.Fatalf("Error preloading key: %v", err)
+		}
+	}
+
+	if stats := cfg.CacheStats(); stats.Hits != 0 || stats.Misses != 2 || stats.Entries != 1 {
+		t.Errorf("stats = %+v, want expired public keys to be misses", stats)
+	}
+
+	if stats := NewConfig(client, id, WithoutPublicKeyCache()).CacheStats(); stats.Entries != 0 || stats.Evictions != 0 {
+		t.Errorf("stats without cache = %+v, want no entries", stats)
+	}
+}
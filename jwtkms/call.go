@@ -0,0 +1,256 @@
+package jwtkms
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// invoke runs a KMS API call with the Config's context, applying the call policies of the Config and mapping its
+// error with mapKMSError.
+func invoke[T any](c *Config, operation Operation, call func(ctx context.Context) (T, error)) (T, error) {
+	if c.offline {
+		var zero T
+		return zero, errNoKMSClient
+	}
+
+	if c.keyStates != nil && (operation == OperationSign || operation == OperationGenerateMac) {
+		if err := c.keyStates.check(c); err != nil {
+			var zero T
+			return zero, err
+		}
+	}
+
+	limited := c.signLimiter != nil && (operation == OperationSign || operation == OperationGenerateMac)
+
+	return retry(c, func() (T, error) {
+		// every attempt draws from the quota, a retry of a throttled call included
+		if limited {
+			release, err := c.signLimiter.acquire(c.ctx)
+			if err != nil {
+				var zero T
+				return zero, err
+			}
+			defer release()
+		}
+
+		return breakerCall(c, func() (T, error) {
+			ctx, cancel := c.callContext()
+			defer cancel()
+
+			out, err := call(ctx)
+			if err != nil {
+				err = mapKMSError(c.timeoutError(ctx, err))
+				c.logKeyState(operation, err)
+
+				return out, err
+			}
+
+			return out, nil
+		})
+	})
+}
+
+func (c *Config) kmsSign(in *kms.SignInput) (*kms.SignOutput, error) {
+	in.GrantTokens = c.grantTokens
+
+	return regionCall(c, OperationSign, in.KeyId, func(c *Config, keyID *string) (*kms.SignOutput, error) {
+		in := *in
+		in.KeyId = keyID
+
+		return signCall(c, OperationSign, string(in.SigningAlgorithm), func(ctx context.Context, cfg *Config) (*kms.SignOutput, error) {
+			return cfg.kmsClient.Sign(ctx, &in, cfg.kmsOptions()...)
+		})
+	})
+}
+
+func (c *Config) kmsVerify(in *kms.VerifyInput) (*kms.VerifyOutput, error) {
+	in.GrantTokens = c.grantTokens
+
+	return regionCall(c, OperationVerify, in.KeyId, func(c *Config, keyID *string) (*kms.VerifyOutput, error) {
+		in := *in
+		in.KeyId = keyID
+
+		return invoke(c, OperationVerify, func(ctx context.Context) (*kms.VerifyOutput, error) {
+			return c.kmsClient.Verify(ctx, &in, c.kmsOptions()...)
+		})
+	})
+}
+
+func (c *Config) kmsGetPublicKey(in *kms.GetPublicKeyInput) (*kms.GetPublicKeyOutput, error) {
+	in.GrantTokens = c.grantTokens
+
+	return regionCall(c, OperationGetPublicKey, in.KeyId, func(c *Config, keyID *string) (*kms.GetPublicKeyOutput, error) {
+		in := *in
+		in.KeyId = keyID
+
+		return invoke(c, OperationGetPublicKey, func(ctx context.Context) (*kms.GetPublicKeyOutput, error) {
+			return c.kmsClient.GetPublicKey(ctx, &in, c.kmsOptions()...)
+		})
+	})
+}
+
+func (c *Config) kmsGenerateMac(client KMSMACClient, in *kms.GenerateMacInput) (*kms.GenerateMacOutput, error) {
+	in.GrantTokens = c.grantTokens
+
+	return regionCall(c, OperationGenerateMac, in.KeyId, func(c *Config, keyID *string) (*kms.GenerateMacOutput, error) {
+		in := *in
+		in.KeyId = keyID
+
+		return signCall(c, OperationGenerateMac, string(in.MacAlgorithm), func(ctx context.Context, cfg *Config) (*kms.GenerateMacOutput, error) {
+			return client.GenerateMac(ctx, &in, cfg.kmsOptions()...)
+		})
+	})
+}
+
+func (c *Config) kmsVerifyMac(client KMSMACClient, in *kms.VerifyMacInput) (*kms.VerifyMacOutput, error) {
+	in.GrantTokens = c.grantTokens
+
+	return regionCall(c, OperationVerifyMac, in.KeyId, func(c *Config, keyID *string) (*kms.VerifyMacOutput, error) {
+		in := *in
+		in.KeyId = keyID
+
+		return invoke(c, OperationVerifyMac, func(ctx context.Context) (*kms.VerifyMacOutput, error) {
+			return client.VerifyMac(ctx, &in, c.kmsOptions()...)
+		})
+	})
+}
+
+func (c *Config) kmsEncrypt(client KMSEncryptionClient, in *kms.EncryptInput) (*kms.EncryptOutput, error) {
+	in.GrantTokens = c.grantTokens
+
+	return invoke(c, OperationEncrypt, func(ctx context.Context) (*kms.EncryptOutput, error) {
+		return client.Encrypt(ctx, in, c.kmsOptions()...)
+	})
+}
+
+func (c *Config) kmsDecrypt(client KMSEncryptionClient, in *kms.DecryptInput) (*kms.DecryptOutput, error) {
+	in.GrantTokens = c.grantTokens
+
+	return invoke(c, OperationDecrypt, func(ctx context.Context) (*kms.DecryptOutput, error) {
+		return client.Decrypt(ctx, in, c.kmsOptions()...)
+	})
+}
+
+// signCall runs a KMS Sign or GenerateMac call with invoke, reporting it to the tracer, hooks and metrics recorder
+// of c.
+func signCall[T any](c *Config, operation Operation, algorithm string, call func(ctx context.Context, cfg *Config) (T, error)) (T, error) {
+	cfg, span := c.startSpan("jwtkms."+string(operation), attributeAlgorithm.String(algorithm))
+
+	if c.hooks.OnSignStart != nil {
+		info := SignInfo{KeyID: c.kmsKeyID, Operation: operation, Algorithm: algorithm}
+		if err := c.hooks.OnSignStart(cfg.ctx, info); err != nil {
+			endSpan(span, err)
+
+			var zero T
+			return zero, err
+		}
+	}
+
+	start := time.Now()
+	out, err := invoke(cfg, operation, func(ctx context.Context) (T, error) {
+		return call(ctx, cfg)
+	})
+
+	if c.metrics != nil || c.hooks.OnSignEnd != nil {
+		m := SignMetrics{
+			KeyID:     c.kmsKeyID,
+			Operation: operation,
+			Algorithm: algorithm,
+			Duration:  time.Since(start),
+			Err:       err,
+			ErrorCode: errorCode(err),
+		}
+
+		if c.metrics != nil {
+			c.metrics.OnSign(m)
+		}
+
+		if c.hooks.OnSignEnd != nil {
+			c.hooks.OnSignEnd(cfg.ctx, m)
+		}
+	}
+
+	endSpan(span, err)
+
+	return out, err
+}
+
+func (c *Config) recordGetPublicKey(cacheHit bool, start time.Time, err error) {
+	if c.metrics == nil {
+		return
+	}
+
+	m := GetPublicKeyMetrics{
+		KeyID:     c.kmsKeyID,
+		CacheHit:  cacheHit,
+		Err:       err,
+		ErrorCode: errorCode(err),
+	}
+
+	if !cacheHit {
+		m.Duration = time.Since(start)
+	}
+
+	c.metrics.OnGetPublicKey(m)
+}
+
+// recordNegativeCacheHit reports a public key lookup answered with the remembered failure err.
+func (c *Config) recordNegativeCacheHit(err error) {
+	if c.metrics == nil {
+		return
+	}
+
+	c.metrics.OnGetPublicKey(GetPublicKeyMetrics{
+		KeyID:            c.kmsKeyID,
+		NegativeCacheHit: true,
+		Err:              err,
+		ErrorCode:        errorCode(err),
+	})
+}
+
+// observeVerify runs verify with a copy of c marked as observed and reports the verification to the tracer, hooks and
+// metrics recorder of c.
+func (c *Config) observeVerify(m kmsSigningMethod, verify func(cfg *Config) error) error {
+	mode := "local"
+	if c.verifyWithKMS {
+		mode = "kms"
+	}
+
+	cfg, span := c.startSpan("jwtkms.Verify", attributeAlgorithm.String(m.Alg()), attributeVerifyMode.String(mode))
+	cfg = cfg.with(func(c *Config) {
+		c.verifyObserved = true
+	})
+
+	start := time.Now()
+	err := verify(cfg)
+
+	if c.metrics != nil || c.hooks.OnVerifyEnd != nil {
+		vm := VerifyMetrics{
+			KeyID:     c.kmsKeyID,
+			Algorithm: m.Alg(),
+			WithKMS:   c.verifyWithKMS,
+			Duration:  time.Since(start),
+			Err:       err,
+			ErrorCode: errorCode(err),
+		}
+
+		if c.metrics != nil {
+			c.metrics.OnVerify(vm)
+		}
+
+		if c.hooks.OnVerifyEnd != nil {
+			c.hooks.OnVerifyEnd(cfg.ctx, vm)
+		}
+	}
+
+	endSpan(span, err)
+
+	return err
+}
+
+// observesVerify reports whether verifications with c have to go through observeVerify.
+func (c *Config) observesVerify() bool {
+	return !c.verifyObserved && (c.metrics != nil || c.tracer != nil || c.hooks.OnVerifyEnd != nil)
+}
@@ -0,0 +1,61 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultXKSCallTimeout is the call timeout of WithExternalKeyStore.
+	defaultXKSCallTimeout = 10 * time.Second
+
+	// defaultXKSRetryAttempts is the number of attempts of WithExternalKeyStore.
+	defaultXKSRetryAttempts = 3
+
+	// defaultXKSRetryBaseDelay is the base delay of the retries of WithExternalKeyStore.
+	defaultXKSRetryBaseDelay = 250 * time.Millisecond
+)
+
+// WithCallTimeout bounds every attempt of the KMS calls of the Config to timeout, independent of the deadline of the
+// context of the call. Attempts exceeding it fail with ErrCallTimeout, which WithRetry retries with RetryTimeouts.
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.callTimeout = timeout
+	}
+}
+
+// WithExternalKeyStore configures the Config for a key in an external key store (XKS), whose calls take the round
+// trip to the external key manager and are slower and more variable than those of keys in KMS: every attempt times
+// out after 10s and up to 3 attempts are made of calls failing with ErrThrottled or ErrCallTimeout, backing off from
+// 250ms. WithCallTimeout and WithRetry options after it override the defaults.
+func WithExternalKeyStore() Option {
+	return func(c *Config) {
+		c.callTimeout = defaultXKSCallTimeout
+		c.retryPolicy = &RetryPolicy{
+			MaxAttempts:   defaultXKSRetryAttempts,
+			BaseDelay:     defaultXKSRetryBaseDelay,
+			RetryTimeouts: true,
+		}
+	}
+}
+
+// callContext returns the context of an attempt of a KMS call, bounded by the call timeout.
+func (c *Config) callContext() (context.Context, context.CancelFunc) {
+	if c.callTimeout <= 0 {
+		return c.ctx, func() {}
+	}
+
+	return context.WithTimeout(c.ctx, c.callTimeout)
+}
+
+// timeoutError wraps err with ErrCallTimeout if the attempt with ctx failed because of the call timeout rather than
+// the context of the call.
+func (c *Config) timeoutError(ctx context.Context, err error) error {
+	if c.callTimeout <= 0 || !errors.Is(ctx.Err(), context.DeadlineExceeded) || c.ctx.Err() != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w after %s: %w", ErrCallTimeout, c.callTimeout, err)
+}
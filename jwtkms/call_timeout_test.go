@@ -0,0 +1,90 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// slowSignKMS makes the first slowCalls Sign calls wait for their context, like an unresponsive external key store.
+type slowSignKMS struct {
+	*mockkms.MockKMS
+	slowCalls int32
+	calls     atomic.Int32
+}
+
+func (c *slowSignKMS) Sign(ctx context.Context, in *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error) {
+	if c.calls.Add(1) <= c.slowCalls {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	return c.MockKMS.Sign(ctx, in, optFns...)
+}
+
+func TestWithCallTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		slowCalls int32
+		opts      []Option
+		wantErr   error
+		wantCalls int32
+	}{
+		{name: "timeout", slowCalls: 1, opts: []Option{WithCallTimeout(10 * time.Millisecond)}, wantErr: ErrCallTimeout, wantCalls: 1},
+		{name: "retried timeout", slowCalls: 1, opts: []Option{
+			WithCallTimeout(10 * time.Millisecond),
+			WithRetry(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, RetryTimeouts: true}),
+		}, wantCalls: 2},
+		{name: "timeouts not retried", slowCalls: 1, opts: []Option{
+			WithCallTimeout(10 * time.Millisecond),
+			WithRetry(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+		}, wantErr: ErrCallTimeout, wantCalls: 1},
+		{name: "external key store", slowCalls: 2, opts: []Option{
+			WithExternalKeyStore(),
+			WithCallTimeout(10 * time.Millisecond),
+		}, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &slowSignKMS{MockKMS: mockkms.NewMockKMS(), slowCalls: tt.slowCalls}
+			id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			_, err = jwt.New(SigningMethodECDSA256).SignedString(NewConfig(client, id, tt.opts...))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if client.calls.Load() != tt.wantCalls {
+				t.Errorf("Sign calls = %d, want %d", client.calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCallTimeoutCallerContext(t *testing.T) {
+	client := &slowSignKMS{MockKMS: mockkms.NewMockKMS(), slowCalls: 1}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	cfg := NewConfig(client, id, WithCallTimeout(time.Minute)).WithContext(ctx)
+
+	// the deadline of the caller is not a call timeout
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); errors.Is(err, ErrCallTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,74 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ClientAssertionType is the client_assertion_type of JWT client assertions, RFC 7523 section 2.2.
+const ClientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+const defaultClientAssertionLifetime = 5 * time.Minute
+
+// ClientAssertion builds private_key_jwt client assertions, RFC 7523, signed with a KMS key, for OAuth clients
+// authenticating at the token endpoint of an authorization server.
+type ClientAssertion struct {
+	cfg      *Config
+	method   jwt.SigningMethod
+	clientID string
+	audience string
+	lifetime time.Duration
+}
+
+// NewClientAssertion creates a ClientAssertion of clientID for audience, usually the token endpoint URL or the
+// issuer of the authorization server, signing with cfg and method. Assertions are valid for lifetime, five minutes
+// when zero or negative.
+func NewClientAssertion(cfg *Config, method jwt.SigningMethod, clientID, audience string, lifetime time.Duration) *ClientAssertion {
+	if lifetime <= 0 {
+		lifetime = defaultClientAssertionLifetime
+	}
+
+	return &ClientAssertion{cfg: cfg, method: method, clientID: clientID, audience: audience, lifetime: lifetime}
+}
+
+// Sign signs a new assertion, using ctx for the KMS calls, with iss and sub the client id, aud, exp, iat and a
+// random jti, so every assertion is single use.
+func (a *ClientAssertion) Sign(ctx context.Context) (string, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", fmt.Errorf("generating jti: %w", err)
+	}
+
+	now := time.Now()
+
+	token := jwt.NewWithClaims(a.method, jwt.RegisteredClaims{
+		Issuer:    a.clientID,
+		Subject:   a.clientID,
+		Audience:  jwt.ClaimStrings{a.audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(a.lifetime)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ID:        base64.RawURLEncoding.EncodeToString(jti),
+	})
+
+	return a.cfg.SignContext(ctx, token)
+}
+
+// Values signs a new assertion like Sign and returns the client_assertion and client_assertion_type parameters of
+// the token request.
+func (a *ClientAssertion) Values(ctx context.Context) (url.Values, error) {
+	assertion, err := a.Sign(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return url.Values{
+		"client_assertion":      {assertion},
+		"client_assertion_type": {ClientAssertionType},
+	}, nil
+}
@@ -0,0 +1,56 @@
+package jwtkms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestClientAssertion(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id)
+	assertion := NewClientAssertion(cfg, SigningMethodECDSA256, "client-1", "https://as.example.com/token", time.Minute)
+
+	values, err := assertion.Values(ctx)
+	if err != nil {
+		t.Fatalf("Error signing assertion: %v", err)
+	}
+
+	if values.Get("client_assertion_type") != ClientAssertionType {
+		t.Errorf("client_assertion_type = %q", values.Get("client_assertion_type"))
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	if _, err := cfg.VerifyContext(ctx, values.Get("client_assertion"), claims,
+		jwt.WithIssuer("client-1"), jwt.WithSubject("client-1"), jwt.WithAudience("https://as.example.com/token"),
+		jwt.WithExpirationRequired()); err != nil {
+		t.Fatalf("Error verifying assertion: %v", err)
+	}
+
+	if claims.ID == "" || claims.ExpiresAt.Sub(claims.IssuedAt.Time) != time.Minute {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	other, err := assertion.Sign(ctx)
+	if err != nil {
+		t.Fatalf("Error signing assertion: %v", err)
+	}
+
+	otherClaims := &jwt.RegisteredClaims{}
+	if _, err := cfg.VerifyContext(ctx, other, otherClaims); err != nil {
+		t.Fatalf("Error verifying assertion: %v", err)
+	}
+
+	if otherClaims.ID == claims.ID {
+		t.Errorf("jti is reused")
+	}
+}
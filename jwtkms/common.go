@@ -2,8 +2,17 @@ package jwtkms
 
 import (
 	"context"
+	"crypto/x509"
+	"errors"
+	"log/slog"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 )
 
 // KMSClient is the subset of `*kms.Client` functionality used when signing and
@@ -16,7 +25,32 @@ type KMSClient interface {
 	GetPublicKey(ctx context.Context, in *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error)
 }
 
+// KMSMACClient is the subset of `*kms.Client` functionality used when signing and verifying JWTs with HMAC KMS keys.
+// It is kept separate from KMSClient so existing KMSClient implementations keep working; the KMSClient passed to
+// Config only needs to implement it when one of the HS* signing methods is used.
+type KMSMACClient interface {
+	GenerateMac(ctx context.Context, in *kms.GenerateMacInput, optFns ...func(*kms.Options)) (*kms.GenerateMacOutput, error)
+	VerifyMac(ctx context.Context, in *kms.VerifyMacInput, optFns ...func(*kms.Options)) (*kms.VerifyMacOutput, error)
+}
+
+// KMSEncryptionClient is the subset of `*kms.Client` functionality used when encrypting and decrypting JWEs with
+// RSA KMS keys. Like KMSMACClient, the KMSClient passed to Config only needs to implement it for JWEs.
+type KMSEncryptionClient interface {
+	Encrypt(ctx context.Context, in *kms.EncryptInput, optFns ...func(*kms.Options)) (*kms.EncryptOutput, error)
+	Decrypt(ctx context.Context, in *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// KMSDescribeKeyClient is the subset of `*kms.Client` functionality used to check the state of KMS keys, see
+// Config.CheckKeyState. Like KMSMACClient, the KMSClient passed to Config only needs to implement it for key state
+// checks.
+type KMSDescribeKeyClient interface {
+	DescribeKey(ctx context.Context, in *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error)
+}
+
 // Config is a struct to be passed to token signing/verification.
+//
+// A Config is immutable once created: the With* methods return modified copies, so a Config can be shared between
+// goroutines and derived from concurrently.
 type Config struct {
 	// context used for kms operations
 	ctx context.Context
@@ -29,26 +63,217 @@ type Config struct {
 
 	// If set to true JWT verification will be performed using KMS's Verify method
 	//
-	// In normal scenarios this can be left on the default false value, which will get, cache(forever) in memory and
-	// use the KMS key's public key to verify signatures
+	// In normal scenarios this can be left on the default false value, which will get the KMS key's public key, cache
+	// it in the least recently used cache of the Config for the public key TTL, see WithPublicKeyTTL, and use it to
+	// verify signatures
 	verifyWithKMS bool
+
+	// Additional AWS KMS Key IDs accepted when verifying, tried in order after kmsKeyID
+	verificationKeyIDs []string
+
+	// If set SignedString sets the kid header of tokens to its result
+	kid func(cfg *Config) (string, error)
+
+	// If set SignedString sets the x5c and x5t#S256 headers of tokens to it
+	certificateChain []*x509.Certificate
+
+	// Functional options applied to every KMS API call
+	apiOptions []func(*kms.Options)
+
+	// Sends the KMS calls to the FIPS endpoints
+	fipsEndpoint bool
+
+	// Endpoint URL of the KMS calls, the client's if empty
+	endpoint string
+
+	// HTTP client of the KMS calls with the TLS settings of WithEndpointTLS, the client's if nil
+	httpClient *awshttp.BuildableClient
+
+	// Credentials of the KMS calls, the client's if nil
+	credentials aws.CredentialsProvider
+
+	// Replica regions of a multi-Region key the KMS calls fail over between, none if nil
+	regions *regionSet
+
+	// Region of the KMS calls of a copy made by regionCall, the client's if empty
+	region string
+
+	// Interval of the latency probes of the regions routing signing calls by latency, not routed if zero
+	latencyProbeInterval time.Duration
+
+	// Retries of throttled KMS calls, none if nil
+	retryPolicy *RetryPolicy
+
+	// Timeout of every attempt of a KMS call, none if zero
+	callTimeout time.Duration
+
+	// Cached key states checked before signing, none if nil
+	keyStates *keyStateChecker
+
+	// Cached results of Healthy, none if nil
+	health *healthCache
+
+	// Signing method returned by SigningMethod, the default of the key spec if nil
+	signingMethod jwt.SigningMethod
+
+	// Circuit breaker guarding KMS calls, none if nil
+	circuitBreaker *CircuitBreaker
+
+	// If set KMS verification falls back to local verification while the circuit breaker is open
+	localVerifyFallback bool
+
+	// Limiter of KMS signing calls, none if nil
+	signLimiter *SignLimiter
+
+	// Recorder of operation metrics, none if nil
+	metrics MetricsRecorder
+
+	// Cache of public keys, the shared pubkeyCache if nil
+	pubKeyCache PublicKeyCache
+
+	// Remembered GetPublicKey failures, none if nil
+	publicKeyFailures *failureCache
+
+	// If set local verification is retried with a refetched public key once the cached one is
+	// refetchInterval old
+	refetchOnInvalidSignature bool
+	refetchInterval           time.Duration
+
+	// Set on the copy of a Config passed down by verifyWithRefetch
+	verifyRefetching bool
+
+	// Deduplicates concurrent GetPublicKey calls of the Configs sharing the group, none if nil
+	publicKeyFetches *singleflight.Group
+
+	// Counts the public key lookups of the Configs sharing the cache, none if nil
+	cacheLookups *lookupCounter
+
+	// Key metadata of keys without a cached public key, shared like the public key cache, none if nil
+	keyMetadataCache *keyMetadataCache
+
+	// Public keys verifying signatures instead of the ones of KMS GetPublicKey, by key id
+	providedPublicKeys map[string]*CachedPublicKey
+
+	// Set on verification-only Configs without a KMS client
+	offline bool
+
+	// Salt length of PSS signatures verified locally, rsa.PSSOptions semantics
+	pssSaltLength int
+
+	// Accepts hash length and maximum length PSS salts, overriding pssSaltLength
+	pssInterop bool
+
+	// Normalizes ECDSA signatures to low-S
+	lowS bool
+
+	// Algs of the tokens the Config verifies, any if empty
+	allowedAlgorithms []string
+
+	// typ header SignedString sets and verification requires, none if empty
+	tokenType string
+
+	// Grant tokens passed to the KMS calls, none if empty
+	grantTokens []string
+
+	// Extension headers added to the protected header of the tokens the Config signs
+	protectedHeaders map[string]interface{}
+
+	// Sends small signing strings to KMS as RAW messages instead of digests
+	rawMessages bool
+
+	// Lifetime of cached public keys, forever if zero
+	publicKeyTTL time.Duration
+
+	// Callbacks run around operations
+	hooks Hooks
+
+	// Tracer of operation spans, none if nil
+	tracer trace.Tracer
+
+	// Logger of cache misses, retries, fallbacks and key state problems, none if nil
+	logger *slog.Logger
+
+	// If set key ARNs are redacted in logs
+	redactKeyARNs bool
+
+	// Set on the copy of a Config passed down by observeVerify, so a verification is observed only once
+	verifyObserved bool
 }
 
 // NewKMSConfig create a new Config with specified parameters.
+//
+// Unlike NewConfig, the Config caches public keys in the cache shared by the package, see WithSharedPublicKeyCache,
+// so Configs created for every verification, e.g. in a jwt.Keyfunc, fetch a public key only once.
 func NewKMSConfig(client KMSClient, keyID string, verify bool) *Config {
-	return &Config{
-		ctx:           context.Background(),
-		kmsClient:     client,
-		kmsKeyID:      keyID,
-		verifyWithKMS: verify,
+	return NewConfig(client, keyID, WithVerifyWithKMS(verify), WithSharedPublicKeyCache())
+}
+
+// VerifyMode selects how a Config verifies signatures.
+type VerifyMode int
+
+const (
+	// VerifyLocally verifies signatures with the cached public key of the KMS key.
+	VerifyLocally VerifyMode = iota
+
+	// VerifyWithKMS verifies signatures with KMS Verify.
+	VerifyWithKMS
+)
+
+// KeyID returns the KMS key id the Config signs with.
+func (c *Config) KeyID() string {
+	return c.kmsKeyID
+}
+
+// VerifyMode returns how the Config verifies signatures.
+func (c *Config) VerifyMode() VerifyMode {
+	if c.verifyWithKMS {
+		return VerifyWithKMS
 	}
+
+	return VerifyLocally
 }
 
 // WithContext returns a copy of Config with context.
 func (c *Config) WithContext(ctx context.Context) *Config {
-	c2 := new(Config)
-	*c2 = *c
-	c2.ctx = ctx
+	return c.with(WithContext(ctx))
+}
+
+// WithKeyID returns a copy of Config for another KMS key, keeping the other settings.
+func (c *Config) WithKeyID(keyID string) *Config {
+	return c.with(func(c *Config) {
+		c.kmsKeyID = keyID
+	})
+}
+
+// WithVerifyMode returns a copy of Config verifying signatures with mode.
+func (c *Config) WithVerifyMode(mode VerifyMode) *Config {
+	return c.with(WithVerifyWithKMS(mode == VerifyWithKMS))
+}
+
+// macClient returns the configured client as a KMSMACClient.
+func (c *Config) macClient() (KMSMACClient, error) {
+	if c.offline {
+		return nil, errNoKMSClient
+	}
+
+	macClient, ok := c.kmsClient.(KMSMACClient)
+	if !ok {
+		return nil, errors.New("kms client does not implement KMSMACClient")
+	}
+
+	return macClient, nil
+}
+
+// encryptionClient returns the configured client as a KMSEncryptionClient.
+func (c *Config) encryptionClient() (KMSEncryptionClient, error) {
+	if c.offline {
+		return nil, errNoKMSClient
+	}
+
+	encryptionClient, ok := c.kmsClient.(KMSEncryptionClient)
+	if !ok {
+		return nil, errors.New("kms client does not implement KMSEncryptionClient")
+	}
 
-	return c2
+	return encryptionClient, nil
 }
@@ -2,4 +2,7 @@ package jwtkms
 
 import "github.com/aws/aws-sdk-go-v2/service/kms"
 
-var _ KMSClient = &kms.Client{}
+var (
+	_ KMSClient    = &kms.Client{}
+	_ KMSMACClient = &kms.Client{}
+)
@@ -0,0 +1,41 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto"
+	"io"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SignContext signs token like SignedString, using ctx for the KMS calls instead of the context of the Config.
+func (c *Config) SignContext(ctx context.Context, token *jwt.Token) (string, error) {
+	return c.WithContext(ctx).SignedString(token)
+}
+
+// VerifyContext parses tokenString into claims and verifies it with the Config, using ctx for the KMS calls instead
+// of the context of the Config.
+func (c *Config) VerifyContext(ctx context.Context, tokenString string, claims jwt.Claims, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	cfg := c.WithContext(ctx)
+
+	return parseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return cfg, cfg.checkTokenType(token)
+	}, nil, opts...)
+}
+
+// KeyfuncContext is Keyfunc with ctx used for the KMS calls of the resolved Configs.
+func KeyfuncContext(ctx context.Context, ks KeySet) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		cfg, err := configForToken(ks, token)
+		if err != nil {
+			return nil, err
+		}
+
+		return cfg.WithContext(ctx), nil
+	}
+}
+
+// SignContext is Sign with ctx used for the KMS call instead of the context of the signer's Config.
+func (s *KMSSigner) SignContext(ctx context.Context, rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return (&KMSSigner{cfg: s.cfg.WithContext(ctx)}).Sign(rand, digest, opts)
+}
@@ -0,0 +1,88 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// contextKMS fails every call whose context is done, like the AWS SDK does.
+type contextKMS struct {
+	*mockkms.MockKMS
+}
+
+func (c contextKMS) Sign(ctx context.Context, in *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return c.MockKMS.Sign(ctx, in, optFns...)
+}
+
+func (c contextKMS) Verify(ctx context.Context, in *kms.VerifyInput, optFns ...func(*kms.Options)) (*kms.VerifyOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return c.MockKMS.Verify(ctx, in, optFns...)
+}
+
+func TestPerCallContext(t *testing.T) {
+	registered(t)
+
+	client := contextKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewKMSConfig(client, id, true)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := cfg.SignContext(canceled, jwt.New(SigningMethodECDSA256)); !errors.Is(err, context.Canceled) {
+		t.Errorf("SignContext error = %v, want %v", err, context.Canceled)
+	}
+
+	signed, err := cfg.SignContext(context.Background(), jwt.New(SigningMethodECDSA256))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if _, err := cfg.VerifyContext(canceled, signed, jwt.MapClaims{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("VerifyContext error = %v, want %v", err, context.Canceled)
+	}
+
+	if _, err := cfg.VerifyContext(context.Background(), signed, jwt.MapClaims{}); err != nil {
+		t.Errorf("Error verifying token: %v", err)
+	}
+
+	token := jwt.New(SigningMethodECDSA256)
+	token.Header["kid"] = "key"
+
+	signed, err = cfg.SignedString(token)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if _, err := jwt.Parse(signed, KeyfuncContext(canceled, StaticKeySet{"key": cfg})); !errors.Is(err, context.Canceled) {
+		t.Errorf("KeyfuncContext error = %v, want %v", err, context.Canceled)
+	}
+
+	signer, err := NewKMSSigner(cfg)
+	if err != nil {
+		t.Fatalf("Error creating signer: %v", err)
+	}
+
+	digest := sha256.Sum256([]byte("message"))
+	if _, err := signer.SignContext(canceled, nil, digest[:], crypto.SHA256); !errors.Is(err, context.Canceled) {
+		t.Errorf("KMSSigner.SignContext error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,36 @@
+package jwtkms
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+)
+
+// WithCredentials makes the Config sign its KMS calls with the credentials of provider, see Config.WithCredentials.
+func WithCredentials(provider aws.CredentialsProvider) Option {
+	return func(c *Config) {
+		if _, ok := provider.(*aws.CredentialsCache); !ok && provider != nil {
+			provider = aws.NewCredentialsCache(provider)
+		}
+
+		c.credentials = provider
+	}
+}
+
+// WithCredentials returns a copy of Config signing its KMS calls with the credentials of provider instead of the
+// client's, so one client serves keys of several AWS accounts. provider is wrapped in an aws.CredentialsCache shared
+// by the copies of the Config, unless it is one.
+func (c *Config) WithCredentials(provider aws.CredentialsProvider) *Config {
+	return c.with(WithCredentials(provider))
+}
+
+// WithAssumeRole makes the Config call KMS with the credentials of roleARN, see Config.WithAssumeRole.
+func WithAssumeRole(client stscreds.AssumeRoleAPIClient, roleARN string, optFns ...func(*stscreds.AssumeRoleOptions)) Option {
+	return WithCredentials(stscreds.NewAssumeRoleProvider(client, roleARN, optFns...))
+}
+
+// WithAssumeRole returns a copy of Config calling KMS with the credentials of the IAM role roleARN, e.g. of the
+// account owning the KMS key, assumed with client, an *sts.Client. The credentials are cached until shortly before
+// they expire and shared by the copies of the Config; optFns set e.g. the session name or external id.
+func (c *Config) WithAssumeRole(client stscreds.AssumeRoleAPIClient, roleARN string, optFns ...func(*stscreds.AssumeRoleOptions)) *Config {
+	return c.with(WithAssumeRole(client, roleARN, optFns...))
+}
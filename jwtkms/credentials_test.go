@@ -0,0 +1,116 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeSTS hands out credentials whose access key id is the assumed role's ARN.
+type fakeSTS struct {
+	calls atomic.Int32
+}
+
+func (s *fakeSTS) AssumeRole(_ context.Context, in *sts.AssumeRoleInput, _ ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	s.calls.Add(1)
+
+	return &sts.AssumeRoleOutput{
+		Credentials: &ststypes.Credentials{
+			AccessKeyId:     aws.String("AKID-" + aws.ToString(in.RoleArn)),
+			SecretAccessKey: aws.String("secret"),
+			SessionToken:    aws.String("token"),
+			Expiration:      aws.Time(time.Now().Add(time.Hour)),
+		},
+	}, nil
+}
+
+func TestWithCredentials(t *testing.T) {
+	recorder := &hostRecorder{}
+	client := newRecordingClient("us-east-1", recorder)
+	ctx := context.Background()
+
+	sign := func(cfg *Config) {
+		t.Helper()
+
+		if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg.WithContext(ctx)); !errors.Is(err, errRequestCaptured) {
+			t.Fatalf("err = %v, want %v", err, errRequestCaptured)
+		}
+	}
+
+	sign(NewConfig(client, "alias/signing", WithCredentials(credentials.NewStaticCredentialsProvider("AKID-STATIC", "secret", ""))))
+	if !strings.Contains(recorder.authorization, "Credential=AKID-STATIC/") {
+		t.Errorf("authorization = %q, want the static credentials", recorder.authorization)
+	}
+
+	stsClient := &fakeSTS{}
+	role := "arn:aws:iam::444455556666:role/signer"
+	cfg := NewConfig(client, "alias/signing").WithAssumeRole(stsClient, role)
+
+	sign(cfg)
+	sign(cfg.WithKeyID("alias/other"))
+
+	if !strings.Contains(recorder.authorization, "Credential=AKID-"+role+"/") {
+		t.Errorf("authorization = %q, want the credentials of %s", recorder.authorization, role)
+	}
+
+	if stsClient.calls.Load() != 1 {
+		t.Errorf("AssumeRole calls = %d, want 1", stsClient.calls.Load())
+	}
+}
+
+func TestNewKeyRegistryWithAssumeRole(t *testing.T) {
+	recorder := &hostRecorder{}
+	stsClient := &fakeSTS{}
+	registry := NewKeyRegistryWithAssumeRole(newRecordingClient("us-east-1", recorder), stsClient)
+
+	role := "arn:aws:iam::444455556666:role/signer"
+	entries := []KeyEntry{
+		{KID: "cross-account", KeyID: "alias/a", Region: "eu-west-1", Role: role},
+		{KID: "own-credentials", KeyID: "alias/b", Credentials: credentials.NewStaticCredentialsProvider("AKID-ENTRY", "secret", "")},
+	}
+
+	for _, entry := range entries {
+		if err := registry.Add(entry); err != nil {
+			t.Fatalf("Error adding key: %v", err)
+		}
+	}
+
+	tests := []struct {
+		kid        string
+		wantHost   string
+		credential string
+	}{
+		{kid: "cross-account", wantHost: "kms.eu-west-1.amazonaws.com", credential: "AKID-" + role},
+		{kid: "own-credentials", wantHost: "kms.us-east-1.amazonaws.com", credential: "AKID-ENTRY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kid, func(t *testing.T) {
+			cfg, err := registry.ConfigForKID(tt.kid)
+			if err != nil {
+				t.Fatalf("Error getting config: %v", err)
+			}
+
+			if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg.WithContext(context.Background())); !errors.Is(err, errRequestCaptured) {
+				t.Fatalf("err = %v, want %v", err, errRequestCaptured)
+			}
+
+			if recorder.host != tt.wantHost {
+				t.Errorf("host = %s, want %s", recorder.host, tt.wantHost)
+			}
+
+			if !strings.Contains(recorder.authorization, "Credential="+tt.credential+"/") {
+				t.Errorf("authorization = %q, want the credentials %s", recorder.authorization, tt.credential)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package jwtkms
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SignDetached signs payload with method into a JWS with a detached, unencoded payload, RFC 7797, as required e.g. by
+// Open Banking request signing, using ctx for the KMS calls. The payload is signed as is, with the b64 header false
+// and listed in crit, and left out of the returned header..signature.
+//
+// header holds additional headers, alg is set from method and the headers derived from the Config are set like in
+// SignedString. header is not modified.
+func (c *Config) SignDetached(ctx context.Context, method jwt.SigningMethod, header map[string]interface{}, payload []byte) (string, error) {
+	token := &jwt.Token{Header: maps.Clone(header), Method: method}
+	if token.Header == nil {
+		token.Header = make(map[string]interface{})
+	}
+
+	crit, err := critHeader(token.Header)
+	if err != nil {
+		return "", err
+	}
+
+	if !slices.Contains(crit, "b64") {
+		crit = append(crit, "b64")
+	}
+
+	token.Header["alg"] = method.Alg()
+	token.Header["b64"] = false
+	token.Header["crit"] = crit
+
+	cfg := c.WithContext(ctx)
+	if err := cfg.setHeaders(token); err != nil {
+		return "", err
+	}
+
+	encodedHeader, err := json.Marshal(token.Header)
+	if err != nil {
+		return "", fmt.Errorf("marshalling header: %w", err)
+	}
+
+	signingString := base64.RawURLEncoding.EncodeToString(encodedHeader) + "." + string(payload)
+
+	sig, err := method.Sign(signingString, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(encodedHeader) + ".." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// VerifyDetached verifies jws, a compact JWS with a detached payload, against payload and method, using ctx for the
+// KMS calls, and returns its header. The payload is unencoded if the b64 header is false, RFC 7797, and base64url
+// encoded otherwise, RFC 7515 appendix F.
+//
+// The alg header must be the one of method, and the JWS is rejected if crit lists headers other than b64, or b64 is
+// false and not listed in crit.
+func (c *Config) VerifyDetached(ctx context.Context, method jwt.SigningMethod, jws string, payload []byte) (map[string]interface{}, error) {
+	encodedHeader, encodedSig, ok := strings.Cut(jws, "..")
+	if !ok || strings.Contains(encodedSig, ".") {
+		return nil, fmt.Errorf("%w: not a detached jws", jwt.ErrTokenMalformed)
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(encodedHeader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decoding header: %w", jwt.ErrTokenMalformed, err)
+	}
+
+	var header map[string]interface{}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("%w: parsing header: %w", jwt.ErrTokenMalformed, err)
+	}
+
+	if alg, _ := header["alg"].(string); alg != method.Alg() {
+		return nil, fmt.Errorf("%w: alg %q, expected %q", jwt.ErrTokenSignatureInvalid, header["alg"], method.Alg())
+	}
+
+	encoded, err := b64Header(header)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decoding signature: %w", jwt.ErrTokenMalformed, err)
+	}
+
+	signingString := encodedHeader + "." + string(payload)
+	if encoded {
+		signingString = encodedHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	}
+
+	if err := method.Verify(signingString, sig, c.WithContext(ctx)); err != nil {
+		return nil, fmt.Errorf("%w: %w", jwt.ErrTokenSignatureInvalid, err)
+	}
+
+	return header, nil
+}
+
+// critHeader returns the crit header, none if absent.
+func critHeader(header map[string]interface{}) ([]string, error) {
+	value, ok := header["crit"]
+	if !ok {
+		return nil, nil
+	}
+
+	var crit []string
+	switch value := value.(type) {
+	case []string:
+		crit = slices.Clone(value)
+
+	case []interface{}:
+		for _, v := range value {
+			name, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: crit header is not a list of strings", jwt.ErrTokenMalformed)
+			}
+
+			crit = append(crit, name)
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: crit header is not a list of strings", jwt.ErrTokenMalformed)
+	}
+
+	if len(crit) == 0 {
+		return nil, fmt.Errorf("%w: crit header is empty", jwt.ErrTokenMalformed)
+	}
+
+	return crit, nil
+}
+
+// b64Header returns whether the payload of a JWS with header is base64url encoded, checking the crit header.
+func b64Header(header map[string]interface{}) (bool, error) {
+	crit, err := critHeader(header)
+	if err != nil {
+		return false, err
+	}
+
+	for _, name := range crit {
+		if name != "b64" {
+			return false, fmt.Errorf("%w: unsupported critical header %q", jwt.ErrTokenUnverifiable, name)
+		}
+
+		if _, ok := header[name]; !ok {
+			return false, fmt.Errorf("%w: critical header %q is missing", jwt.ErrTokenMalformed, name)
+		}
+	}
+
+	value, ok := header["b64"]
+	if !ok {
+		return true, nil
+	}
+
+	encoded, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: b64 header is not a boolean", jwt.ErrTokenMalformed)
+	}
+
+	if !encoded && !slices.Contains(crit, "b64") {
+		return false, fmt.Errorf("%w: b64 header is false but not critical", jwt.ErrTokenMalformed)
+	}
+
+	return encoded, nil
+}
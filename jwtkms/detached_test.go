@@ -0,0 +1,120 @@
+package jwtkms
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestSignDetached(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id, WithKID("signing-key"))
+	payload := []byte(`{"Data":{"Amount":"10.00"}}`)
+
+	header := map[string]interface{}{"typ": "JOSE"}
+
+	jws, err := cfg.SignDetached(ctx, SigningMethodPS256, header, payload)
+	if err != nil {
+		t.Fatalf("Error signing detached jws: %v", err)
+	}
+
+	if len(header) != 1 {
+		t.Errorf("header was modified: %v", header)
+	}
+
+	got, err := cfg.VerifyDetached(ctx, SigningMethodPS256, jws, payload)
+	if err != nil {
+		t.Fatalf("Error verifying detached jws: %v", err)
+	}
+
+	if got["b64"] != false || got["kid"] != "signing-key" || got["typ"] != "JOSE" {
+		t.Errorf("header = %v", got)
+	}
+
+	if _, err := cfg.VerifyDetached(ctx, SigningMethodPS256, jws, []byte(`{"Data":{"Amount":"99.00"}}`)); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Errorf("err = %v, want %v", err, jwt.ErrTokenSignatureInvalid)
+	}
+
+	if _, err := cfg.VerifyDetached(ctx, SigningMethodRS256, jws, payload); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Errorf("err = %v, want %v for another alg", err, jwt.ErrTokenSignatureInvalid)
+	}
+}
+
+func TestVerifyDetachedEncodedPayload(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id)
+
+	signed, err := jwt.NewWithClaims(SigningMethodECDSA256, jwt.MapClaims{"sub": "detached"}).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	parts := strings.Split(signed, ".")
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("Error decoding payload: %v", err)
+	}
+
+	if _, err := cfg.VerifyDetached(context.Background(), SigningMethodECDSA256, parts[0]+".."+parts[2], payload); err != nil {
+		t.Errorf("Error verifying detached jws: %v", err)
+	}
+}
+
+func TestVerifyDetachedInvalid(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id)
+
+	jws := func(header map[string]interface{}) string {
+		data, err := json.Marshal(header)
+		if err != nil {
+			t.Fatalf("Error marshalling header: %v", err)
+		}
+
+		return base64.RawURLEncoding.EncodeToString(data) + "..c2ln"
+	}
+
+	tests := []struct {
+		name string
+		jws  string
+		want error
+	}{
+		{name: "attached payload", jws: "e30.cGF5bG9hZA.c2ln", want: jwt.ErrTokenMalformed},
+		{name: "b64 not critical", jws: jws(map[string]interface{}{"alg": "ES256", "b64": false}), want: jwt.ErrTokenMalformed},
+		{name: "b64 not boolean", jws: jws(map[string]interface{}{"alg": "ES256", "b64": "false", "crit": []string{"b64"}}), want: jwt.ErrTokenMalformed},
+		{name: "unknown critical header", jws: jws(map[string]interface{}{"alg": "ES256", "b64": false, "crit": []string{"b64", "exp"}, "exp": 1}), want: jwt.ErrTokenUnverifiable},
+		{name: "missing critical header", jws: jws(map[string]interface{}{"alg": "ES256", "crit": []string{"b64"}}), want: jwt.ErrTokenMalformed},
+		{name: "empty crit", jws: jws(map[string]interface{}{"alg": "ES256", "crit": []string{}}), want: jwt.ErrTokenMalformed},
+		{name: "alg none", jws: jws(map[string]interface{}{"alg": "none"}), want: jwt.ErrTokenSignatureInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := cfg.VerifyDetached(context.Background(), SigningMethodECDSA256, tt.jws, []byte("payload")); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DPoPRequest describes the HTTP request a DPoP proof is created for.
+type DPoPRequest struct {
+	// Method is the HTTP method of the request, e.g. POST.
+	Method string
+
+	// URL is the target URI of the request; its query and fragment are left out of the proof.
+	URL string
+
+	// AccessToken, if set, is the access token sent with the request, bound to the proof with the ath claim.
+	AccessToken string
+
+	// Nonce, if set, is the nonce provided by the server in the DPoP-Nonce header.
+	Nonce string
+}
+
+// DPoPProof creates a DPoP proof JWT, RFC 9449, for req signed with the Config and method, using ctx for the KMS
+// calls, for clients binding access tokens to a KMS key. The header has typ dpop+jwt and the public JWK of the key,
+// the claims a random jti, htm, htu and iat, and ath and nonce when set.
+func (c *Config) DPoPProof(ctx context.Context, method jwt.SigningMethod, req DPoPRequest) (string, error) {
+	htu, err := url.Parse(req.URL)
+	if err != nil {
+		return "", fmt.Errorf("parsing url: %w", err)
+	}
+
+	htu.RawQuery, htu.Fragment, htu.RawFragment = "", "", ""
+
+	cfg := c.WithContext(ctx)
+
+	pub, err := getPublicKey(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	jwk, err := NewJWK(pub)
+	if err != nil {
+		return "", fmt.Errorf("creating jwk: %w", err)
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", fmt.Errorf("generating jti: %w", err)
+	}
+
+	claims := jwt.MapClaims{
+		"jti": base64.RawURLEncoding.EncodeToString(jti),
+		"htm": req.Method,
+		"htu": htu.String(),
+		"iat": time.Now().Unix(),
+	}
+
+	if req.AccessToken != "" {
+		ath := sha256.Sum256([]byte(req.AccessToken))
+		claims["ath"] = base64.RawURLEncoding.EncodeToString(ath[:])
+	}
+
+	if req.Nonce != "" {
+		claims["nonce"] = req.Nonce
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = jwk
+
+	return token.SignedString(cfg)
+}
@@ -0,0 +1,95 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestDPoPProof(t *testing.T) {
+	registered(t)
+
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id)
+
+	proof, err := cfg.DPoPProof(context.Background(), SigningMethodECDSA256, DPoPRequest{
+		Method:      "GET",
+		URL:         "https://resource.example.org/protected?page=2#top",
+		AccessToken: "Kz~8mXK1EalYznwH-LC-1fBAo.4Ljp~zsPE_NeO.gxU",
+		Nonce:       "eyJ7S_zG.eyJH0-Z.HX4w-7v",
+	})
+	if err != nil {
+		t.Fatalf("Error creating proof: %v", err)
+	}
+
+	// the proof verifies with the key of its jwk header alone, like a DPoP server does it
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(proof, claims, func(token *jwt.Token) (interface{}, error) {
+		data, err := json.Marshal(token.Header["jwk"])
+		if err != nil {
+			return nil, err
+		}
+
+		var jwk JWK
+		if err := json.Unmarshal(data, &jwk); err != nil {
+			return nil, err
+		}
+
+		pub, err := jwk.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+
+		return NewOfflineConfig(id, pub), nil
+	})
+	if err != nil {
+		t.Fatalf("Error verifying proof: %v", err)
+	}
+
+	if token.Header["typ"] != "dpop+jwt" {
+		t.Errorf("typ = %v, want dpop+jwt", token.Header["typ"])
+	}
+
+	ath := sha256.Sum256([]byte("Kz~8mXK1EalYznwH-LC-1fBAo.4Ljp~zsPE_NeO.gxU"))
+
+	want := map[string]interface{}{
+		"htm":   "GET",
+		"htu":   "https://resource.example.org/protected",
+		"ath":   base64.RawURLEncoding.EncodeToString(ath[:]),
+		"nonce": "eyJ7S_zG.eyJH0-Z.HX4w-7v",
+	}
+
+	for name, value := range want {
+		if claims[name] != value {
+			t.Errorf("%s = %v, want %v", name, claims[name], value)
+		}
+	}
+
+	if claims["jti"] == "" || claims["iat"] == nil {
+		t.Errorf("claims = %v", claims)
+	}
+
+	other, err := cfg.DPoPProof(context.Background(), SigningMethodECDSA256, DPoPRequest{Method: "GET", URL: "https://resource.example.org/protected"})
+	if err != nil {
+		t.Fatalf("Error creating proof: %v", err)
+	}
+
+	otherClaims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(other, otherClaims); err != nil {
+		t.Fatalf("Error parsing proof: %v", err)
+	}
+
+	if otherClaims["jti"] == claims["jti"] {
+		t.Errorf("jti is not unique")
+	}
+}
@@ -0,0 +1,149 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// dryRunMessage is the message of dry run calls, KMS does not sign it.
+var dryRunMessage = []byte("jwtkms dry run")
+
+// DryRun checks that the caller is permitted to sign and verify tokens of method with the KMS key and that the key is
+// usable, e.g. in a deploy pipeline, with KMS Sign and Verify calls, GenerateMac and VerifyMac for HMAC methods, that
+// have DryRun set: KMS checks the permissions and key state of the calls without signing anything. The grant tokens
+// and API options of the Config are used; the calls are not retried, traced or counted in the metrics.
+//
+// The returned error is the mapped KMS error of the first failing call, e.g. ErrAccessDenied or ErrKeyDisabled.
+func (c *Config) DryRun(ctx context.Context, method jwt.SigningMethod) error {
+	cfg := c.WithContext(ctx)
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	if cfg.offline {
+		return errNoKMSClient
+	}
+
+	if m, ok := method.(*HMACSigningMethod); ok {
+		return cfg.dryRunMAC(types.MacAlgorithmSpec(m.algo))
+	}
+
+	m, ok := method.(kmsSigningMethod)
+	if !ok || m.kmsSigningAlgorithm() == "" {
+		return &ConfigError{Field: "SigningMethod", Err: fmt.Errorf("%s is not a jwtkms signing method", method.Alg())}
+	}
+
+	algorithm := m.kmsSigningAlgorithm()
+
+	if err := cfg.dryRunSign(algorithm); err != nil {
+		return fmt.Errorf("dry run of sign: %w", err)
+	}
+
+	if err := cfg.dryRunVerify(algorithm); err != nil {
+		return fmt.Errorf("dry run of verify: %w", err)
+	}
+
+	return nil
+}
+
+// dryRunMAC runs the dry run of DryRun for an HMAC key.
+func (c *Config) dryRunMAC(algorithm types.MacAlgorithmSpec) error {
+	if err := c.dryRunGenerateMac(algorithm); err != nil {
+		return fmt.Errorf("dry run of generate mac: %w", err)
+	}
+
+	if err := c.dryRunVerifyMac(algorithm); err != nil {
+		return fmt.Errorf("dry run of verify mac: %w", err)
+	}
+
+	return nil
+}
+
+// dryRunSign sends a KMS Sign call with DryRun set.
+func (c *Config) dryRunSign(algorithm types.SigningAlgorithmSpec) error {
+	return dryRunCall(func() error {
+		_, err := c.kmsClient.Sign(c.ctx, &kms.SignInput{
+			KeyId:            aws.String(c.kmsKeyID),
+			Message:          dryRunMessage,
+			MessageType:      types.MessageTypeRaw,
+			SigningAlgorithm: algorithm,
+			GrantTokens:      c.grantTokens,
+			DryRun:           aws.Bool(true),
+		}, c.kmsOptions()...)
+		return err
+	})
+}
+
+// dryRunVerify sends a KMS Verify call with DryRun set.
+func (c *Config) dryRunVerify(algorithm types.SigningAlgorithmSpec) error {
+	return dryRunCall(func() error {
+		_, err := c.kmsClient.Verify(c.ctx, &kms.VerifyInput{
+			KeyId:            aws.String(c.kmsKeyID),
+			Message:          dryRunMessage,
+			MessageType:      types.MessageTypeRaw,
+			Signature:        dryRunMessage,
+			SigningAlgorithm: algorithm,
+			GrantTokens:      c.grantTokens,
+			DryRun:           aws.Bool(true),
+		}, c.kmsOptions()...)
+		return err
+	})
+}
+
+// dryRunGenerateMac sends a KMS GenerateMac call with DryRun set.
+func (c *Config) dryRunGenerateMac(algorithm types.MacAlgorithmSpec) error {
+	macClient, err := c.macClient()
+	if err != nil {
+		return err
+	}
+
+	return dryRunCall(func() error {
+		_, err := macClient.GenerateMac(c.ctx, &kms.GenerateMacInput{
+			KeyId:        aws.String(c.kmsKeyID),
+			MacAlgorithm: algorithm,
+			Message:      dryRunMessage,
+			GrantTokens:  c.grantTokens,
+			DryRun:       aws.Bool(true),
+		}, c.kmsOptions()...)
+		return err
+	})
+}
+
+// dryRunVerifyMac sends a KMS VerifyMac call with DryRun set.
+func (c *Config) dryRunVerifyMac(algorithm types.MacAlgorithmSpec) error {
+	macClient, err := c.macClient()
+	if err != nil {
+		return err
+	}
+
+	return dryRunCall(func() error {
+		_, err := macClient.VerifyMac(c.ctx, &kms.VerifyMacInput{
+			KeyId:        aws.String(c.kmsKeyID),
+			Mac:          dryRunMessage,
+			MacAlgorithm: algorithm,
+			Message:      dryRunMessage,
+			GrantTokens:  c.grantTokens,
+			DryRun:       aws.Bool(true),
+		}, c.kmsOptions()...)
+		return err
+	})
+}
+
+// dryRunCall runs a KMS call with DryRun set. The DryRunOperationException KMS answers calls that would have
+// succeeded with is no error, other errors are mapped with mapKMSError.
+func dryRunCall(call func() error) error {
+	err := call()
+
+	var dryRun *types.DryRunOperationException
+	if err == nil || errors.As(err, &dryRun) {
+		return nil
+	}
+
+	return mapKMSError(err)
+}
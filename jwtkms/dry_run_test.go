@@ -0,0 +1,67 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/smithy-go"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// verifyDeniedKMS denies KMS Verify calls, like a key policy granting only kms:Sign.
+type verifyDeniedKMS struct {
+	*mockkms.MockKMS
+}
+
+func (c *verifyDeniedKMS) Verify(context.Context, *kms.VerifyInput, ...func(*kms.Options)) (*kms.VerifyOutput, error) {
+	return nil, &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized to perform kms:Verify"}
+}
+
+func TestDryRun(t *testing.T) {
+	client := mockkms.NewMockKMS()
+
+	generate := func(keyType mockkms.KeyType) string {
+		id, err := client.GenerateKey(keyType)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		return id
+	}
+
+	ecdsaKey := generate(mockkms.KeyTypeECCNISTP256)
+	rsaKey := generate(mockkms.KeyTypeRSA2048)
+	hmacKey := generate(mockkms.KeyTypeHMAC256)
+	disabledKey := generate(mockkms.KeyTypeECCNISTP256)
+	client.DisableKey(disabledKey)
+
+	tests := []struct {
+		name    string
+		cfg     *Config
+		method  jwt.SigningMethod
+		wantErr error
+	}{
+		{name: "ES256", cfg: NewConfig(client, ecdsaKey), method: SigningMethodECDSA256},
+		{name: "PS256", cfg: NewConfig(client, rsaKey), method: SigningMethodPS256},
+		{name: "HS256", cfg: NewConfig(client, hmacKey), method: SigningMethodHS256},
+		{name: "disabled key", cfg: NewConfig(client, disabledKey), method: SigningMethodECDSA256, wantErr: ErrKeyDisabled},
+		{name: "unknown key", cfg: NewConfig(client, "unknown"), method: SigningMethodECDSA256, wantErr: ErrKeyNotFound},
+		{name: "verify denied", cfg: NewConfig(&verifyDeniedKMS{MockKMS: client}, ecdsaKey), method: SigningMethodECDSA256, wantErr: ErrAccessDenied},
+		{name: "foreign method", cfg: NewConfig(client, ecdsaKey), method: jwt.SigningMethodES256, wantErr: ErrInvalidConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.DryRun(context.Background(), tt.method); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := NewConfig(client, ecdsaKey).DryRun(context.Background(), SigningMethodECDSA384); err == nil {
+		t.Errorf("expected error for an algorithm the key does not support")
+	}
+}
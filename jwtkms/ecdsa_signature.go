@@ -0,0 +1,82 @@
+package jwtkms
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ecdsaSignature is the ASN.1 structure of an ECDSA signature as returned by KMS.
+type ecdsaSignature struct {
+	R *big.Int
+	S *big.Int
+}
+
+// curveByteSize returns the size of the R and S values of signatures on a curve of curveBits bits.
+func curveByteSize(curveBits int) (int, error) {
+	if curveBits <= 0 {
+		return 0, fmt.Errorf("invalid curve size of %d bits", curveBits)
+	}
+
+	return (curveBits + 7) / 8, nil
+}
+
+// DERToJOSE converts an ASN.1 DER encoded ECDSA signature, as returned by KMS Sign and crypto.Signer
+// implementations like KMSSigner, to the R||S form of JOSE (RFC 7518 section 3.4) for a curve of curveBits bits, like
+// 256 for ES256 or 521 for ES512. It fails if R or S do not fit the curve size.
+func DERToJOSE(der []byte, curveBits int) ([]byte, error) {
+	keyBytes, err := curveByteSize(curveBits)
+	if err != nil {
+		return nil, err
+	}
+
+	var p ecdsaSignature
+
+	rest, err := asn1.Unmarshal(der, &p)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling signature: %w", err)
+	}
+
+	if len(rest) > 0 {
+		return nil, errors.New("unmarshalling signature: trailing data")
+	}
+
+	if p.R.Sign() <= 0 || p.S.Sign() <= 0 {
+		return nil, errors.New("signature values are not positive")
+	}
+
+	if p.R.BitLen() > 8*keyBytes || p.S.BitLen() > 8*keyBytes {
+		return nil, fmt.Errorf("signature values do not fit a %d bit curve", curveBits)
+	}
+
+	// R and S are padded with zeros on the left to keyBytes each
+	out := make([]byte, 2*keyBytes)
+	p.R.FillBytes(out[:keyBytes])
+	p.S.FillBytes(out[keyBytes:])
+
+	return out, nil
+}
+
+// JOSEToDER converts a JOSE R||S ECDSA signature for a curve of curveBits bits to its ASN.1 DER encoding, as taken by
+// KMS Verify and ecdsa.VerifyASN1. It fails if sig is not twice the curve size long.
+func JOSEToDER(sig []byte, curveBits int) ([]byte, error) {
+	keyBytes, err := curveByteSize(curveBits)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sig) != 2*keyBytes {
+		return nil, fmt.Errorf("signature is %d bytes, a %d bit curve needs %d", len(sig), curveBits, 2*keyBytes)
+	}
+
+	der, err := asn1.Marshal(ecdsaSignature{
+		R: new(big.Int).SetBytes(sig[:keyBytes]),
+		S: new(big.Int).SetBytes(sig[keyBytes:]),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling signature: %w", err)
+	}
+
+	return der, nil
+}
@@ -0,0 +1,97 @@
+package jwtkms
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+)
+
+func TestDERToJOSE(t *testing.T) {
+	tests := []struct {
+		name  string
+		curve elliptic.Curve
+	}{
+		{name: "P-256", curve: elliptic.P256()},
+		{name: "P-384", curve: elliptic.P384()},
+		{name: "P-521", curve: elliptic.P521()},
+	}
+
+	digest := sha256.Sum256([]byte("header.payload"))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ecdsa.GenerateKey(tt.curve, rand.Reader)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			der, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+			if err != nil {
+				t.Fatalf("Error signing: %v", err)
+			}
+
+			bits := tt.curve.Params().BitSize
+
+			jose, err := DERToJOSE(der, bits)
+			if err != nil {
+				t.Fatalf("Error converting signature to JOSE: %v", err)
+			}
+
+			if want := 2 * ((bits + 7) / 8); len(jose) != want {
+				t.Fatalf("len(jose) = %d, want %d", len(jose), want)
+			}
+
+			back, err := JOSEToDER(jose, bits)
+			if err != nil {
+				t.Fatalf("Error converting signature to DER: %v", err)
+			}
+
+			if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], back) {
+				t.Errorf("converted signature does not verify")
+			}
+		})
+	}
+}
+
+func TestDERToJOSEInvalid(t *testing.T) {
+	mustMarshal := func(r, s *big.Int) []byte {
+		der, err := asn1.Marshal(ecdsaSignature{R: r, S: s})
+		if err != nil {
+			t.Fatalf("Error marshalling signature: %v", err)
+		}
+
+		return der
+	}
+
+	one := big.NewInt(1)
+	tooLarge := new(big.Int).Lsh(one, 256)
+
+	tests := []struct {
+		name      string
+		der       []byte
+		curveBits int
+	}{
+		{name: "R too large", der: mustMarshal(tooLarge, one), curveBits: 256},
+		{name: "S too large", der: mustMarshal(one, tooLarge), curveBits: 256},
+		{name: "zero R", der: mustMarshal(big.NewInt(0), one), curveBits: 256},
+		{name: "trailing data", der: append(mustMarshal(one, one), 0), curveBits: 256},
+		{name: "not DER", der: []byte{1, 2, 3}, curveBits: 256},
+		{name: "no curve size", der: mustMarshal(one, one), curveBits: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DERToJOSE(tt.der, tt.curveBits); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+
+	if _, err := JOSEToDER(make([]byte, 63), 256); err == nil {
+		t.Errorf("expected error for a JOSE signature of the wrong length")
+	}
+}
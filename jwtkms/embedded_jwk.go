@@ -0,0 +1,69 @@
+package jwtkms
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrJWKNotPinned is returned for tokens whose jwk header is not one of the pinned keys.
+var ErrJWKNotPinned = errors.New("jwk header is not a pinned key")
+
+// PinnedJWKKeyfunc returns a jwt.Keyfunc verifying tokens with the public key of their jwk header, RFC 7515 section
+// 4.1.3, like DPoP proofs, but only if its base64url encoded RFC 7638 SHA-256 thumbprint is one of pins. Tokens
+// without a jwk header, with private key members in it or with another key fail, ErrJWKNotPinned for the latter.
+//
+// The algorithm of the key is not checked against the alg of the token, restrict it with jwt.WithValidMethods.
+func PinnedJWKKeyfunc(pins ...string) jwt.Keyfunc {
+	pins = slices.Clone(pins)
+
+	return func(token *jwt.Token) (interface{}, error) {
+		jwk, err := embeddedJWK(token)
+		if err != nil {
+			return nil, err
+		}
+
+		thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("computing jwk thumbprint: %w", err)
+		}
+
+		if encoded := base64.RawURLEncoding.EncodeToString(thumbprint); !slices.Contains(pins, encoded) {
+			return nil, fmt.Errorf("%w: thumbprint %s", ErrJWKNotPinned, encoded)
+		}
+
+		return jwk.PublicKey()
+	}
+}
+
+// embeddedJWK returns the public JWK of the jwk header of token.
+func embeddedJWK(token *jwt.Token) (*JWK, error) {
+	header, ok := token.Header["jwk"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("token has no jwk header")
+	}
+
+	// members of private keys, RFC 7518 sections 6.2.2, 6.3.2 and 6.4.1
+	for _, member := range []string{"d", "p", "q", "dp", "dq", "qi", "oth", "k"} {
+		if _, ok := header[member]; ok {
+			return nil, fmt.Errorf("jwk header has private key member %q", member)
+		}
+	}
+
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling jwk header: %w", err)
+	}
+
+	var jwk JWK
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return nil, fmt.Errorf("unmarshalling jwk header: %w", err)
+	}
+
+	return &jwk, nil
+}
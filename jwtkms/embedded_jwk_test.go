@@ -0,0 +1,83 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestPinnedJWKKeyfunc(t *testing.T) {
+	registered(t)
+
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id)
+
+	proof, err := cfg.DPoPProof(ctx, SigningMethodECDSA256, DPoPRequest{Method: "GET", URL: "https://resource.example.org/"})
+	if err != nil {
+		t.Fatalf("Error creating proof: %v", err)
+	}
+
+	pub, err := ParsePublicKeyPEM(publicKeyPEM(t, client, id))
+	if err != nil {
+		t.Fatalf("Error parsing public key: %v", err)
+	}
+
+	jwk, err := NewJWK(pub)
+	if err != nil {
+		t.Fatalf("Error creating jwk: %v", err)
+	}
+
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		t.Fatalf("Error computing thumbprint: %v", err)
+	}
+
+	pin := base64.RawURLEncoding.EncodeToString(thumbprint)
+
+	if _, err := jwt.Parse(proof, PinnedJWKKeyfunc("other", pin), jwt.WithValidMethods([]string{"ES256"})); err != nil {
+		t.Fatalf("Error verifying proof with a pinned jwk: %v", err)
+	}
+
+	if _, err := jwt.Parse(proof, PinnedJWKKeyfunc("other")); !errors.Is(err, ErrJWKNotPinned) {
+		t.Errorf("err = %v, want %v", err, ErrJWKNotPinned)
+	}
+
+	header := map[string]interface{}{"kty": jwk.KeyType, "crv": jwk.Curve, "x": jwk.X, "y": jwk.Y, "d": "AA"}
+
+	tests := []struct {
+		name   string
+		header map[string]interface{}
+	}{
+		{name: "no jwk", header: map[string]interface{}{}},
+		{name: "private key", header: map[string]interface{}{"jwk": header}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := jwt.New(SigningMethodECDSA256)
+			for name, value := range tt.header {
+				token.Header[name] = value
+			}
+
+			signed, err := cfg.SignContext(ctx, token)
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			if _, err := jwt.Parse(signed, PinnedJWKKeyfunc(pin)); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
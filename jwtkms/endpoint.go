@@ -0,0 +1,77 @@
+package jwtkms
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// WithEndpoint makes the Config send its KMS calls to endpoint, see Config.WithEndpoint.
+func WithEndpoint(endpoint string) Option {
+	return func(c *Config) {
+		c.endpoint = endpoint
+	}
+}
+
+// WithEndpoint returns a copy of Config sending its KMS calls to endpoint, a URL like http://localhost:4566, instead
+// of the endpoint of the client's region, e.g. for LocalStack, local-kms or a VPC endpoint. It applies to *kms.Client
+// clients, which resolve endpoints per call, so the same client serves emulators and AWS.
+func (c *Config) WithEndpoint(endpoint string) *Config {
+	return c.with(WithEndpoint(endpoint))
+}
+
+// WithEndpointTLS makes the Config send its KMS calls with the TLS settings tlsConfig, see Config.WithEndpointTLS.
+func WithEndpointTLS(tlsConfig *tls.Config) Option {
+	return func(c *Config) {
+		c.httpClient = awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+			tr.TLSClientConfig = tlsConfig.Clone()
+		})
+	}
+}
+
+// WithEndpointTLS returns a copy of Config sending its KMS calls over an HTTP client with the TLS settings tlsConfig
+// instead of the client's, e.g. trusting the self-signed certificate of an emulator with RootCAs. The HTTP client is
+// shared by the copies of the Config.
+func (c *Config) WithEndpointTLS(tlsConfig *tls.Config) *Config {
+	return c.with(WithEndpointTLS(tlsConfig))
+}
+
+// kmsOptions returns the functional options of the Config's KMS calls: those of WithAPIOptions followed by the ones
+// of the endpoint, credentials and region settings.
+func (c *Config) kmsOptions() []func(*kms.Options) {
+	region := c.region
+	if region == "" && c.regions != nil {
+		region = c.regions.regions[0]
+	}
+
+	if !c.fipsEndpoint && c.endpoint == "" && c.httpClient == nil && c.credentials == nil && region == "" {
+		return c.apiOptions
+	}
+
+	optFns := append([]func(*kms.Options){}, c.apiOptions...)
+
+	return append(optFns, func(o *kms.Options) {
+		if c.fipsEndpoint {
+			o.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateEnabled
+		}
+
+		if c.endpoint != "" {
+			o.BaseEndpoint = aws.String(c.endpoint)
+		}
+
+		if c.httpClient != nil {
+			o.HTTPClient = c.httpClient
+		}
+
+		if c.credentials != nil {
+			o.Credentials = c.credentials
+		}
+
+		if region != "" {
+			o.Region = region
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestWithEndpoint(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"__type":"NotFoundException","message":"no such key"}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	client := kms.New(kms.Options{
+		Region:      "us-east-1",
+		Credentials: aws.AnonymousCredentials{},
+		Retryer:     aws.NopRetryer{},
+	})
+
+	cfg := NewConfig(client, "alias/signing", WithEndpoint(server.URL)).WithContext(context.Background())
+
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); err == nil || requests.Load() != 0 {
+		t.Fatalf("err = %v, requests = %d, want a TLS error without requests", err, requests.Load())
+	}
+
+	cfg = cfg.WithEndpointTLS(&tls.Config{RootCAs: roots})
+
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("err = %v, want %v", err, ErrKeyNotFound)
+	}
+
+	if requests.Load() == 0 {
+		t.Errorf("the endpoint got no requests")
+	}
+}
@@ -0,0 +1,70 @@
+package jwtkms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/smithy-go"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrKeyDisabled is returned when the KMS key is disabled or in a state that does not allow the operation,
+	// e.g. pending deletion.
+	ErrKeyDisabled = errors.New("kms key is disabled or unavailable")
+
+	// ErrKeyNotFound is returned when the KMS key or alias does not exist.
+	ErrKeyNotFound = errors.New("kms key not found")
+
+	// ErrThrottled is returned when KMS rejected the request because of request quotas. It is safe to retry.
+	ErrThrottled = errors.New("kms request throttled")
+
+	// ErrAccessDenied is returned when the caller is not allowed to use the KMS key.
+	ErrAccessDenied = errors.New("kms access denied")
+
+	// ErrInvalidSignature is returned when a signature does not verify, locally or with KMS. It is the same value as
+	// jwt.ErrSignatureInvalid, so either can be matched with errors.Is.
+	ErrInvalidSignature = jwt.ErrSignatureInvalid
+
+	// ErrDecryption is returned when a JWE can not be decrypted, with KMS or locally. The cause is deliberately not
+	// distinguished further.
+	ErrDecryption = errors.New("jwe decryption failed")
+
+	// ErrCallTimeout is returned when a KMS call exceeded the timeout of WithCallTimeout, or KMS timed out calling a
+	// dependency like an external key store proxy. It is safe to retry.
+	ErrCallTimeout = errors.New("kms call timed out")
+
+	// ErrAlgorithmNotAllowed is returned when verifying a token whose alg is not allowed by WithAllowedAlgorithms.
+	ErrAlgorithmNotAllowed = errors.New("signing algorithm is not allowed")
+)
+
+// kmsErrorCodes maps KMS error codes to the package errors.
+var kmsErrorCodes = map[string]error{
+	"DisabledException":            ErrKeyDisabled,
+	"KMSInvalidStateException":     ErrKeyDisabled,
+	"KeyUnavailableException":      ErrKeyDisabled,
+	"NotFoundException":            ErrKeyNotFound,
+	"ThrottlingException":          ErrThrottled,
+	"LimitExceededException":       ErrThrottled,
+	"AccessDeniedException":        ErrAccessDenied,
+	"KMSInvalidSignatureException": ErrInvalidSignature,
+	"KMSInvalidMacException":       ErrInvalidSignature,
+	"InvalidCiphertextException":   ErrDecryption,
+	"DependencyTimeoutException":   ErrCallTimeout,
+}
+
+// mapKMSError wraps the error of a KMS call with the package error for its error code, keeping the original
+// error in the chain. Errors without a matching package error are returned unchanged.
+func mapKMSError(err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	mapped, ok := kmsErrorCodes[apiErr.ErrorCode()]
+	if !ok {
+		return err
+	}
+
+	return fmt.Errorf("%w: %w", mapped, err)
+}
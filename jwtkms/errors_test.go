@@ -0,0 +1,109 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/smithy-go"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// errorKMS fails every Sign call with err.
+type errorKMS struct {
+	*mockkms.MockKMS
+	err error
+}
+
+func (c errorKMS) Sign(context.Context, *kms.SignInput, ...func(*kms.Options)) (*kms.SignOutput, error) {
+	return nil, c.err
+}
+
+func TestKMSErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "throttled", err: &smithy.GenericAPIError{Code: "ThrottlingException"}, want: ErrThrottled},
+		{name: "access denied", err: &smithy.GenericAPIError{Code: "AccessDeniedException"}, want: ErrAccessDenied},
+		{name: "invalid state", err: &smithy.GenericAPIError{Code: "KMSInvalidStateException"}, want: ErrKeyDisabled},
+		{name: "dependency timeout", err: &smithy.GenericAPIError{Code: "DependencyTimeoutException"}, want: ErrCallTimeout},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := errorKMS{MockKMS: mockkms.NewMockKMS(), err: test.err}
+			id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			_, err = jwt.New(SigningMethodECDSA256).SignedString(NewKMSConfig(client, id, false))
+			if !errors.Is(err, test.want) || !errors.Is(err, test.err) {
+				t.Errorf("error = %v, want %v wrapping %v", err, test.want, test.err)
+			}
+		})
+	}
+}
+
+func TestKeyErrors(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	if _, err := jwt.New(SigningMethodRS256).SignedString(NewKMSConfig(kms, "missing", false)); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("error = %v, want %v", err, ErrKeyNotFound)
+	}
+
+	signed, err := jwt.New(SigningMethodRS256).SignedString(NewKMSConfig(kms, id, false))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	kms.DisableKey(id)
+
+	if _, err := jwt.New(SigningMethodRS256).SignedString(NewKMSConfig(kms, id, false)); !errors.Is(err, ErrKeyDisabled) {
+		t.Errorf("error = %v, want %v", err, ErrKeyDisabled)
+	}
+
+	if _, err := NewKMSConfig(kms, id, true).VerifyContext(context.Background(), signed, jwt.MapClaims{}); !errors.Is(err, ErrKeyDisabled) {
+		t.Errorf("error = %v, want %v", err, ErrKeyDisabled)
+	}
+}
+
+func TestInvalidSignatureError(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+
+	for _, keyType := range []mockkms.KeyType{mockkms.KeyTypeRSA2048, mockkms.KeyTypeHMAC256} {
+		signID, err := kms.GenerateKey(keyType)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		otherID, err := kms.GenerateKey(keyType)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		method := jwt.SigningMethod(SigningMethodRS256)
+		if keyType == mockkms.KeyTypeHMAC256 {
+			method = SigningMethodHS256
+		}
+
+		signed, err := jwt.New(method).SignedString(NewKMSConfig(kms, signID, false))
+		if err != nil {
+			t.Fatalf("Error signing token: %v", err)
+		}
+
+		for _, verifyWithKMS := range []bool{false, true} {
+			_, err := NewKMSConfig(kms, otherID, verifyWithKMS).VerifyContext(context.Background(), signed, jwt.MapClaims{})
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("%s verifyWithKMS %v: error = %v, want %v", method.Alg(), verifyWithKMS, err, ErrInvalidSignature)
+			}
+		}
+	}
+}
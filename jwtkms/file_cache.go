@@ -0,0 +1,227 @@
+package jwtkms
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// PEM headers of the files of a file PublicKeyCache.
+const (
+	pemHeaderKeyID             = "Key-Id"
+	pemHeaderKeyARN            = "Key-Arn"
+	pemHeaderKeySpec           = "Key-Spec"
+	pemHeaderSigningAlgorithms = "Signing-Algorithms"
+	pemHeaderFetchedAt         = "Fetched-At"
+	pemHeaderExpiresAt         = "Expires-At"
+)
+
+// FilePublicKeyCacheSettings configures the PublicKeyCache returned by NewFilePublicKeyCache.
+type FilePublicKeyCacheSettings struct {
+	// Dir is the directory of the cache files, created if it does not exist.
+	Dir string
+
+	// OnError, if set, is called with the errors reading and writing cache files, which otherwise make Get report a
+	// miss and Add only update the in-memory cache.
+	OnError func(error)
+}
+
+// fileCache is a PublicKeyCache persisting public keys as PEM files, with an in-memory cache in front.
+type fileCache struct {
+	settings FilePublicKeyCacheSettings
+	memory   *pubKeyCache
+}
+
+// NewFilePublicKeyCache returns a PublicKeyCache persisting public keys to PEM files in a directory, one per key id
+// with its metadata in the PEM headers, so short-lived processes like CLI invocations and Lambda cold starts verify
+// locally without calling GetPublicKey every time.
+func NewFilePublicKeyCache(settings FilePublicKeyCacheSettings) (PublicKeyCache, error) {
+	if settings.Dir == "" {
+		return nil, errors.New("file public key cache has no directory")
+	}
+
+	if err := os.MkdirAll(settings.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating public key cache directory: %w", err)
+	}
+
+	return &fileCache{
+		settings: settings,
+		memory:   newPubKeyCache(PublicKeyCacheSettings{}),
+	}, nil
+}
+
+func (c *fileCache) Get(keyID string) *CachedPublicKey {
+	if key := c.memory.Get(keyID); key != nil {
+		return key
+	}
+
+	data, err := os.ReadFile(c.path(keyID))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			c.onError(fmt.Errorf("reading public key of %s: %w", keyID, err))
+		}
+
+		return nil
+	}
+
+	key, err := decodePublicKeyPEM(data)
+	if err != nil {
+		c.onError(fmt.Errorf("decoding public key of %s: %w", keyID, err))
+		return nil
+	}
+
+	c.memory.Add(keyID, key)
+
+	return key
+}
+
+func (c *fileCache) Add(keyID string, key *CachedPublicKey) {
+	c.memory.Add(keyID, key)
+
+	data, err := encodePublicKeyPEM(keyID, key)
+	if err != nil {
+		c.onError(fmt.Errorf("encoding public key of %s: %w", keyID, err))
+		return
+	}
+
+	if err := c.write(keyID, data); err != nil {
+		c.onError(fmt.Errorf("writing public key of %s: %w", keyID, err))
+	}
+}
+
+func (c *fileCache) Delete(keyID string) {
+	c.memory.Delete(keyID)
+
+	if err := os.Remove(c.path(keyID)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		c.onError(fmt.Errorf("removing public key of %s: %w", keyID, err))
+	}
+}
+
+// CacheStats implements PublicKeyCacheStatsReporter with the entries and evictions of the in-memory cache.
+func (c *fileCache) CacheStats() CacheStats {
+	return c.memory.CacheStats()
+}
+
+// write replaces the file of keyID atomically, so concurrent processes never read a partial file.
+func (c *fileCache) write(keyID string, data []byte) error {
+	f, err := os.CreateTemp(c.settings.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck
+
+	if _, err := f.Write(data); err != nil {
+		f.Close() //nolint:errcheck
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), c.path(keyID))
+}
+
+// path returns the file of keyID, named after its hash as key ARNs are not valid file names everywhere.
+func (c *fileCache) path(keyID string) string {
+	sum := sha256.Sum256([]byte(keyID))
+	return filepath.Join(c.settings.Dir, hex.EncodeToString(sum[:])+".pem")
+}
+
+func (c *fileCache) onError(err error) {
+	if c.settings.OnError != nil {
+		c.settings.OnError(err)
+	}
+}
+
+func encodePublicKeyPEM(keyID string, key *CachedPublicKey) ([]byte, error) {
+	der := key.DER
+	if der == nil {
+		var err error
+		if der, err = x509.MarshalPKIXPublicKey(key.PublicKey); err != nil {
+			return nil, err
+		}
+	}
+
+	headers := map[string]string{pemHeaderKeyID: keyID}
+
+	if key.KeyARN != "" {
+		headers[pemHeaderKeyARN] = key.KeyARN
+	}
+
+	if key.KeySpec != "" {
+		headers[pemHeaderKeySpec] = string(key.KeySpec)
+	}
+
+	if len(key.SigningAlgorithms) > 0 {
+		algorithms := make([]string, len(key.SigningAlgorithms))
+		for i, algorithm := range key.SigningAlgorithms {
+			algorithms[i] = string(algorithm)
+		}
+
+		headers[pemHeaderSigningAlgorithms] = strings.Join(algorithms, ",")
+	}
+
+	if !key.FetchedAt.IsZero() {
+		headers[pemHeaderFetchedAt] = key.FetchedAt.Format(time.RFC3339Nano)
+	}
+
+	if !key.ExpiresAt.IsZero() {
+		headers[pemHeaderExpiresAt] = key.ExpiresAt.Format(time.RFC3339Nano)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Headers: headers, Bytes: der}), nil
+}
+
+func decodePublicKeyPEM(data []byte) (*CachedPublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.New("no PUBLIC KEY PEM block")
+	}
+
+	pub, err := ParsePublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &CachedPublicKey{
+		PublicKey: pub,
+		DER:       block.Bytes,
+		KeyARN:    block.Headers[pemHeaderKeyARN],
+		KeySpec:   types.KeySpec(block.Headers[pemHeaderKeySpec]),
+	}
+
+	if algorithms := block.Headers[pemHeaderSigningAlgorithms]; algorithms != "" {
+		for _, algorithm := range strings.Split(algorithms, ",") {
+			key.SigningAlgorithms = append(key.SigningAlgorithms, types.SigningAlgorithmSpec(algorithm))
+		}
+	}
+
+	if key.FetchedAt, err = parsePEMTime(block.Headers[pemHeaderFetchedAt]); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", pemHeaderFetchedAt, err)
+	}
+
+	if key.ExpiresAt, err = parsePEMTime(block.Headers[pemHeaderExpiresAt]); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", pemHeaderExpiresAt, err)
+	}
+
+	return key, nil
+}
+
+func parsePEMTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339Nano, s)
+}
@@ -0,0 +1,97 @@
+package jwtkms
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestFilePublicKeyCache(t *testing.T) {
+	registered(t)
+
+	client := &getPublicKeyCountingKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCSECGP256K1)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "cache")
+	newCache := func() PublicKeyCache {
+		t.Helper()
+		cache, err := NewFilePublicKeyCache(FilePublicKeyCacheSettings{
+			Dir:     dir,
+			OnError: func(err error) { t.Errorf("unexpected cache error: %v", err) },
+		})
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+
+		return cache
+	}
+
+	cfg := NewConfig(client, mockkms.KeyARN(id), WithPublicKeyCache(newCache()), WithPublicKeyTTL(time.Hour))
+
+	signed, err := jwt.New(SigningMethodES256K).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("cache files = %v, %v, want one", files, err)
+	}
+
+	// a new process reads the public key from disk
+	cache := newCache()
+	if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) {
+		return NewConfig(client, mockkms.KeyARN(id), WithPublicKeyCache(cache)), nil
+	}); err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	if client.calls.Load() != 1 {
+		t.Errorf("GetPublicKey calls = %d, want 1", client.calls.Load())
+	}
+
+	cached := cache.Get(mockkms.KeyARN(id))
+	if cached.KeyARN != mockkms.KeyARN(id) || cached.KeySpec == "" || len(cached.SigningAlgorithms) == 0 ||
+		cached.FetchedAt.IsZero() || cached.ExpiresAt.IsZero() {
+		t.Errorf("metadata not persisted: %+v", cached)
+	}
+
+	cache.Delete(mockkms.KeyARN(id))
+
+	if _, err := os.Stat(files[0]); !os.IsNotExist(err) {
+		t.Errorf("cache file not removed: %v", err)
+	}
+}
+
+func TestFilePublicKeyCacheCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+
+	var errs []error
+	cache, err := NewFilePublicKeyCache(FilePublicKeyCacheSettings{Dir: dir, OnError: func(err error) { errs = append(errs, err) }})
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+
+	if err := os.WriteFile(cache.(*fileCache).path("key"), []byte("garbage"), 0o600); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+
+	if cache.Get("key") != nil {
+		t.Errorf("expected a miss")
+	}
+
+	if len(errs) != 1 {
+		t.Errorf("errors = %v, want 1", errs)
+	}
+}
@@ -0,0 +1,16 @@
+package jwtkms
+
+// WithFIPSEndpoint makes the Config call the FIPS endpoints of KMS, see Config.WithFIPSEndpoint.
+func WithFIPSEndpoint() Option {
+	return func(c *Config) {
+		c.fipsEndpoint = true
+	}
+}
+
+// WithFIPSEndpoint returns a copy of Config sending its KMS calls to the FIPS 140 validated endpoints of KMS,
+// kms-fips.<region>.amazonaws.com, as FedRAMP workloads require, in the region of the client. The endpoint is resolved
+// by *kms.Client clients per call, so the client needs no FIPS configuration; calls of clients with a custom endpoint
+// fail, KMS has no FIPS variant of them.
+func (c *Config) WithFIPSEndpoint() *Config {
+	return c.with(WithFIPSEndpoint())
+}
@@ -0,0 +1,69 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// errRequestCaptured fails the requests of hostRecorder.
+var errRequestCaptured = errors.New("request captured")
+
+// hostRecorder is an HTTP client recording the host and Authorization header of the last request instead of sending
+// it.
+type hostRecorder struct {
+	host          string
+	authorization string
+}
+
+func (r *hostRecorder) Do(req *http.Request) (*http.Response, error) {
+	r.host = req.URL.Host
+	r.authorization = req.Header.Get("Authorization")
+
+	return nil, errRequestCaptured
+}
+
+// newRecordingClient creates a kms.Client of region sending its requests to recorder.
+func newRecordingClient(region string, recorder *hostRecorder) *kms.Client {
+	return kms.New(kms.Options{
+		Region:      region,
+		Credentials: aws.AnonymousCredentials{},
+		HTTPClient:  recorder,
+		Retryer:     aws.NopRetryer{},
+	})
+}
+
+func TestWithFIPSEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		wantHost string
+	}{
+		{name: "default", wantHost: "kms.us-gov-west-1.amazonaws.com"},
+		{name: "fips", opts: []Option{WithFIPSEndpoint()}, wantHost: "kms-fips.us-gov-west-1.amazonaws.com"},
+		{name: "fips with api options", opts: []Option{
+			WithFIPSEndpoint(),
+			WithAPIOptions(func(o *kms.Options) { o.Region = "us-east-1" }),
+		}, wantHost: "kms-fips.us-east-1.amazonaws.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &hostRecorder{}
+			cfg := NewConfig(newRecordingClient("us-gov-west-1", recorder), "alias/signing", tt.opts...)
+
+			if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg.WithContext(context.Background())); !errors.Is(err, errRequestCaptured) {
+				t.Fatalf("err = %v, want %v", err, errRequestCaptured)
+			}
+
+			if recorder.host != tt.wantHost {
+				t.Errorf("host = %s, want %s", recorder.host, tt.wantHost)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package jwtkms
+
+import "slices"
+
+// WithGrantTokens makes the Config pass grantTokens to its KMS calls, see Config.WithGrantTokens.
+func WithGrantTokens(grantTokens ...string) Option {
+	return func(c *Config) {
+		c.grantTokens = slices.Clone(grantTokens)
+	}
+}
+
+// WithGrantTokens returns a copy of Config passing grantTokens to its KMS Sign, Verify, GetPublicKey, GenerateMac,
+// VerifyMac, Encrypt and Decrypt calls. Grant tokens make the permissions of a grant effective immediately, before the
+// grant is eventually consistent, so workloads authorized by freshly created grants do not fail with ErrAccessDenied.
+// KMS accepts up to 10 grant tokens per call.
+func (c *Config) WithGrantTokens(grantTokens ...string) *Config {
+	return c.with(WithGrantTokens(grantTokens...))
+}
@@ -0,0 +1,80 @@
+package jwtkms
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// grantTokenKMS records the grant tokens of the KMS calls by operation.
+type grantTokenKMS struct {
+	*mockkms.MockKMS
+
+	mu     sync.Mutex
+	tokens map[string][]string
+}
+
+func (c *grantTokenKMS) record(operation string, grantTokens []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokens[operation] = grantTokens
+}
+
+func (c *grantTokenKMS) Sign(ctx context.Context, in *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error) {
+	c.record("Sign", in.GrantTokens)
+	return c.MockKMS.Sign(ctx, in, optFns...)
+}
+
+func (c *grantTokenKMS) Verify(ctx context.Context, in *kms.VerifyInput, optFns ...func(*kms.Options)) (*kms.VerifyOutput, error) {
+	c.record("Verify", in.GrantTokens)
+	return c.MockKMS.Verify(ctx, in, optFns...)
+}
+
+func (c *grantTokenKMS) GetPublicKey(ctx context.Context, in *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error) {
+	c.record("GetPublicKey", in.GrantTokens)
+	return c.MockKMS.GetPublicKey(ctx, in, optFns...)
+}
+
+func TestWithGrantTokens(t *testing.T) {
+	registered(t)
+
+	client := &grantTokenKMS{MockKMS: mockkms.NewMockKMS(), tokens: map[string][]string{}}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	grantTokens := []string{"grant-token-1", "grant-token-2"}
+	cfg := NewConfig(client, id, WithGrantTokens(grantTokens...))
+
+	signed, err := jwt.New(SigningMethodECDSA256).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	for _, cfg := range []*Config{cfg, cfg.WithVerifyMode(VerifyWithKMS)} {
+		if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+			t.Fatalf("Error verifying token: %v", err)
+		}
+	}
+
+	for _, operation := range []string{"Sign", "Verify", "GetPublicKey"} {
+		if got := client.tokens[operation]; !slices.Equal(got, grantTokens) {
+			t.Errorf("%s grant tokens = %v, want %v", operation, got, grantTokens)
+		}
+	}
+
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg.WithGrantTokens()); err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if got := client.tokens["Sign"]; len(got) != 0 {
+		t.Errorf("Sign grant tokens = %v, want none", got)
+	}
+}
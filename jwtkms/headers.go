@@ -0,0 +1,48 @@
+package jwtkms
+
+import (
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SignedString signs token with the Config and returns the complete, signed token.
+//
+// Headers derived from the Config, the kid of a kid option, the typ of WithTokenType and the x5c and x5t#S256 of a
+// certificate chain, and the headers of WithProtectedHeaders, are set before signing unless the token already has them;
+// the typ "JWT" set by jwt.New is replaced. Tokens signed with token.SignedString(cfg) never get them, as the header is
+// already encoded when the signing method is called.
+func (c *Config) SignedString(token *jwt.Token) (string, error) {
+	if err := c.setHeaders(token); err != nil {
+		return "", err
+	}
+
+	return token.SignedString(c)
+}
+
+// setHeaders sets the headers derived from the Config that token does not have yet.
+func (c *Config) setHeaders(token *jwt.Token) error {
+	if _, ok := token.Header["kid"]; !ok && c.kid != nil {
+		kid, err := c.kid(c)
+		if err != nil {
+			return fmt.Errorf("deriving kid: %w", err)
+		}
+
+		token.Header["kid"] = kid
+	}
+
+	// jwt.New always sets the generic typ
+	if typ, ok := token.Header["typ"]; (!ok || typ == TokenTypeJWT) && c.tokenType != "" {
+		token.Header["typ"] = c.tokenType
+	}
+
+	if err := setProtectedHeaders(token, c.protectedHeaders); err != nil {
+		return err
+	}
+
+	if err := c.setCertificateHeaders(token); err != nil {
+		return err
+	}
+
+	return checkCritHeader(token.Header)
+}
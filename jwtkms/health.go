@@ -0,0 +1,131 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultHealthCacheTTL is how long Healthy reuses the result of a check.
+const defaultHealthCacheTTL = 10 * time.Second
+
+// healthCache caches the results of Healthy by key id, shared by the copies of a Config.
+type healthCache struct {
+	ttl    time.Duration
+	now    func() time.Time
+	checks singleflight.Group
+
+	mu      sync.Mutex
+	results map[string]healthResult
+}
+
+// healthResult is a cached result of Healthy.
+type healthResult struct {
+	err       error
+	checkedAt time.Time
+}
+
+func newHealthCache(ttl time.Duration) *healthCache {
+	return &healthCache{
+		ttl:     ttl,
+		now:     time.Now,
+		results: make(map[string]healthResult),
+	}
+}
+
+// WithHealthCacheTTL makes Healthy reuse the result of a check for ttl instead of 10s, checking on every call if ttl
+// is zero.
+func WithHealthCacheTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.health = newHealthCache(ttl)
+	}
+}
+
+// Healthy reports whether the Config can use its KMS key, for readiness probes: the Config is valid, KMS is reachable,
+// the credentials are accepted and the key is usable. The key is checked with KMS DescribeKey like CheckKeyState if the
+// client implements KMSDescribeKeyClient, and with an uncached GetPublicKey call otherwise.
+//
+// Results, failures included, are cached for 10s, see WithHealthCacheTTL, so frequent probes cost few KMS calls.
+// Verification-only Configs are healthy when valid.
+func (c *Config) Healthy(ctx context.Context) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	if c.offline {
+		return nil
+	}
+
+	h := c.health
+	if h == nil {
+		return c.WithContext(ctx).checkHealth()
+	}
+
+	h.mu.Lock()
+	result, ok := h.results[c.kmsKeyID]
+	h.mu.Unlock()
+
+	if ok && h.now().Sub(result.checkedAt) < h.ttl {
+		return result.err
+	}
+
+	// concurrent probes share one check, which outlives a probe giving up
+	cfg := c.WithContext(context.WithoutCancel(ctx))
+	ch := h.checks.DoChan(c.kmsKeyID, func() (interface{}, error) {
+		err := cfg.checkHealth()
+
+		h.mu.Lock()
+		h.results[cfg.kmsKeyID] = healthResult{err: err, checkedAt: h.now()}
+		h.mu.Unlock()
+
+		return nil, err
+	})
+
+	select {
+	case res := <-ch:
+		return res.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// checkHealth checks the KMS key of c for Healthy.
+func (c *Config) checkHealth() error {
+	if _, ok := c.kmsClient.(KMSDescribeKeyClient); ok {
+		return c.CheckKeyState(c.ctx)
+	}
+
+	_, err := c.kmsGetPublicKey(&kms.GetPublicKeyInput{KeyId: aws.String(c.kmsKeyID)})
+
+	// HMAC keys have no public key, KMS answering is all the check needs
+	var unsupported *types.UnsupportedOperationException
+	if err != nil && !errors.As(err, &unsupported) {
+		return fmt.Errorf("getting public key: %w", err)
+	}
+
+	return nil
+}
+
+// Healthy reports whether all keys of the registry are usable, see Config.Healthy. The returned error joins the
+// errors of the unhealthy keys.
+func (r *KeyRegistry) Healthy(ctx context.Context) error {
+	r.mu.RLock()
+	entries := append([]*registryEntry{}, r.entries...)
+	r.mu.RUnlock()
+
+	var errs []error
+	for _, e := range entries {
+		if err := e.cfg.Healthy(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("key %q: %w", e.KID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,137 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// noDescribeKeyKMS is a KMSClient without DescribeKey.
+type noDescribeKeyKMS struct {
+	KMSClient
+}
+
+func TestHealthy(t *testing.T) {
+	client := mockkms.NewMockKMS()
+
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		state   types.KeyState
+		client  func(*mockkms.MockKMS) KMSClient
+		wantErr error
+	}{
+		{name: "enabled", keyType: mockkms.KeyTypeECCNISTP256, state: types.KeyStateEnabled},
+		{name: "disabled", keyType: mockkms.KeyTypeECCNISTP256, state: types.KeyStateDisabled, wantErr: ErrKeyDisabled},
+		{name: "pending deletion", keyType: mockkms.KeyTypeRSA2048, state: types.KeyStatePendingDeletion, wantErr: ErrKeyPendingDeletion},
+		{
+			name: "no DescribeKey", keyType: mockkms.KeyTypeECCNISTP256, state: types.KeyStateEnabled,
+			client: func(m *mockkms.MockKMS) KMSClient { return noDescribeKeyKMS{m} },
+		},
+		{
+			name: "no DescribeKey HMAC", keyType: mockkms.KeyTypeHMAC256, state: types.KeyStateEnabled,
+			client: func(m *mockkms.MockKMS) KMSClient { return noDescribeKeyKMS{m} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := client.GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			client.SetKeyState(id, tt.state)
+
+			var c KMSClient = client
+			if tt.client != nil {
+				c = tt.client(client)
+			}
+
+			if err := NewConfig(c, id).Healthy(context.Background()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := NewConfig(client, "unknown").Healthy(context.Background()); err == nil {
+		t.Errorf("expected error for an unknown key")
+	}
+
+	if err := NewConfig(client, "").Healthy(context.Background()); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidConfig)
+	}
+}
+
+func TestHealthyCache(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	now := time.Now()
+	cfg := NewConfig(client, id)
+	cfg.health.now = func() time.Time { return now }
+
+	if err := cfg.Healthy(context.Background()); err != nil {
+		t.Fatalf("Error checking health: %v", err)
+	}
+
+	client.DisableKey(id)
+
+	// derived Configs share the cached result
+	if err := cfg.WithContext(context.Background()).Healthy(context.Background()); err != nil {
+		t.Errorf("Error checking health within the cache ttl: %v", err)
+	}
+
+	now = now.Add(defaultHealthCacheTTL)
+	if err := cfg.Healthy(context.Background()); !errors.Is(err, ErrKeyDisabled) {
+		t.Errorf("err = %v, want %v", err, ErrKeyDisabled)
+	}
+
+	uncached := NewConfig(client, id, WithHealthCacheTTL(0))
+	if err := uncached.Healthy(context.Background()); !errors.Is(err, ErrKeyDisabled) {
+		t.Errorf("err = %v, want %v", err, ErrKeyDisabled)
+	}
+}
+
+func TestKeyRegistryHealthy(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	registry := NewKeyRegistry(client)
+
+	var ids []string
+	for _, kid := range []string{"a", "b"} {
+		id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		if err := registry.Add(KeyEntry{KID: kid, KeyID: id}); err != nil {
+			t.Fatalf("Error adding key: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := registry.Healthy(context.Background()); err != nil {
+		t.Fatalf("Error checking health: %v", err)
+	}
+
+	client.SetKeyState(ids[1], types.KeyStatePendingDeletion)
+
+	registry = NewKeyRegistry(client)
+	for i, kid := range []string{"a", "b"} {
+		if err := registry.Add(KeyEntry{KID: kid, KeyID: ids[i]}); err != nil {
+			t.Fatalf("Error adding key: %v", err)
+		}
+	}
+
+	if err := registry.Healthy(context.Background()); !errors.Is(err, ErrKeyPendingDeletion) {
+		t.Errorf("err = %v, want %v", err, ErrKeyPendingDeletion)
+	}
+}
@@ -0,0 +1,34 @@
+package jwtkms
+
+import "context"
+
+// Hooks are callbacks run around the KMS-backed operations of a Config, for auditing, quota accounting or fault
+// injection in wrappers. Nil callbacks are skipped, and all of them are called synchronously with the context of the
+// operation and must be safe for concurrent use.
+type Hooks struct {
+	// OnSignStart is called before every KMS Sign or GenerateMac call. A non-nil error aborts the call and is
+	// returned by the signing method.
+	OnSignStart func(ctx context.Context, info SignInfo) error
+
+	// OnSignEnd is called after every KMS Sign or GenerateMac call OnSignStart did not abort.
+	OnSignEnd func(ctx context.Context, m SignMetrics)
+
+	// OnVerifyEnd is called after every token signature verification, local or with KMS.
+	OnVerifyEnd func(ctx context.Context, m VerifyMetrics)
+}
+
+// SignInfo describes a KMS signing call about to be made.
+type SignInfo struct {
+	KeyID     string
+	Operation Operation
+
+	// Algorithm is the KMS signing or MAC algorithm.
+	Algorithm string
+}
+
+// WithHooks makes the Config run hooks around its operations.
+func WithHooks(hooks Hooks) Option {
+	return func(c *Config) {
+		c.hooks = hooks
+	}
+}
@@ -0,0 +1,71 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestHooks(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+
+	var starts, ends, verifies atomic.Int32
+	errQuota := errors.New("quota exceeded")
+	cfg := NewConfig(kms, id, WithHooks(Hooks{
+		OnSignStart: func(ctx context.Context, info SignInfo) error {
+			if ctx.Value(ctxKey{}) != "request" {
+				t.Errorf("OnSignStart called without the caller's context")
+			}
+
+			if info.KeyID != id || info.Operation != OperationSign || info.Algorithm != "ECDSA_SHA_256" {
+				t.Errorf("unexpected sign info %+v", info)
+			}
+
+			if starts.Add(1) > 1 {
+				return errQuota
+			}
+
+			return nil
+		},
+		OnSignEnd: func(_ context.Context, m SignMetrics) {
+			ends.Add(1)
+			if m.Err != nil {
+				t.Errorf("unexpected sign error %v", m.Err)
+			}
+		},
+		OnVerifyEnd: func(_ context.Context, m VerifyMetrics) {
+			verifies.Add(1)
+			if m.KeyID != id || m.Algorithm != "ES256" || m.Err != nil {
+				t.Errorf("unexpected verify metrics %+v", m)
+			}
+		},
+	}))
+
+	signed, err := cfg.SignContext(ctx, jwt.New(SigningMethodECDSA256))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if _, err := cfg.VerifyContext(ctx, signed, jwt.MapClaims{}); err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	if _, err := cfg.SignContext(ctx, jwt.New(SigningMethodECDSA256)); !errors.Is(err, errQuota) {
+		t.Fatalf("err = %v, want %v", err, errQuota)
+	}
+
+	if starts.Load() != 2 || ends.Load() != 1 || verifies.Load() != 1 {
+		t.Errorf("starts = %d, ends = %d, verifies = %d, want 2, 1, 1", starts.Load(), ends.Load(), verifies.Load())
+	}
+}
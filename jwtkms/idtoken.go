@@ -0,0 +1,144 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const defaultIDTokenLifetime = time.Hour
+
+// IDTokenIssuer issues OpenID Connect ID tokens signed with a KMS key, setting the standard claims and headers.
+type IDTokenIssuer struct {
+	cfg      *Config
+	method   jwt.SigningMethod
+	issuer   string
+	lifetime time.Duration
+}
+
+// NewIDTokenIssuer creates an IDTokenIssuer for issuer, the iss claim, signing with cfg and method. Tokens are valid
+// for lifetime, one hour when zero or negative.
+//
+// The kid header is the one configured on cfg, or the key ARN, the kid the jwks package serves by default.
+func NewIDTokenIssuer(cfg *Config, method jwt.SigningMethod, issuer string, lifetime time.Duration) *IDTokenIssuer {
+	if cfg.kid == nil {
+		cfg = cfg.WithKeyIDHeader()
+	}
+
+	if lifetime <= 0 {
+		lifetime = defaultIDTokenLifetime
+	}
+
+	return &IDTokenIssuer{cfg: cfg, method: method, issuer: issuer, lifetime: lifetime}
+}
+
+// IDTokenRequest holds the claims of an ID token specific to an authentication.
+type IDTokenRequest struct {
+	Subject  string
+	Audience []string
+
+	// Nonce is the nonce of the authentication request, omitted when empty.
+	Nonce string
+
+	// AuthTime is the time the end user authenticated, omitted when zero.
+	AuthTime time.Time
+
+	// AuthorizedParty is the azp claim, the client the token is issued to, omitted when empty.
+	AuthorizedParty string
+
+	// AccessToken, if set, is the access token issued along, bound with the at_hash claim.
+	AccessToken string
+
+	// Extra holds additional claims, e.g. email or acr. It must not repeat the claims above or exp and iat.
+	Extra map[string]interface{}
+}
+
+// Issue signs an ID token for req, using ctx for the KMS calls. The header has typ JWT and the kid, the claims iss,
+// sub, aud, exp, iat and the claims of req that are set.
+func (i *IDTokenIssuer) Issue(ctx context.Context, req IDTokenRequest) (string, error) {
+	if req.Subject == "" {
+		return "", errors.New("id token subject is empty")
+	}
+
+	if len(req.Audience) == 0 {
+		return "", errors.New("id token audience is empty")
+	}
+
+	now := time.Now()
+
+	claims := jwt.MapClaims{
+		"iss": i.issuer,
+		"sub": req.Subject,
+		"aud": req.Audience,
+		"exp": now.Add(i.lifetime).Unix(),
+		"iat": now.Unix(),
+	}
+
+	if len(req.Audience) == 1 {
+		claims["aud"] = req.Audience[0]
+	}
+
+	if req.Nonce != "" {
+		claims["nonce"] = req.Nonce
+	}
+
+	if !req.AuthTime.IsZero() {
+		claims["auth_time"] = req.AuthTime.Unix()
+	}
+
+	if req.AuthorizedParty != "" {
+		claims["azp"] = req.AuthorizedParty
+	}
+
+	if req.AccessToken != "" {
+		atHash, err := accessTokenHash(i.method, req.AccessToken)
+		if err != nil {
+			return "", err
+		}
+
+		claims["at_hash"] = atHash
+	}
+
+	for name := range req.Extra {
+		if _, ok := claims[name]; ok || name == "nonce" || name == "auth_time" || name == "azp" || name == "at_hash" {
+			return "", fmt.Errorf("extra claim %q is a standard claim", name)
+		}
+	}
+
+	maps.Copy(claims, req.Extra)
+
+	token := jwt.NewWithClaims(i.method, claims)
+	token.Header["typ"] = "JWT"
+
+	return i.cfg.SignContext(ctx, token)
+}
+
+// atHashFunctions are the hash functions of the at_hash claim, by alg.
+var atHashFunctions = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+	"HS256": crypto.SHA256, "HS384": crypto.SHA384, "HS512": crypto.SHA512,
+	"ES256K": crypto.SHA256,
+}
+
+// accessTokenHash returns the at_hash of accessToken for method: the base64url encoded left half of its hash with
+// the hash function of the alg, OpenID Connect Core section 3.1.3.6.
+func accessTokenHash(method jwt.SigningMethod, accessToken string) (string, error) {
+	hash, ok := atHashFunctions[method.Alg()]
+	if !ok {
+		return "", fmt.Errorf("at_hash is not supported for %s", method.Alg())
+	}
+
+	hasher := hash.New()
+	hasher.Write([]byte(accessToken)) //nolint:errcheck
+	sum := hasher.Sum(nil)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2]), nil
+}
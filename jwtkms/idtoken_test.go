@@ -0,0 +1,118 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestIDTokenIssuer(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id)
+	issuer := NewIDTokenIssuer(cfg, SigningMethodRS256, "https://op.example.com", 10*time.Minute)
+
+	authTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	signed, err := issuer.Issue(ctx, IDTokenRequest{
+		Subject:     "248289761001",
+		Audience:    []string{"s6BhdRkqt3"},
+		Nonce:       "n-0S6_WzA2Mj",
+		AuthTime:    authTime,
+		AccessToken: "jHkWEdUXMU1BwAsC4vtUsZwnNvTIxEl0z9K3vx5KF0Y",
+		Extra:       map[string]interface{}{"email": "janedoe@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Error issuing id token: %v", err)
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := cfg.VerifyContext(ctx, signed, claims,
+		jwt.WithIssuer("https://op.example.com"), jwt.WithAudience("s6BhdRkqt3"), jwt.WithExpirationRequired())
+	if err != nil {
+		t.Fatalf("Error verifying id token: %v", err)
+	}
+
+	if token.Header["typ"] != "JWT" || token.Header["kid"] != mockkms.KeyARN(id) {
+		t.Errorf("header = %v", token.Header)
+	}
+
+	sum := sha256.Sum256([]byte("jHkWEdUXMU1BwAsC4vtUsZwnNvTIxEl0z9K3vx5KF0Y"))
+
+	want := map[string]interface{}{
+		"sub":       "248289761001",
+		"aud":       "s6BhdRkqt3",
+		"nonce":     "n-0S6_WzA2Mj",
+		"auth_time": float64(authTime.Unix()),
+		"at_hash":   base64.RawURLEncoding.EncodeToString(sum[:16]),
+		"email":     "janedoe@example.com",
+	}
+
+	for name, value := range want {
+		if claims[name] != value {
+			t.Errorf("%s = %v, want %v", name, claims[name], value)
+		}
+	}
+
+	if exp, _ := claims.GetExpirationTime(); exp.Sub(time.Now()) > 10*time.Minute {
+		t.Errorf("exp = %v, want within the lifetime", exp)
+	}
+
+	// a kid configured on the Config is kept
+	signed, err = NewIDTokenIssuer(cfg.WithThumbprintKeyIDHeader(), SigningMethodRS256, "https://op.example.com", 0).
+		Issue(ctx, IDTokenRequest{Subject: "sub", Audience: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("Error issuing id token: %v", err)
+	}
+
+	kid, err := thumbprint(cfg)
+	if err != nil {
+		t.Fatalf("Error computing thumbprint: %v", err)
+	}
+
+	token, err = cfg.VerifyContext(ctx, signed, jwt.MapClaims{}, jwt.WithAudience("b"))
+	if err != nil {
+		t.Fatalf("Error verifying id token: %v", err)
+	}
+
+	if token.Header["kid"] != kid {
+		t.Errorf("kid = %v, want %v", token.Header["kid"], kid)
+	}
+}
+
+func TestIDTokenIssuerInvalid(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	issuer := NewIDTokenIssuer(NewConfig(client, id), SigningMethodECDSA256, "https://op.example.com", 0)
+
+	tests := []struct {
+		name string
+		req  IDTokenRequest
+	}{
+		{name: "no subject", req: IDTokenRequest{Audience: []string{"client"}}},
+		{name: "no audience", req: IDTokenRequest{Subject: "sub"}},
+		{name: "standard extra claim", req: IDTokenRequest{Subject: "sub", Audience: []string{"client"}, Extra: map[string]interface{}{"iss": "other"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := issuer.Issue(context.Background(), tt.req); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
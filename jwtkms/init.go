@@ -1,6 +1,8 @@
 // Package jwtkms provides an AWS KMS(Key Management Service) adapter to be used with the popular GoLang JWT library
 //
-// Importing this package will auto register the provided SigningMethods and make them available for use.
+// Importing this package does not register the provided SigningMethods with golang-jwt; call Register or MustRegister
+// once, e.g. in main, so jwt.Parse verifies tokens with them. ParseWithConfig, Config.VerifyContext and Verifier
+// verify with them without registration.
 // Make sure to use a keyConfig with a keyId that provides the requested SigningMethod's algorithm for Sign/Verify.
 //
 // By default JWT signature verification will happen by downloading and caching the public key of the KMS key,
@@ -9,8 +11,11 @@ package jwtkms
 
 import (
 	"crypto"
+	"crypto/elliptic"
 
-	"github.com/golang-jwt/jwt/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/singleflight"
 )
 
 var (
@@ -18,6 +23,8 @@ var (
 	SigningMethodECDSA384 *ECDSASigningMethod
 	SigningMethodECDSA512 *ECDSASigningMethod
 
+	SigningMethodES256K *ECDSASigningMethod
+
 	SigningMethodRS256 *RSASigningMethod
 	SigningMethodRS384 *RSASigningMethod
 	SigningMethodRS512 *RSASigningMethod
@@ -25,29 +32,44 @@ var (
 	SigningMethodPS256 *PSSSigningMethod
 	SigningMethodPS384 *PSSSigningMethod
 	SigningMethodPS512 *PSSSigningMethod
+
+	SigningMethodHS256 *HMACSigningMethod
+	SigningMethodHS384 *HMACSigningMethod
+	SigningMethodHS512 *HMACSigningMethod
+
+	SigningMethodSM2 *SM2SigningMethod
 )
 
-var pubkeyCache = newPubKeyCache()
+var (
+	pubkeyCache = newPubKeyCache(PublicKeyCacheSettings{})
+
+	// sharedPublicKeyFetches deduplicates the GetPublicKey calls of the Configs using pubkeyCache.
+	sharedPublicKeyFetches singleflight.Group
+
+	// sharedCacheLookups counts the public key lookups of the Configs using pubkeyCache.
+	sharedCacheLookups = newLookupCounter()
+)
 
 func init() {
-	registerECDSASigningMethods()
-	registerRSASigningMethods()
-	registerPSSSigningMethods()
+	newECDSASigningMethods()
+	newRSASigningMethods()
+	newPSSSigningMethods()
+	newHMACSigningMethods()
+	newSM2SigningMethod()
 }
 
-func registerECDSASigningMethods() {
+func newECDSASigningMethods() {
 	SigningMethodECDSA256 = &ECDSASigningMethod{
 		name:                  "ES256",
 		algo:                  "ECDSA_SHA_256",
 		hash:                  crypto.SHA256,
 		keySize:               32,
 		curveBits:             256,
+		curve:                 elliptic.P256(),
 		fallbackSigningMethod: jwt.SigningMethodES256,
 	}
 
-	jwt.RegisterSigningMethod(SigningMethodECDSA256.Alg(), func() jwt.SigningMethod {
-		return SigningMethodECDSA256
-	})
+	addSigningMethod(SigningMethodECDSA256)
 
 	SigningMethodECDSA384 = &ECDSASigningMethod{
 		name:                  "ES384",
@@ -55,12 +77,11 @@ func registerECDSASigningMethods() {
 		hash:                  crypto.SHA384,
 		keySize:               48,
 		curveBits:             384,
+		curve:                 elliptic.P384(),
 		fallbackSigningMethod: jwt.SigningMethodES384,
 	}
 
-	jwt.RegisterSigningMethod(jwt.SigningMethodES384.Alg(), func() jwt.SigningMethod {
-		return SigningMethodECDSA384
-	})
+	addSigningMethod(SigningMethodECDSA384)
 
 	SigningMethodECDSA512 = &ECDSASigningMethod{
 		name:                  "ES512",
@@ -68,15 +89,25 @@ func registerECDSASigningMethods() {
 		hash:                  crypto.SHA512,
 		keySize:               66,
 		curveBits:             521,
+		curve:                 elliptic.P521(),
 		fallbackSigningMethod: jwt.SigningMethodES512,
 	}
 
-	jwt.RegisterSigningMethod(jwt.SigningMethodES512.Alg(), func() jwt.SigningMethod {
-		return SigningMethodECDSA512
-	})
+	addSigningMethod(SigningMethodECDSA512)
+
+	SigningMethodES256K = &ECDSASigningMethod{
+		name:      "ES256K",
+		algo:      "ECDSA_SHA_256",
+		hash:      crypto.SHA256,
+		keySize:   32,
+		curveBits: 256,
+		curve:     secp256k1.S256(),
+	}
+
+	addSigningMethod(SigningMethodES256K)
 }
 
-func registerRSASigningMethods() {
+func newRSASigningMethods() {
 	SigningMethodRS256 = &RSASigningMethod{
 		name:                  "RS256",
 		algo:                  "RSASSA_PKCS1_V1_5_SHA_256",
@@ -84,9 +115,7 @@ func registerRSASigningMethods() {
 		fallbackSigningMethod: jwt.SigningMethodRS256,
 	}
 
-	jwt.RegisterSigningMethod(SigningMethodRS256.Alg(), func() jwt.SigningMethod {
-		return SigningMethodRS256
-	})
+	addSigningMethod(SigningMethodRS256)
 
 	SigningMethodRS384 = &RSASigningMethod{
 		name:                  "RS384",
@@ -95,9 +124,7 @@ func registerRSASigningMethods() {
 		fallbackSigningMethod: jwt.SigningMethodRS384,
 	}
 
-	jwt.RegisterSigningMethod(SigningMethodRS384.Alg(), func() jwt.SigningMethod {
-		return SigningMethodRS384
-	})
+	addSigningMethod(SigningMethodRS384)
 
 	SigningMethodRS512 = &RSASigningMethod{
 		name:                  "RS512",
@@ -106,12 +133,10 @@ func registerRSASigningMethods() {
 		fallbackSigningMethod: jwt.SigningMethodRS512,
 	}
 
-	jwt.RegisterSigningMethod(SigningMethodRS512.Alg(), func() jwt.SigningMethod {
-		return SigningMethodRS512
-	})
+	addSigningMethod(SigningMethodRS512)
 }
 
-func registerPSSSigningMethods() {
+func newPSSSigningMethods() {
 	SigningMethodPS256 = &PSSSigningMethod{
 		RSASigningMethod{
 			name: "PS256",
@@ -121,9 +146,7 @@ func registerPSSSigningMethods() {
 		jwt.SigningMethodPS256,
 	}
 
-	jwt.RegisterSigningMethod(SigningMethodPS256.Alg(), func() jwt.SigningMethod {
-		return SigningMethodPS256
-	})
+	addSigningMethod(SigningMethodPS256)
 
 	SigningMethodPS384 = &PSSSigningMethod{
 		RSASigningMethod{
@@ -134,9 +157,7 @@ func registerPSSSigningMethods() {
 		jwt.SigningMethodPS384,
 	}
 
-	jwt.RegisterSigningMethod(SigningMethodPS384.Alg(), func() jwt.SigningMethod {
-		return SigningMethodPS384
-	})
+	addSigningMethod(SigningMethodPS384)
 
 	SigningMethodPS512 = &PSSSigningMethod{
 		RSASigningMethod{
@@ -147,7 +168,39 @@ func registerPSSSigningMethods() {
 		jwt.SigningMethodPS512,
 	}
 
-	jwt.RegisterSigningMethod(SigningMethodPS512.Alg(), func() jwt.SigningMethod {
-		return SigningMethodPS512
-	})
+	addSigningMethod(SigningMethodPS512)
+}
+
+func newHMACSigningMethods() {
+	SigningMethodHS256 = &HMACSigningMethod{
+		name:                  "HS256",
+		algo:                  "HMAC_SHA_256",
+		fallbackSigningMethod: jwt.SigningMethodHS256,
+	}
+
+	addSigningMethod(SigningMethodHS256)
+
+	SigningMethodHS384 = &HMACSigningMethod{
+		name:                  "HS384",
+		algo:                  "HMAC_SHA_384",
+		fallbackSigningMethod: jwt.SigningMethodHS384,
+	}
+
+	addSigningMethod(SigningMethodHS384)
+
+	SigningMethodHS512 = &HMACSigningMethod{
+		name:                  "HS512",
+		algo:                  "HMAC_SHA_512",
+		fallbackSigningMethod: jwt.SigningMethodHS512,
+	}
+
+	addSigningMethod(SigningMethodHS512)
+}
+
+func newSM2SigningMethod() {
+	SigningMethodSM2 = &SM2SigningMethod{
+		name: "SM2",
+	}
+
+	addSigningMethod(SigningMethodSM2)
 }
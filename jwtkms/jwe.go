@@ -0,0 +1,216 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWE key management algorithms wrapping the content encryption key with a KMS RSA key, RFC 7518 section 4.3.
+const (
+	KeyAlgorithmRSAOAEP    = "RSA-OAEP"
+	KeyAlgorithmRSAOAEP256 = "RSA-OAEP-256"
+)
+
+// JWE content encryption algorithms, RFC 7518 section 5.3.
+const (
+	ContentEncryptionA128GCM = "A128GCM"
+	ContentEncryptionA192GCM = "A192GCM"
+	ContentEncryptionA256GCM = "A256GCM"
+)
+
+var jweKeyAlgorithms = map[string]types.EncryptionAlgorithmSpec{
+	KeyAlgorithmRSAOAEP:    types.EncryptionAlgorithmSpecRsaesOaepSha1,
+	KeyAlgorithmRSAOAEP256: types.EncryptionAlgorithmSpecRsaesOaepSha256,
+}
+
+var jweContentKeySizes = map[string]int{
+	ContentEncryptionA128GCM: 16,
+	ContentEncryptionA192GCM: 24,
+	ContentEncryptionA256GCM: 32,
+}
+
+// EncryptJWE encrypts plaintext into a compact JWE for the RSA KMS key of the Config, using ctx for the KMS calls.
+// The content encryption key is generated locally and wrapped with KMS Encrypt, so the private key never leaves KMS.
+//
+// alg is KeyAlgorithmRSAOAEP or KeyAlgorithmRSAOAEP256, enc one of the ContentEncryption algorithms. header holds
+// additional protected headers, alg and enc are set and the headers derived from the Config, e.g. a kid, are set
+// like in SignedString. header is not modified. The KMS client must implement KMSEncryptionClient.
+func (c *Config) EncryptJWE(ctx context.Context, alg, enc string, header map[string]interface{}, plaintext []byte) (string, error) {
+	encryptionAlgorithm, ok := jweKeyAlgorithms[alg]
+	if !ok {
+		return "", fmt.Errorf("unsupported jwe alg %q", alg)
+	}
+
+	keySize, ok := jweContentKeySizes[enc]
+	if !ok {
+		return "", fmt.Errorf("unsupported jwe enc %q", enc)
+	}
+
+	cfg := c.WithContext(ctx)
+
+	client, err := cfg.encryptionClient()
+	if err != nil {
+		return "", err
+	}
+
+	token := &jwt.Token{Header: maps.Clone(header)}
+	if token.Header == nil {
+		token.Header = make(map[string]interface{})
+	}
+
+	token.Header["alg"] = alg
+	token.Header["enc"] = enc
+
+	if err := cfg.setHeaders(token); err != nil {
+		return "", err
+	}
+
+	headerJSON, err := json.Marshal(token.Header)
+	if err != nil {
+		return "", fmt.Errorf("marshalling header: %w", err)
+	}
+
+	cek := make([]byte, keySize)
+	if _, err := rand.Read(cek); err != nil {
+		return "", fmt.Errorf("generating content encryption key: %w", err)
+	}
+
+	out, err := cfg.kmsEncrypt(client, &kms.EncryptInput{
+		KeyId:               aws.String(cfg.kmsKeyID),
+		Plaintext:           cek,
+		EncryptionAlgorithm: encryptionAlgorithm,
+	})
+	if err != nil {
+		return "", fmt.Errorf("wrapping content encryption key: %w", err)
+	}
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", fmt.Errorf("generating iv: %w", err)
+	}
+
+	encodedHeader := base64.RawURLEncoding.EncodeToString(headerJSON)
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(encodedHeader))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return strings.Join([]string{
+		encodedHeader,
+		base64.RawURLEncoding.EncodeToString(out.CiphertextBlob),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// DecryptJWE decrypts a compact JWE made with EncryptJWE, or by any producer using the public key of the Config's RSA
+// KMS key, unwrapping the content encryption key with KMS Decrypt, using ctx for the KMS calls. It returns the
+// plaintext and the protected header. JWEs with a zip or crit header are rejected.
+//
+// Decryption failures, whether of the key or of the content, match ErrDecryption.
+func (c *Config) DecryptJWE(ctx context.Context, jwe string) ([]byte, map[string]interface{}, error) {
+	parts := strings.Split(jwe, ".")
+	if len(parts) != 5 {
+		return nil, nil, fmt.Errorf("%w: jwe has %d parts, expected 5", jwt.ErrTokenMalformed, len(parts))
+	}
+
+	decoded := make([][]byte, len(parts))
+	for i, part := range parts {
+		var err error
+		if decoded[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return nil, nil, fmt.Errorf("%w: decoding jwe part %d: %w", jwt.ErrTokenMalformed, i, err)
+		}
+	}
+
+	var header map[string]interface{}
+	if err := json.Unmarshal(decoded[0], &header); err != nil {
+		return nil, nil, fmt.Errorf("%w: parsing header: %w", jwt.ErrTokenMalformed, err)
+	}
+
+	alg, _ := header["alg"].(string)
+	encryptionAlgorithm, ok := jweKeyAlgorithms[alg]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: unsupported jwe alg %q", jwt.ErrTokenUnverifiable, header["alg"])
+	}
+
+	enc, _ := header["enc"].(string)
+	keySize, ok := jweContentKeySizes[enc]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: unsupported jwe enc %q", jwt.ErrTokenUnverifiable, header["enc"])
+	}
+
+	for _, name := range []string{"zip", "crit"} {
+		if _, ok := header[name]; ok {
+			return nil, nil, fmt.Errorf("%w: unsupported jwe header %q", jwt.ErrTokenUnverifiable, name)
+		}
+	}
+
+	cfg := c.WithContext(ctx)
+
+	client, err := cfg.encryptionClient()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out, err := cfg.kmsDecrypt(client, &kms.DecryptInput{
+		KeyId:               aws.String(cfg.kmsKeyID),
+		CiphertextBlob:      decoded[1],
+		EncryptionAlgorithm: encryptionAlgorithm,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("unwrapping content encryption key: %w", err)
+	}
+
+	if len(out.Plaintext) != keySize {
+		return nil, nil, fmt.Errorf("%w: content encryption key is %d bytes, %s needs %d", ErrDecryption,
+			len(out.Plaintext), enc, keySize)
+	}
+
+	gcm, err := newGCM(out.Plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	iv, ciphertext, tag := decoded[2], decoded[3], decoded[4]
+	if len(iv) != gcm.NonceSize() || len(tag) != gcm.Overhead() {
+		return nil, nil, fmt.Errorf("%w: invalid iv or tag size", jwt.ErrTokenMalformed)
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrDecryption, err)
+	}
+
+	return plaintext, header, nil
+}
+
+// newGCM returns the AES-GCM AEAD of the content encryption key cek.
+func newGCM(cek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("creating gcm: %w", err)
+	}
+
+	return gcm, nil
+}
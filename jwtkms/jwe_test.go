@@ -0,0 +1,135 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestEncryptJWE(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id, WithKID("encryption-key"))
+	plaintext := []byte(`{"sub":"confidential"}`)
+
+	for _, alg := range []string{KeyAlgorithmRSAOAEP, KeyAlgorithmRSAOAEP256} {
+		for _, enc := range []string{ContentEncryptionA128GCM, ContentEncryptionA192GCM, ContentEncryptionA256GCM} {
+			t.Run(alg+"/"+enc, func(t *testing.T) {
+				jwe, err := cfg.EncryptJWE(ctx, alg, enc, map[string]interface{}{"typ": "JWT"}, plaintext)
+				if err != nil {
+					t.Fatalf("Error encrypting jwe: %v", err)
+				}
+
+				got, header, err := cfg.DecryptJWE(ctx, jwe)
+				if err != nil {
+					t.Fatalf("Error decrypting jwe: %v", err)
+				}
+
+				if string(got) != string(plaintext) {
+					t.Errorf("plaintext = %s, want %s", got, plaintext)
+				}
+
+				if header["alg"] != alg || header["enc"] != enc || header["kid"] != "encryption-key" || header["typ"] != "JWT" {
+					t.Errorf("header = %v", header)
+				}
+			})
+		}
+	}
+}
+
+func TestDecryptJWEInterop(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	pub, err := ParsePublicKeyPEM(publicKeyPEM(t, client, id))
+	if err != nil {
+		t.Fatalf("Error parsing public key: %v", err)
+	}
+
+	encrypter, err := jose.NewEncrypter(jose.A256GCM, jose.Recipient{Algorithm: jose.RSA_OAEP_256, Key: pub.(*rsa.PublicKey)}, nil)
+	if err != nil {
+		t.Fatalf("Error creating encrypter: %v", err)
+	}
+
+	object, err := encrypter.Encrypt([]byte("from another stack"))
+	if err != nil {
+		t.Fatalf("Error encrypting: %v", err)
+	}
+
+	jwe, err := object.CompactSerialize()
+	if err != nil {
+		t.Fatalf("Error serializing jwe: %v", err)
+	}
+
+	got, _, err := NewConfig(client, id).DecryptJWE(context.Background(), jwe)
+	if err != nil {
+		t.Fatalf("Error decrypting jwe: %v", err)
+	}
+
+	if string(got) != "from another stack" {
+		t.Errorf("plaintext = %q", got)
+	}
+}
+
+func TestDecryptJWEInvalid(t *testing.T) {
+	client := mockkms.NewMockKMS()
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, ids[0])
+
+	jwe, err := cfg.EncryptJWE(ctx, KeyAlgorithmRSAOAEP256, ContentEncryptionA256GCM, nil, []byte("secret"))
+	if err != nil {
+		t.Fatalf("Error encrypting jwe: %v", err)
+	}
+
+	parts := strings.Split(jwe, ".")
+	tampered := strings.Join(append(parts[:3:3], "AAAAAAAA", parts[4]), ".")
+
+	tests := []struct {
+		name string
+		cfg  *Config
+		jwe  string
+	}{
+		{name: "tampered ciphertext", cfg: cfg, jwe: tampered},
+		{name: "other key", cfg: NewConfig(client, ids[1]), jwe: jwe},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.cfg.DecryptJWE(ctx, tt.jwe); !errors.Is(err, ErrDecryption) {
+				t.Errorf("err = %v, want %v", err, ErrDecryption)
+			}
+		})
+	}
+
+	if _, err := cfg.EncryptJWE(ctx, "dir", ContentEncryptionA256GCM, nil, nil); err == nil {
+		t.Errorf("expected error for an unsupported alg")
+	}
+
+	if _, _, err := cfg.DecryptJWE(ctx, "a.b.c"); err == nil {
+		t.Errorf("expected error for a jws")
+	}
+}
@@ -0,0 +1,160 @@
+package jwtkms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWK is the public JSON Web Key (RFC 7517) representation of an RSA or EC key.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+
+	// EC parameters, RFC 7518 section 6.2
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+
+	// RSA parameters, RFC 7518 section 6.3
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+}
+
+// NewJWK creates the JWK of an *rsa.PublicKey or *ecdsa.PublicKey on the P-256, P-384, P-521 or secp256k1 curve.
+// Only the key parameters are populated, Use, KeyID and Algorithm are left to the caller.
+func NewJWK(pub crypto.PublicKey) (*JWK, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return &JWK{
+			KeyType: "RSA",
+			N:       base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+
+	case *ecdsa.PublicKey:
+		crv, ok := jwkCurveNames[pub.Curve]
+		if !ok {
+			return nil, errors.New("unsupported elliptic curve")
+		}
+
+		// coordinates are padded to the size of the curve, RFC 7518 section 6.2.1.2
+		size := (pub.Curve.Params().BitSize + 7) / 8
+
+		return &JWK{
+			KeyType: "EC",
+			Curve:   crv,
+			X:       base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+			Y:       base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size))),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", pub)
+	}
+}
+
+// PublicKey returns the *rsa.PublicKey or *ecdsa.PublicKey the JWK describes.
+func (j *JWK) PublicKey() (crypto.PublicKey, error) {
+	switch j.KeyType {
+	case "RSA":
+		n, err := decodeJWKInt(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("decoding n: %w", err)
+		}
+
+		e, err := decodeJWKInt(j.E)
+		if err != nil {
+			return nil, fmt.Errorf("decoding e: %w", err)
+		}
+
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("exponent too large")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		for c, name := range jwkCurveNames {
+			if name == j.Curve {
+				curve = c
+			}
+		}
+
+		if curve == nil {
+			return nil, fmt.Errorf("unsupported curve %q", j.Curve)
+		}
+
+		x, err := decodeJWKInt(j.X)
+		if err != nil {
+			return nil, fmt.Errorf("decoding x: %w", err)
+		}
+
+		y, err := decodeJWKInt(j.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decoding y: %w", err)
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", j.KeyType)
+	}
+}
+
+// Thumbprint returns the RFC 7638 thumbprint of the JWK computed with hash.
+func (j *JWK) Thumbprint(hash crypto.Hash) ([]byte, error) {
+	if !hash.Available() {
+		return nil, jwt.ErrHashUnavailable
+	}
+
+	// only the required members, in lexicographic order and without whitespace, RFC 7638 section 3.2
+	var members string
+	switch j.KeyType {
+	case "RSA":
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, j.E, j.N)
+	case "EC":
+		members = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, j.Curve, j.X, j.Y)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", j.KeyType)
+	}
+
+	hasher := hash.New()
+	hasher.Write([]byte(members)) //nolint:errcheck
+
+	return hasher.Sum(nil), nil
+}
+
+var jwkCurveNames = map[elliptic.Curve]string{
+	elliptic.P256():  "P-256",
+	elliptic.P384():  "P-384",
+	elliptic.P521():  "P-521",
+	secp256k1.S256(): "secp256k1",
+}
+
+func decodeJWKInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,260 @@
+package jwtkms
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/errgroup"
+)
+
+// JSONSignature is a signature of a JWS in the JSON serialization, RFC 7515 section 7.2, with its base64url encoded
+// protected header and its unprotected header.
+type JSONSignature struct {
+	Protected string                 `json:"protected,omitempty"`
+	Header    map[string]interface{} `json:"header,omitempty"`
+	Signature string                 `json:"signature"`
+}
+
+// FlattenedJWS is a JWS in the flattened JSON serialization, RFC 7515 section 7.2.2.
+type FlattenedJWS struct {
+	Payload string `json:"payload"`
+	JSONSignature
+}
+
+// GeneralJWS is a JWS in the general JSON serialization, RFC 7515 section 7.2.1.
+type GeneralJWS struct {
+	Payload    string          `json:"payload"`
+	Signatures []JSONSignature `json:"signatures"`
+}
+
+// General returns the JWS in the general JSON serialization.
+func (f *FlattenedJWS) General() *GeneralJWS {
+	return &GeneralJWS{Payload: f.Payload, Signatures: []JSONSignature{f.JSONSignature}}
+}
+
+// SignJSON signs payload with method into a JWS in the flattened JSON serialization, using ctx for the KMS calls,
+// for document signing contexts mandating the JSON serialization. Marshal the result, or its General form, with
+// encoding/json.
+//
+// protected holds additional protected headers, alg is set from method and the headers derived from the Config are
+// set like in SignedString. header holds the unprotected headers, which must not repeat a protected one. The payload
+// is base64url encoded unless protected has b64 false, RFC 7797. Neither map is modified.
+func (c *Config) SignJSON(ctx context.Context, method jwt.SigningMethod, protected, header map[string]interface{}, payload []byte) (*FlattenedJWS, error) {
+	encodedPayload, err := encodeJSONPayload(protected, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signJSON(c.WithContext(ctx), method, protected, header, encodedPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FlattenedJWS{Payload: encodedPayload, JSONSignature: signature}, nil
+}
+
+// JSONSigner is one of the signers of a JWS with multiple signatures, see SignGeneralJSON.
+type JSONSigner struct {
+	Config *Config
+	Method jwt.SigningMethod
+
+	// Protected and Header are the additional protected and the unprotected headers of the signature, like in
+	// SignJSON.
+	Protected map[string]interface{}
+	Header    map[string]interface{}
+}
+
+// SignGeneralJSON signs payload with every signer into a JWS in the general JSON serialization, using ctx for the KMS
+// calls, made concurrently. During a key rollover, signing with the previous and the next key keeps verifiers pinned
+// to either key working, VerifyJSON accepts a JWS if one of its signatures verifies.
+//
+// The signatures are in the order of signers, each with the headers derived from its Config, e.g. a kid. The signers
+// must agree on the b64 header.
+func SignGeneralJSON(ctx context.Context, payload []byte, signers ...JSONSigner) (*GeneralJWS, error) {
+	if len(signers) == 0 {
+		return nil, errors.New("no signers")
+	}
+
+	encodedPayload, err := encodeJSONPayload(signers[0].Protected, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, signer := range signers[1:] {
+		if encoded, err := encodeJSONPayload(signer.Protected, payload); err != nil || encoded != encodedPayload {
+			return nil, errors.New("signers do not agree on the b64 header")
+		}
+	}
+
+	jws := &GeneralJWS{Payload: encodedPayload, Signatures: make([]JSONSignature, len(signers))}
+
+	var g errgroup.Group
+	for i, signer := range signers {
+		g.Go(func() error {
+			signature, err := signJSON(signer.Config.WithContext(ctx), signer.Method, signer.Protected, signer.Header, encodedPayload)
+			if err != nil {
+				return fmt.Errorf("signing with signer %d: %w", i, err)
+			}
+
+			jws.Signatures[i] = signature
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return jws, nil
+}
+
+// encodeJSONPayload returns payload as in a JWS with the protected header: base64url encoded unless b64 is false.
+func encodeJSONPayload(protected map[string]interface{}, payload []byte) (string, error) {
+	encoded, err := b64Header(protected)
+	if err != nil {
+		return "", err
+	}
+
+	if !encoded {
+		return string(payload), nil
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// signJSON signs the encoded payload of a JWS in the JSON serialization with cfg and method.
+func signJSON(cfg *Config, method jwt.SigningMethod, protected, header map[string]interface{}, encodedPayload string) (JSONSignature, error) {
+	token := &jwt.Token{Header: maps.Clone(protected), Method: method}
+	if token.Header == nil {
+		token.Header = make(map[string]interface{})
+	}
+
+	token.Header["alg"] = method.Alg()
+
+	if err := cfg.setHeaders(token); err != nil {
+		return JSONSignature{}, err
+	}
+
+	for name := range header {
+		if _, ok := token.Header[name]; ok {
+			return JSONSignature{}, fmt.Errorf("header %q is both protected and unprotected", name)
+		}
+	}
+
+	protectedJSON, err := json.Marshal(token.Header)
+	if err != nil {
+		return JSONSignature{}, fmt.Errorf("marshalling protected header: %w", err)
+	}
+
+	signature := JSONSignature{
+		Protected: base64.RawURLEncoding.EncodeToString(protectedJSON),
+		Header:    maps.Clone(header),
+	}
+
+	sig, err := method.Sign(signature.Protected+"."+encodedPayload, cfg)
+	if err != nil {
+		return JSONSignature{}, err
+	}
+
+	signature.Signature = base64.RawURLEncoding.EncodeToString(sig)
+
+	return signature, nil
+}
+
+// VerifyJSON verifies data, a JWS in the flattened or general JSON serialization, with method, using ctx for the KMS
+// calls, and returns its payload and the protected and unprotected headers of the verified signature, merged. A
+// general JWS is valid if one of its signatures with the alg of method verifies with the Config.
+func (c *Config) VerifyJSON(ctx context.Context, method jwt.SigningMethod, data []byte) ([]byte, map[string]interface{}, error) {
+	var jws struct {
+		GeneralJWS
+		JSONSignature
+	}
+
+	if err := json.Unmarshal(data, &jws); err != nil {
+		return nil, nil, fmt.Errorf("%w: parsing jws: %w", jwt.ErrTokenMalformed, err)
+	}
+
+	signatures := jws.Signatures
+	if signatures == nil {
+		// the flattened serialization has no signatures member
+		signatures = []JSONSignature{jws.JSONSignature}
+	}
+
+	cfg := c.WithContext(ctx)
+	err := fmt.Errorf("%w: no signature with alg %q", jwt.ErrTokenUnverifiable, method.Alg())
+
+	for _, signature := range signatures {
+		payload, header, verifyErr := verifyJSONSignature(cfg, method, jws.Payload, signature)
+		if verifyErr == nil {
+			return payload, header, nil
+		}
+
+		if header != nil || errors.Is(verifyErr, jwt.ErrTokenMalformed) {
+			// the last error of a malformed signature or one with the alg of method is the most helpful
+			err = verifyErr
+		}
+	}
+
+	return nil, nil, err
+}
+
+// verifyJSONSignature verifies one signature of a JWS in the JSON serialization. The header is returned along with
+// the verification error if the alg of the signature is the one of method.
+func verifyJSONSignature(cfg *Config, method jwt.SigningMethod, encodedPayload string, signature JSONSignature) ([]byte, map[string]interface{}, error) {
+	protectedJSON, err := base64.RawURLEncoding.DecodeString(signature.Protected)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: decoding protected header: %w", jwt.ErrTokenMalformed, err)
+	}
+
+	protected := make(map[string]interface{})
+	if len(protectedJSON) > 0 {
+		if err := json.Unmarshal(protectedJSON, &protected); err != nil {
+			return nil, nil, fmt.Errorf("%w: parsing protected header: %w", jwt.ErrTokenMalformed, err)
+		}
+	}
+
+	header := maps.Clone(protected)
+	for name, value := range signature.Header {
+		if _, ok := header[name]; ok {
+			return nil, nil, fmt.Errorf("%w: header %q is both protected and unprotected", jwt.ErrTokenMalformed, name)
+		}
+
+		header[name] = value
+	}
+
+	if alg, _ := header["alg"].(string); alg != method.Alg() {
+		return nil, nil, fmt.Errorf("%w: alg %q, expected %q", jwt.ErrTokenSignatureInvalid, header["alg"], method.Alg())
+	}
+
+	// b64 and crit are only meaningful in the protected header
+	encoded, err := b64Header(protected)
+	if err != nil {
+		return nil, header, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(signature.Signature)
+	if err != nil {
+		return nil, header, fmt.Errorf("%w: decoding signature: %w", jwt.ErrTokenMalformed, err)
+	}
+
+	if err := method.Verify(signature.Protected+"."+encodedPayload, sig, cfg); err != nil {
+		return nil, header, fmt.Errorf("%w: %w", jwt.ErrTokenSignatureInvalid, err)
+	}
+
+	if !encoded {
+		return []byte(encodedPayload), header, nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, header, fmt.Errorf("%w: decoding payload: %w", jwt.ErrTokenMalformed, err)
+	}
+
+	return payload, header, nil
+}
@@ -0,0 +1,201 @@
+package jwtkms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestSignJSON(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP384)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id, WithKID("document-key"))
+	payload := []byte(`{"document":"contract.pdf"}`)
+
+	jws, err := cfg.SignJSON(ctx, SigningMethodECDSA384, map[string]interface{}{"typ": "JOSE+JSON"}, map[string]interface{}{"note": "unprotected"}, payload)
+	if err != nil {
+		t.Fatalf("Error signing jws: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		jws  interface{}
+	}{
+		{name: "flattened", jws: jws},
+		{name: "general", jws: jws.General()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.jws)
+			if err != nil {
+				t.Fatalf("Error marshalling jws: %v", err)
+			}
+
+			got, header, err := cfg.VerifyJSON(ctx, SigningMethodECDSA384, data)
+			if err != nil {
+				t.Fatalf("Error verifying jws: %v", err)
+			}
+
+			if string(got) != string(payload) {
+				t.Errorf("payload = %s, want %s", got, payload)
+			}
+
+			if header["kid"] != "document-key" || header["typ"] != "JOSE+JSON" || header["note"] != "unprotected" {
+				t.Errorf("header = %v", header)
+			}
+
+			if _, _, err := cfg.VerifyJSON(ctx, SigningMethodECDSA256, data); !errors.Is(err, jwt.ErrTokenUnverifiable) {
+				t.Errorf("err = %v, want %v for another alg", err, jwt.ErrTokenUnverifiable)
+			}
+		})
+	}
+}
+
+func TestSignJSONUnencodedPayload(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id)
+
+	jws, err := cfg.SignJSON(ctx, SigningMethodRS256, map[string]interface{}{"b64": false, "crit": []string{"b64"}}, nil, []byte("$.02"))
+	if err != nil {
+		t.Fatalf("Error signing jws: %v", err)
+	}
+
+	if jws.Payload != "$.02" {
+		t.Errorf("payload = %q, want it unencoded", jws.Payload)
+	}
+
+	data, err := json.Marshal(jws)
+	if err != nil {
+		t.Fatalf("Error marshalling jws: %v", err)
+	}
+
+	if _, _, err := cfg.VerifyJSON(ctx, SigningMethodRS256, data); err != nil {
+		t.Errorf("Error verifying jws: %v", err)
+	}
+}
+
+func TestVerifyJSONInvalid(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id)
+
+	if _, err := cfg.SignJSON(ctx, SigningMethodECDSA256, map[string]interface{}{"kid": "a"}, map[string]interface{}{"kid": "b"}, nil); err == nil {
+		t.Errorf("expected error for a header both protected and unprotected")
+	}
+
+	jws, err := cfg.SignJSON(ctx, SigningMethodECDSA256, nil, nil, []byte("payload"))
+	if err != nil {
+		t.Fatalf("Error signing jws: %v", err)
+	}
+
+	tampered := *jws
+	tampered.Payload = "cGF5bG9hZDI"
+
+	duplicated := *jws
+	duplicated.Header = map[string]interface{}{"alg": "ES256"}
+
+	tests := []struct {
+		name string
+		jws  interface{}
+		want error
+	}{
+		{name: "tampered payload", jws: &tampered, want: jwt.ErrTokenSignatureInvalid},
+		{name: "duplicated header", jws: &duplicated, want: jwt.ErrTokenMalformed},
+		{name: "not json", jws: "jws", want: jwt.ErrTokenMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.jws)
+			if err != nil {
+				t.Fatalf("Error marshalling jws: %v", err)
+			}
+
+			if _, _, err := cfg.VerifyJSON(ctx, SigningMethodECDSA256, data); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignGeneralJSON(t *testing.T) {
+	client := mockkms.NewMockKMS()
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	ctx := context.Background()
+	payload := []byte(`{"document":"contract.pdf"}`)
+
+	jws, err := SignGeneralJSON(ctx, payload,
+		JSONSigner{Config: NewConfig(client, ids[0], WithKeyIDHeader()), Method: SigningMethodECDSA256},
+		JSONSigner{Config: NewConfig(client, ids[1], WithKeyIDHeader()), Method: SigningMethodECDSA256, Header: map[string]interface{}{"note": "next"}},
+	)
+	if err != nil {
+		t.Fatalf("Error signing jws: %v", err)
+	}
+
+	if len(jws.Signatures) != 2 {
+		t.Fatalf("signatures = %d, want 2", len(jws.Signatures))
+	}
+
+	data, err := json.Marshal(jws)
+	if err != nil {
+		t.Fatalf("Error marshalling jws: %v", err)
+	}
+
+	for i, id := range ids[:2] {
+		_, header, err := NewConfig(client, id).VerifyJSON(ctx, SigningMethodECDSA256, data)
+		if err != nil {
+			t.Fatalf("Error verifying jws with key %d: %v", i, err)
+		}
+
+		if kid, _ := header["kid"].(string); !strings.HasSuffix(kid, id) {
+			t.Errorf("kid = %v, want the arn of %v", kid, id)
+		}
+	}
+
+	if _, _, err := NewConfig(client, ids[2]).VerifyJSON(ctx, SigningMethodECDSA256, data); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Errorf("err = %v, want %v for a key that did not sign", err, jwt.ErrTokenSignatureInvalid)
+	}
+
+	if _, err := SignGeneralJSON(ctx, payload); err == nil {
+		t.Errorf("expected error without signers")
+	}
+
+	if _, err := SignGeneralJSON(ctx, payload,
+		JSONSigner{Config: NewConfig(client, ids[0]), Method: SigningMethodECDSA256},
+		JSONSigner{Config: NewConfig(client, ids[1]), Method: SigningMethodECDSA256, Protected: map[string]interface{}{"b64": false, "crit": []string{"b64"}}},
+	); err == nil {
+		t.Errorf("expected error for signers disagreeing on b64")
+	}
+}
@@ -0,0 +1,51 @@
+package jwtkms
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// ErrKIDNotAllowed is returned by KeyARNKeySet for kids that are not one of its key ARNs.
+var ErrKIDNotAllowed = errors.New("kid is not an allowed kms key arn")
+
+// KeyARNKeySet is a KeySet for tokens whose kid is the ARN of the KMS key they are signed with, see WithKeyIDHeader.
+// Only the allowlisted key ARNs are verified with, so a token can not point verification at another key, e.g. one an
+// attacker controls in the same account.
+type KeyARNKeySet struct {
+	configs map[string]*Config
+}
+
+var _ KeySet = &KeyARNKeySet{}
+
+// NewKeyARNKeySet creates a KeyARNKeySet verifying with the KMS keys of keyARNs through client. opts are applied to
+// the Config of every key. Alias ARNs are rejected, an alias can be pointed at another key.
+func NewKeyARNKeySet(client KMSClient, keyARNs []string, opts ...Option) (*KeyARNKeySet, error) {
+	configs := make(map[string]*Config, len(keyARNs))
+	for _, keyARN := range keyARNs {
+		parsed, err := arn.Parse(keyARN)
+		if err != nil {
+			return nil, fmt.Errorf("malformed arn %q: %w", keyARN, err)
+		}
+
+		if parsed.Service != "kms" || !strings.HasPrefix(parsed.Resource, "key/") {
+			return nil, fmt.Errorf("arn %q is not a kms key arn", keyARN)
+		}
+
+		configs[keyARN] = NewConfig(client, keyARN, opts...)
+	}
+
+	return &KeyARNKeySet{configs: configs}, nil
+}
+
+// ConfigForKID returns the Config of the key ARN kid, or an ErrKIDNotAllowed error if kid is not allowlisted.
+func (s *KeyARNKeySet) ConfigForKID(kid string) (*Config, error) {
+	cfg, ok := s.configs[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKIDNotAllowed, kid)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,55 @@
+package jwtkms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestKeyARNKeySet(t *testing.T) {
+	registered(t)
+
+	client := mockkms.NewMockKMS()
+
+	var arns []string
+	for i := 0; i < 2; i++ {
+		id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		arns = append(arns, mockkms.KeyARN(id))
+	}
+
+	sign := func(keyARN string) string {
+		t.Helper()
+
+		signed, err := NewConfig(client, keyARN, WithKeyIDHeader()).SignedString(jwt.New(SigningMethodECDSA256))
+		if err != nil {
+			t.Fatalf("Error signing token: %v", err)
+		}
+
+		return signed
+	}
+
+	keySet, err := NewKeyARNKeySet(client, arns[:1])
+	if err != nil {
+		t.Fatalf("Error creating key set: %v", err)
+	}
+
+	if _, err := jwt.Parse(sign(arns[0]), Keyfunc(keySet)); err != nil {
+		t.Errorf("Error verifying token of an allowed key: %v", err)
+	}
+
+	if _, err := jwt.Parse(sign(arns[1]), Keyfunc(keySet)); !errors.Is(err, ErrKIDNotAllowed) {
+		t.Errorf("err = %v, want %v", err, ErrKIDNotAllowed)
+	}
+
+	for _, keyARN := range []string{"1234abcd", "arn:aws:kms:us-east-1:111122223333:alias/signing", "arn:aws:s3:::bucket/key/x"} {
+		if _, err := NewKeyARNKeySet(client, []string{keyARN}); err == nil {
+			t.Errorf("expected error for %q", keyARN)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// defaultKeyMetadataFailureTTL is how long a failure to fetch the metadata of a key is remembered when the Config has
+// no negative cache TTL.
+const defaultKeyMetadataFailureTTL = time.Minute
+
+// sharedKeyMetadata is the key metadata cache of the Configs sharing the package's public key cache.
+var sharedKeyMetadata = newKeyMetadataCache()
+
+// keyMetadataCache remembers the key spec and signing algorithms of keys, and failures to fetch them caused by the
+// key, by key id. It holds the metadata of keys whose public key is not cached, e.g. keys of signers not granted
+// kms:GetPublicKey, HMAC keys and Configs without a public key cache.
+type keyMetadataCache struct {
+	mu      sync.Mutex
+	entries map[string]keyMetadataEntry
+}
+
+type keyMetadataEntry struct {
+	metadata *CachedPublicKey
+	err      error
+
+	// expiresAt is when the entry has to be fetched again, never if zero.
+	expiresAt time.Time
+}
+
+func newKeyMetadataCache() *keyMetadataCache {
+	return &keyMetadataCache{entries: make(map[string]keyMetadataEntry)}
+}
+
+// get returns the remembered metadata or failure of keyID, ok is false if there is none.
+func (m *keyMetadataCache) get(keyID string, now time.Time) (metadata *CachedPublicKey, err error, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[keyID]
+	if !ok {
+		return nil, nil, false
+	}
+
+	if !entry.expiresAt.IsZero() && !now.Before(entry.expiresAt) {
+		delete(m.entries, keyID)
+		return nil, nil, false
+	}
+
+	return entry.metadata, entry.err, true
+}
+
+func (m *keyMetadataCache) add(keyID string, entry keyMetadataEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[keyID] = entry
+}
+
+func (m *keyMetadataCache) delete(keyID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, keyID)
+}
+
+// keyMetadata returns the key spec and signing algorithms of the configured key: those of its known public key, or
+// else remembered or fetched with GetPublicKey, or with DescribeKey if that fails and the client implements
+// KMSDescribeKeyClient, e.g. for a signer not granted kms:GetPublicKey or an HMAC key. The metadata is remembered for
+// the public key TTL of the Config; failures caused by the key, like the denial of both calls, for the negative cache
+// TTL, a minute by default. Otherwise the error is the GetPublicKey one.
+func (c *Config) keyMetadata() (*CachedPublicKey, error) {
+	if known := c.knownPublicKey(); known != nil {
+		return known, nil
+	}
+
+	now := time.Now()
+	if c.keyMetadataCache != nil {
+		if metadata, err, ok := c.keyMetadataCache.get(c.kmsKeyID, now); ok {
+			return metadata, err
+		}
+	}
+
+	metadata, err, remember := c.fetchKeyMetadata()
+	if c.keyMetadataCache == nil || !remember {
+		return metadata, err
+	}
+
+	entry := keyMetadataEntry{metadata: metadata, err: err}
+	switch {
+	case err != nil && c.publicKeyFailures != nil:
+		entry.expiresAt = now.Add(c.publicKeyFailures.ttl)
+	case err != nil:
+		entry.expiresAt = now.Add(defaultKeyMetadataFailureTTL)
+	case c.publicKeyTTL > 0:
+		entry.expiresAt = now.Add(c.publicKeyTTL)
+	}
+
+	c.keyMetadataCache.add(c.kmsKeyID, entry)
+
+	return metadata, err
+}
+
+// fetchKeyMetadata fetches the metadata of the configured key for keyMetadata, remember reports whether the result is
+// to be remembered: always on success, only for failures caused by the key otherwise.
+func (c *Config) fetchKeyMetadata() (metadata *CachedPublicKey, err error, remember bool) {
+	cached, err := getCachedPublicKey(c)
+	if err == nil {
+		return cached, nil, true
+	}
+
+	// HMAC keys have no public key, without DescribeKey their key spec stays unknown
+	var unsupported *types.UnsupportedOperationException
+	client, ok := c.kmsClient.(KMSDescribeKeyClient)
+	if !ok {
+		return nil, err, keyCausedFailure(err) || errors.As(err, &unsupported)
+	}
+
+	out, describeErr := invoke(c, OperationDescribeKey, func(ctx context.Context) (*kms.DescribeKeyOutput, error) {
+		return client.DescribeKey(ctx, &kms.DescribeKeyInput{
+			KeyId:       aws.String(c.kmsKeyID),
+			GrantTokens: c.grantTokens,
+		}, c.kmsOptions()...)
+	})
+	if describeErr != nil {
+		return nil, err, keyCausedFailure(describeErr)
+	}
+
+	if out.KeyMetadata == nil {
+		return nil, err, false
+	}
+
+	return &CachedPublicKey{
+		KeyARN:            aws.ToString(out.KeyMetadata.Arn),
+		KeySpec:           out.KeyMetadata.KeySpec,
+		SigningAlgorithms: out.KeyMetadata.SigningAlgorithms,
+		FetchedAt:         time.Now(),
+	}, nil, true
+}
+
+// keyCausedFailure reports whether err is caused by the key rather than a transient problem: ErrKeyNotFound,
+// ErrAccessDenied or ErrKeyDisabled.
+func keyCausedFailure(err error) bool {
+	return errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrAccessDenied) || errors.Is(err, ErrKeyDisabled)
+}
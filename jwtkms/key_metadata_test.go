@@ -0,0 +1,94 @@
+package jwtkms
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// metadataCallsKMS counts the GetPublicKey and DescribeKey calls of a deniedKMS.
+type metadataCallsKMS struct {
+	*deniedKMS
+	getPublicKey atomic.Int32
+	describeKey  atomic.Int32
+}
+
+func (c *metadataCallsKMS) GetPublicKey(ctx context.Context, in *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error) {
+	c.getPublicKey.Add(1)
+	return c.deniedKMS.GetPublicKey(ctx, in, optFns...)
+}
+
+func (c *metadataCallsKMS) DescribeKey(ctx context.Context, in *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+	c.describeKey.Add(1)
+	return c.deniedKMS.DescribeKey(ctx, in, optFns...)
+}
+
+func TestKeyMetadataCache(t *testing.T) {
+	tests := []struct {
+		name             string
+		denied           []string
+		opts             []Option
+		wantGetPublicKey int32
+		wantDescribeKey  int32
+	}{
+		{name: "public key", wantGetPublicKey: 1},
+		{name: "without public key cache", opts: []Option{WithoutPublicKeyCache()}, wantGetPublicKey: 1},
+		{name: "GetPublicKey denied", denied: []string{"kms:GetPublicKey"}, wantGetPublicKey: 1, wantDescribeKey: 1},
+		{
+			name:             "GetPublicKey and DescribeKey denied",
+			denied:           []string{"kms:GetPublicKey", "kms:DescribeKey"},
+			wantGetPublicKey: 1,
+			wantDescribeKey:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &metadataCallsKMS{deniedKMS: &deniedKMS{MockKMS: mockkms.NewMockKMS(), denied: tt.denied}}
+			id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			cfg := NewConfig(client, id, tt.opts...)
+			for i := 0; i < 3; i++ {
+				if _, err := cfg.SignContext(context.Background(), jwt.New(SigningMethodECDSA256)); err != nil {
+					t.Fatalf("Error signing token: %v", err)
+				}
+			}
+
+			if n := client.getPublicKey.Load(); n != tt.wantGetPublicKey {
+				t.Errorf("GetPublicKey calls = %d, want %d", n, tt.wantGetPublicKey)
+			}
+
+			if n := client.describeKey.Load(); n != tt.wantDescribeKey {
+				t.Errorf("DescribeKey calls = %d, want %d", n, tt.wantDescribeKey)
+			}
+		})
+	}
+}
+
+func TestKeyMetadataCacheInvalidate(t *testing.T) {
+	client := &metadataCallsKMS{deniedKMS: &deniedKMS{MockKMS: mockkms.NewMockKMS(), denied: []string{"kms:GetPublicKey"}}}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCSECGP256K1)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id)
+	for i := 0; i < 2; i++ {
+		if _, err := cfg.keyMetadata(); err != nil {
+			t.Fatalf("Error fetching key metadata: %v", err)
+		}
+
+		cfg.Invalidate(id)
+	}
+
+	if n := client.describeKey.Load(); n != 2 {
+		t.Errorf("DescribeKey calls = %d, want 2", n)
+	}
+}
@@ -0,0 +1,192 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+var (
+	// ErrKeyPendingDeletion is matched by KeyStateErrors of keys scheduled for deletion.
+	ErrKeyPendingDeletion = errors.New("kms key is pending deletion")
+
+	// ErrKeyPendingImport is matched by KeyStateErrors of keys waiting for imported key material.
+	ErrKeyPendingImport = errors.New("kms key is pending import")
+)
+
+// KeyStateError is returned when the KMS key is in a state that does not allow signing, as reported by DescribeKey.
+// It matches ErrKeyDisabled, and ErrKeyPendingDeletion or ErrKeyPendingImport for keys in those states.
+type KeyStateError struct {
+	KeyID string
+	State types.KeyState
+
+	// DeletionDate is when a key pending deletion is deleted, zero otherwise.
+	DeletionDate time.Time
+}
+
+func (e *KeyStateError) Error() string {
+	if !e.DeletionDate.IsZero() {
+		return fmt.Sprintf("kms key %s is %s, deletion is scheduled for %s", e.KeyID, e.State, e.DeletionDate.Format(time.RFC3339))
+	}
+
+	return fmt.Sprintf("kms key %s is %s", e.KeyID, e.State)
+}
+
+// Is reports whether target is ErrKeyDisabled or the error of the key's state.
+func (e *KeyStateError) Is(target error) bool {
+	switch target {
+	case ErrKeyDisabled:
+		return true
+	case ErrKeyPendingDeletion:
+		return e.State == types.KeyStatePendingDeletion || e.State == types.KeyStatePendingReplicaDeletion
+	case ErrKeyPendingImport:
+		return e.State == types.KeyStatePendingImport
+	}
+
+	return false
+}
+
+// CheckKeyState checks the state of the KMS key with KMS DescribeKey, e.g. at startup, returning a *KeyStateError if
+// the key is disabled, pending deletion, pending import or otherwise unusable. The KMS client must implement
+// KMSDescribeKeyClient.
+func (c *Config) CheckKeyState(ctx context.Context) error {
+	cfg := c.WithContext(ctx)
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	client, ok := cfg.kmsClient.(KMSDescribeKeyClient)
+	if !ok {
+		return errors.New("kms client does not implement KMSDescribeKeyClient")
+	}
+
+	out, err := invoke(cfg, OperationDescribeKey, func(ctx context.Context) (*kms.DescribeKeyOutput, error) {
+		return client.DescribeKey(ctx, &kms.DescribeKeyInput{
+			KeyId:       aws.String(cfg.kmsKeyID),
+			GrantTokens: cfg.grantTokens,
+		}, cfg.kmsOptions()...)
+	})
+	if err != nil {
+		return fmt.Errorf("describing key: %w", err)
+	}
+
+	return keyStateError(cfg.kmsKeyID, out.KeyMetadata)
+}
+
+// keyStateError returns the KeyStateError of a key with metadata, nil if the key is usable.
+func keyStateError(keyID string, metadata *types.KeyMetadata) error {
+	if metadata == nil {
+		return nil
+	}
+
+	switch metadata.KeyState {
+	case types.KeyStateEnabled, types.KeyStateUpdating, "":
+		return nil
+	}
+
+	return &KeyStateError{
+		KeyID:        keyID,
+		State:        metadata.KeyState,
+		DeletionDate: aws.ToTime(metadata.DeletionDate),
+	}
+}
+
+// keyStateChecker caches the key states of WithKeyStateCheck, shared by the copies of a Config.
+type keyStateChecker struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	states map[string]*keyState
+}
+
+// keyState is the cached state of a key.
+type keyState struct {
+	err       error
+	checkedAt time.Time
+	checking  bool
+}
+
+// WithKeyStateCheck makes the Config check the state of the KMS key before signing, see Config.WithKeyStateCheck.
+func WithKeyStateCheck(interval time.Duration) Option {
+	return func(c *Config) {
+		c.keyStates = &keyStateChecker{
+			interval: interval,
+			now:      time.Now,
+			states:   make(map[string]*keyState),
+		}
+	}
+}
+
+// WithKeyStateCheck returns a copy of Config checking the state of its KMS key with CheckKeyState every interval, so
+// signing with a disabled key, a key pending deletion or pending import fails fast with a *KeyStateError and a
+// warning is logged when the key becomes unusable, instead of Sign calls failing mid-request.
+//
+// The first signing call with the key checks the state, later ones once interval passed refresh it in the
+// background. If the state can not be checked, e.g. without kms:DescribeKey permission, signing is not blocked.
+func (c *Config) WithKeyStateCheck(interval time.Duration) *Config {
+	return c.with(WithKeyStateCheck(interval))
+}
+
+// check returns the cached key state error of the key of c, checking the state if it was never checked and
+// refreshing it in the background once the interval passed.
+func (k *keyStateChecker) check(c *Config) error {
+	k.mu.Lock()
+
+	state, ok := k.states[c.kmsKeyID]
+	if !ok {
+		state = &keyState{checking: true}
+		k.states[c.kmsKeyID] = state
+		k.mu.Unlock()
+
+		k.refresh(c, state)
+
+		k.mu.Lock()
+		defer k.mu.Unlock()
+
+		return state.err
+	}
+
+	defer k.mu.Unlock()
+
+	if !state.checking && k.now().Sub(state.checkedAt) >= k.interval {
+		state.checking = true
+
+		// the check outlives the call that started it
+		go k.refresh(c.WithContext(context.WithoutCancel(c.ctx)), state)
+	}
+
+	return state.err
+}
+
+// refresh checks the key state of c and updates state, logging changes of the key's usability.
+func (k *keyStateChecker) refresh(c *Config, state *keyState) {
+	err := c.CheckKeyState(c.ctx)
+
+	var stateErr *KeyStateError
+	if err != nil && !errors.As(err, &stateErr) {
+		c.log(slog.LevelWarn, "checking KMS key state failed", slog.Any("error", err))
+		err = nil
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	switch {
+	case stateErr != nil && state.err == nil:
+		c.log(slog.LevelWarn, "KMS key unusable", slog.String("key_state", string(stateErr.State)))
+	case stateErr == nil && state.err != nil:
+		c.log(slog.LevelInfo, "KMS key usable again")
+	}
+
+	state.err = err
+	state.checking = false
+	state.checkedAt = k.now()
+}
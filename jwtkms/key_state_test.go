@@ -0,0 +1,108 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestCheckKeyState(t *testing.T) {
+	client := mockkms.NewMockKMS()
+
+	tests := []struct {
+		name    string
+		state   types.KeyState
+		wantErr []error
+	}{
+		{name: "enabled", state: types.KeyStateEnabled},
+		{name: "disabled", state: types.KeyStateDisabled, wantErr: []error{ErrKeyDisabled}},
+		{name: "pending deletion", state: types.KeyStatePendingDeletion, wantErr: []error{ErrKeyDisabled, ErrKeyPendingDeletion}},
+		{name: "pending import", state: types.KeyStatePendingImport, wantErr: []error{ErrKeyDisabled, ErrKeyPendingImport}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			client.SetKeyState(id, tt.state)
+
+			err = NewConfig(client, id).CheckKeyState(context.Background())
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Error checking key state: %v", err)
+				}
+				return
+			}
+
+			var stateErr *KeyStateError
+			if !errors.As(err, &stateErr) || stateErr.State != tt.state {
+				t.Fatalf("err = %v, want a KeyStateError of %s", err, tt.state)
+			}
+
+			for _, want := range tt.wantErr {
+				if !errors.Is(err, want) {
+					t.Errorf("err = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+
+	if err := NewConfig(client, "unknown").CheckKeyState(context.Background()); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("err = %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestWithKeyStateCheck(t *testing.T) {
+	client := &signCountingKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	now := time.Now()
+	cfg := NewConfig(client, id, WithKeyStateCheck(time.Minute))
+	cfg.keyStates.now = func() time.Time { return now }
+
+	client.SetKeyState(id, types.KeyStatePendingDeletion)
+
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); !errors.Is(err, ErrKeyPendingDeletion) {
+		t.Fatalf("err = %v, want %v", err, ErrKeyPendingDeletion)
+	}
+
+	if client.calls.Load() != 0 {
+		t.Errorf("Sign calls = %d, want 0", client.calls.Load())
+	}
+
+	// the cached state is kept within the interval
+	client.SetKeyState(id, types.KeyStateEnabled)
+
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); !errors.Is(err, ErrKeyPendingDeletion) {
+		t.Fatalf("err = %v, want %v", err, ErrKeyPendingDeletion)
+	}
+
+	now = now.Add(time.Minute)
+
+	// the refresh runs in the background, the signing call starting it still fails
+	jwt.New(SigningMethodECDSA256).SignedString(cfg) //nolint:errcheck
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); err == nil {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("the key state was not refreshed")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,54 @@
+package jwtkms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeySet resolves the Config a token is verified with from the kid in its header.
+type KeySet interface {
+	ConfigForKID(kid string) (*Config, error)
+}
+
+// StaticKeySet is a KeySet backed by a fixed map of kid to Config.
+type StaticKeySet map[string]*Config
+
+// ConfigForKID returns the Config registered for kid.
+func (s StaticKeySet) ConfigForKID(kid string) (*Config, error) {
+	cfg, ok := s[kid]
+	if !ok {
+		return nil, fmt.Errorf("no key for kid %q", kid)
+	}
+
+	return cfg, nil
+}
+
+// Keyfunc returns a jwt.Keyfunc that looks up the Config for the token's kid header in ks,
+// so tokens signed with several KMS keys can be verified with jwt.Parse and jwt.ParseWithClaims, which need the
+// signing methods registered, see Register.
+func Keyfunc(ks KeySet) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		return configForToken(ks, token)
+	}
+}
+
+// configForToken returns the Config of ks for the kid header of token.
+func configForToken(ks KeySet, token *jwt.Token) (*Config, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok || kid == "" {
+		return nil, errors.New("token has no kid header")
+	}
+
+	cfg, err := ks.ConfigForKID(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.checkTokenType(token); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
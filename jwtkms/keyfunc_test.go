@@ -0,0 +1,65 @@
+package jwtkms
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestKeyfunc(t *testing.T) {
+	registered(t)
+
+	kms := mockkms.NewMockKMS()
+
+	ecID, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	rsaID, err := kms.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	keySet := StaticKeySet{
+		"ec":  NewKMSConfig(kms, ecID, false),
+		"rsa": NewKMSConfig(kms, rsaID, false),
+	}
+
+	tests := []struct {
+		name          string
+		signingMethod jwt.SigningMethod
+		kid           string
+		signKID       string
+		wantErr       bool
+	}{
+		{name: "ec", signingMethod: SigningMethodECDSA256, kid: "ec", signKID: "ec"},
+		{name: "rsa", signingMethod: SigningMethodRS256, kid: "rsa", signKID: "rsa"},
+		{name: "unknown kid", signingMethod: SigningMethodECDSA256, kid: "other", signKID: "ec", wantErr: true},
+		{name: "missing kid", signingMethod: SigningMethodECDSA256, signKID: "ec", wantErr: true},
+		{name: "wrong key", signingMethod: SigningMethodRS256, kid: "ec", signKID: "rsa", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			token := jwt.NewWithClaims(test.signingMethod, jwt.MapClaims{"claim": "value"})
+			if test.kid != "" {
+				token.Header["kid"] = test.kid
+			}
+
+			signed, err := token.SignedString(keySet[test.signKID])
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			_, err = jwt.Parse(signed, Keyfunc(keySet))
+			if test.wantErr && err == nil {
+				t.Errorf("expected error verifying token")
+			}
+
+			if !test.wantErr && err != nil {
+				t.Errorf("Error verifying token: %v", err)
+			}
+		})
+	}
+}
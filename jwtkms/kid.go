@@ -0,0 +1,68 @@
+package jwtkms
+
+import (
+	"crypto"
+	"encoding/base64"
+	"strings"
+)
+
+// KIDFunc computes the kid header of a token from the ARN and public key of the KMS key it is signed with.
+type KIDFunc func(keyARN string, pub crypto.PublicKey) (string, error)
+
+// WithKeyIDHeader returns a copy of Config whose SignedString sets the kid header of tokens to the ARN of the KMS key.
+//
+// The ARN is taken from the key id when it is a key ARN and looked up with GetPublicKey otherwise, so HMAC keys, which
+// have no public key, must be configured with their key ARN.
+func (c *Config) WithKeyIDHeader() *Config {
+	return c.with(WithKeyIDHeader())
+}
+
+// WithThumbprintKeyIDHeader returns a copy of Config whose SignedString sets the kid header of tokens to the base64url
+// encoded RFC 7638 SHA-256 thumbprint of the KMS public key.
+//
+// Unlike the key ARN the thumbprint does not reveal the AWS account id, and it matches the kid JWKS consumers compute
+// for the key. It is only available for RSA and EC keys.
+func (c *Config) WithThumbprintKeyIDHeader() *Config {
+	return c.with(WithThumbprintKeyIDHeader())
+}
+
+// WithKIDFunc returns a copy of Config whose SignedString sets the kid header of tokens to the result of f, for naming
+// schemes like alias names, key versions or truncated hashes. The public key is fetched with GetPublicKey, so f can
+// not be used with HMAC keys.
+func (c *Config) WithKIDFunc(f KIDFunc) *Config {
+	return c.with(WithKIDFunc(f))
+}
+
+// keyARN returns the ARN of the configured KMS key.
+func keyARN(cfg *Config) (string, error) {
+	if strings.HasPrefix(cfg.kmsKeyID, "arn:") && strings.Contains(cfg.kmsKeyID, ":key/") {
+		return cfg.kmsKeyID, nil
+	}
+
+	cached, err := getCachedPublicKey(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	return cached.KeyARN, nil
+}
+
+// thumbprint returns the base64url encoded RFC 7638 SHA-256 thumbprint of the configured KMS key.
+func thumbprint(cfg *Config) (string, error) {
+	pub, err := getPublicKey(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	jwk, err := NewJWK(pub)
+	if err != nil {
+		return "", err
+	}
+
+	sum, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(sum), nil
+}
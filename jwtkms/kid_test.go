@@ -0,0 +1,182 @@
+package jwtkms
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestKeyIDHeader(t *testing.T) {
+	registered(t)
+
+	tests := []struct {
+		name          string
+		keyType       mockkms.KeyType
+		signingMethod jwt.SigningMethod
+		useARN        bool
+	}{
+		{name: "ES256", keyType: mockkms.KeyTypeECCNISTP256, signingMethod: SigningMethodECDSA256},
+		{name: "RS256", keyType: mockkms.KeyTypeRSA2048, signingMethod: SigningMethodRS256},
+		{name: "HS256 by ARN", keyType: mockkms.KeyTypeHMAC256, signingMethod: SigningMethodHS256, useARN: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kms := mockkms.NewMockKMS()
+			id, err := kms.GenerateKey(test.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			keyID := id
+			if test.useARN {
+				keyID = mockkms.KeyARN(id)
+			}
+
+			cfg := NewKMSConfig(kms, keyID, false)
+
+			signed, err := cfg.WithKeyIDHeader().SignedString(jwt.New(test.signingMethod))
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			token, err := jwt.Parse(signed, Keyfunc(StaticKeySet{mockkms.KeyARN(id): cfg}))
+			if err != nil {
+				t.Fatalf("Error verifying token: %v", err)
+			}
+
+			if kid := token.Header["kid"]; kid != mockkms.KeyARN(id) {
+				t.Errorf("kid = %v, want %s", kid, mockkms.KeyARN(id))
+			}
+
+			signed, err = cfg.SignedString(jwt.New(test.signingMethod))
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			token, _, err = jwt.NewParser().ParseUnverified(signed, jwt.MapClaims{})
+			if err != nil {
+				t.Fatalf("Error parsing token: %v", err)
+			}
+
+			if _, ok := token.Header["kid"]; ok {
+				t.Errorf("kid header set without WithKeyIDHeader")
+			}
+		})
+	}
+}
+
+func TestKeyIDHeaderKeepsExistingKID(t *testing.T) {
+	registered(t)
+
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	token := jwt.New(SigningMethodECDSA256)
+	token.Header["kid"] = "custom"
+
+	signed, err := NewKMSConfig(kms, id, false).WithKeyIDHeader().SignedString(token)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(signed, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("Error parsing token: %v", err)
+	}
+
+	if kid := parsed.Header["kid"]; kid != "custom" {
+		t.Errorf("kid = %v, want custom", kid)
+	}
+}
+
+func TestThumbprintKeyIDHeader(t *testing.T) {
+	registered(t)
+
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewKMSConfig(kms, id, false)
+
+	pub, err := getPublicKey(cfg)
+	if err != nil {
+		t.Fatalf("Error getting public key: %v", err)
+	}
+
+	jwk, err := NewJWK(pub)
+	if err != nil {
+		t.Fatalf("Error creating JWK: %v", err)
+	}
+
+	sum, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		t.Fatalf("Error computing thumbprint: %v", err)
+	}
+
+	signed, err := cfg.WithThumbprintKeyIDHeader().SignedString(jwt.New(SigningMethodECDSA256))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	token, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) {
+		return cfg, nil
+	})
+	if err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	if kid, want := token.Header["kid"], base64.RawURLEncoding.EncodeToString(sum); kid != want {
+		t.Errorf("kid = %v, want %s", kid, want)
+	}
+}
+
+func TestKIDFunc(t *testing.T) {
+	registered(t)
+
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewKMSConfig(kms, id, false).WithKIDFunc(func(keyARN string, pub crypto.PublicKey) (string, error) {
+		if _, ok := pub.(*rsa.PublicKey); !ok {
+			t.Errorf("public key is %T, want *rsa.PublicKey", pub)
+		}
+
+		return keyARN[strings.LastIndex(keyARN, "/")+1:] + "-v1", nil
+	})
+
+	signed, err := cfg.SignedString(jwt.New(SigningMethodRS256))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	token, err := jwt.Parse(signed, Keyfunc(StaticKeySet{id + "-v1": cfg}))
+	if err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	if kid := token.Header["kid"]; kid != id+"-v1" {
+		t.Errorf("kid = %v, want %s-v1", kid, id)
+	}
+
+	failing := cfg.WithKIDFunc(func(string, crypto.PublicKey) (string, error) {
+		return "", errors.New("no kid")
+	})
+
+	if _, err := failing.SignedString(jwt.New(SigningMethodRS256)); err == nil {
+		t.Errorf("expected error from KIDFunc")
+	}
+}
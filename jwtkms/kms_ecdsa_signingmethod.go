@@ -3,7 +3,8 @@ package jwtkms
 import (
 	"crypto"
 	"crypto/ecdsa"
-	"crypto/x509"
+	"crypto/elliptic"
+	"crypto/rand"
 	"encoding/asn1"
 	"errors"
 	"fmt"
@@ -12,16 +13,19 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/kms/types"
-	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 // ECDSASigningMethod is an ECDSA implementation of the SigningMethod interface that uses KMS to Sign/Verify JWTs.
+//
+// Methods without a golang-jwt counterpart, like ES256K, have no fallbackSigningMethod and only accept a *Config.
 type ECDSASigningMethod struct {
 	name                  string
 	algo                  string
 	hash                  crypto.Hash
 	keySize               int
 	curveBits             int
+	curve                 elliptic.Curve
 	fallbackSigningMethod *jwt.SigningMethodECDSA
 }
 
@@ -29,110 +33,158 @@ func (m *ECDSASigningMethod) Alg() string {
 	return m.name
 }
 
-func (m *ECDSASigningMethod) Verify(signingString, signature string, keyConfig interface{}) error {
+// Verify verifies sig with a *Config, or locally with an *ecdsa.PublicKey, like the golang-jwt method of the same alg.
+func (m *ECDSASigningMethod) Verify(signingString string, sig []byte, keyConfig interface{}) error {
 	cfg, ok := keyConfig.(*Config)
 	if !ok {
-		_, isBuiltInECDSA := keyConfig.(*ecdsa.PublicKey)
-		if isBuiltInECDSA {
-			return m.fallbackSigningMethod.Verify(signingString, signature, keyConfig)
+		pub, isBuiltInECDSA := keyConfig.(*ecdsa.PublicKey)
+		if !isBuiltInECDSA {
+			return jwt.ErrInvalidKeyType
 		}
 
-		return jwt.ErrInvalidKeyType
+		if m.fallbackSigningMethod != nil {
+			return m.fallbackSigningMethod.Verify(signingString, sig, keyConfig)
+		}
+
+		hashedSigningString, r, s, err := m.parseSignature(signingString, sig)
+		if err != nil {
+			return err
+		}
+
+		return verifyECDSAWithKey(pub, m.curve, hashedSigningString, r, s)
+	}
+
+	if done, err := cfg.prepareVerify(m, signingString, sig); done {
+		return err
 	}
 
-	sig, err := jwt.DecodeSegment(signature)
+	hashedSigningString, r, s, err := m.parseSignature(signingString, sig)
 	if err != nil {
-		return fmt.Errorf("decoding signature: %w", err)
+		return err
+	}
+
+	if cfg.verifyWithKMS {
+		return verifyECDSA(cfg, m.algo, hashedSigningString, r, s)
 	}
 
+	return localVerifyECDSA(cfg, m.curve, hashedSigningString, r, s)
+}
+
+// parseSignature hashes signingString and splits the JOSE signature sig into R and S.
+func (m *ECDSASigningMethod) parseSignature(signingString string, sig []byte) ([]byte, *big.Int, *big.Int, error) {
 	if !m.hash.Available() {
-		return jwt.ErrHashUnavailable
+		return nil, nil, nil, jwt.ErrHashUnavailable
+	}
+
+	// JOSE signatures are R||S, each padded to the size of the curve
+	if len(sig) != 2*m.keySize {
+		return nil, nil, nil, fmt.Errorf("%w: signature is %d bytes, %s needs %d", ErrInvalidSignature, len(sig), m.name,
+			2*m.keySize)
 	}
 
 	hasher := m.hash.New()
 	hasher.Write([]byte(signingString)) //nolint:errcheck
-	hashedSigningString := hasher.Sum(nil)
 
-	r := new(big.Int).SetBytes(sig[:m.keySize])
-	s := new(big.Int).SetBytes(sig[m.keySize:])
+	return hasher.Sum(nil), new(big.Int).SetBytes(sig[:m.keySize]), new(big.Int).SetBytes(sig[m.keySize:]), nil
+}
 
-	if cfg.verifyWithKMS {
-		return verifyECDSA(cfg, m.algo, hashedSigningString, r, s)
+// Sign signs signingString with a *Config, or locally with an *ecdsa.PrivateKey, like the golang-jwt method of the same
+// alg.
+func (m *ECDSASigningMethod) Sign(signingString string, keyConfig interface{}) ([]byte, error) {
+	keyConfig, err := resolveSigningKey(keyConfig, m.name)
+	if err != nil {
+		return nil, err
 	}
 
-	return localVerifyECDSA(cfg, hashedSigningString, r, s)
-}
-
-func (m *ECDSASigningMethod) Sign(signingString string, keyConfig interface{}) (string, error) {
 	cfg, ok := keyConfig.(*Config)
 	if !ok {
-		_, isBuiltInEcdsa := keyConfig.(*ecdsa.PublicKey)
-		if isBuiltInEcdsa {
+		key, isBuiltInECDSA := keyConfig.(*ecdsa.PrivateKey)
+		if !isBuiltInECDSA {
+			return nil, jwt.ErrInvalidKeyType
+		}
+
+		if m.fallbackSigningMethod != nil {
 			return m.fallbackSigningMethod.Sign(signingString, keyConfig)
 		}
 
-		return "", jwt.ErrInvalidKeyType
+		return m.signWithKey(signingString, key)
+	}
+
+	if err := cfg.validate(m); err != nil {
+		return nil, err
 	}
 
 	if !m.hash.Available() {
-		return "", jwt.ErrHashUnavailable
+		return nil, jwt.ErrHashUnavailable
 	}
 
-	hasher := m.hash.New()
-	hasher.Write([]byte(signingString)) //nolint:errcheck
-	hashedSigningString := hasher.Sum(nil)
+	message, messageType := cfg.signingMessage(m.hash, signingString)
 
-	signInput := &kms.SignInput{
-		KeyId:            aws.String(cfg.kmsKeyID),
-		Message:          hashedSigningString,
-		MessageType:      types.MessageTypeDigest,
-		SigningAlgorithm: types.SigningAlgorithmSpec(m.algo),
+	signature, err := (&KMSSigner{cfg: cfg}).sign(types.SigningAlgorithmSpec(m.algo), messageType, message)
+	if err != nil {
+		return nil, cfg.diagnoseSignError(m, err)
 	}
 
-	signOutput, err := cfg.kmsClient.Sign(cfg.ctx, signInput)
+	jose, err := DERToJOSE(signature, m.curveBits)
 	if err != nil {
-		return "", fmt.Errorf("signing digest: %w", err)
+		return nil, err
 	}
 
-	p := struct {
-		R *big.Int
-		S *big.Int
-	}{}
+	if cfg.lowS {
+		normalizeLowS(jose, m.curve.Params().N)
+	}
 
-	_, err = asn1.Unmarshal(signOutput.Signature, &p)
-	if err != nil {
-		return "", fmt.Errorf("unmarshalling signature: %w", err)
+	return jose, nil
+}
+
+// WithLowS makes the Config normalize the ECDSA signatures returned by KMS to their low-S form, with S at most half
+// the curve order, for secp256k1 verifiers rejecting high-S signatures as malleable. Both forms verify with Go.
+func WithLowS() Option {
+	return func(c *Config) {
+		c.lowS = true
 	}
+}
+
+// normalizeLowS replaces the S of the JOSE signature sig by n - S if it is larger than half the curve order n.
+func normalizeLowS(sig []byte, n *big.Int) {
+	keySize := len(sig) / 2
 
-	curveBits := m.curveBits
-	keyBytes := curveBits / 8
-	if curveBits%8 > 0 {
-		keyBytes++
+	s := new(big.Int).SetBytes(sig[keySize:])
+	if s.Cmp(new(big.Int).Rsh(n, 1)) <= 0 {
+		return
 	}
 
-	// We serialize the outputs (r and s) into big-endian byte arrays and pad
-	// them with zeros on the left to make sure the sizes work out. Both arrays
-	// must be keyBytes long, and the output must be 2*keyBytes long.
-	rBytes := p.R.Bytes()
-	rBytesPadded := make([]byte, keyBytes)
-	copy(rBytesPadded[keyBytes-len(rBytes):], rBytes)
+	s.Sub(n, s).FillBytes(sig[keySize:])
+}
+
+// signWithKey signs signingString locally with key, for methods without a golang-jwt counterpart.
+func (m *ECDSASigningMethod) signWithKey(signingString string, key *ecdsa.PrivateKey) ([]byte, error) {
+	if bits := key.Curve.Params().BitSize; bits != m.curveBits {
+		return nil, fmt.Errorf("%w: key is on a %d bit curve, %s needs %d bits", jwt.ErrInvalidKey, bits, m.name,
+			m.curveBits)
+	}
+
+	if !m.hash.Available() {
+		return nil, jwt.ErrHashUnavailable
+	}
+
+	hasher := m.hash.New()
+	hasher.Write([]byte(signingString)) //nolint:errcheck
 
-	sBytes := p.S.Bytes()
-	sBytesPadded := make([]byte, keyBytes)
-	copy(sBytesPadded[keyBytes-len(sBytes):], sBytes)
+	r, s, err := ecdsa.Sign(rand.Reader, key, hasher.Sum(nil))
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
 
-	out := append(rBytesPadded, sBytesPadded...)
+	out := make([]byte, 2*m.keySize)
+	r.FillBytes(out[:m.keySize])
+	s.FillBytes(out[m.keySize:])
 
-	return jwt.EncodeSegment(out), nil
+	return out, nil
 }
 
 func verifyECDSA(cfg *Config, algo string, hashedSigningString []byte, r *big.Int, s *big.Int) error {
-	p := struct {
-		R *big.Int
-		S *big.Int
-	}{r, s}
-
-	derSig, err := asn1.Marshal(p)
+	derSig, err := asn1.Marshal(ecdsaSignature{r, s})
 	if err != nil {
 		return fmt.Errorf("marshalling signature: %w", err)
 	}
@@ -145,36 +197,22 @@ func verifyECDSA(cfg *Config, algo string, hashedSigningString []byte, r *big.In
 		SigningAlgorithm: types.SigningAlgorithmSpec(algo),
 	}
 
-	verifyOutput, err := cfg.kmsClient.Verify(cfg.ctx, verifyInput)
+	verifyOutput, err := cfg.kmsVerify(verifyInput)
 	if err != nil {
 		return fmt.Errorf("verifying signature remotely: %w", err)
 	}
 
 	if !verifyOutput.SignatureValid {
-		return jwt.ErrSignatureInvalid
+		return ErrInvalidSignature
 	}
 
 	return nil
 }
 
-func localVerifyECDSA(cfg *Config, hashedSigningString []byte, r *big.Int, s *big.Int) error {
-	var ecdsaPublicKey *ecdsa.PublicKey
-
-	cachedKey := pubkeyCache.Get(cfg.kmsKeyID)
-	if cachedKey == nil {
-		getPubKeyOutput, err := cfg.kmsClient.GetPublicKey(cfg.ctx, &kms.GetPublicKeyInput{
-			KeyId: aws.String(cfg.kmsKeyID),
-		})
-		if err != nil {
-			return fmt.Errorf("getting public key: %w", err)
-		}
-
-		cachedKey, err = x509.ParsePKIXPublicKey(getPubKeyOutput.PublicKey)
-		if err != nil {
-			return fmt.Errorf("parsing public key: %w", err)
-		}
-
-		pubkeyCache.Add(cfg.kmsKeyID, cachedKey)
+func localVerifyECDSA(cfg *Config, curve elliptic.Curve, hashedSigningString []byte, r *big.Int, s *big.Int) error {
+	cachedKey, err := getPublicKey(cfg)
+	if err != nil {
+		return err
 	}
 
 	ecdsaPublicKey, ok := cachedKey.(*ecdsa.PublicKey)
@@ -182,9 +220,17 @@ func localVerifyECDSA(cfg *Config, hashedSigningString []byte, r *big.Int, s *bi
 		return errors.New("invalid key type for key")
 	}
 
-	valid := ecdsa.Verify(ecdsaPublicKey, hashedSigningString, r, s)
-	if !valid {
-		return jwt.ErrSignatureInvalid
+	return verifyECDSAWithKey(ecdsaPublicKey, curve, hashedSigningString, r, s)
+}
+
+func verifyECDSAWithKey(pub *ecdsa.PublicKey, curve elliptic.Curve, hashedSigningString []byte, r *big.Int, s *big.Int) error {
+	if name := pub.Curve.Params().Name; name != curve.Params().Name {
+		return fmt.Errorf("%w: key is on curve %s, the signing method needs %s", ErrIncompatibleKeySpec, name,
+			curve.Params().Name)
+	}
+
+	if !ecdsa.Verify(pub, hashedSigningString, r, s) {
+		return ErrInvalidSignature
 	}
 
 	return nil
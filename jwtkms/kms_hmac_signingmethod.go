@@ -0,0 +1,111 @@
+package jwtkms
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// maxMACMessageSize is the largest message KMS accepts for GenerateMac/VerifyMac.
+const maxMACMessageSize = 4096
+
+// HMACSigningMethod is an HMAC implementation of the SigningMethod interface that uses KMS GenerateMac/VerifyMac to
+// Sign/Verify JWTs, so the secret never leaves KMS.
+type HMACSigningMethod struct {
+	name                  string
+	algo                  string
+	fallbackSigningMethod *jwt.SigningMethodHMAC
+}
+
+func (m *HMACSigningMethod) Alg() string {
+	return m.name
+}
+
+func (m *HMACSigningMethod) Verify(signingString string, sig []byte, keyConfig interface{}) error {
+	cfg, ok := keyConfig.(*Config)
+	if !ok {
+		_, isBuiltInHMAC := keyConfig.([]byte)
+		if isBuiltInHMAC {
+			return m.fallbackSigningMethod.Verify(signingString, sig, keyConfig)
+		}
+
+		return jwt.ErrInvalidKeyType
+	}
+
+	if done, err := cfg.prepareVerify(m, signingString, sig); done {
+		return err
+	}
+
+	macClient, err := cfg.macClient()
+	if err != nil {
+		return err
+	}
+
+	if len(signingString) > maxMACMessageSize {
+		return fmt.Errorf("signing string is %d bytes, KMS accepts at most %d", len(signingString), maxMACMessageSize)
+	}
+
+	verifyMacInput := &kms.VerifyMacInput{
+		KeyId:        aws.String(cfg.kmsKeyID),
+		Mac:          sig,
+		MacAlgorithm: types.MacAlgorithmSpec(m.algo),
+		Message:      []byte(signingString),
+	}
+
+	verifyMacOutput, err := cfg.kmsVerifyMac(macClient, verifyMacInput)
+	if err != nil {
+		return fmt.Errorf("verifying mac remotely: %w", err)
+	}
+
+	if !verifyMacOutput.MacValid {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func (m *HMACSigningMethod) Sign(signingString string, keyConfig interface{}) ([]byte, error) {
+	keyConfig, err := resolveSigningKey(keyConfig, m.name)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, ok := keyConfig.(*Config)
+	if !ok {
+		_, isBuiltInHMAC := keyConfig.([]byte)
+		if isBuiltInHMAC {
+			return m.fallbackSigningMethod.Sign(signingString, keyConfig)
+		}
+
+		return nil, jwt.ErrInvalidKeyType
+	}
+
+	if err := cfg.validate(m); err != nil {
+		return nil, err
+	}
+
+	macClient, err := cfg.macClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(signingString) > maxMACMessageSize {
+		return nil, fmt.Errorf("signing string is %d bytes, KMS accepts at most %d", len(signingString), maxMACMessageSize)
+	}
+
+	generateMacInput := &kms.GenerateMacInput{
+		KeyId:        aws.String(cfg.kmsKeyID),
+		MacAlgorithm: types.MacAlgorithmSpec(m.algo),
+		Message:      []byte(signingString),
+	}
+
+	generateMacOutput, err := cfg.kmsGenerateMac(macClient, generateMacInput)
+	if err != nil {
+		return nil, fmt.Errorf("generating mac: %w", cfg.diagnoseSignError(m, err))
+	}
+
+	return generateMacOutput.Mac, nil
+}
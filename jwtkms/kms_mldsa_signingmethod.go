@@ -0,0 +1,232 @@
+//go:build go1.27
+
+package jwtkms
+
+import (
+	"crypto"
+	"crypto/mldsa"
+	"crypto/sha3"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	SigningMethodMLDSA44 *MLDSASigningMethod
+	SigningMethodMLDSA65 *MLDSASigningMethod
+	SigningMethodMLDSA87 *MLDSASigningMethod
+)
+
+// MLDSASigningMethod is an ML-DSA (FIPS 204) implementation of the SigningMethod interface that uses KMS to
+// Sign/Verify JWTs.
+//
+// ML-DSA signs the message itself rather than a digest, so signing strings are sent to KMS as RAW messages, or as
+// an external μ message representative when they are too large for RAW.
+type MLDSASigningMethod struct {
+	name   string
+	params mldsa.Parameters
+}
+
+func init() {
+	newMLDSASigningMethods()
+}
+
+func newMLDSASigningMethods() {
+	SigningMethodMLDSA44 = &MLDSASigningMethod{
+		name:   "ML-DSA-44",
+		params: mldsa.MLDSA44(),
+	}
+
+	addSigningMethod(SigningMethodMLDSA44)
+
+	SigningMethodMLDSA65 = &MLDSASigningMethod{
+		name:   "ML-DSA-65",
+		params: mldsa.MLDSA65(),
+	}
+
+	addSigningMethod(SigningMethodMLDSA65)
+
+	SigningMethodMLDSA87 = &MLDSASigningMethod{
+		name:   "ML-DSA-87",
+		params: mldsa.MLDSA87(),
+	}
+
+	addSigningMethod(SigningMethodMLDSA87)
+}
+
+func (m *MLDSASigningMethod) Alg() string {
+	return m.name
+}
+
+func (m *MLDSASigningMethod) kmsSigningAlgorithm() types.SigningAlgorithmSpec {
+	return types.SigningAlgorithmSpecMlDsaShake256
+}
+
+func (m *MLDSASigningMethod) Verify(signingString string, sig []byte, keyConfig interface{}) error {
+	cfg, ok := keyConfig.(*Config)
+	if !ok {
+		pub, isBuiltInMLDSA := keyConfig.(*mldsa.PublicKey)
+		if isBuiltInMLDSA {
+			return m.verify(pub, signingString, sig)
+		}
+
+		return jwt.ErrInvalidKeyType
+	}
+
+	if done, err := cfg.prepareVerify(m, signingString, sig); done {
+		return err
+	}
+
+	if cfg.verifyWithKMS {
+		return m.verifyWithKMS(cfg, signingString, sig)
+	}
+
+	cachedKey, err := getPublicKey(cfg)
+	if err != nil {
+		return err
+	}
+
+	pub, ok := cachedKey.(*mldsa.PublicKey)
+	if !ok {
+		return errors.New("invalid key type for key")
+	}
+
+	return m.verify(pub, signingString, sig)
+}
+
+func (m *MLDSASigningMethod) Sign(signingString string, keyConfig interface{}) ([]byte, error) {
+	keyConfig, err := resolveSigningKey(keyConfig, m.name)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, ok := keyConfig.(*Config)
+	if !ok {
+		return nil, jwt.ErrInvalidKeyType
+	}
+
+	if err := cfg.validate(m); err != nil {
+		return nil, err
+	}
+
+	message, messageType, err := m.message(cfg, signingString)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := (&KMSSigner{cfg: cfg}).sign(types.SigningAlgorithmSpecMlDsaShake256, messageType, message)
+	if err != nil {
+		return nil, cfg.diagnoseSignError(m, err)
+	}
+
+	return signature, nil
+}
+
+func (m *MLDSASigningMethod) verify(pub *mldsa.PublicKey, signingString string, sig []byte) error {
+	if pub.Parameters() != m.params {
+		return fmt.Errorf("%w: key is %s, the signing method needs %s", ErrIncompatibleKeySpec, pub.Parameters(),
+			m.params)
+	}
+
+	if err := mldsa.Verify(pub, []byte(signingString), sig, nil); err != nil {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func (m *MLDSASigningMethod) verifyWithKMS(cfg *Config, signingString string, sig []byte) error {
+	message, messageType, err := m.message(cfg, signingString)
+	if err != nil {
+		return err
+	}
+
+	verifyInput := &kms.VerifyInput{
+		KeyId:            aws.String(cfg.kmsKeyID),
+		Message:          message,
+		MessageType:      messageType,
+		Signature:        sig,
+		SigningAlgorithm: types.SigningAlgorithmSpecMlDsaShake256,
+	}
+
+	verifyOutput, err := cfg.kmsVerify(verifyInput)
+	if err != nil {
+		return fmt.Errorf("verifying signature remotely: %w", err)
+	}
+
+	if !verifyOutput.SignatureValid {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// message returns the signing string as KMS expects it: RAW when it fits, the external μ otherwise.
+func (m *MLDSASigningMethod) message(cfg *Config, signingString string) ([]byte, types.MessageType, error) {
+	if len(signingString) <= maxRawMessageSize {
+		return []byte(signingString), types.MessageTypeRaw, nil
+	}
+
+	cachedKey, err := getPublicKey(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pub, ok := cachedKey.(*mldsa.PublicKey)
+	if !ok {
+		return nil, "", errors.New("invalid key type for key")
+	}
+
+	return externalMu(pub, []byte(signingString)), types.MessageTypeExternalMu, nil
+}
+
+// externalMu computes the μ message representative of FIPS 204 Algorithm 7 for an empty context string.
+func externalMu(pub *mldsa.PublicKey, message []byte) []byte {
+	tr := sha3.SumSHAKE256(pub.Bytes(), 64)
+
+	h := sha3.NewSHAKE256()
+	h.Write(tr)           //nolint:errcheck
+	h.Write([]byte{0, 0}) //nolint:errcheck
+	h.Write(message)      //nolint:errcheck
+
+	mu := make([]byte, 64)
+	h.Read(mu) //nolint:errcheck
+
+	return mu
+}
+
+// mldsaSignerAlgorithm reports the KMS signing algorithm and message type for ML-DSA keys used through KMSSigner.
+func mldsaSignerAlgorithm(pub crypto.PublicKey, opts crypto.SignerOpts) (types.SigningAlgorithmSpec, types.MessageType, bool) {
+	if _, ok := pub.(*mldsa.PublicKey); !ok {
+		return "", "", false
+	}
+
+	if opts != nil && opts.HashFunc() == crypto.MLDSAMu {
+		return types.SigningAlgorithmSpecMlDsaShake256, types.MessageTypeExternalMu, true
+	}
+
+	return types.SigningAlgorithmSpecMlDsaShake256, types.MessageTypeRaw, true
+}
+
+// mldsaSigningMethod returns the ML-DSA signing method of keys of keySpec.
+func mldsaSigningMethod(keySpec types.KeySpec) (jwt.SigningMethod, bool) {
+	switch keySpec {
+	case types.KeySpecMlDsa44:
+		return SigningMethodMLDSA44, true
+	case types.KeySpecMlDsa65:
+		return SigningMethodMLDSA65, true
+	case types.KeySpecMlDsa87:
+		return SigningMethodMLDSA87, true
+	}
+
+	return nil, false
+}
+
+func (m *MLDSASigningMethod) supportsKeySpec(keySpec types.KeySpec) bool {
+	method, ok := mldsaSigningMethod(keySpec)
+	return ok && method == m
+}
@@ -0,0 +1,20 @@
+//go:build !go1.27
+
+package jwtkms
+
+import (
+	"crypto"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mldsaSignerAlgorithm always reports false, ML-DSA keys require go1.27.
+func mldsaSignerAlgorithm(crypto.PublicKey, crypto.SignerOpts) (types.SigningAlgorithmSpec, types.MessageType, bool) {
+	return "", "", false
+}
+
+// mldsaSigningMethod always reports false, ML-DSA keys require go1.27.
+func mldsaSigningMethod(types.KeySpec) (jwt.SigningMethod, bool) {
+	return nil, false
+}
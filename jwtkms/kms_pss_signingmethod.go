@@ -3,13 +3,11 @@ package jwtkms
 import (
 	"crypto"
 	"crypto/rsa"
-	"crypto/x509"
 	"errors"
 	"fmt"
+	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/kms"
-	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 // RSASigningMethod is an RSA implementation of the SigningMethod interface that uses KMS to Sign/Verify JWTs.
@@ -19,20 +17,19 @@ type PSSSigningMethod struct {
 	fallbackSigningMethod *jwt.SigningMethodRSAPSS
 }
 
-func (m *PSSSigningMethod) Verify(signingString, signature string, keyConfig interface{}) error {
+func (m *PSSSigningMethod) Verify(signingString string, sig []byte, keyConfig interface{}) error {
 	cfg, ok := keyConfig.(*Config)
 	if !ok {
 		_, isBuiltInRsa := keyConfig.(*rsa.PublicKey)
 		if isBuiltInRsa {
-			return m.fallbackSigningMethod.Verify(signingString, signature, keyConfig)
+			return m.fallbackSigningMethod.Verify(signingString, sig, keyConfig)
 		}
 
 		return jwt.ErrInvalidKeyType
 	}
 
-	sig, err := jwt.DecodeSegment(signature)
-	if err != nil {
-		return fmt.Errorf("decoding signature: %w", err)
+	if done, err := cfg.prepareVerify(m, signingString, sig); done {
+		return err
 	}
 
 	if !m.hash.Available() {
@@ -50,24 +47,38 @@ func (m *PSSSigningMethod) Verify(signingString, signature string, keyConfig int
 	return localVerifyPSS(cfg, m.hash, hashedSigningString, sig)
 }
 
-func localVerifyPSS(cfg *Config, hash crypto.Hash, hashedSigningString []byte, sig []byte) error {
-	var rsaPublicKey *rsa.PublicKey
-
-	cachedKey := pubkeyCache.Get(cfg.kmsKeyID)
-	if cachedKey == nil {
-		getPubKeyOutput, err := cfg.kmsClient.GetPublicKey(cfg.ctx, &kms.GetPublicKeyInput{
-			KeyId: aws.String(cfg.kmsKeyID),
-		})
-		if err != nil {
-			return fmt.Errorf("getting public key: %w", err)
-		}
+// Sign signs signingString with a *Config, or locally with an *rsa.PrivateKey, like the golang-jwt method of the same
+// alg.
+func (m *PSSSigningMethod) Sign(signingString string, keyConfig interface{}) ([]byte, error) {
+	if _, isBuiltInRsa := keyConfig.(*rsa.PrivateKey); isBuiltInRsa {
+		return m.fallbackSigningMethod.Sign(signingString, keyConfig)
+	}
 
-		cachedKey, err = x509.ParsePKIXPublicKey(getPubKeyOutput.PublicKey)
-		if err != nil {
-			return fmt.Errorf("parsing public key: %w", err)
-		}
+	return m.RSASigningMethod.Sign(signingString, keyConfig)
+}
+
+// WithPSSSaltLength makes the Config require the salt length of PSS signatures verified locally:
+// rsa.PSSSaltLengthEqualsHash, the salt length of KMS signatures, a number of bytes, or rsa.PSSSaltLengthAuto, the
+// default, accepting any salt length. A signature with another salt length is reported as such.
+func WithPSSSaltLength(saltLength int) Option {
+	return func(c *Config) {
+		c.pssSaltLength = saltLength
+	}
+}
 
-		pubkeyCache.Add(cfg.kmsKeyID, cachedKey)
+// WithPSSInterop makes the Config accept PSS signatures verified locally with a salt as long as the digest, like the
+// ones of KMS, or of the maximum length, like the ones of rsa.SignPSS by default and other stacks, and reject any other
+// salt length. It takes precedence over WithPSSSaltLength.
+func WithPSSInterop() Option {
+	return func(c *Config) {
+		c.pssInterop = true
+	}
+}
+
+func localVerifyPSS(cfg *Config, hash crypto.Hash, hashedSigningString []byte, sig []byte) error {
+	cachedKey, err := getPublicKey(cfg)
+	if err != nil {
+		return err
 	}
 
 	rsaPublicKey, ok := cachedKey.(*rsa.PublicKey)
@@ -75,9 +86,45 @@ func localVerifyPSS(cfg *Config, hash crypto.Hash, hashedSigningString []byte, s
 		return errors.New("invalid key type for key")
 	}
 
-	if err := rsa.VerifyPSS(rsaPublicKey, hash, hashedSigningString, sig, &rsa.PSSOptions{}); err != nil {
-		return fmt.Errorf("verifying signature locally: %w", err)
+	saltLengths := []int{cfg.pssSaltLength}
+	if cfg.pssInterop {
+		saltLengths = []int{rsa.PSSSaltLengthEqualsHash, pssMaxSaltLength(rsaPublicKey, hash)}
+	}
+
+	for _, saltLength := range saltLengths {
+		err = rsa.VerifyPSS(rsaPublicKey, hash, hashedSigningString, sig, &rsa.PSSOptions{SaltLength: saltLength})
+		if err == nil {
+			return nil
+		}
+	}
+
+	if saltLengths[0] != rsa.PSSSaltLengthAuto &&
+		rsa.VerifyPSS(rsaPublicKey, hash, hashedSigningString, sig, &rsa.PSSOptions{}) == nil {
+		names := make([]string, len(saltLengths))
+		for i, saltLength := range saltLengths {
+			names[i] = pssSaltLengthName(saltLength, rsaPublicKey, hash)
+		}
+
+		return fmt.Errorf("verifying signature locally: %w: salt length is not %s", ErrInvalidSignature,
+			strings.Join(names, " or "))
+	}
+
+	return fmt.Errorf("verifying signature locally: %w: %w", ErrInvalidSignature, err)
+}
+
+// pssMaxSaltLength returns the maximum salt length of PSS signatures of pub, RFC 8017 section 9.1.1.
+func pssMaxSaltLength(pub *rsa.PublicKey, hash crypto.Hash) int {
+	return (pub.N.BitLen()-1+7)/8 - hash.Size() - 2
+}
+
+// pssSaltLengthName describes a salt length of rsa.PSSOptions for error messages.
+func pssSaltLengthName(saltLength int, pub *rsa.PublicKey, hash crypto.Hash) string {
+	switch saltLength {
+	case rsa.PSSSaltLengthEqualsHash:
+		return fmt.Sprintf("the hash length of %d bytes", hash.Size())
+	case pssMaxSaltLength(pub, hash):
+		return fmt.Sprintf("the maximum length of %d bytes", saltLength)
 	}
 
-	return nil
+	return fmt.Sprintf("%d bytes", saltLength)
 }
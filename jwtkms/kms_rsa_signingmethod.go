@@ -3,14 +3,13 @@ package jwtkms
 import (
 	"crypto"
 	"crypto/rsa"
-	"crypto/x509"
 	"errors"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/kms/types"
-	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 // RSASigningMethod is an RSA implementation of the SigningMethod interface that uses KMS to Sign/Verify JWTs.
@@ -25,20 +24,19 @@ func (m *RSASigningMethod) Alg() string {
 	return m.name
 }
 
-func (m *RSASigningMethod) Verify(signingString, signature string, keyConfig interface{}) error {
+func (m *RSASigningMethod) Verify(signingString string, sig []byte, keyConfig interface{}) error {
 	cfg, ok := keyConfig.(*Config)
 	if !ok {
 		_, isBuiltInRsa := keyConfig.(*rsa.PublicKey)
 		if isBuiltInRsa {
-			return m.fallbackSigningMethod.Verify(signingString, signature, keyConfig)
+			return m.fallbackSigningMethod.Verify(signingString, sig, keyConfig)
 		}
 
 		return jwt.ErrInvalidKeyType
 	}
 
-	sig, err := jwt.DecodeSegment(signature)
-	if err != nil {
-		return fmt.Errorf("decoding signature: %w", err)
+	if done, err := cfg.prepareVerify(m, signingString, sig); done {
+		return err
 	}
 
 	if !m.hash.Available() {
@@ -56,38 +54,38 @@ func (m *RSASigningMethod) Verify(signingString, signature string, keyConfig int
 	return localVerifyRSA(cfg, m.hash, hashedSigningString, sig)
 }
 
-func (m *RSASigningMethod) Sign(signingString string, keyConfig interface{}) (string, error) {
+func (m *RSASigningMethod) Sign(signingString string, keyConfig interface{}) ([]byte, error) {
+	keyConfig, err := resolveSigningKey(keyConfig, m.name)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg, ok := keyConfig.(*Config)
 	if !ok {
-		_, isBuiltInRsa := keyConfig.(*rsa.PublicKey)
-		if isBuiltInRsa {
+		_, isBuiltInRsa := keyConfig.(*rsa.PrivateKey)
+		if isBuiltInRsa && m.fallbackSigningMethod != nil {
 			return m.fallbackSigningMethod.Sign(signingString, keyConfig)
 		}
 
-		return "", jwt.ErrInvalidKeyType
+		return nil, jwt.ErrInvalidKeyType
 	}
 
-	if !m.hash.Available() {
-		return "", jwt.ErrHashUnavailable
+	if err := cfg.validate(m); err != nil {
+		return nil, err
 	}
 
-	hasher := m.hash.New()
-	hasher.Write([]byte(signingString)) //nolint:errcheck
-	hashedSigningString := hasher.Sum(nil)
-
-	signInput := &kms.SignInput{
-		KeyId:            aws.String(cfg.kmsKeyID),
-		Message:          hashedSigningString,
-		MessageType:      types.MessageTypeDigest,
-		SigningAlgorithm: types.SigningAlgorithmSpec(m.algo),
+	if !m.hash.Available() {
+		return nil, jwt.ErrHashUnavailable
 	}
 
-	signOutput, err := cfg.kmsClient.Sign(cfg.ctx, signInput)
+	message, messageType := cfg.signingMessage(m.hash, signingString)
+
+	signature, err := (&KMSSigner{cfg: cfg}).sign(types.SigningAlgorithmSpec(m.algo), messageType, message)
 	if err != nil {
-		return "", fmt.Errorf("signing digest: %w", err)
+		return nil, cfg.diagnoseSignError(m, err)
 	}
 
-	return jwt.EncodeSegment(signOutput.Signature), nil
+	return signature, nil
 }
 
 func verifyRSAOrPSS(cfg *Config, algo string, hashedSigningString []byte, sig []byte) error {
@@ -99,36 +97,22 @@ func verifyRSAOrPSS(cfg *Config, algo string, hashedSigningString []byte, sig []
 		SigningAlgorithm: types.SigningAlgorithmSpec(algo),
 	}
 
-	verifyOutput, err := cfg.kmsClient.Verify(cfg.ctx, verifyInput)
+	verifyOutput, err := cfg.kmsVerify(verifyInput)
 	if err != nil {
 		return fmt.Errorf("verifying signature remotely: %w", err)
 	}
 
 	if !verifyOutput.SignatureValid {
-		return jwt.ErrSignatureInvalid
+		return ErrInvalidSignature
 	}
 
 	return nil
 }
 
 func localVerifyRSA(cfg *Config, hash crypto.Hash, hashedSigningString []byte, sig []byte) error {
-	var rsaPublicKey *rsa.PublicKey
-
-	cachedKey := pubkeyCache.Get(cfg.kmsKeyID)
-	if cachedKey == nil {
-		getPubKeyOutput, err := cfg.kmsClient.GetPublicKey(cfg.ctx, &kms.GetPublicKeyInput{
-			KeyId: aws.String(cfg.kmsKeyID),
-		})
-		if err != nil {
-			return fmt.Errorf("getting public key: %w", err)
-		}
-
-		cachedKey, err = x509.ParsePKIXPublicKey(getPubKeyOutput.PublicKey)
-		if err != nil {
-			return fmt.Errorf("parsing public key: %w", err)
-		}
-
-		pubkeyCache.Add(cfg.kmsKeyID, cachedKey)
+	cachedKey, err := getPublicKey(cfg)
+	if err != nil {
+		return err
 	}
 
 	rsaPublicKey, ok := cachedKey.(*rsa.PublicKey)
@@ -137,7 +121,7 @@ func localVerifyRSA(cfg *Config, hash crypto.Hash, hashedSigningString []byte, s
 	}
 
 	if err := rsa.VerifyPKCS1v15(rsaPublicKey, hash, hashedSigningString, sig); err != nil {
-		return fmt.Errorf("verifying signature locally: %w", err)
+		return fmt.Errorf("verifying signature locally: %w: %w", ErrInvalidSignature, err)
 	}
 
 	return nil
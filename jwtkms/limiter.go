@@ -0,0 +1,141 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrSignQueueFull is returned without calling KMS when a SignLimiter already has MaxQueue callers waiting.
+var ErrSignQueueFull = errors.New("kms sign queue is full")
+
+// SignLimiterSettings configures a SignLimiter. Zero values disable the respective limit.
+type SignLimiterSettings struct {
+	// MaxConcurrent caps the number of KMS signing calls in flight.
+	MaxConcurrent int
+
+	// RatePerSecond caps the rate of KMS signing calls, allowing bursts of up to Burst calls.
+	RatePerSecond float64
+	Burst         int
+
+	// MaxQueue caps the number of callers waiting for the limiter, further callers fail with ErrSignQueueFull.
+	MaxQueue int
+}
+
+// SignLimiter caps concurrent and per-second KMS Sign and GenerateMac calls to stay under the KMS request quotas.
+// Callers over the limits wait, bounded by their context, in a queue of at most MaxQueue callers.
+//
+// A SignLimiter is safe for concurrent use; share one between the Configs drawing from the same quota.
+type SignLimiter struct {
+	sem      chan struct{}
+	rate     float64
+	burst    float64
+	maxQueue int
+	now      func() time.Time
+
+	mu     sync.Mutex
+	queued int
+	tokens float64
+	last   time.Time
+}
+
+// NewSignLimiter creates a SignLimiter.
+func NewSignLimiter(settings SignLimiterSettings) *SignLimiter {
+	l := &SignLimiter{
+		rate:     settings.RatePerSecond,
+		burst:    float64(settings.Burst),
+		maxQueue: settings.MaxQueue,
+		now:      time.Now,
+	}
+
+	if settings.MaxConcurrent > 0 {
+		l.sem = make(chan struct{}, settings.MaxConcurrent)
+	}
+
+	if l.burst < 1 {
+		l.burst = 1
+	}
+
+	l.tokens = l.burst
+
+	return l
+}
+
+// WithSignLimiter limits the KMS signing calls of the Config with limiter.
+func WithSignLimiter(limiter *SignLimiter) Option {
+	return func(c *Config) {
+		c.signLimiter = limiter
+	}
+}
+
+// acquire waits until a call is allowed by the limits, returning the function releasing its concurrency slot.
+func (l *SignLimiter) acquire(ctx context.Context) (func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	if l.maxQueue > 0 && l.queued >= l.maxQueue {
+		l.mu.Unlock()
+		return nil, ErrSignQueueFull
+	}
+	l.queued++
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.queued--
+		l.mu.Unlock()
+	}()
+
+	if err := l.waitRate(ctx); err != nil {
+		return nil, err
+	}
+
+	if l.sem == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.sem <- struct{}{}:
+		return func() { <-l.sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// waitRate reserves a token of the rate limit and waits until it is available.
+func (l *SignLimiter) waitRate(ctx context.Context) error {
+	if l.rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,160 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// blockingKMS blocks Sign calls until unblock is closed and records the maximum number of calls in flight.
+type blockingKMS struct {
+	*mockkms.MockKMS
+	unblock     chan struct{}
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (c *blockingKMS) Sign(ctx context.Context, in *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error) {
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+
+	for {
+		m := c.maxInFlight.Load()
+		if n <= m || c.maxInFlight.CompareAndSwap(m, n) {
+			break
+		}
+	}
+
+	<-c.unblock
+
+	return c.MockKMS.Sign(ctx, in, optFns...)
+}
+
+func newBlockingKMS(t *testing.T) (*blockingKMS, string) {
+	t.Helper()
+
+	client := &blockingKMS{MockKMS: mockkms.NewMockKMS(), unblock: make(chan struct{})}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	return client, id
+}
+
+func TestSignLimiterConcurrency(t *testing.T) {
+	client, id := newBlockingKMS(t)
+	cfg := NewConfig(client, id, WithSignLimiter(NewSignLimiter(SignLimiterSettings{MaxConcurrent: 2})))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); err != nil {
+				t.Errorf("Error signing token: %v", err)
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(client.unblock)
+	wg.Wait()
+
+	if n := client.maxInFlight.Load(); n != 2 {
+		t.Errorf("max concurrent Sign calls = %d, want 2", n)
+	}
+}
+
+func TestSignLimiterQueue(t *testing.T) {
+	client, id := newBlockingKMS(t)
+	limiter := NewSignLimiter(SignLimiterSettings{MaxConcurrent: 1, MaxQueue: 1})
+	cfg := NewConfig(client, id, WithSignLimiter(limiter))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); err != nil {
+				t.Errorf("Error signing token: %v", err)
+			}
+		}()
+	}
+
+	// wait for one call in flight and one queued
+	for {
+		limiter.mu.Lock()
+		queued := limiter.queued
+		limiter.mu.Unlock()
+
+		if queued == 1 && client.inFlight.Load() == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); !errors.Is(err, ErrSignQueueFull) {
+		t.Errorf("error = %v, want %v", err, ErrSignQueueFull)
+	}
+
+	close(client.unblock)
+	wg.Wait()
+}
+
+func TestSignLimiterRate(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(kms, id, WithSignLimiter(NewSignLimiter(SignLimiterSettings{RatePerSecond: 20, Burst: 1})))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); err != nil {
+			t.Fatalf("Error signing token: %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 calls at 20/s took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := cfg.SignContext(ctx, jwt.New(SigningMethodECDSA256)); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestSignLimiterRetries(t *testing.T) {
+	client := &throttlingKMS{MockKMS: mockkms.NewMockKMS(), throttled: 2}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	limiter := NewSignLimiter(SignLimiterSettings{RatePerSecond: 1, Burst: 5})
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	cfg := NewConfig(client, id, WithSignLimiter(limiter), WithRetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	// each of the 3 attempts takes a token of the rate limit
+	if limiter.tokens != 2 {
+		t.Errorf("tokens = %v, want 2", limiter.tokens)
+	}
+}
@@ -0,0 +1,81 @@
+package jwtkms
+
+import (
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// redacted replaces the account id and resource name of redacted key ARNs.
+const redacted = "REDACTED"
+
+// kmsARNPattern matches the KMS ARNs in error messages.
+var kmsARNPattern = regexp.MustCompile(`arn:aws[a-z-]*:kms:[^\s"']+`)
+
+// WithLogger makes the Config log to logger: public key cache misses at debug level, retried throttled calls,
+// fallback verification and disabled or missing keys at warn level.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Config) {
+		c.logger = logger
+	}
+}
+
+// WithKeyARNRedaction makes the Config redact the account id and key id or alias name of key ARNs it logs, e.g.
+// arn:aws:kms:eu-west-1:REDACTED:key/REDACTED. Key ids that are not ARNs are logged as is.
+func WithKeyARNRedaction() Option {
+	return func(c *Config) {
+		c.redactKeyARNs = true
+	}
+}
+
+// log logs msg with the key id of the Config and args, if the Config has a logger.
+func (c *Config) log(level slog.Level, msg string, args ...any) {
+	if c.logger == nil || !c.logger.Enabled(c.ctx, level) {
+		return
+	}
+
+	keyID := c.kmsKeyID
+	if c.redactKeyARNs {
+		keyID = redactARN(keyID)
+	}
+
+	c.logger.Log(c.ctx, level, msg, append([]any{slog.String("key_id", keyID)}, args...)...)
+}
+
+// logKeyState logs errors caused by the state of the key, which need the attention of an operator rather than a
+// retry.
+func (c *Config) logKeyState(operation Operation, err error) {
+	if !errors.Is(err, ErrKeyDisabled) && !errors.Is(err, ErrKeyNotFound) {
+		return
+	}
+
+	msg := err.Error()
+	if c.redactKeyARNs {
+		msg = kmsARNPattern.ReplaceAllStringFunc(msg, redactARN)
+	}
+
+	c.log(slog.LevelWarn, "KMS key unusable",
+		slog.String("operation", string(operation)),
+		slog.String("error_code", errorCode(err)),
+		slog.String("error", msg),
+	)
+}
+
+func redactARN(s string) string {
+	a, err := arn.Parse(s)
+	if err != nil {
+		return s
+	}
+
+	a.AccountID = redacted
+	if resourceType, _, ok := strings.Cut(a.Resource, "/"); ok {
+		a.Resource = resourceType + "/" + redacted
+	} else {
+		a.Resource = redacted
+	}
+
+	return a.String()
+}
@@ -0,0 +1,80 @@
+package jwtkms
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestLogger(t *testing.T) {
+	registered(t)
+
+	client := &throttlingKMS{MockKMS: mockkms.NewMockKMS(), throttled: 1}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cfg := NewConfig(client, mockkms.KeyARN(id),
+		WithLogger(logger),
+		WithKeyARNRedaction(),
+		WithRetry(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+	)
+
+	signed, err := jwt.New(SigningMethodECDSA256).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	client.DisableKey(id)
+
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); err == nil {
+		t.Fatalf("expected error signing with a disabled key")
+	}
+
+	logs := buf.String()
+	for _, want := range []string{
+		`level=WARN msg="retrying throttled KMS call"`,
+		`level=DEBUG msg="public key cache miss"`,
+		`level=WARN msg="KMS key unusable" key_id=arn:aws:kms:us-east-1:REDACTED:key/REDACTED operation=Sign error_code=DisabledException`,
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs do not contain %q:\n%s", want, logs)
+		}
+	}
+
+	if strings.Contains(logs, mockkms.KeyARN(id)) {
+		t.Errorf("logs contain the key ARN:\n%s", logs)
+	}
+}
+
+func TestRedactARN(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "arn:aws:kms:eu-west-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab", want: "arn:aws:kms:eu-west-1:REDACTED:key/REDACTED"},
+		{in: "arn:aws:kms:eu-west-1:111122223333:alias/signing", want: "arn:aws:kms:eu-west-1:REDACTED:alias/REDACTED"},
+		{in: "alias/signing", want: "alias/signing"},
+		{in: "1234abcd-12ab-34cd-56ef-1234567890ab", want: "1234abcd-12ab-34cd-56ef-1234567890ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := redactARN(tt.in); got != tt.want {
+				t.Errorf("redactARN(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
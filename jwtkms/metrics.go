@@ -0,0 +1,101 @@
+package jwtkms
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aws/smithy-go"
+)
+
+// Operation is a KMS API operation called by the package.
+type Operation string
+
+const (
+	OperationSign         Operation = "Sign"
+	OperationVerify       Operation = "Verify"
+	OperationGetPublicKey Operation = "GetPublicKey"
+	OperationGenerateMac  Operation = "GenerateMac"
+	OperationVerifyMac    Operation = "VerifyMac"
+	OperationEncrypt      Operation = "Encrypt"
+	OperationDecrypt      Operation = "Decrypt"
+	OperationDescribeKey  Operation = "DescribeKey"
+)
+
+// MetricsRecorder receives a record of every KMS-backed operation of the Configs it is set on, for telemetry on KMS
+// latency and cache effectiveness. Its methods are called synchronously and must be safe for concurrent use.
+type MetricsRecorder interface {
+	// OnSign is called after every KMS Sign or GenerateMac call.
+	OnSign(SignMetrics)
+
+	// OnVerify is called after every token signature verification, local or with KMS.
+	OnVerify(VerifyMetrics)
+
+	// OnGetPublicKey is called after every public key lookup, served from the cache or by KMS GetPublicKey.
+	OnGetPublicKey(GetPublicKeyMetrics)
+}
+
+// SignMetrics describes a KMS signing call.
+type SignMetrics struct {
+	KeyID     string
+	Operation Operation
+
+	// Algorithm is the KMS signing or MAC algorithm.
+	Algorithm string
+
+	// Duration includes retries and time spent waiting for a SignLimiter.
+	Duration time.Duration
+	Err      error
+
+	// ErrorCode is the KMS error code of Err, empty if Err is not a KMS error.
+	ErrorCode string
+}
+
+// VerifyMetrics describes a signature verification.
+type VerifyMetrics struct {
+	KeyID string
+
+	// Algorithm is the alg of the signing method.
+	Algorithm string
+
+	// WithKMS is set when the signature was verified with KMS instead of the cached public key.
+	WithKMS  bool
+	Duration time.Duration
+	Err      error
+
+	// ErrorCode is the KMS error code of Err, empty if Err is not a KMS error.
+	ErrorCode string
+}
+
+// GetPublicKeyMetrics describes a public key lookup.
+type GetPublicKeyMetrics struct {
+	KeyID    string
+	CacheHit bool
+
+	// NegativeCacheHit is set when the lookup is a miss answered with a failure remembered by WithNegativeCacheTTL,
+	// without calling KMS.
+	NegativeCacheHit bool
+
+	// Duration is the duration of the GetPublicKey call, zero for cache hits.
+	Duration time.Duration
+	Err      error
+
+	// ErrorCode is the KMS error code of Err, empty if Err is not a KMS error.
+	ErrorCode string
+}
+
+// WithMetricsRecorder makes the Config report its operations to recorder.
+func WithMetricsRecorder(recorder MetricsRecorder) Option {
+	return func(c *Config) {
+		c.metrics = recorder
+	}
+}
+
+// errorCode returns the KMS error code of err, or "" if err is not a KMS error.
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+
+	return ""
+}
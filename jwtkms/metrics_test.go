@@ -0,0 +1,83 @@
+package jwtkms
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+type recordingMetrics struct {
+	mu           sync.Mutex
+	sign         []SignMetrics
+	verify       []VerifyMetrics
+	getPublicKey []GetPublicKeyMetrics
+}
+
+func (r *recordingMetrics) OnSign(m SignMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sign = append(r.sign, m)
+}
+
+func (r *recordingMetrics) OnVerify(m VerifyMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.verify = append(r.verify, m)
+}
+
+func (r *recordingMetrics) OnGetPublicKey(m GetPublicKeyMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.getPublicKey = append(r.getPublicKey, m)
+}
+
+func TestMetricsRecorder(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	metrics := &recordingMetrics{}
+	cfg := NewConfig(kms, id, WithMetricsRecorder(metrics), WithVerificationKeyIDs(id))
+
+	signed, err := jwt.New(SigningMethodECDSA256).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cfg.VerifyContext(context.Background(), signed, jwt.MapClaims{}); err != nil {
+			t.Fatalf("Error verifying token: %v", err)
+		}
+	}
+
+	if _, err := cfg.WithVerifyMode(VerifyWithKMS).VerifyContext(context.Background(), tamperSignature(signed), jwt.MapClaims{}); err == nil {
+		t.Fatalf("expected error verifying a tampered token")
+	}
+
+	if len(metrics.sign) != 1 || metrics.sign[0].KeyID != id || metrics.sign[0].Algorithm != "ECDSA_SHA_256" ||
+		metrics.sign[0].Operation != OperationSign || metrics.sign[0].Err != nil {
+		t.Errorf("unexpected sign metrics %+v", metrics.sign)
+	}
+
+	// a rotating Config reports one verification, not one per key
+	if len(metrics.verify) != 3 {
+		t.Fatalf("len(verify) = %d, want 3", len(metrics.verify))
+	}
+
+	if v := metrics.verify[0]; v.Algorithm != "ES256" || v.WithKMS || v.Err != nil {
+		t.Errorf("unexpected verify metrics %+v", v)
+	}
+
+	if v := metrics.verify[2]; !v.WithKMS || v.Err == nil || v.ErrorCode != "KMSInvalidSignatureException" {
+		t.Errorf("unexpected verify metrics %+v", v)
+	}
+
+	if len(metrics.getPublicKey) < 2 || metrics.getPublicKey[0].CacheHit || !metrics.getPublicKey[1].CacheHit {
+		t.Errorf("unexpected get public key metrics %+v", metrics.getPublicKey)
+	}
+}
@@ -0,0 +1,43 @@
+package jwtkms
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Middleware returns net/http middleware verifying the bearer token of requests with v. The claims of verified tokens
+// are stored in the request context, see ClaimsFromContext. Requests failing verification are passed to onError, or
+// answered with 401 Unauthorized and a WWW-Authenticate header, RFC 6750 section 3, when onError is nil.
+func Middleware(v *Verifier, onError func(w http.ResponseWriter, r *http.Request, err error)) func(http.Handler) http.Handler {
+	if onError == nil {
+		onError = unauthorized
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, err := v.VerifyRequest(r)
+			if err != nil {
+				onError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ContextWithClaims(r.Context(), token.Claims)))
+		})
+	}
+}
+
+// unauthorized is the default error handler of Middleware.
+func unauthorized(w http.ResponseWriter, _ *http.Request, err error) {
+	w.Header().Set("WWW-Authenticate", BearerChallenge(err))
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// BearerChallenge returns the WWW-Authenticate header value, RFC 6750 section 3, of responses to requests failing
+// verification with err.
+func BearerChallenge(err error) string {
+	if errors.Is(err, ErrNoBearerToken) {
+		return "Bearer"
+	}
+
+	return `Bearer error="invalid_token"`
+}
@@ -0,0 +1,76 @@
+package jwtkms
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestMiddleware(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id)
+
+	signed, err := cfg.SignContext(context.Background(), jwt.NewWithClaims(SigningMethodRS256, jwt.MapClaims{
+		"sub": "user",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	handler := Middleware(NewConfigVerifier(cfg, VerifierSettings{}), nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := ClaimsFromContext(r.Context())
+		if !ok {
+			t.Errorf("no claims in the request context")
+			return
+		}
+
+		sub, _ := claims.GetSubject()
+		w.Write([]byte(sub))
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantChallenge string
+	}{
+		{name: "valid", authorization: "Bearer " + signed, wantStatus: http.StatusOK},
+		{name: "no token", wantStatus: http.StatusUnauthorized, wantChallenge: "Bearer"},
+		{name: "invalid token", authorization: "Bearer " + tamperSignature(signed), wantStatus: http.StatusUnauthorized, wantChallenge: `Bearer error="invalid_token"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			if got := rec.Header().Get("WWW-Authenticate"); got != tt.wantChallenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.wantChallenge)
+			}
+
+			if tt.wantStatus == http.StatusOK && rec.Body.String() != "user" {
+				t.Errorf("body = %q, want %q", rec.Body.String(), "user")
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package jwtkms
+
+import (
+	"sync"
+	"time"
+)
+
+// WithNegativeCacheTTL makes the Config remember GetPublicKey failures caused by the key, ErrKeyNotFound,
+// ErrAccessDenied and ErrKeyDisabled, for ttl and return the remembered error instead of calling KMS again, so a
+// wrong key id does not send every verification to KMS. Throttling and other transient failures are not remembered.
+func WithNegativeCacheTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.publicKeyFailures = &failureCache{
+			ttl:      ttl,
+			failures: make(map[string]failure),
+		}
+	}
+}
+
+// failureCache remembers GetPublicKey failures per key id.
+type failureCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	failures map[string]failure
+}
+
+type failure struct {
+	err       error
+	expiresAt time.Time
+}
+
+// get returns the remembered failure of keyID, or nil if there is none.
+func (f *failureCache) get(keyID string, now time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fail, ok := f.failures[keyID]
+	if !ok {
+		return nil
+	}
+
+	if !now.Before(fail.expiresAt) {
+		delete(f.failures, keyID)
+		return nil
+	}
+
+	return fail.err
+}
+
+// add remembers err for keyID if it is caused by the key.
+func (f *failureCache) add(keyID string, err error, now time.Time) {
+	if !keyCausedFailure(err) {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures[keyID] = failure{err: err, expiresAt: now.Add(f.ttl)}
+}
+
+func (f *failureCache) delete(keyID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.failures, keyID)
+}
@@ -0,0 +1,124 @@
+package jwtkms
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestNegativeCache(t *testing.T) {
+	registered(t)
+
+	client := &getPublicKeyCountingKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	signed, err := jwt.New(SigningMethodECDSA256).SignedString(NewConfig(client, id))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	client.calls.Store(0)
+
+	cfg := NewConfig(client, "unknown-key", WithNegativeCacheTTL(50*time.Millisecond))
+	verify := func() {
+		t.Helper()
+		_, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil })
+		if !errors.Is(err, ErrKeyNotFound) {
+			t.Fatalf("err = %v, want %v", err, ErrKeyNotFound)
+		}
+	}
+
+	verify()
+	verify()
+
+	if client.calls.Load() != 1 {
+		t.Errorf("GetPublicKey calls = %d, want 1", client.calls.Load())
+	}
+
+	time.Sleep(75 * time.Millisecond)
+	verify()
+
+	if client.calls.Load() != 2 {
+		t.Errorf("GetPublicKey calls = %d, want 2", client.calls.Load())
+	}
+
+	cfg.Invalidate("unknown-key")
+	verify()
+
+	if client.calls.Load() != 3 {
+		t.Errorf("GetPublicKey calls = %d, want 3", client.calls.Load())
+	}
+}
+
+func TestFailureCacheAdd(t *testing.T) {
+	tests := []struct {
+		err        error
+		remembered bool
+	}{
+		{err: ErrKeyNotFound, remembered: true},
+		{err: ErrAccessDenied, remembered: true},
+		{err: ErrKeyDisabled, remembered: true},
+		{err: ErrThrottled, remembered: false},
+		{err: errors.New("connection reset"), remembered: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			f := &failureCache{ttl: time.Minute, failures: make(map[string]failure)}
+			now := time.Now()
+
+			f.add("key", fmt.Errorf("getting public key: %w", tt.err), now)
+
+			if got := f.get("key", now) != nil; got != tt.remembered {
+				t.Errorf("remembered = %v, want %v", got, tt.remembered)
+			}
+
+			if f.get("key", now.Add(time.Minute)) != nil {
+				t.Errorf("failure remembered after the ttl")
+			}
+		})
+	}
+}
+
+func TestNegativeCacheMetrics(t *testing.T) {
+	metrics := &recordingMetrics{}
+	cfg := NewConfig(mockkms.NewMockKMS(), "unknown-key", WithNegativeCacheTTL(time.Minute),
+		WithMetricsRecorder(metrics))
+
+	for i := 0; i < 2; i++ {
+		if _, err := getCachedPublicKey(cfg); !errors.Is(err, ErrKeyNotFound) {
+			t.Fatalf("err = %v, want %v", err, ErrKeyNotFound)
+		}
+	}
+
+	if len(metrics.getPublicKey) != 2 {
+		t.Fatalf("GetPublicKey metrics = %d, want 2", len(metrics.getPublicKey))
+	}
+
+	for i, m := range metrics.getPublicKey {
+		if m.CacheHit {
+			t.Errorf("lookup %d reported as a cache hit", i)
+		}
+		if !errors.Is(m.Err, ErrKeyNotFound) {
+			t.Errorf("lookup %d err = %v, want %v", i, m.Err, ErrKeyNotFound)
+		}
+	}
+
+	if metrics.getPublicKey[0].NegativeCacheHit {
+		t.Errorf("GetPublicKey call reported as a negative cache hit")
+	}
+	if !metrics.getPublicKey[1].NegativeCacheHit {
+		t.Errorf("remembered failure not reported as a negative cache hit")
+	}
+
+	if stats := cfg.CacheStats(); stats.Hits != 0 || stats.Misses != 2 {
+		t.Errorf("hits, misses = %d, %d, want 0, 2", stats.Hits, stats.Misses)
+	}
+}
@@ -0,0 +1,44 @@
+package jwtkms
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SignAndEncrypt signs token with signer and encrypts the signed token with EncryptJWE into a nested JWT for
+// encrypter, RFC 7519 section 5.2, the usual form of confidential ID tokens. The JWE header gets cty JWT and the kid
+// derived from encrypter; header holds its additional headers. alg and enc are the ones of EncryptJWE.
+func SignAndEncrypt(ctx context.Context, signer *Config, token *jwt.Token, encrypter *Config, alg, enc string, header map[string]interface{}) (string, error) {
+	signed, err := signer.SignContext(ctx, token)
+	if err != nil {
+		return "", fmt.Errorf("signing token: %w", err)
+	}
+
+	header = maps.Clone(header)
+	if header == nil {
+		header = make(map[string]interface{})
+	}
+
+	header["cty"] = "JWT"
+
+	return encrypter.EncryptJWE(ctx, alg, enc, header, []byte(signed))
+}
+
+// DecryptAndVerify decrypts a nested JWT made with SignAndEncrypt with decrypter and verifies the signed token it
+// contains with verifier like VerifyContext, returning the verified token. JWEs without cty JWT are rejected.
+func DecryptAndVerify(ctx context.Context, decrypter *Config, jwe string, verifier *Config, claims jwt.Claims, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	signed, header, err := decrypter.DecryptJWE(ctx, jwe)
+	if err != nil {
+		return nil, err
+	}
+
+	if cty, _ := header["cty"].(string); !strings.EqualFold(cty, "JWT") {
+		return nil, fmt.Errorf("%w: jwe cty is %q, expected JWT", jwt.ErrTokenMalformed, header["cty"])
+	}
+
+	return verifier.VerifyContext(ctx, string(signed), claims, opts...)
+}
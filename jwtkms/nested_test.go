@@ -0,0 +1,63 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestSignAndEncrypt(t *testing.T) {
+	client := mockkms.NewMockKMS()
+
+	signingKey, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	encryptionKey, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	signer := NewConfig(client, signingKey)
+	encrypter := NewConfig(client, encryptionKey)
+
+	token := jwt.NewWithClaims(SigningMethodECDSA256, jwt.MapClaims{"sub": "confidential"})
+
+	jwe, err := SignAndEncrypt(ctx, signer, token, encrypter, KeyAlgorithmRSAOAEP256, ContentEncryptionA256GCM, nil)
+	if err != nil {
+		t.Fatalf("Error signing and encrypting token: %v", err)
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := DecryptAndVerify(ctx, encrypter, jwe, signer, claims); err != nil {
+		t.Fatalf("Error decrypting and verifying token: %v", err)
+	}
+
+	if claims["sub"] != "confidential" {
+		t.Errorf("sub = %v, want confidential", claims["sub"])
+	}
+
+	// a JWE of a token that is not a nested JWT
+	plain, err := encrypter.EncryptJWE(ctx, KeyAlgorithmRSAOAEP256, ContentEncryptionA256GCM, nil, []byte("{}"))
+	if err != nil {
+		t.Fatalf("Error encrypting jwe: %v", err)
+	}
+
+	if _, err := DecryptAndVerify(ctx, encrypter, plain, signer, jwt.MapClaims{}); !errors.Is(err, jwt.ErrTokenMalformed) {
+		t.Errorf("err = %v, want %v", err, jwt.ErrTokenMalformed)
+	}
+
+	other, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	if _, err := DecryptAndVerify(ctx, encrypter, jwe, NewConfig(client, other), jwt.MapClaims{}); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidSignature)
+	}
+}
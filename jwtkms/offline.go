@@ -0,0 +1,55 @@
+package jwtkms
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// errNoKMSClient is the error of KMS calls of verification-only Configs.
+var errNoKMSClient = &ConfigError{Field: "KMSClient", Err: errors.New("verification-only config has no kms client")}
+
+// NewOfflineConfig creates a verification-only Config for keyID, verifying signatures locally with pub and without a
+// KMS client, so services verifying KMS signed tokens need no AWS configuration or credentials at runtime. pub is an
+// *rsa.PublicKey or *ecdsa.PublicKey, see ParsePublicKeyPEM.
+//
+// Signing, verifying with KMS and verifying with keys other than the provided ones fail with an ErrInvalidConfig
+// error. More keys, e.g. for rotation, are added with WithVerificationKeyIDs and WithPublicKey.
+func NewOfflineConfig(keyID string, pub crypto.PublicKey, opts ...Option) *Config {
+	opts = append([]Option{WithPublicKey(keyID, pub)}, opts...)
+
+	return NewConfig(nil, keyID, append(opts, func(c *Config) {
+		c.offline = true
+	})...)
+}
+
+// jwkSet is a JSON Web Key Set, RFC 7517 section 5.
+type jwkSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewOfflineKeySet parses a JSON Web Key Set, like the one served by the jwks package, into a KeySet of
+// verification-only Configs keyed by the kid of the keys, see NewOfflineConfig. opts are applied to every Config.
+func NewOfflineKeySet(jwks []byte, opts ...Option) (StaticKeySet, error) {
+	var set jwkSet
+	if err := json.Unmarshal(jwks, &set); err != nil {
+		return nil, fmt.Errorf("parsing jwk set: %w", err)
+	}
+
+	keySet := make(StaticKeySet, len(set.Keys))
+	for i, jwk := range set.Keys {
+		if jwk.KeyID == "" {
+			return nil, fmt.Errorf("jwk %d has no kid", i)
+		}
+
+		pub, err := jwk.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("parsing jwk %q: %w", jwk.KeyID, err)
+		}
+
+		keySet[jwk.KeyID] = NewOfflineConfig(jwk.KeyID, pub, opts...)
+	}
+
+	return keySet, nil
+}
@@ -0,0 +1,124 @@
+package jwtkms
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestNewOfflineConfig(t *testing.T) {
+	registered(t)
+
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP384)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	signed, err := jwt.New(SigningMethodECDSA384).SignedString(NewConfig(client, id))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	pub, err := ParsePublicKeyPEM(publicKeyPEM(t, client, id))
+	if err != nil {
+		t.Fatalf("Error parsing public key: %v", err)
+	}
+
+	cfg := NewOfflineConfig(id, pub)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Error validating offline config: %v", err)
+	}
+
+	verify := func(cfg *Config) error {
+		_, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil })
+		return err
+	}
+
+	if err := verify(cfg); err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{name: "sign", run: func() error {
+			_, err := jwt.New(SigningMethodECDSA384).SignedString(cfg)
+			return err
+		}},
+		{name: "verify with KMS", run: func() error { return verify(cfg.WithVerifyMode(VerifyWithKMS)) }},
+		{name: "unknown key", run: func() error { return verify(cfg.WithKeyID("other-key")) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("err = %v, want %v", err, ErrInvalidConfig)
+			}
+		})
+	}
+}
+
+func TestNewOfflineKeySet(t *testing.T) {
+	registered(t)
+
+	client := mockkms.NewMockKMS()
+
+	set := jwkSet{}
+	signed := map[string]string{}
+	for _, keyType := range []mockkms.KeyType{mockkms.KeyTypeECCNISTP256, mockkms.KeyTypeRSA2048} {
+		id, err := client.GenerateKey(keyType)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		pub, err := ParsePublicKeyPEM(publicKeyPEM(t, client, id))
+		if err != nil {
+			t.Fatalf("Error parsing public key: %v", err)
+		}
+
+		jwk, err := NewJWK(pub)
+		if err != nil {
+			t.Fatalf("Error creating jwk: %v", err)
+		}
+
+		jwk.KeyID = id
+		set.Keys = append(set.Keys, *jwk)
+
+		method := jwt.SigningMethod(SigningMethodECDSA256)
+		if keyType == mockkms.KeyTypeRSA2048 {
+			method = SigningMethodRS256
+		}
+
+		token := jwt.New(method)
+		token.Header["kid"] = jwk.KeyID
+
+		if signed[jwk.KeyID], err = token.SignedString(NewConfig(client, id)); err != nil {
+			t.Fatalf("Error signing token: %v", err)
+		}
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("Error marshalling jwk set: %v", err)
+	}
+
+	keySet, err := NewOfflineKeySet(data)
+	if err != nil {
+		t.Fatalf("Error parsing jwk set: %v", err)
+	}
+
+	for kid, token := range signed {
+		if _, err := jwt.Parse(token, Keyfunc(keySet)); err != nil {
+			t.Errorf("Error verifying token of %s: %v", kid, err)
+		}
+	}
+
+	if _, err := NewOfflineKeySet([]byte(`{"keys":[{"kty":"EC","crv":"P-256","x":"AA","y":"AA"}]}`)); err == nil {
+		t.Errorf("expected error for a jwk without kid")
+	}
+}
@@ -0,0 +1,120 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"golang.org/x/sync/singleflight"
+)
+
+// Option configures a Config created with NewConfig.
+type Option func(*Config)
+
+// NewConfig creates a Config signing and verifying with the KMS key keyID through client.
+//
+// Without options signatures are verified locally with the cached public key and KMS calls use context.Background().
+// The public key is cached in a cache of the Config, shared only with the Configs derived from it.
+func NewConfig(client KMSClient, keyID string, opts ...Option) *Config {
+	c := &Config{
+		ctx:              context.Background(),
+		kmsClient:        client,
+		kmsKeyID:         keyID,
+		pubKeyCache:      newPubKeyCache(PublicKeyCacheSettings{}),
+		publicKeyFetches: new(singleflight.Group),
+		cacheLookups:     newLookupCounter(),
+		keyMetadataCache: newKeyMetadataCache(),
+		health:           newHealthCache(defaultHealthCacheTTL),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// with returns a copy of Config with opts applied.
+func (c *Config) with(opts ...Option) *Config {
+	c2 := new(Config)
+	*c2 = *c
+
+	for _, opt := range opts {
+		opt(c2)
+	}
+
+	return c2
+}
+
+// WithVerifyWithKMS makes the Config verify signatures with KMS Verify instead of the cached public key.
+func WithVerifyWithKMS(verify bool) Option {
+	return func(c *Config) {
+		c.verifyWithKMS = verify
+	}
+}
+
+// WithContext sets the context used for KMS calls.
+func WithContext(ctx context.Context) Option {
+	return func(c *Config) {
+		c.ctx = ctx
+	}
+}
+
+// WithAPIOptions sets functional options passed to every KMS API call, e.g. to override the region or retryer.
+func WithAPIOptions(optFns ...func(*kms.Options)) Option {
+	return func(c *Config) {
+		c.apiOptions = append([]func(*kms.Options){}, optFns...)
+	}
+}
+
+// WithKID makes SignedString set the kid header of tokens to kid.
+func WithKID(kid string) Option {
+	return func(c *Config) {
+		c.kid = func(*Config) (string, error) {
+			return kid, nil
+		}
+	}
+}
+
+// WithKeyIDHeader makes SignedString set the kid header of tokens to the key ARN, see Config.WithKeyIDHeader.
+func WithKeyIDHeader() Option {
+	return func(c *Config) {
+		c.kid = keyARN
+	}
+}
+
+// WithThumbprintKeyIDHeader makes SignedString set the kid header of tokens to the RFC 7638 thumbprint of the public
+// key, see Config.WithThumbprintKeyIDHeader.
+func WithThumbprintKeyIDHeader() Option {
+	return func(c *Config) {
+		c.kid = thumbprint
+	}
+}
+
+// WithKIDFunc makes SignedString set the kid header of tokens to the result of f, see Config.WithKIDFunc.
+func WithKIDFunc(f KIDFunc) Option {
+	return func(c *Config) {
+		c.kid = func(cfg *Config) (string, error) {
+			cached, err := getCachedPublicKey(cfg)
+			if err != nil {
+				return "", err
+			}
+
+			return f(cached.KeyARN, cached.PublicKey)
+		}
+	}
+}
+
+// WithVerificationKeyIDs makes the Config accept signatures of keyIDs too, see Config.WithVerificationKeyIDs.
+func WithVerificationKeyIDs(keyIDs ...string) Option {
+	return func(c *Config) {
+		c.verificationKeyIDs = append([]string(nil), keyIDs...)
+	}
+}
+
+// WithCertificateChain makes SignedString set the x5c and x5t#S256 headers of tokens, see Config.WithCertificateChain.
+func WithCertificateChain(chain ...*x509.Certificate) Option {
+	return func(c *Config) {
+		c.certificateChain = append([]*x509.Certificate(nil), chain...)
+	}
+}
@@ -0,0 +1,105 @@
+package jwtkms
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// apiOptionsKMS records the number of functional options passed to Sign.
+type apiOptionsKMS struct {
+	*mockkms.MockKMS
+	signOptions int
+}
+
+func (c *apiOptionsKMS) Sign(ctx context.Context, in *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error) {
+	c.signOptions = len(optFns)
+	return c.MockKMS.Sign(ctx, in, optFns...)
+}
+
+func TestNewConfig(t *testing.T) {
+	registered(t)
+
+	client := &apiOptionsKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	noop := func(*kms.Options) {}
+
+	cfg := NewConfig(client, id,
+		WithVerifyWithKMS(true),
+		WithKID("key-1"),
+		WithAPIOptions(noop, noop),
+	)
+
+	if !cfg.verifyWithKMS {
+		t.Errorf("WithVerifyWithKMS not applied")
+	}
+
+	signed, err := cfg.SignedString(jwt.New(SigningMethodECDSA256))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if client.signOptions != 2 {
+		t.Errorf("Sign got %d API options, want 2", client.signOptions)
+	}
+
+	token, err := jwt.Parse(signed, Keyfunc(StaticKeySet{"key-1": cfg}))
+	if err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	if kid := token.Header["kid"]; kid != "key-1" {
+		t.Errorf("kid = %v, want key-1", kid)
+	}
+}
+
+func TestConfigClones(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	ids := make([]string, 2)
+	for i := range ids {
+		id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+		ids[i] = id
+	}
+
+	base := NewKMSConfig(kms, ids[0], false)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			cfg := base.WithKeyID(ids[i%2]).WithVerifyMode(VerifyMode(i % 2)).WithContext(context.Background())
+
+			signed, err := jwt.New(SigningMethodECDSA256).SignedString(cfg)
+			if err != nil {
+				t.Errorf("Error signing token: %v", err)
+				return
+			}
+
+			if _, err := cfg.VerifyContext(context.Background(), signed, jwt.MapClaims{}); err != nil {
+				t.Errorf("Error verifying token: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if base.KeyID() != ids[0] || base.VerifyMode() != VerifyLocally {
+		t.Errorf("base Config was modified: key %s, mode %v", base.KeyID(), base.VerifyMode())
+	}
+
+	if cfg := base.WithKeyID(ids[1]).WithVerifyMode(VerifyWithKMS); cfg.KeyID() != ids[1] || cfg.VerifyMode() != VerifyWithKMS {
+		t.Errorf("clone has key %s, mode %v", cfg.KeyID(), cfg.VerifyMode())
+	}
+}
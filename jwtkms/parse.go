@@ -0,0 +1,235 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ParseOption configures ParseWithConfig.
+type ParseOption func(*parseSettings)
+
+// parseSettings holds the ParseOptions of a ParseWithConfig call.
+type parseSettings struct {
+	algorithms    []string
+	leeway        time.Duration
+	now           func() time.Time
+	revoker       Revoker
+	tokenType     string
+	parserOptions []jwt.ParserOption
+}
+
+// AllowAlgorithms restricts the alg of tokens to algs instead of the algorithms of the KMS key.
+func AllowAlgorithms(algs ...string) ParseOption {
+	return func(s *parseSettings) {
+		s.algorithms = algs
+	}
+}
+
+// Leeway allows exp, nbf and iat claims to be off by up to leeway, for clock skew between the issuer and verifier.
+func Leeway(leeway time.Duration) ParseOption {
+	return func(s *parseSettings) {
+		s.leeway = leeway
+	}
+}
+
+// Clock makes the exp, nbf and iat claims be validated against the time returned by now instead of time.Now, e.g. to
+// freeze time in tests.
+func Clock(now func() time.Time) ParseOption {
+	return func(s *parseSettings) {
+		s.now = now
+	}
+}
+
+// CheckRevocation fails tokens r reports as revoked with ErrTokenRevoked.
+func CheckRevocation(r Revoker) ParseOption {
+	return func(s *parseSettings) {
+		s.revoker = r
+	}
+}
+
+// RequireType requires the typ header typ, in addition to the one required by the Config, see WithTokenType.
+func RequireType(typ string) ParseOption {
+	return func(s *parseSettings) {
+		s.tokenType = typ
+	}
+}
+
+// RequireIssuer requires the iss claim to be iss.
+func RequireIssuer(iss string) ParseOption {
+	return ParserOptions(jwt.WithIssuer(iss))
+}
+
+// RequireAudience requires aud to be one of the audiences of the aud claim.
+func RequireAudience(aud string) ParseOption {
+	return ParserOptions(jwt.WithAudience(aud))
+}
+
+// RequireSubject requires the sub claim to be sub.
+func RequireSubject(sub string) ParseOption {
+	return ParserOptions(jwt.WithSubject(sub))
+}
+
+// ParserOptions passes additional options to the jwt parser.
+func ParserOptions(opts ...jwt.ParserOption) ParseOption {
+	return func(s *parseSettings) {
+		s.parserOptions = append(s.parserOptions, opts...)
+	}
+}
+
+// ParseWithConfig parses and verifies tokenString with cfg, using ctx for the KMS calls, and returns its claims. T is a
+// pointer to a claims struct or a map type like jwt.MapClaims, allocated by ParseWithConfig. The claims are validated
+// after the signature is verified, including the ones required by RequireIssuer, RequireAudience and RequireSubject.
+//
+// The alg of the token must be one of the algorithms allowed by the Config, see WithAllowedAlgorithms, or else of the
+// algorithms of the KMS key; see AllowAlgorithms for keys without a public key like HMAC keys.
+func ParseWithConfig[T jwt.Claims](ctx context.Context, tokenString string, cfg *Config, opts ...ParseOption) (T, error) {
+	var zero T
+
+	settings := parseSettings{}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	claims, err := newClaims[T]()
+	if err != nil {
+		return zero, err
+	}
+
+	cfg = cfg.WithContext(ctx)
+
+	algorithms := settings.algorithms
+	if algorithms == nil && len(cfg.allowedAlgorithms) > 0 {
+		algorithms = cfg.allowedAlgorithms
+	}
+
+	if algorithms == nil {
+		if algorithms, err = cfg.keyAlgorithms(); err != nil {
+			return zero, fmt.Errorf("resolving algorithms of the key: %w", err)
+		}
+	}
+
+	parserOptions := []jwt.ParserOption{jwt.WithLeeway(settings.leeway)}
+	if settings.now != nil {
+		parserOptions = append(parserOptions, jwt.WithTimeFunc(settings.now))
+	}
+
+	parserOptions = append(parserOptions, settings.parserOptions...)
+
+	if _, err := parseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if settings.tokenType != "" {
+			if err := requireTokenType(token, settings.tokenType); err != nil {
+				return nil, err
+			}
+		}
+
+		return cfg, cfg.checkTokenType(token)
+	}, algorithms, parserOptions...); err != nil {
+		return zero, err
+	}
+
+	if settings.revoker != nil {
+		if err := checkRevoked(ctx, settings.revoker, claims); err != nil {
+			return zero, err
+		}
+	}
+
+	return claims, nil
+}
+
+// parseWithClaims is jwt.ParseWithClaims verifying the signature with the signing method of this package for the alg
+// of the token, see GetSigningMethod, so tokens verify with a *Config whether the methods are registered with golang-jwt
+// or not. Tokens with an alg other than validMethods fail, unless validMethods is nil.
+func parseWithClaims(tokenString string, claims jwt.Claims, keyfunc jwt.Keyfunc, validMethods []string, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	if validMethods != nil {
+		opts = append([]jwt.ParserOption{jwt.WithValidMethods(validMethods)}, opts...)
+	}
+
+	parser := jwt.NewParser(opts...)
+
+	token, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// the method of golang-jwt of the alg, e.g. ES256, does not verify with a *Config
+		if m := GetSigningMethod(token.Method.Alg()); m != nil {
+			token.Method = m
+		}
+
+		return keyfunc(token)
+	})
+
+	// golang-jwt stops after decoding the claims of algs it has no method for, e.g. ES256K
+	if token == nil || token.Method != nil || !errors.Is(err, jwt.ErrTokenUnverifiable) {
+		return token, err
+	}
+
+	alg, _ := token.Header["alg"].(string)
+	if token.Method = GetSigningMethod(alg); token.Method == nil {
+		return token, err
+	}
+
+	if validMethods != nil && !slices.Contains(validMethods, alg) {
+		return token, fmt.Errorf("%w: signing method %s is invalid", jwt.ErrTokenSignatureInvalid, alg)
+	}
+
+	// the token has three segments, golang-jwt decoded the first two
+	sep := strings.LastIndex(tokenString, ".")
+	signingString := tokenString[:sep]
+
+	if token.Signature, err = parser.DecodeSegment(tokenString[sep+1:]); err != nil {
+		return token, fmt.Errorf("%w: could not base64 decode signature: %w", jwt.ErrTokenMalformed, err)
+	}
+
+	key, err := keyfunc(token)
+	if err != nil {
+		return token, fmt.Errorf("%w: error while executing keyfunc: %w", jwt.ErrTokenUnverifiable, err)
+	}
+
+	if err := token.Method.Verify(signingString, token.Signature, key); err != nil {
+		return token, fmt.Errorf("%w: %w", jwt.ErrTokenSignatureInvalid, err)
+	}
+
+	if err := jwt.NewValidator(opts...).Validate(claims); err != nil {
+		return token, fmt.Errorf("%w: %w", jwt.ErrTokenInvalidClaims, err)
+	}
+
+	token.Valid = true
+
+	return token, nil
+}
+
+// newClaims allocates the claims of type T, a pointer or map type.
+func newClaims[T jwt.Claims]() (T, error) {
+	var claims T
+
+	typ := reflect.TypeOf(&claims).Elem()
+	switch typ.Kind() {
+	case reflect.Pointer:
+		return reflect.New(typ.Elem()).Interface().(T), nil
+	case reflect.Map:
+		return reflect.MakeMap(typ).Interface().(T), nil
+	}
+
+	return claims, fmt.Errorf("claims type %s is not a pointer or map type", typ)
+}
+
+// keyAlgorithms returns the sorted algs of the signing methods of this package the KMS key signs with.
+func (c *Config) keyAlgorithms() ([]string, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	key := c.knownPublicKey()
+	if key == nil {
+		var err error
+		if key, err = getCachedPublicKey(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return AlgorithmsForKeySpec(key.KeySpec, key.SigningAlgorithms), nil
+}
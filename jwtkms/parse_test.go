@@ -0,0 +1,230 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// testClaims are the claims of the ParseWithConfig tests.
+type testClaims struct {
+	Scope string `json:"scope"`
+	jwt.RegisteredClaims
+}
+
+func TestParseWithConfig(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id)
+
+	sign := func(method jwt.SigningMethod, claims jwt.Claims) string {
+		t.Helper()
+
+		signed, err := cfg.SignContext(ctx, jwt.NewWithClaims(method, claims))
+		if err != nil {
+			t.Fatalf("Error signing token: %v", err)
+		}
+
+		return signed
+	}
+
+	signed := sign(SigningMethodPS256, &testClaims{Scope: "read", RegisteredClaims: jwt.RegisteredClaims{Subject: "user"}})
+
+	claims, err := ParseWithConfig[*testClaims](ctx, signed, cfg)
+	if err != nil {
+		t.Fatalf("Error parsing token: %v", err)
+	}
+
+	if claims.Scope != "read" || claims.Subject != "user" {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	mapClaims, err := ParseWithConfig[jwt.MapClaims](ctx, signed, cfg)
+	if err != nil {
+		t.Fatalf("Error parsing token into map claims: %v", err)
+	}
+
+	if mapClaims["scope"] != "read" {
+		t.Errorf("scope = %v, want read", mapClaims["scope"])
+	}
+
+	expired := sign(SigningMethodRS256, jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})
+
+	tests := []struct {
+		name    string
+		token   string
+		opts    []ParseOption
+		wantErr error
+	}{
+		{name: "algorithm not allowed", token: signed, opts: []ParseOption{AllowAlgorithms("RS256")}, wantErr: jwt.ErrTokenSignatureInvalid},
+		{name: "expired", token: expired, wantErr: jwt.ErrTokenExpired},
+		{name: "expired within leeway", token: expired, opts: []ParseOption{Leeway(2 * time.Minute)}},
+		{name: "parser options", token: signed, opts: []ParseOption{ParserOptions(jwt.WithIssuer("issuer"))}, wantErr: jwt.ErrTokenRequiredClaimMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWithConfig[jwt.MapClaims](ctx, tt.token, cfg, tt.opts...)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Error parsing token: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := ParseWithConfig[testClaims](ctx, signed, cfg); err == nil {
+		t.Errorf("expected error for a claims type that is not a pointer")
+	}
+}
+
+func TestKeyAlgorithms(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		want    string
+	}{
+		{name: "P-256", keyType: mockkms.KeyTypeECCNISTP256, want: "[ES256]"},
+		{name: "P-384", keyType: mockkms.KeyTypeECCNISTP384, want: "[ES384]"},
+		{name: "secp256k1", keyType: mockkms.KeyTypeECCSECGP256K1, want: "[ES256K]"},
+		{name: "RSA", keyType: mockkms.KeyTypeRSA2048, want: "[PS256 PS384 PS512 RS256 RS384 RS512]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := mockkms.NewMockKMS()
+			id, err := client.GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			algs, err := NewConfig(client, id).keyAlgorithms()
+			if err != nil {
+				t.Fatalf("Error resolving algorithms: %v", err)
+			}
+
+			if got := fmt.Sprint(algs); got != tt.want {
+				t.Errorf("algs = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWithConfigRequiredClaims(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id)
+
+	signed, err := cfg.SignContext(ctx, jwt.NewWithClaims(SigningMethodECDSA256, jwt.MapClaims{
+		"iss": "issuer",
+		"aud": []string{"a", "b"},
+		"sub": "user",
+	}))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    []ParseOption
+		wantErr error
+	}{
+		{name: "all match", opts: []ParseOption{RequireIssuer("issuer"), RequireAudience("b"), RequireSubject("user")}},
+		{name: "issuer", opts: []ParseOption{RequireIssuer("other")}, wantErr: jwt.ErrTokenInvalidIssuer},
+		{name: "audience", opts: []ParseOption{RequireAudience("c")}, wantErr: jwt.ErrTokenInvalidAudience},
+		{name: "subject", opts: []ParseOption{RequireSubject("other")}, wantErr: jwt.ErrTokenInvalidSubject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWithConfig[jwt.MapClaims](ctx, signed, cfg, tt.opts...)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Error parsing token: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// claims are only validated once the signature verified
+	if _, err := ParseWithConfig[jwt.MapClaims](ctx, tamperSignature(signed), cfg, RequireIssuer("other")); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Errorf("err = %v, want %v", err, jwt.ErrTokenSignatureInvalid)
+	}
+}
+
+func TestParseWithConfigClock(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id)
+
+	issuedAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	signed, err := cfg.SignContext(ctx, jwt.NewWithClaims(SigningMethodECDSA256, jwt.MapClaims{
+		"nbf": issuedAt.Unix(),
+		"exp": issuedAt.Add(time.Minute).Unix(),
+	}))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	at := func(now time.Time) ParseOption {
+		return Clock(func() time.Time { return now })
+	}
+
+	tests := []struct {
+		name    string
+		opts    []ParseOption
+		wantErr error
+	}{
+		{name: "valid", opts: []ParseOption{at(issuedAt.Add(30 * time.Second))}},
+		{name: "not valid yet", opts: []ParseOption{at(issuedAt.Add(-5 * time.Second))}, wantErr: jwt.ErrTokenNotValidYet},
+		{name: "clock skew within leeway", opts: []ParseOption{at(issuedAt.Add(-5 * time.Second)), Leeway(10 * time.Second)}},
+		{name: "expired", opts: []ParseOption{at(issuedAt.Add(2 * time.Minute))}, wantErr: jwt.ErrTokenExpired},
+		{name: "real clock", wantErr: jwt.ErrTokenNotValidYet},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWithConfig[jwt.MapClaims](ctx, signed, cfg, tt.opts...)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Error parsing token: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
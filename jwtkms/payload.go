@@ -0,0 +1,42 @@
+package jwtkms
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SignPayload signs an arbitrary payload, e.g. a webhook body or a manifest, with the key of the Config and method,
+// one of the signing methods of this package, using ctx for the KMS calls. The signature is encoded like the signature
+// of a JWT signed with method. HMAC signing methods accept payloads of at most 4096 bytes.
+func (c *Config) SignPayload(ctx context.Context, method jwt.SigningMethod, payload []byte) ([]byte, error) {
+	return method.Sign(string(payload), c.WithContext(ctx))
+}
+
+// SignPayloadString is SignPayload with the signature base64url encoded without padding, as in a JWS.
+func (c *Config) SignPayloadString(ctx context.Context, method jwt.SigningMethod, payload []byte) (string, error) {
+	sig, err := c.SignPayload(ctx, method, payload)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// VerifyPayload verifies the signature of payload made with SignPayload, like the signature of a JWT, using ctx for
+// the KMS calls.
+func (c *Config) VerifyPayload(ctx context.Context, method jwt.SigningMethod, payload, sig []byte) error {
+	return method.Verify(string(payload), sig, c.WithContext(ctx))
+}
+
+// VerifyPayloadString is VerifyPayload with a signature made with SignPayloadString.
+func (c *Config) VerifyPayloadString(ctx context.Context, method jwt.SigningMethod, payload []byte, sig string) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("%w: decoding signature: %w", ErrInvalidSignature, err)
+	}
+
+	return c.VerifyPayload(ctx, method, payload, decoded)
+}
@@ -0,0 +1,56 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestSignPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		method  jwt.SigningMethod
+	}{
+		{name: "ES256", keyType: mockkms.KeyTypeECCNISTP256, method: SigningMethodECDSA256},
+		{name: "RS256", keyType: mockkms.KeyTypeRSA2048, method: SigningMethodRS256},
+		{name: "PS384", keyType: mockkms.KeyTypeRSA2048, method: SigningMethodPS384},
+		{name: "HS256", keyType: mockkms.KeyTypeHMAC256, method: SigningMethodHS256},
+	}
+
+	ctx := context.Background()
+	payload := []byte(`{"event":"invoice.paid"}`)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := mockkms.NewMockKMS()
+			id, err := client.GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			cfg := NewConfig(client, id)
+
+			sig, err := cfg.SignPayloadString(ctx, tt.method, payload)
+			if err != nil {
+				t.Fatalf("Error signing payload: %v", err)
+			}
+
+			if err := cfg.VerifyPayloadString(ctx, tt.method, payload, sig); err != nil {
+				t.Fatalf("Error verifying payload: %v", err)
+			}
+
+			tampered := append([]byte("x"), payload...)
+			if err := cfg.VerifyPayloadString(ctx, tt.method, tampered, sig); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("err = %v, want %v", err, ErrInvalidSignature)
+			}
+
+			if err := cfg.VerifyPayloadString(ctx, tt.method, payload, "!"); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("err = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// PreflightError is the error of Preflight for a key the Config cannot use.
+type PreflightError struct {
+	// KeyID is the key id of the Config.
+	KeyID string
+
+	// Missing are the denied IAM actions, e.g. "kms:Sign".
+	Missing []string
+
+	// Errs are the errors of the failed checks, access denied and others, prefixed with their action.
+	Errs []error
+}
+
+func (e *PreflightError) Error() string {
+	if len(e.Missing) > 0 {
+		return fmt.Sprintf("preflight of kms key %q: missing permissions %s", e.KeyID, strings.Join(e.Missing, ", "))
+	}
+
+	return fmt.Sprintf("preflight of kms key %q: %v", e.KeyID, errors.Join(e.Errs...))
+}
+
+func (e *PreflightError) Unwrap() []error {
+	return e.Errs
+}
+
+// Preflight checks at startup that the caller is permitted every KMS call cfg makes with its key: kms:Sign,
+// kms:Verify if cfg verifies with KMS, and kms:GetPublicKey, or kms:GenerateMac and kms:VerifyMac for HMAC keys.
+// Sign, Verify and the MAC calls are sent with DryRun set, see DryRun, and GetPublicKey is called once.
+//
+// The algorithm of the checks is taken from the public key or, when kms:GetPublicKey is denied, from DescribeKey as far
+// as the client implements KMSDescribeKeyClient. The returned *PreflightError lists all denied actions in Missing and
+// matches ErrAccessDenied; an error not caused by permissions, e.g. ErrKeyDisabled, is returned in it as well.
+func Preflight(ctx context.Context, cfg *Config) error {
+	cfg = cfg.WithContext(ctx)
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	if cfg.offline {
+		return errNoKMSClient
+	}
+
+	result := &PreflightError{KeyID: cfg.kmsKeyID}
+	check := func(action string, err error) {
+		if err == nil {
+			return
+		}
+
+		if errors.Is(err, ErrAccessDenied) {
+			result.Missing = append(result.Missing, action)
+		}
+
+		result.Errs = append(result.Errs, fmt.Errorf("%s: %w", action, err))
+	}
+
+	out, err := cfg.kmsClient.GetPublicKey(cfg.ctx, &kms.GetPublicKeyInput{
+		KeyId:       aws.String(cfg.kmsKeyID),
+		GrantTokens: cfg.grantTokens,
+	}, cfg.kmsOptions()...)
+
+	// HMAC keys have no public key and KMS answers after authorizing the call
+	var unsupported *types.UnsupportedOperationException
+	isHMAC := errors.As(err, &unsupported)
+	if !isHMAC {
+		check("kms:GetPublicKey", mapKMSError(err))
+	}
+
+	signingAlgorithm, macAlgorithm, known := cfg.preflightAlgorithms(out, isHMAC)
+
+	// KMS authorizes calls before checking their algorithm, a guessed algorithm the key does not support still
+	// tells the call is permitted
+	dryRun := func(err error) error {
+		var invalidUsage *types.InvalidKeyUsageException
+		if !known && errors.As(err, &invalidUsage) {
+			return nil
+		}
+
+		return err
+	}
+
+	if macAlgorithm != "" {
+		check("kms:GenerateMac", dryRun(cfg.dryRunGenerateMac(macAlgorithm)))
+		check("kms:VerifyMac", dryRun(cfg.dryRunVerifyMac(macAlgorithm)))
+	} else {
+		check("kms:Sign", dryRun(cfg.dryRunSign(signingAlgorithm)))
+		if cfg.verifyWithKMS {
+			check("kms:Verify", dryRun(cfg.dryRunVerify(signingAlgorithm)))
+		}
+	}
+
+	if len(result.Errs) > 0 {
+		return result
+	}
+
+	return nil
+}
+
+// preflightAlgorithms returns the signing algorithm, or the MAC algorithm of HMAC keys, Preflight checks with, known
+// false for a guess.
+func (c *Config) preflightAlgorithms(out *kms.GetPublicKeyOutput, isHMAC bool) (types.SigningAlgorithmSpec, types.MacAlgorithmSpec, bool) {
+	if out != nil && len(out.SigningAlgorithms) > 0 {
+		return out.SigningAlgorithms[0], "", true
+	}
+
+	if key := c.knownPublicKey(); key != nil && len(key.SigningAlgorithms) > 0 {
+		return key.SigningAlgorithms[0], "", true
+	}
+
+	if describeClient, ok := c.kmsClient.(KMSDescribeKeyClient); ok {
+		desc, err := describeClient.DescribeKey(c.ctx, &kms.DescribeKeyInput{
+			KeyId:       aws.String(c.kmsKeyID),
+			GrantTokens: c.grantTokens,
+		}, c.kmsOptions()...)
+		if err == nil && desc.KeyMetadata != nil {
+			if len(desc.KeyMetadata.MacAlgorithms) > 0 {
+				return "", desc.KeyMetadata.MacAlgorithms[0], true
+			}
+
+			if len(desc.KeyMetadata.SigningAlgorithms) > 0 {
+				return desc.KeyMetadata.SigningAlgorithms[0], "", true
+			}
+		}
+	}
+
+	if isHMAC {
+		return "", types.MacAlgorithmSpecHmacSha256, false
+	}
+
+	return types.SigningAlgorithmSpecEcdsaSha256, "", false
+}
@@ -0,0 +1,159 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/smithy-go"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// deniedKMS denies the KMS calls of the IAM actions in denied, like a key policy not granting them.
+type deniedKMS struct {
+	*mockkms.MockKMS
+	denied []string
+}
+
+func (c *deniedKMS) deny(action string) error {
+	if !slices.Contains(c.denied, action) {
+		return nil
+	}
+
+	return &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized to perform " + action}
+}
+
+func (c *deniedKMS) Sign(ctx context.Context, in *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error) {
+	if err := c.deny("kms:Sign"); err != nil {
+		return nil, err
+	}
+
+	return c.MockKMS.Sign(ctx, in, optFns...)
+}
+
+func (c *deniedKMS) Verify(ctx context.Context, in *kms.VerifyInput, optFns ...func(*kms.Options)) (*kms.VerifyOutput, error) {
+	if err := c.deny("kms:Verify"); err != nil {
+		return nil, err
+	}
+
+	return c.MockKMS.Verify(ctx, in, optFns...)
+}
+
+func (c *deniedKMS) GetPublicKey(ctx context.Context, in *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error) {
+	if err := c.deny("kms:GetPublicKey"); err != nil {
+		return nil, err
+	}
+
+	return c.MockKMS.GetPublicKey(ctx, in, optFns...)
+}
+
+func (c *deniedKMS) DescribeKey(ctx context.Context, in *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+	if err := c.deny("kms:DescribeKey"); err != nil {
+		return nil, err
+	}
+
+	return c.MockKMS.DescribeKey(ctx, in, optFns...)
+}
+
+func (c *deniedKMS) GenerateMac(ctx context.Context, in *kms.GenerateMacInput, optFns ...func(*kms.Options)) (*kms.GenerateMacOutput, error) {
+	if err := c.deny("kms:GenerateMac"); err != nil {
+		return nil, err
+	}
+
+	return c.MockKMS.GenerateMac(ctx, in, optFns...)
+}
+
+func (c *deniedKMS) VerifyMac(ctx context.Context, in *kms.VerifyMacInput, optFns ...func(*kms.Options)) (*kms.VerifyMacOutput, error) {
+	if err := c.deny("kms:VerifyMac"); err != nil {
+		return nil, err
+	}
+
+	return c.MockKMS.VerifyMac(ctx, in, optFns...)
+}
+
+func TestPreflight(t *testing.T) {
+	client := mockkms.NewMockKMS()
+
+	generate := func(keyType mockkms.KeyType) string {
+		id, err := client.GenerateKey(keyType)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		return id
+	}
+
+	ecdsaKey := generate(mockkms.KeyTypeECCNISTP384)
+	hmacKey := generate(mockkms.KeyTypeHMAC512)
+
+	tests := []struct {
+		name        string
+		keyID       string
+		denied      []string
+		opts        []Option
+		wantMissing []string
+	}{
+		{name: "all permitted", keyID: ecdsaKey},
+		{name: "all permitted verifying with KMS", keyID: ecdsaKey, opts: []Option{WithVerifyWithKMS(true)}},
+		{name: "sign denied", keyID: ecdsaKey, denied: []string{"kms:Sign"}, wantMissing: []string{"kms:Sign"}},
+		{name: "verify denied", keyID: ecdsaKey, denied: []string{"kms:Verify"}},
+		{
+			name: "verify denied verifying with KMS", keyID: ecdsaKey, denied: []string{"kms:Verify"},
+			opts: []Option{WithVerifyWithKMS(true)}, wantMissing: []string{"kms:Verify"},
+		},
+		{
+			name: "get public key denied", keyID: ecdsaKey, denied: []string{"kms:GetPublicKey"},
+			wantMissing: []string{"kms:GetPublicKey"},
+		},
+		{
+			name: "nothing but describe key permitted", keyID: ecdsaKey, denied: []string{"kms:GetPublicKey", "kms:Sign", "kms:Verify"},
+			opts: []Option{WithVerifyWithKMS(true)}, wantMissing: []string{"kms:GetPublicKey", "kms:Sign", "kms:Verify"},
+		},
+		{
+			name: "algorithm guessed", keyID: ecdsaKey, denied: []string{"kms:GetPublicKey", "kms:DescribeKey"},
+			wantMissing: []string{"kms:GetPublicKey"},
+		},
+		{name: "HMAC all permitted", keyID: hmacKey},
+		{
+			name: "HMAC verify mac denied", keyID: hmacKey, denied: []string{"kms:VerifyMac"},
+			wantMissing: []string{"kms:VerifyMac"},
+		},
+		{
+			name: "HMAC algorithm guessed", keyID: hmacKey, denied: []string{"kms:DescribeKey", "kms:GenerateMac"},
+			wantMissing: []string{"kms:GenerateMac"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig(&deniedKMS{MockKMS: client, denied: tt.denied}, tt.keyID, tt.opts...)
+
+			err := Preflight(context.Background(), cfg)
+			if len(tt.wantMissing) == 0 {
+				if err != nil {
+					t.Errorf("Error running preflight: %v", err)
+				}
+
+				return
+			}
+
+			var preflightErr *PreflightError
+			if !errors.As(err, &preflightErr) || !errors.Is(err, ErrAccessDenied) {
+				t.Fatalf("err = %v, want a *PreflightError matching %v", err, ErrAccessDenied)
+			}
+
+			if !slices.Equal(preflightErr.Missing, tt.wantMissing) {
+				t.Errorf("Missing = %v, want %v", preflightErr.Missing, tt.wantMissing)
+			}
+		})
+	}
+
+	disabledKey := generate(mockkms.KeyTypeECCNISTP256)
+	client.DisableKey(disabledKey)
+
+	if err := Preflight(context.Background(), NewConfig(client, disabledKey)); !errors.Is(err, ErrKeyDisabled) || errors.Is(err, ErrAccessDenied) {
+		t.Errorf("err = %v, want %v", err, ErrKeyDisabled)
+	}
+}
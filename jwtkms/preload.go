@@ -0,0 +1,83 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PreloadKeys fetches the public keys of keyIDs in parallel and caches them in the cache of the Config, so the first
+// verifications after startup do not wait for GetPublicKey. Without keyIDs the key ids of the Config are preloaded.
+// Keys already cached are not fetched again. The error joins the failures of all keys.
+func (c *Config) PreloadKeys(ctx context.Context, keyIDs ...string) error {
+	if len(keyIDs) == 0 {
+		keyIDs = append([]string{c.kmsKeyID}, c.verificationKeyIDs...)
+	}
+
+	cfg := c.WithContext(ctx)
+	errs := make([]error, len(keyIDs))
+
+	var wg sync.WaitGroup
+	for i, keyID := range keyIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, err := getCachedPublicKey(cfg.WithKeyID(keyID)); err != nil {
+				errs[i] = fmt.Errorf("preloading %s: %w", keyID, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// RefreshPublicKeys fetches the public keys of keyIDs again with GetPublicKey in parallel, bypassing the cache of the
+// Config, and returns them in the order given. Only if every fetch succeeds the fetched keys replace the cached ones,
+// otherwise the cache keeps serving the keys it has and the error joins the failures of all keys. Provided public
+// keys, see WithPublicKey, are returned without calling KMS.
+func (c *Config) RefreshPublicKeys(ctx context.Context, keyIDs ...string) ([]*CachedPublicKey, error) {
+	cfg := c.WithContext(ctx)
+	keys := make([]*CachedPublicKey, len(keyIDs))
+	errs := make([]error, len(keyIDs))
+
+	var wg sync.WaitGroup
+	for i, keyID := range keyIDs {
+		if provided, ok := cfg.providedPublicKeys[keyID]; ok {
+			keys[i] = provided
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			key, err := requestPublicKey(cfg.WithKeyID(keyID), time.Now())
+			if err != nil {
+				errs[i] = fmt.Errorf("refreshing %s: %w", keyID, err)
+			}
+
+			keys[i] = key
+		}()
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	for i, keyID := range keyIDs {
+		if _, ok := cfg.providedPublicKeys[keyID]; !ok {
+			cfg.publicKeyCache().Add(keyID, keys[i])
+		}
+	}
+
+	cfg.recordCacheStats()
+
+	return keys, nil
+}
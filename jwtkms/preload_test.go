@@ -0,0 +1,96 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestPreloadKeys(t *testing.T) {
+	registered(t)
+
+	client := &getPublicKeyCountingKMS{MockKMS: mockkms.NewMockKMS()}
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	cfg := NewConfig(client, ids[0], WithVerificationKeyIDs(ids[1:]...))
+	if err := cfg.PreloadKeys(context.Background()); err != nil {
+		t.Fatalf("Error preloading keys: %v", err)
+	}
+
+	if client.calls.Load() != 3 {
+		t.Fatalf("GetPublicKey calls = %d, want 3", client.calls.Load())
+	}
+
+	for _, id := range ids {
+		signed, err := jwt.New(SigningMethodECDSA256).SignedString(cfg.WithKeyID(id))
+		if err != nil {
+			t.Fatalf("Error signing token: %v", err)
+		}
+
+		if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+			t.Fatalf("Error verifying token: %v", err)
+		}
+	}
+
+	if client.calls.Load() != 3 {
+		t.Errorf("GetPublicKey calls = %d after verifying, want 3", client.calls.Load())
+	}
+
+	err := cfg.PreloadKeys(context.Background(), ids[0], "unknown-key")
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("err = %v, want %v", err, ErrKeyNotFound)
+	}
+
+	if client.calls.Load() != 4 {
+		t.Errorf("GetPublicKey calls = %d, want 4", client.calls.Load())
+	}
+}
+
+func TestRefreshPublicKeys(t *testing.T) {
+	client := &getPublicKeyCountingKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id)
+	if err := cfg.PreloadKeys(context.Background()); err != nil {
+		t.Fatalf("Error preloading keys: %v", err)
+	}
+
+	cached := cfg.knownPublicKey()
+
+	keys, err := cfg.RefreshPublicKeys(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Error refreshing keys: %v", err)
+	}
+
+	if client.calls.Load() != 2 {
+		t.Errorf("GetPublicKey calls = %d, want 2", client.calls.Load())
+	}
+
+	if len(keys) != 1 || keys[0] == cached || cfg.knownPublicKey() != keys[0] {
+		t.Errorf("refreshed key did not replace the cached one")
+	}
+
+	// a failed refresh keeps every cached key
+	if _, err := cfg.RefreshPublicKeys(context.Background(), id, "unknown-key"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("err = %v, want %v", err, ErrKeyNotFound)
+	}
+
+	if cfg.knownPublicKey() != keys[0] {
+		t.Errorf("cached key replaced by a failed refresh")
+	}
+}
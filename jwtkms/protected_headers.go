@@ -0,0 +1,115 @@
+package jwtkms
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrReservedHeader is returned when signing with protected headers that would replace a header set by the signing
+// method or derived from the Config.
+var ErrReservedHeader = errors.New("header is reserved")
+
+// reservedHeaders are the headers WithProtectedHeaders and Builder.WithProtectedHeaders can not set, with the option
+// setting them instead.
+var reservedHeaders = map[string]string{
+	"alg":      "the signing method",
+	"kid":      "WithKeyIDHeader",
+	"typ":      "WithTokenType",
+	"x5c":      "WithCertificateChain",
+	"x5t#S256": "WithCertificateChain",
+	"b64":      "SignDetached",
+}
+
+// registeredHeaders are the header names registered by RFC 7515 section 4.1, which must not be listed in crit.
+var registeredHeaders = []string{"alg", "jku", "jwk", "kid", "x5u", "x5c", "x5t", "x5t#S256", "typ", "cty", "crit"}
+
+// WithProtectedHeaders makes the Config add headers to the protected header of the tokens it signs, see
+// Config.WithProtectedHeaders.
+func WithProtectedHeaders(headers map[string]interface{}) Option {
+	return func(c *Config) {
+		c.protectedHeaders = maps.Clone(c.protectedHeaders)
+		if c.protectedHeaders == nil {
+			c.protectedHeaders = make(map[string]interface{}, len(headers))
+		}
+
+		maps.Copy(c.protectedHeaders, headers)
+	}
+}
+
+// WithProtectedHeaders returns a copy of Config whose SignedString, Builders, SignDetached, SignJSON and JWE encryption
+// add headers, e.g. crit, url or vendor extensions, to the protected header unless the token already has them. The
+// headers are merged with those of previous calls.
+//
+// Headers set by the signing method or derived from the Config, alg, kid, typ, x5c, x5t#S256 and b64, are reserved:
+// Validate and signing fail with ErrReservedHeader. A crit header must list only extension headers the token has.
+func (c *Config) WithProtectedHeaders(headers map[string]interface{}) *Config {
+	return c.with(WithProtectedHeaders(headers))
+}
+
+// WithProtectedHeaders returns a copy of the Builder adding headers like Config.WithProtectedHeaders. Unlike
+// WithHeader, reserved headers fail Sign with ErrReservedHeader.
+func (b Builder) WithProtectedHeaders(headers map[string]interface{}) Builder {
+	b.protectedHeaders = maps.Clone(b.protectedHeaders)
+	if b.protectedHeaders == nil {
+		b.protectedHeaders = make(map[string]interface{}, len(headers))
+	}
+
+	maps.Copy(b.protectedHeaders, headers)
+
+	return b
+}
+
+// checkProtectedHeaders fails if headers set a reserved header.
+func checkProtectedHeaders(headers map[string]interface{}) error {
+	names := slices.Collect(maps.Keys(headers))
+	sort.Strings(names)
+
+	for _, name := range names {
+		if option, ok := reservedHeaders[name]; ok {
+			return fmt.Errorf("%w: %q is set by %s", ErrReservedHeader, name, option)
+		}
+	}
+
+	return nil
+}
+
+// setProtectedHeaders adds headers to the header of token that it does not have yet.
+func setProtectedHeaders(token *jwt.Token, headers map[string]interface{}) error {
+	if err := checkProtectedHeaders(headers); err != nil {
+		return err
+	}
+
+	for name, value := range headers {
+		if _, ok := token.Header[name]; !ok {
+			token.Header[name] = value
+		}
+	}
+
+	return nil
+}
+
+// checkCritHeader fails if the crit header of header lists registered headers or headers header does not have, RFC
+// 7515 section 4.1.11.
+func checkCritHeader(header map[string]interface{}) error {
+	crit, err := critHeader(header)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range crit {
+		if slices.Contains(registeredHeaders, name) {
+			return fmt.Errorf("%w: crit header lists the registered header %q", jwt.ErrTokenMalformed, name)
+		}
+
+		if _, ok := header[name]; !ok {
+			return fmt.Errorf("%w: critical header %q is missing", jwt.ErrTokenMalformed, name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestWithProtectedHeaders(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id).
+		WithProtectedHeaders(map[string]interface{}{"url": "https://example.com/orders"}).
+		WithProtectedHeaders(map[string]interface{}{"crit": []string{"vnd"}, "vnd": "value"})
+
+	signed, err := NewBuilder(cfg).WithProtectedHeaders(map[string]interface{}{"nonce": "abc"}).Sign(ctx)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	// jwt.Parser does not check crit, the extension is understood by the caller
+	token, err := cfg.VerifyContext(ctx, signed, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	for name, want := range map[string]interface{}{"url": "https://example.com/orders", "vnd": "value", "nonce": "abc", "alg": "ES256"} {
+		if token.Header[name] != want {
+			t.Errorf("header %s = %v, want %v", name, token.Header[name], want)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		sign    func() (string, error)
+		wantErr error
+	}{
+		{name: "config alg", sign: func() (string, error) {
+			return SignedString(NewConfig(client, id).WithProtectedHeaders(map[string]interface{}{"alg": "none"}), nil, nil)
+		}, wantErr: ErrReservedHeader},
+		{name: "config kid", sign: func() (string, error) {
+			return SignedString(NewConfig(client, id).WithProtectedHeaders(map[string]interface{}{"kid": "other"}), nil, nil)
+		}, wantErr: ErrReservedHeader},
+		{name: "builder typ", sign: func() (string, error) {
+			return NewBuilder(NewConfig(client, id)).WithProtectedHeaders(map[string]interface{}{"typ": "at+jwt"}).Sign(ctx)
+		}, wantErr: ErrReservedHeader},
+		{name: "crit lists registered header", sign: func() (string, error) {
+			return SignedString(NewConfig(client, id).WithProtectedHeaders(map[string]interface{}{"crit": []string{"kid"}}), nil, nil)
+		}, wantErr: jwt.ErrTokenMalformed},
+		{name: "crit header missing", sign: func() (string, error) {
+			return SignedString(NewConfig(client, id).WithProtectedHeaders(map[string]interface{}{"crit": []string{"vnd"}}), nil, nil)
+		}, wantErr: jwt.ErrTokenMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.sign(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := NewConfig(client, id, WithProtectedHeaders(map[string]interface{}{"x5c": nil})).Validate(); !errors.Is(err, ErrReservedHeader) {
+		t.Errorf("err = %v, want %v", err, ErrReservedHeader)
+	}
+}
@@ -0,0 +1,124 @@
+package jwtkms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// WithPublicKey makes the Config verify signatures of keyID locally with pub instead of fetching the public key with
+// KMS GetPublicKey, for verification-only services that are not granted kms:GetPublicKey. keyID is the Config's key
+// id or one of its verification key ids. pub is an *rsa.PublicKey or *ecdsa.PublicKey, see ParsePublicKeyPEM.
+//
+// Signatures are still verified with KMS if the Config verifies with KMS.
+func WithPublicKey(keyID string, pub crypto.PublicKey) Option {
+	return func(c *Config) {
+		keySpec, algorithms := publicKeyMetadata(pub)
+
+		key := &CachedPublicKey{
+			PublicKey:         pub,
+			KeySpec:           keySpec,
+			SigningAlgorithms: algorithms,
+		}
+
+		if strings.HasPrefix(keyID, "arn:") && strings.Contains(keyID, ":key/") {
+			key.KeyARN = keyID
+		}
+
+		// copied, the map may be shared with the Config this Config is derived from
+		c.providedPublicKeys = maps.Clone(c.providedPublicKeys)
+		if c.providedPublicKeys == nil {
+			c.providedPublicKeys = make(map[string]*CachedPublicKey)
+		}
+
+		c.providedPublicKeys[keyID] = key
+	}
+}
+
+// ParsePublicKeyPEM parses the first PEM block of data, a PKIX PUBLIC KEY as written by the AWS CLI from the output of
+// GetPublicKey, an RSA PUBLIC KEY or a CERTIFICATE, whose public key is returned.
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return ParsePublicKey(block.Bytes)
+
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing certificate: %w", err)
+		}
+
+		return cert.PublicKey, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+// knownPublicKey returns the provided or cached public key of the configured key without fetching it, nil if there
+// is none.
+func (c *Config) knownPublicKey() *CachedPublicKey {
+	if key, ok := c.providedPublicKeys[c.kmsKeyID]; ok {
+		return key
+	}
+
+	return c.publicKeyCache().Get(c.kmsKeyID)
+}
+
+// publicKeyMetadata returns the KMS key spec and signing algorithms of a key like pub, none if unknown.
+func publicKeyMetadata(pub crypto.PublicKey) (types.KeySpec, []types.SigningAlgorithmSpec) {
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		switch pub.Curve.Params().Name {
+		case "P-256":
+			return types.KeySpecEccNistP256, []types.SigningAlgorithmSpec{types.SigningAlgorithmSpecEcdsaSha256}
+		case "P-384":
+			return types.KeySpecEccNistP384, []types.SigningAlgorithmSpec{types.SigningAlgorithmSpecEcdsaSha384}
+		case "P-521":
+			return types.KeySpecEccNistP521, []types.SigningAlgorithmSpec{types.SigningAlgorithmSpecEcdsaSha512}
+		case "secp256k1":
+			return types.KeySpecEccSecgP256k1, []types.SigningAlgorithmSpec{types.SigningAlgorithmSpecEcdsaSha256}
+		case "sm2p256v1":
+			return types.KeySpecSm2, []types.SigningAlgorithmSpec{types.SigningAlgorithmSpecSm2dsa}
+		}
+
+	case *rsa.PublicKey:
+		algorithms := []types.SigningAlgorithmSpec{
+			types.SigningAlgorithmSpecRsassaPkcs1V15Sha256,
+			types.SigningAlgorithmSpecRsassaPkcs1V15Sha384,
+			types.SigningAlgorithmSpecRsassaPkcs1V15Sha512,
+			types.SigningAlgorithmSpecRsassaPssSha256,
+			types.SigningAlgorithmSpecRsassaPssSha384,
+			types.SigningAlgorithmSpecRsassaPssSha512,
+		}
+
+		switch pub.Size() * 8 {
+		case 2048:
+			return types.KeySpecRsa2048, algorithms
+		case 3072:
+			return types.KeySpecRsa3072, algorithms
+		case 4096:
+			return types.KeySpecRsa4096, algorithms
+		}
+
+		return "", algorithms
+	}
+
+	return "", nil
+}
@@ -0,0 +1,143 @@
+package jwtkms
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// publicKeyPEM returns the PEM encoded public key of the KMS key id.
+func publicKeyPEM(t *testing.T, client *mockkms.MockKMS, id string) []byte {
+	t.Helper()
+
+	out, err := client.GetPublicKey(context.Background(), &kms.GetPublicKeyInput{KeyId: aws.String(id)})
+	if err != nil {
+		t.Fatalf("Error getting public key: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: out.PublicKey})
+}
+
+func TestWithPublicKey(t *testing.T) {
+	registered(t)
+
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		method  jwt.SigningMethod
+	}{
+		{name: "ES256", keyType: mockkms.KeyTypeECCNISTP256, method: SigningMethodECDSA256},
+		{name: "ES256K", keyType: mockkms.KeyTypeECCSECGP256K1, method: SigningMethodES256K},
+		{name: "RS256", keyType: mockkms.KeyTypeRSA2048, method: SigningMethodRS256},
+		{name: "PS256", keyType: mockkms.KeyTypeRSA2048, method: SigningMethodPS256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := mockkms.NewMockKMS()
+			id, err := signer.GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			signed, err := jwt.New(tt.method).SignedString(NewConfig(signer, id))
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			pub, err := ParsePublicKeyPEM(publicKeyPEM(t, signer, id))
+			if err != nil {
+				t.Fatalf("Error parsing public key: %v", err)
+			}
+
+			// the verifier is not allowed to call GetPublicKey
+			verifier := &getPublicKeyCountingKMS{MockKMS: mockkms.NewMockKMS()}
+			cfg := NewConfig(verifier, id, WithPublicKey(id, pub))
+
+			if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+				t.Fatalf("Error verifying token: %v", err)
+			}
+
+			if verifier.calls.Load() != 0 {
+				t.Errorf("GetPublicKey calls = %d, want 0", verifier.calls.Load())
+			}
+		})
+	}
+}
+
+func TestWithPublicKeyRotation(t *testing.T) {
+	registered(t)
+
+	client := mockkms.NewMockKMS()
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	oldPub, err := ParsePublicKeyPEM(publicKeyPEM(t, client, ids[0]))
+	if err != nil {
+		t.Fatalf("Error parsing public key: %v", err)
+	}
+
+	signed, err := jwt.New(SigningMethodECDSA256).SignedString(NewConfig(client, ids[0]))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	client.DisableKey(ids[0])
+
+	cfg := NewConfig(client, ids[1], WithVerificationKeyIDs(ids[0]), WithPublicKey(ids[0], oldPub))
+	if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+		t.Fatalf("Error verifying token with the provided public key of the previous key: %v", err)
+	}
+}
+
+func TestWithPublicKeyIncompatible(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	pub, err := ParsePublicKeyPEM(publicKeyPEM(t, client, id))
+	if err != nil {
+		t.Fatalf("Error parsing public key: %v", err)
+	}
+
+	cfg := NewConfig(client, id, WithPublicKey(id, pub))
+	if err := cfg.ValidateFor(SigningMethodECDSA256); !errors.Is(err, ErrIncompatibleKeySpec) {
+		t.Errorf("err = %v, want %v", err, ErrIncompatibleKeySpec)
+	}
+}
+
+func TestParsePublicKeyPEM(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "no PEM", data: []byte("not a pem"), wantErr: true},
+		{name: "private key", data: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{0}}), wantErr: true},
+		{name: "malformed key", data: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte{0}}), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParsePublicKeyPEM(tt.data); (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
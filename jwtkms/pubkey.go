@@ -0,0 +1,205 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/emmansun/gmsm/sm2"
+)
+
+var (
+	oidPublicKeyECDSA  = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidNamedCurveP256K = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	oidNamedCurveSM2   = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+)
+
+// subjectPublicKeyInfo is the DER structure KMS GetPublicKey returns, as defined in RFC 5280.
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// PublicKey returns the public key of the KMS key keyID, provided or fetched with GetPublicKey and cached like for
+// verification, using ctx for the KMS calls. The calls go through the retries, circuit breaker and call timeout of
+// the Config.
+func (c *Config) PublicKey(ctx context.Context, keyID string) (*CachedPublicKey, error) {
+	return getCachedPublicKey(c.WithContext(ctx).WithKeyID(keyID))
+}
+
+// getPublicKey returns the public key of the configured KMS key, fetching and caching it on first use.
+func getPublicKey(cfg *Config) (crypto.PublicKey, error) {
+	cached, err := getCachedPublicKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return cached.PublicKey, nil
+}
+
+// getCachedPublicKey returns the provided public key or cache entry of the configured KMS key, fetching it on first
+// use. Concurrent misses of Configs sharing a singleflight group share a single GetPublicKey call.
+func getCachedPublicKey(cfg *Config) (*CachedPublicKey, error) {
+	if provided, ok := cfg.providedPublicKeys[cfg.kmsKeyID]; ok {
+		return provided, nil
+	}
+
+	start := time.Now()
+
+	cached := cfg.publicKeyCache().Get(cfg.kmsKeyID)
+	if cached != nil && !cached.expired(start) {
+		cfg.countCacheLookup(true)
+		cfg.recordGetPublicKey(true, start, nil)
+		_, span := cfg.startSpan("jwtkms.GetPublicKey", attributeCacheHit.Bool(true))
+		span.End()
+
+		return cached, nil
+	}
+
+	cfg.countCacheLookup(false)
+
+	if cfg.publicKeyFailures != nil {
+		if err := cfg.publicKeyFailures.get(cfg.kmsKeyID, start); err != nil {
+			cfg.recordNegativeCacheHit(err)
+			return nil, err
+		}
+	}
+
+	cfg.log(slog.LevelDebug, "public key cache miss")
+
+	return fetchPublicKeyOnce(cfg, start)
+}
+
+// fetchPublicKeyOnce runs fetchPublicKey, sharing the call with the concurrent callers of the Config's singleflight
+// group. The shared call runs detached from the context of the caller starting it, bounded by the call timeout of the
+// Config, so a canceled caller does not fail the others; every caller stops waiting when its own context is done.
+func fetchPublicKeyOnce(cfg *Config, start time.Time) (*CachedPublicKey, error) {
+	if cfg.publicKeyFetches == nil || cfg.ctx == nil {
+		return fetchPublicKey(cfg, start)
+	}
+
+	detached := cfg.WithContext(context.WithoutCancel(cfg.ctx))
+	ch := cfg.publicKeyFetches.DoChan(cfg.kmsKeyID, func() (interface{}, error) {
+		return fetchPublicKey(detached, start)
+	})
+
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		return res.Val.(*CachedPublicKey), nil
+	case <-cfg.ctx.Done():
+		return nil, fmt.Errorf("getting public key: %w", cfg.ctx.Err())
+	}
+}
+
+// fetchPublicKey fetches the public key of the configured KMS key with KMS GetPublicKey and caches it.
+func fetchPublicKey(cfg *Config, start time.Time) (*CachedPublicKey, error) {
+	cached, err := requestPublicKey(cfg, start)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.publicKeyCache().Add(cfg.kmsKeyID, cached)
+	cfg.recordCacheStats()
+
+	return cached, nil
+}
+
+// requestPublicKey fetches the public key of the configured KMS key with KMS GetPublicKey, without caching it.
+func requestPublicKey(cfg *Config, start time.Time) (*CachedPublicKey, error) {
+	spanCfg, span := cfg.startSpan("jwtkms.GetPublicKey", attributeCacheHit.Bool(false))
+	getPubKeyOutput, err := spanCfg.kmsGetPublicKey(&kms.GetPublicKeyInput{
+		KeyId: aws.String(cfg.kmsKeyID),
+	})
+	cfg.recordGetPublicKey(false, start, err)
+	if err != nil {
+		endSpan(span, err)
+
+		err = fmt.Errorf("getting public key: %w", err)
+		if cfg.publicKeyFailures != nil {
+			cfg.publicKeyFailures.add(cfg.kmsKeyID, err, time.Now())
+		}
+
+		return nil, err
+	}
+
+	span.SetAttributes(attributeKeyARN.String(aws.ToString(getPubKeyOutput.KeyId)))
+	span.End()
+
+	publicKey, err := ParsePublicKey(getPubKeyOutput.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key: %w", err)
+	}
+
+	cached := &CachedPublicKey{
+		PublicKey:         publicKey,
+		DER:               getPubKeyOutput.PublicKey,
+		KeyARN:            aws.ToString(getPubKeyOutput.KeyId),
+		KeySpec:           getPubKeyOutput.KeySpec,
+		SigningAlgorithms: getPubKeyOutput.SigningAlgorithms,
+		FetchedAt:         time.Now(),
+	}
+
+	if cfg.publicKeyTTL > 0 {
+		cached.ExpiresAt = cached.FetchedAt.Add(cfg.publicKeyTTL)
+	}
+
+	return cached, nil
+}
+
+// ParsePublicKey parses a DER encoded SubjectPublicKeyInfo as returned by KMS GetPublicKey.
+//
+// On top of what x509.ParsePKIXPublicKey supports it handles secp256k1 and SM2 keys, both returned as *ecdsa.PublicKey.
+func ParsePublicKey(der []byte) (crypto.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err == nil {
+		return key, nil
+	}
+
+	var spki subjectPublicKeyInfo
+	if rest, asn1Err := asn1.Unmarshal(der, &spki); asn1Err != nil || len(rest) > 0 {
+		return nil, err
+	}
+
+	if !spki.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, err
+	}
+
+	var namedCurve asn1.ObjectIdentifier
+	if _, asn1Err := asn1.Unmarshal(spki.Algorithm.Parameters.FullBytes, &namedCurve); asn1Err != nil {
+		return nil, err
+	}
+
+	switch {
+	case namedCurve.Equal(oidNamedCurveP256K):
+		pub, err := secp256k1.ParsePubKey(spki.PublicKey.RightAlign())
+		if err != nil {
+			return nil, fmt.Errorf("parsing secp256k1 public key: %w", err)
+		}
+
+		return pub.ToECDSA(), nil
+
+	case namedCurve.Equal(oidNamedCurveSM2):
+		pub, err := sm2.NewPublicKey(spki.PublicKey.RightAlign())
+		if err != nil {
+			return nil, fmt.Errorf("parsing sm2 public key: %w", err)
+		}
+
+		return pub, nil
+
+	default:
+		return nil, errors.New("unsupported elliptic curve")
+	}
+}
@@ -1,31 +1,252 @@
 package jwtkms
 
 import (
+	"container/list"
 	"crypto"
 	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
 )
 
+// CachedPublicKey is a public key returned by KMS GetPublicKey together with the metadata of its key.
+type CachedPublicKey struct {
+	PublicKey crypto.PublicKey
+
+	// DER is the DER encoded SubjectPublicKeyInfo returned by KMS, for caches serializing public keys; it can be
+	// parsed with ParsePublicKey.
+	DER []byte
+
+	KeyARN            string
+	KeySpec           types.KeySpec
+	SigningAlgorithms []types.SigningAlgorithmSpec
+
+	// FetchedAt is when the public key was fetched from KMS.
+	FetchedAt time.Time
+
+	// ExpiresAt is when the public key has to be fetched again, never if zero.
+	ExpiresAt time.Time
+}
+
+// expired reports whether k has to be fetched again at now.
+func (k *CachedPublicKey) expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+// PublicKeyCache stores the public keys fetched from KMS, keyed by the key id of the Config, which may be a key id,
+// key ARN, alias name or alias ARN. Implementations must be safe for concurrent use.
+type PublicKeyCache interface {
+	// Get returns the cached public key of keyID, or nil if there is none.
+	Get(keyID string) *CachedPublicKey
+
+	// Add caches the public key of keyID.
+	Add(keyID string, key *CachedPublicKey)
+
+	// Delete removes the public key of keyID from the cache.
+	Delete(keyID string)
+}
+
+// defaultPublicKeyCacheSize is the MaxEntries of PublicKeyCacheSettings if not set, and of the shared cache.
+const defaultPublicKeyCacheSize = 10000
+
+// PublicKeyCacheSettings configures the in-memory PublicKeyCache returned by NewPublicKeyCache.
+type PublicKeyCacheSettings struct {
+	// MaxEntries caps the number of cached public keys, evicting the least recently used ones. Defaults to 10000.
+	MaxEntries int
+
+	// OnEvict, if set, is called with public keys evicted to stay under MaxEntries.
+	OnEvict func(keyID string, key *CachedPublicKey)
+}
+
+// pubKeyCache is a size-bounded LRU PublicKeyCache.
 type pubKeyCache struct {
-	pubKeys map[string]crypto.PublicKey
-	mutex   sync.RWMutex
+	settings PublicKeyCacheSettings
+
+	mutex   sync.Mutex
+	pubKeys map[string]*list.Element
+	lru     *list.List
+	stats   CacheStats
 }
 
-func newPubKeyCache() *pubKeyCache {
+type pubKeyCacheEntry struct {
+	keyID string
+	key   *CachedPublicKey
+}
+
+// NewPublicKeyCache returns an empty in-memory PublicKeyCache, like the one every Config created by NewConfig has.
+func NewPublicKeyCache(settings PublicKeyCacheSettings) PublicKeyCache {
+	return newPubKeyCache(settings)
+}
+
+func newPubKeyCache(settings PublicKeyCacheSettings) *pubKeyCache {
+	if settings.MaxEntries <= 0 {
+		settings.MaxEntries = defaultPublicKeyCacheSize
+	}
+
 	return &pubKeyCache{
-		pubKeys: make(map[string]crypto.PublicKey),
+		settings: settings,
+		pubKeys:  make(map[string]*list.Element),
+		lru:      list.New(),
+		stats:    CacheStats{Keys: make(map[string]KeyCacheStats)},
 	}
 }
 
-func (c *pubKeyCache) Add(keyID string, key crypto.PublicKey) {
+func (c *pubKeyCache) Add(keyID string, key *CachedPublicKey) {
+	var evicted []*pubKeyCacheEntry
+
+	c.mutex.Lock()
+
+	if e, ok := c.pubKeys[keyID]; ok {
+		e.Value.(*pubKeyCacheEntry).key = key
+		c.lru.MoveToFront(e)
+	} else {
+		c.pubKeys[keyID] = c.lru.PushFront(&pubKeyCacheEntry{keyID: keyID, key: key})
+	}
+
+	for c.lru.Len() > c.settings.MaxEntries {
+		entry := c.lru.Remove(c.lru.Back()).(*pubKeyCacheEntry)
+		delete(c.pubKeys, entry.keyID)
+		evicted = append(evicted, entry)
+
+		c.stats.Evictions++
+		keyStats := c.stats.Keys[entry.keyID]
+		keyStats.Evictions++
+		c.stats.Keys[entry.keyID] = keyStats
+	}
+
+	c.mutex.Unlock()
+
+	if c.settings.OnEvict != nil {
+		for _, entry := range evicted {
+			c.settings.OnEvict(entry.keyID, entry.key)
+		}
+	}
+}
+
+func (c *pubKeyCache) Get(keyID string) *CachedPublicKey {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	c.pubKeys[keyID] = key
+	e, ok := c.pubKeys[keyID]
+	if !ok {
+		return nil
+	}
+
+	c.lru.MoveToFront(e)
+
+	return e.Value.(*pubKeyCacheEntry).key
 }
 
-func (c *pubKeyCache) Get(keyID string) crypto.PublicKey {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+// CacheStats implements PublicKeyCacheStatsReporter, with the number of entries and evictions.
+func (c *pubKeyCache) CacheStats() CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	stats.Keys = make(map[string]KeyCacheStats, len(c.stats.Keys))
+	for keyID, keyStats := range c.stats.Keys {
+		stats.Keys[keyID] = keyStats
+	}
+
+	return stats
+}
+
+// KeyIDs implements PublicKeyCacheLister, listing the key ids from the most to the least recently used.
+func (c *pubKeyCache) KeyIDs() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keyIDs := make([]string, 0, c.lru.Len())
+	for e := c.lru.Front(); e != nil; e = e.Next() {
+		keyIDs = append(keyIDs, e.Value.(*pubKeyCacheEntry).keyID)
+	}
+
+	return keyIDs
+}
+
+func (c *pubKeyCache) Delete(keyID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if e, ok := c.pubKeys[keyID]; ok {
+		c.lru.Remove(e)
+		delete(c.pubKeys, keyID)
+	}
+
+	delete(c.stats.Keys, keyID)
+}
+
+// WithPublicKeyCache makes the Config cache public keys in cache instead of a cache of its own, e.g. to share it
+// between Configs.
+func WithPublicKeyCache(cache PublicKeyCache) Option {
+	return func(c *Config) {
+		c.pubKeyCache = cache
+		c.publicKeyFetches = new(singleflight.Group)
+		c.cacheLookups = newLookupCounter()
+	}
+}
+
+// WithSharedPublicKeyCache makes the Config cache public keys in the cache shared by the package, for Configs created
+// per request.
+func WithSharedPublicKeyCache() Option {
+	return func(c *Config) {
+		c.pubKeyCache = pubkeyCache
+		c.publicKeyFetches = &sharedPublicKeyFetches
+		c.cacheLookups = sharedCacheLookups
+		c.keyMetadataCache = sharedKeyMetadata
+	}
+}
+
+// Invalidate removes the public key of keyID, or the remembered failure to fetch it, from the cache of the Config,
+// so the next use fetches it again.
+func (c *Config) Invalidate(keyID string) {
+	c.publicKeyCache().Delete(keyID)
+
+	if c.publicKeyFailures != nil {
+		c.publicKeyFailures.delete(keyID)
+	}
+
+	if c.cacheLookups != nil {
+		c.cacheLookups.delete(keyID)
+	}
+
+	if c.keyMetadataCache != nil {
+		c.keyMetadataCache.delete(keyID)
+	}
+}
+
+// WithoutPublicKeyCache makes the Config fetch and parse the public key with KMS GetPublicKey on every use, for
+// environments where key material must not be cached in process memory.
+func WithoutPublicKeyCache() Option {
+	return WithPublicKeyCache(noPublicKeyCache{})
+}
+
+// noPublicKeyCache is a PublicKeyCache that caches nothing.
+type noPublicKeyCache struct{}
+
+func (noPublicKeyCache) Get(string) *CachedPublicKey { return nil }
+
+func (noPublicKeyCache) Add(string, *CachedPublicKey) {}
+
+func (noPublicKeyCache) Delete(string) {}
+
+// WithPublicKeyTTL makes the Config fetch cached public keys again once they are older than ttl, so a re-imported
+// or replaced key stops verifying with stale key material. Expired keys are refreshed lazily, by the first lookup
+// after they expire, and an error refreshing them fails the lookup. Zero, the default, caches public keys forever.
+func WithPublicKeyTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.publicKeyTTL = ttl
+	}
+}
+
+// publicKeyCache returns the public key cache of the Config.
+func (c *Config) publicKeyCache() PublicKeyCache {
+	if c.pubKeyCache != nil {
+		return c.pubKeyCache
+	}
 
-	return c.pubKeys[keyID]
+	return pubkeyCache
 }
@@ -0,0 +1,430 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+type countingCache struct {
+	PublicKeyCache
+	gets, adds atomic.Int32
+}
+
+func (c *countingCache) Get(keyID string) *CachedPublicKey {
+	c.gets.Add(1)
+	return c.PublicKeyCache.Get(keyID)
+}
+
+func (c *countingCache) Add(keyID string, key *CachedPublicKey) {
+	c.adds.Add(1)
+	c.PublicKeyCache.Add(keyID, key)
+}
+
+type getPublicKeyCountingKMS struct {
+	*mockkms.MockKMS
+	calls atomic.Int32
+}
+
+func (c *getPublicKeyCountingKMS) GetPublicKey(ctx context.Context, in *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error) {
+	c.calls.Add(1)
+	return c.MockKMS.GetPublicKey(ctx, in, optFns...)
+}
+
+func TestPublicKeyCache(t *testing.T) {
+	registered(t)
+
+	client := &getPublicKeyCountingKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cache := &countingCache{PublicKeyCache: NewPublicKeyCache(PublicKeyCacheSettings{})}
+	cfg := NewConfig(client, id, WithPublicKeyCache(cache))
+
+	signed, err := jwt.New(SigningMethodECDSA256).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+			t.Fatalf("Error verifying token: %v", err)
+		}
+	}
+
+	if cache.adds.Load() != 1 || cache.gets.Load() < 2 || client.calls.Load() != 1 {
+		t.Errorf("adds = %d, gets = %d, GetPublicKey calls = %d, want 1, >= 2, 1", cache.adds.Load(), cache.gets.Load(), client.calls.Load())
+	}
+
+	if pubkeyCache.Get(id) != nil {
+		t.Errorf("public key added to the shared cache")
+	}
+
+	// a Config sharing the cache does not fetch the key again
+	if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return NewConfig(client, id, WithPublicKeyCache(cache)), nil }); err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	cache.Delete(id)
+
+	if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	if client.calls.Load() != 2 {
+		t.Errorf("GetPublicKey calls = %d, want 2", client.calls.Load())
+	}
+}
+
+func TestPublicKeyTTL(t *testing.T) {
+	registered(t)
+
+	client := &getPublicKeyCountingKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cache := NewPublicKeyCache(PublicKeyCacheSettings{})
+	cfg := NewConfig(client, id, WithPublicKeyCache(cache), WithPublicKeyTTL(50*time.Millisecond))
+
+	signed, err := jwt.New(SigningMethodECDSA256).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	verify := func() {
+		t.Helper()
+		if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+			t.Fatalf("Error verifying token: %v", err)
+		}
+	}
+
+	verify()
+	verify()
+
+	if client.calls.Load() != 1 {
+		t.Fatalf("GetPublicKey calls = %d, want 1", client.calls.Load())
+	}
+
+	if cached := cache.Get(id); cached == nil || cached.ExpiresAt.IsZero() {
+		t.Fatalf("cached public key has no expiry: %+v", cached)
+	}
+
+	time.Sleep(75 * time.Millisecond)
+	verify()
+
+	if client.calls.Load() != 2 {
+		t.Errorf("GetPublicKey calls = %d, want 2", client.calls.Load())
+	}
+
+	client.DisableKey(id)
+	time.Sleep(75 * time.Millisecond)
+
+	if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); !errors.Is(err, ErrKeyDisabled) {
+		t.Errorf("err = %v, want %v", err, ErrKeyDisabled)
+	}
+}
+
+func TestPublicKeyCacheLRU(t *testing.T) {
+	var evicted []string
+	cache := NewPublicKeyCache(PublicKeyCacheSettings{
+		MaxEntries: 2,
+		OnEvict: func(keyID string, _ *CachedPublicKey) {
+			evicted = append(evicted, keyID)
+		},
+	})
+
+	cache.Add("a", &CachedPublicKey{KeyARN: "a"})
+	cache.Add("b", &CachedPublicKey{KeyARN: "b"})
+
+	// a is now more recently used than b
+	if cache.Get("a") == nil {
+		t.Fatalf("a not cached")
+	}
+
+	cache.Add("c", &CachedPublicKey{KeyARN: "c"})
+
+	if cache.Get("b") != nil {
+		t.Errorf("b not evicted")
+	}
+
+	if cache.Get("a") == nil || cache.Get("c") == nil {
+		t.Errorf("a or c evicted")
+	}
+
+	// replacing an entry does not evict
+	cache.Add("c", &CachedPublicKey{KeyARN: "c2"})
+	if got := cache.Get("c"); got == nil || got.KeyARN != "c2" {
+		t.Errorf("c = %+v, want c2", got)
+	}
+
+	cache.Delete("a")
+	cache.Add("d", &CachedPublicKey{KeyARN: "d"})
+
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Errorf("evicted = %v, want [b]", evicted)
+	}
+}
+
+func TestWithoutPublicKeyCache(t *testing.T) {
+	registered(t)
+
+	client := &getPublicKeyCountingKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id, WithoutPublicKeyCache())
+
+	signed, err := jwt.New(SigningMethodRS256).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	// signing fetched the key's metadata
+	client.calls.Store(0)
+
+	for i := 0; i < 3; i++ {
+		if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+			t.Fatalf("Error verifying token: %v", err)
+		}
+	}
+
+	if client.calls.Load() != 3 {
+		t.Errorf("GetPublicKey calls = %d, want 3", client.calls.Load())
+	}
+
+	if pubkeyCache.Get(id) != nil {
+		t.Errorf("public key added to the shared cache")
+	}
+}
+
+func TestPerConfigPublicKeyCache(t *testing.T) {
+	registered(t)
+
+	client := &getPublicKeyCountingKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	signed, err := jwt.New(SigningMethodECDSA256).SignedString(NewConfig(client, id))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	verify := func(cfg *Config) {
+		t.Helper()
+		if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+			t.Fatalf("Error verifying token: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		verify    func()
+		wantCalls int32
+	}{
+		{
+			name: "configs do not share their cache",
+			verify: func() {
+				verify(NewConfig(client, id))
+				verify(NewConfig(client, id))
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "derived configs share the cache",
+			verify: func() {
+				cfg := NewConfig(client, id)
+				verify(cfg)
+				verify(cfg.WithContext(context.Background()))
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "shared cache",
+			verify: func() {
+				verify(NewConfig(client, id, WithSharedPublicKeyCache()))
+				verify(NewConfig(client, id, WithSharedPublicKeyCache()))
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "invalidate",
+			verify: func() {
+				cfg := NewConfig(client, id)
+				verify(cfg)
+				cfg.Invalidate(id)
+				verify(cfg)
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pubkeyCache.Delete(id)
+			client.calls.Store(0)
+
+			tt.verify()
+
+			if client.calls.Load() != tt.wantCalls {
+				t.Errorf("GetPublicKey calls = %d, want %d", client.calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestNewKMSConfigSharedPublicKeyCache(t *testing.T) {
+	registered(t)
+
+	client := &getPublicKeyCountingKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	signed, err := jwt.New(SigningMethodECDSA256).SignedString(NewConfig(client, id))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	client.calls.Store(0)
+
+	// the legacy pattern, a Config built per verification
+	keyfunc := func(*jwt.Token) (interface{}, error) {
+		return NewKMSConfig(client, id, false), nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := jwt.Parse(signed, keyfunc); err != nil {
+			t.Fatalf("Error verifying token: %v", err)
+		}
+	}
+
+	if client.calls.Load() != 1 {
+		t.Errorf("GetPublicKey calls = %d, want 1", client.calls.Load())
+	}
+}
+
+type slowPublicKeyKMS struct {
+	getPublicKeyCountingKMS
+}
+
+func (c *slowPublicKeyKMS) GetPublicKey(ctx context.Context, in *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error) {
+	time.Sleep(50 * time.Millisecond)
+	return c.getPublicKeyCountingKMS.GetPublicKey(ctx, in, optFns...)
+}
+
+func TestPublicKeyFetchDeduplication(t *testing.T) {
+	registered(t)
+
+	client := &slowPublicKeyKMS{getPublicKeyCountingKMS{MockKMS: mockkms.NewMockKMS()}}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id)
+
+	signed, err := jwt.New(SigningMethodECDSA256).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+				t.Errorf("Error verifying token: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if client.calls.Load() != 1 {
+		t.Errorf("GetPublicKey calls = %d, want 1", client.calls.Load())
+	}
+}
+
+// blockingPublicKeyKMS blocks GetPublicKey until release is closed, failing it if its context is done by then.
+type blockingPublicKeyKMS struct {
+	getPublicKeyCountingKMS
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingPublicKeyKMS) GetPublicKey(ctx context.Context, in *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error) {
+	close(c.started)
+	<-c.release
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return c.getPublicKeyCountingKMS.GetPublicKey(ctx, in, optFns...)
+}
+
+func TestPublicKeyFetchCanceledLeader(t *testing.T) {
+	client := &blockingPublicKeyKMS{
+		getPublicKeyCountingKMS: getPublicKeyCountingKMS{MockKMS: mockkms.NewMockKMS()},
+		started:                 make(chan struct{}),
+		release:                 make(chan struct{}),
+	}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		_, err := getCachedPublicKey(cfg.WithContext(ctx))
+		leader <- err
+	}()
+
+	<-client.started
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := getCachedPublicKey(cfg); err != nil {
+				t.Errorf("Error getting public key: %v", err)
+			}
+		}()
+	}
+
+	// the followers join the call of the leader, which is canceled while it is in flight
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Errorf("leader err = %v, want %v", err, context.Canceled)
+	}
+
+	close(client.release)
+	wg.Wait()
+
+	if client.calls.Load() != 1 {
+		t.Errorf("GetPublicKey calls = %d, want 1", client.calls.Load())
+	}
+}
@@ -0,0 +1,32 @@
+package jwtkms
+
+import (
+	"crypto"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// maxRawMessageSize is the largest message KMS accepts with MessageType RAW.
+const maxRawMessageSize = 4096
+
+// WithRawMessages makes the Config send ECDSA and RSA signing strings of at most 4096 bytes to KMS as RAW messages,
+// for KMS to hash them itself, instead of precomputed digests. Larger signing strings are still sent as digests.
+// The signatures are the same either way.
+func WithRawMessages() Option {
+	return func(c *Config) {
+		c.rawMessages = true
+	}
+}
+
+// signingMessage returns signingString as KMS Sign expects it: RAW if the Config signs raw messages and it fits, its
+// digest with hash otherwise.
+func (c *Config) signingMessage(hash crypto.Hash, signingString string) ([]byte, types.MessageType) {
+	if c.rawMessages && len(signingString) <= maxRawMessageSize {
+		return []byte(signingString), types.MessageTypeRaw
+	}
+
+	hasher := hash.New()
+	hasher.Write([]byte(signingString)) //nolint:errcheck
+
+	return hasher.Sum(nil), types.MessageTypeDigest
+}
@@ -0,0 +1,80 @@
+package jwtkms
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// messageTypeRecordingKMS records the message type of Sign calls.
+type messageTypeRecordingKMS struct {
+	*mockkms.MockKMS
+	mu           sync.Mutex
+	messageTypes []types.MessageType
+}
+
+func (c *messageTypeRecordingKMS) Sign(ctx context.Context, in *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error) {
+	c.mu.Lock()
+	c.messageTypes = append(c.messageTypes, in.MessageType)
+	c.mu.Unlock()
+
+	return c.MockKMS.Sign(ctx, in, optFns...)
+}
+
+func TestWithRawMessages(t *testing.T) {
+	registered(t)
+
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		method  jwt.SigningMethod
+	}{
+		{name: "ES256", keyType: mockkms.KeyTypeECCNISTP256, method: SigningMethodECDSA256},
+		{name: "ES512", keyType: mockkms.KeyTypeECCNISTP521, method: SigningMethodECDSA512},
+		{name: "RS384", keyType: mockkms.KeyTypeRSA2048, method: SigningMethodRS384},
+		{name: "PS256", keyType: mockkms.KeyTypeRSA2048, method: SigningMethodPS256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &messageTypeRecordingKMS{MockKMS: mockkms.NewMockKMS()}
+			id, err := client.GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			cfg := NewConfig(client, id, WithRawMessages())
+
+			for _, claims := range []jwt.MapClaims{
+				{"sub": "small"},
+				{"sub": strings.Repeat("a", 2*maxRawMessageSize)},
+			} {
+				signed, err := jwt.NewWithClaims(tt.method, claims).SignedString(cfg)
+				if err != nil {
+					t.Fatalf("Error signing token: %v", err)
+				}
+
+				if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+					t.Fatalf("Error verifying token: %v", err)
+				}
+			}
+
+			want := []types.MessageType{types.MessageTypeRaw, types.MessageTypeDigest}
+			if len(client.messageTypes) != len(want) {
+				t.Fatalf("message types = %v, want %v", client.messageTypes, want)
+			}
+
+			for i := range want {
+				if client.messageTypes[i] != want[i] {
+					t.Errorf("message types = %v, want %v", client.messageTypes, want)
+				}
+			}
+		})
+	}
+}
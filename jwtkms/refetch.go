@@ -0,0 +1,44 @@
+package jwtkms
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// WithRefetchOnInvalidSignature makes the Config fetch the public key again and retry once when a signature does not
+// verify locally with the cached public key, which may be stale after the key material was replaced. The public key
+// is fetched again at most once per minInterval, so invalid tokens do not turn into a GetPublicKey call each.
+func WithRefetchOnInvalidSignature(minInterval time.Duration) Option {
+	return func(c *Config) {
+		c.refetchOnInvalidSignature = true
+		c.refetchInterval = minInterval
+	}
+}
+
+// verifyWithRefetch runs verify and, if the signature is invalid and the cached public key is at least the refetch
+// interval old, fetches the public key again and runs verify again.
+func (c *Config) verifyWithRefetch(verify func(cfg *Config) error) error {
+	cfg := c.with(func(c *Config) {
+		c.verifyRefetching = true
+	})
+
+	err := verify(cfg)
+	if !errors.Is(err, ErrInvalidSignature) {
+		return err
+	}
+
+	now := time.Now()
+	if cached := c.publicKeyCache().Get(c.kmsKeyID); cached != nil && now.Sub(cached.FetchedAt) < c.refetchInterval {
+		return err
+	}
+
+	c.log(slog.LevelDebug, "invalid signature, fetching public key again")
+
+	if _, fetchErr := fetchPublicKeyOnce(cfg, now); fetchErr != nil {
+		c.log(slog.LevelWarn, "fetching public key again failed", slog.Any("error", fetchErr))
+		return err
+	}
+
+	return verify(cfg)
+}
@@ -0,0 +1,65 @@
+package jwtkms
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestRefetchOnInvalidSignature(t *testing.T) {
+	registered(t)
+
+	client := &getPublicKeyCountingKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	otherID, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	stale, err := getCachedPublicKey(NewConfig(client, otherID))
+	if err != nil {
+		t.Fatalf("Error getting public key: %v", err)
+	}
+
+	signed, err := jwt.New(SigningMethodECDSA256).SignedString(NewConfig(client, id))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		fetchedAt time.Time
+		wantErr   error
+		wantCalls int32
+	}{
+		{name: "disabled", fetchedAt: time.Now().Add(-time.Hour), wantErr: ErrInvalidSignature},
+		{name: "refetched", opts: []Option{WithRefetchOnInvalidSignature(time.Minute)}, fetchedAt: time.Now().Add(-time.Hour), wantCalls: 1},
+		{name: "fetched recently", opts: []Option{WithRefetchOnInvalidSignature(time.Minute)}, fetchedAt: time.Now(), wantErr: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client.calls.Store(0)
+
+			cfg := NewConfig(client, id, tt.opts...)
+			cfg.publicKeyCache().Add(id, &CachedPublicKey{PublicKey: stale.PublicKey, FetchedAt: tt.fetchedAt})
+
+			_, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil })
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if client.calls.Load() != tt.wantCalls {
+				t.Errorf("GetPublicKey calls = %d, want %d", client.calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package jwtkms
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// defaultPublicKeyRefreshInterval is the interval of NewPublicKeyRefresher if not positive.
+const defaultPublicKeyRefreshInterval = 5 * time.Minute
+
+// PublicKeyCacheLister is implemented by PublicKeyCaches able to list the key ids they hold, like the in-memory
+// cache. PublicKeyRefresher refreshes all of them.
+type PublicKeyCacheLister interface {
+	KeyIDs() []string
+}
+
+// PublicKeyRefresher fetches the public keys cached by a Config again in the background, so verification does not
+// wait for KMS when they expire. Set the interval below the TTL of WithPublicKeyTTL.
+type PublicKeyRefresher struct {
+	cfg      *Config
+	interval time.Duration
+
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPublicKeyRefresher starts refreshing the public keys cached by cfg about every interval, with jitter. All keys of
+// the Config's cache are refreshed if it is a PublicKeyCacheLister, the key ids of the Config otherwise, with the KMS
+// client of cfg, so Configs sharing a cache should use the same client. Failures are logged and keep the cached key.
+//
+// An interval that is not positive defaults to 5 minutes. Close stops the refresher.
+func NewPublicKeyRefresher(cfg *Config, interval time.Duration) *PublicKeyRefresher {
+	if interval <= 0 {
+		interval = defaultPublicKeyRefreshInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(cfg.ctx))
+
+	r := &PublicKeyRefresher{
+		cfg:      cfg.WithContext(ctx),
+		interval: interval,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	go r.run(ctx)
+
+	return r
+}
+
+// Close stops the refresher, waiting for a refresh in progress to be canceled. It is safe to call Close more than
+// once.
+func (r *PublicKeyRefresher) Close() {
+	r.closeOnce.Do(func() {
+		r.cancel()
+		<-r.done
+	})
+}
+
+func (r *PublicKeyRefresher) run(ctx context.Context) {
+	defer close(r.done)
+
+	for {
+		timer := time.NewTimer(r.delay())
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		r.refresh()
+	}
+}
+
+// delay returns the time until the next refresh, between 80% and 100% of the interval so replicas do not refresh in
+// lockstep.
+func (r *PublicKeyRefresher) delay() time.Duration {
+	jitter := r.interval / 5
+	if jitter <= 0 {
+		return r.interval
+	}
+
+	return r.interval - rand.N(jitter)
+}
+
+func (r *PublicKeyRefresher) refresh() {
+	start := time.Now()
+
+	for _, keyID := range r.keyIDs() {
+		if _, ok := r.cfg.providedPublicKeys[keyID]; ok {
+			continue
+		}
+
+		cfg := r.cfg.WithKeyID(keyID)
+		if _, err := fetchPublicKey(cfg, start); err != nil {
+			cfg.log(slog.LevelWarn, "refreshing public key failed", slog.Any("error", err))
+		}
+	}
+}
+
+// keyIDs returns the key ids to refresh.
+func (r *PublicKeyRefresher) keyIDs() []string {
+	switch cache := r.cfg.publicKeyCache().(type) {
+	case noPublicKeyCache:
+		return nil
+	case PublicKeyCacheLister:
+		return cache.KeyIDs()
+	}
+
+	return append([]string{r.cfg.kmsKeyID}, r.cfg.verificationKeyIDs...)
+}
@@ -0,0 +1,83 @@
+package jwtkms
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestPublicKeyRefresher(t *testing.T) {
+	registered(t)
+
+	client := &getPublicKeyCountingKMS{MockKMS: mockkms.NewMockKMS()}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id, WithPublicKeyTTL(time.Hour))
+
+	signed, err := jwt.New(SigningMethodECDSA256).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	expiresAt := cfg.publicKeyCache().Get(id).ExpiresAt
+
+	r := NewPublicKeyRefresher(cfg, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	r.Close()
+	r.Close()
+
+	calls := client.calls.Load()
+	if calls < 2 {
+		t.Fatalf("GetPublicKey calls = %d, want at least 2", calls)
+	}
+
+	if cached := cfg.publicKeyCache().Get(id); !cached.ExpiresAt.After(expiresAt) {
+		t.Errorf("cached public key not refreshed")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if client.calls.Load() != calls {
+		t.Errorf("public keys refreshed after Close")
+	}
+}
+
+func TestPublicKeyRefresherKeyIDs(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+
+	tests := []struct {
+		name string
+		cfg  *Config
+		want []string
+	}{
+		{name: "cache keys", cfg: NewConfig(kms, "a", WithVerificationKeyIDs("b")), want: []string{"c"}},
+		{name: "config keys", cfg: NewConfig(kms, "a", WithVerificationKeyIDs("b"), WithPublicKeyCache(&countingCache{PublicKeyCache: NewPublicKeyCache(PublicKeyCacheSettings{})})), want: []string{"a", "b"}},
+		{name: "no cache", cfg: NewConfig(kms, "a", WithoutPublicKeyCache()), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.publicKeyCache().Add("c", &CachedPublicKey{})
+
+			got := (&PublicKeyRefresher{cfg: tt.cfg}).keyIDs()
+			if len(got) != len(tt.want) {
+				t.Fatalf("keyIDs = %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("keyIDs = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
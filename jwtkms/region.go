@@ -0,0 +1,161 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// defaultRegionCooldown is how long a failed region is skipped before it is tried again.
+const defaultRegionCooldown = 30 * time.Second
+
+// regionSet holds the replica regions of a multi-Region key and their health, shared by the copies of a Config.
+type regionSet struct {
+	regions  []string
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	failedAt map[string]time.Time
+
+	// Smoothed call latencies by region, probing state of WithLatencyRouting
+	latency  map[string]time.Duration
+	probedAt time.Time
+	probing  bool
+}
+
+// WithRegions makes the Config fail over between the replicas of a multi-Region key, see Config.WithRegions.
+func WithRegions(regions ...string) Option {
+	return func(c *Config) {
+		if len(regions) == 0 {
+			c.regions = nil
+			return
+		}
+
+		c.regions = &regionSet{
+			regions:  append([]string{}, regions...),
+			cooldown: defaultRegionCooldown,
+			now:      time.Now,
+			failedAt: make(map[string]time.Time),
+			latency:  make(map[string]time.Duration),
+		}
+	}
+}
+
+// WithRegions returns a copy of Config calling KMS in the first of regions, the primary region of a multi-Region key,
+// and failing Sign, Verify, GetPublicKey, GenerateMac and VerifyMac calls over to the replicas in the following regions, in order, when a
+// region is unavailable or throttled, i.e. its calls fail with errors other than invalid signatures, unknown or
+// disabled keys, denied access or canceled contexts. A failed region is skipped for 30s, then tried again, so calls
+// return to the primary once it recovered; if all regions failed they are all tried.
+//
+// Key and alias ARNs are rewritten to the region of each call, multi-Region key ids and alias names, which are the
+// same in every region, are sent as is. The health of the regions is shared by the copies of the Config. The regions
+// apply to *kms.Client clients, which take the region per call.
+func (c *Config) WithRegions(regions ...string) *Config {
+	return c.with(WithRegions(regions...))
+}
+
+// order returns the regions in the order they are tried: the healthy ones, by latency if byLatency, then the failed
+// ones.
+func (s *regionSet) order(byLatency bool) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	healthy := make([]string, 0, len(s.regions))
+	var failed []string
+	for _, region := range s.regions {
+		if failedAt, ok := s.failedAt[region]; ok && now.Sub(failedAt) < s.cooldown {
+			failed = append(failed, region)
+			continue
+		}
+
+		healthy = append(healthy, region)
+	}
+
+	if byLatency {
+		s.sortByLatency(healthy)
+	}
+
+	return append(healthy, failed...)
+}
+
+// record updates the health of region with the result of a call made with the context ctx of the caller.
+func (s *regionSet) record(ctx context.Context, region string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if isRegionFailure(ctx, err) {
+		s.failedAt[region] = s.now()
+		return
+	}
+
+	delete(s.failedAt, region)
+}
+
+// isRegionFailure reports whether err of a call made with the context ctx of the caller is a reason to fail over to
+// the next region: KMS being unavailable, see isKMSFailure, or throttling the calls of the region.
+func isRegionFailure(ctx context.Context, err error) bool {
+	return isKMSFailure(ctx, err) || errors.Is(err, ErrThrottled)
+}
+
+// regionCall runs the KMS call operation with the key id keyID, in the regions of the Config in turn until a region
+// does not fail, see WithRegions and WithLatencyRouting. call gets a copy of the Config calling the region and keyID
+// for the region.
+func regionCall[T any](c *Config, operation Operation, keyID *string, call func(cfg *Config, keyID *string) (T, error)) (T, error) {
+	if c.regions == nil {
+		return call(c, keyID)
+	}
+
+	byLatency := c.latencyProbeInterval > 0 && (operation == OperationSign || operation == OperationGenerateMac)
+	if byLatency {
+		c.probeLatenciesAfterInterval()
+	}
+
+	var (
+		out T
+		err error
+	)
+
+	for i, region := range c.regions.order(byLatency) {
+		if i > 0 {
+			c.log(slog.LevelWarn, "failing KMS call over to replica region", slog.String("region", region), slog.Any("error", err))
+		}
+
+		cfg := c.with(func(c *Config) {
+			c.region = region
+		})
+
+		start := time.Now()
+		out, err = call(cfg, aws.String(regionKeyID(aws.ToString(keyID), region)))
+		c.regions.record(c.ctx, region, err)
+
+		if err == nil {
+			c.regions.recordLatency(region, time.Since(start))
+		}
+
+		if !isRegionFailure(c.ctx, err) || c.ctx.Err() != nil {
+			return out, err
+		}
+	}
+
+	return out, err
+}
+
+// regionKeyID returns keyID for region: key and alias ARNs with the region replaced, other key ids unchanged.
+func regionKeyID(keyID, region string) string {
+	parsed, err := arn.Parse(keyID)
+	if err != nil || parsed.Service != "kms" {
+		return keyID
+	}
+
+	parsed.Region = region
+
+	return parsed.String()
+}
@@ -0,0 +1,130 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// latencyProbeTimeout bounds the latency probe of a region.
+const latencyProbeTimeout = 5 * time.Second
+
+// WithLatencyRouting makes the Config route signing calls to the fastest region, see Config.WithLatencyRouting.
+func WithLatencyRouting(probeInterval time.Duration) Option {
+	return func(c *Config) {
+		c.latencyProbeInterval = probeInterval
+	}
+}
+
+// WithLatencyRouting returns a copy of Config routing its Sign and GenerateMac calls to the healthy region of
+// WithRegions with the lowest latency instead of the primary, e.g. for a globally deployed token issuer, still failing
+// over to the other regions in order of latency. Verify and GetPublicKey calls keep the order of WithRegions.
+//
+// Latencies are smoothed over the calls to a region and measured with a KMS GetPublicKey call to every region by the
+// first signing call after probeInterval passed, in the background. Regions without a measured latency come last.
+func (c *Config) WithLatencyRouting(probeInterval time.Duration) *Config {
+	return c.with(WithLatencyRouting(probeInterval))
+}
+
+// sortByLatency sorts regions by their latency, regions without latency last. s.mu must be held.
+func (s *regionSet) sortByLatency(regions []string) {
+	sort.SliceStable(regions, func(i, j int) bool {
+		li, iok := s.latency[regions[i]]
+		lj, jok := s.latency[regions[j]]
+
+		if iok != jok {
+			return iok
+		}
+
+		return li < lj
+	})
+}
+
+// recordLatency updates the smoothed latency of region with the latency of a call.
+func (s *regionSet) recordLatency(region string, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.latency[region]
+	if !ok {
+		s.latency[region] = latency
+		return
+	}
+
+	// exponentially weighted moving average, weighing the latest call 1/4
+	s.latency[region] = previous + (latency-previous)/4
+}
+
+// probeLatenciesAfterInterval starts probing the latencies of the regions in the background unless they were probed
+// within the probe interval or are being probed.
+func (c *Config) probeLatenciesAfterInterval() {
+	s := c.regions
+	if c.offline {
+		return
+	}
+
+	s.mu.Lock()
+	if s.probing || (!s.probedAt.IsZero() && s.now().Sub(s.probedAt) < c.latencyProbeInterval) {
+		s.mu.Unlock()
+		return
+	}
+
+	s.probing = true
+	s.mu.Unlock()
+
+	// the probes outlive the call that started them
+	cfg := c.WithContext(context.WithoutCancel(c.ctx))
+
+	go func() {
+		cfg.probeLatencies()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.probing = false
+		s.probedAt = s.now()
+	}()
+}
+
+// probeLatencies measures the latency of a GetPublicKey call to every region.
+func (c *Config) probeLatencies() {
+	for _, region := range c.regions.regions {
+		ctx, cancel := context.WithTimeout(c.ctx, latencyProbeTimeout)
+
+		cfg := c.with(func(c *Config) {
+			c.ctx = ctx
+			c.region = region
+		})
+
+		start := time.Now()
+		_, err := cfg.kmsClient.GetPublicKey(ctx, &kms.GetPublicKeyInput{
+			KeyId:       aws.String(regionKeyID(c.kmsKeyID, region)),
+			GrantTokens: c.grantTokens,
+		}, cfg.kmsOptions()...)
+		latency := time.Since(start)
+
+		cancel()
+
+		// HMAC keys have no public key, KMS answering is all the probe needs
+		var unsupported *types.UnsupportedOperationException
+		if errors.As(err, &unsupported) {
+			err = nil
+		}
+
+		err = mapKMSError(err)
+		c.regions.record(c.ctx, region, err)
+
+		if isRegionFailure(c.ctx, err) {
+			c.log(slog.LevelWarn, "latency probe of region failed", slog.String("region", region), slog.Any("error", err))
+			continue
+		}
+
+		c.regions.recordLatency(region, latency)
+	}
+}
@@ -0,0 +1,132 @@
+package jwtkms
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// slowRegionKMS is a regionKMS whose GetPublicKey calls take the delay of the region.
+type slowRegionKMS struct {
+	*regionKMS
+	delays map[string]time.Duration
+}
+
+func (c *slowRegionKMS) GetPublicKey(ctx context.Context, in *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error) {
+	var o kms.Options
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	time.Sleep(c.delays[o.Region])
+
+	return c.MockKMS.GetPublicKey(ctx, in, optFns...)
+}
+
+func TestWithLatencyRouting(t *testing.T) {
+	registered(t)
+
+	client := &slowRegionKMS{
+		regionKMS: &regionKMS{MockKMS: mockkms.NewMockKMS(), down: map[string]bool{}},
+		delays:    map[string]time.Duration{"us-east-1": 50 * time.Millisecond, "eu-west-1": 20 * time.Millisecond},
+	}
+
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id,
+		WithRegions("us-east-1", "eu-west-1", "ap-southeast-2"),
+		WithLatencyRouting(time.Minute),
+		WithVerifyWithKMS(true),
+	)
+
+	// the probe runs in the background, probed synchronously to test the routing
+	cfg.probeLatencies()
+
+	signed, err := jwt.New(SigningMethodECDSA256).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if got := client.calls(); !slices.Equal(got, []string{"ap-southeast-2"}) {
+		t.Errorf("Sign regions = %v, want [ap-southeast-2]", got)
+	}
+
+	if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return cfg, nil }); err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	if got := client.calls(); !slices.Equal(got, []string{"us-east-1"}) {
+		t.Errorf("Verify regions = %v, want the primary [us-east-1]", got)
+	}
+
+	client.mu.Lock()
+	client.down["ap-southeast-2"] = true
+	client.mu.Unlock()
+
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if got := client.calls(); !slices.Equal(got, []string{"ap-southeast-2", "eu-west-1"}) {
+		t.Errorf("Sign regions = %v, want a fail over to the next fastest [ap-southeast-2 eu-west-1]", got)
+	}
+}
+
+func TestLatencyProbeInterval(t *testing.T) {
+	client := &slowRegionKMS{regionKMS: &regionKMS{MockKMS: mockkms.NewMockKMS(), down: map[string]bool{}}}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id, WithRegions("us-east-1", "eu-west-1"), WithLatencyRouting(time.Hour))
+
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	waitProbed := func() time.Time {
+		t.Helper()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			cfg.regions.mu.Lock()
+			probedAt, probing := cfg.regions.probedAt, cfg.regions.probing
+			cfg.regions.mu.Unlock()
+
+			if !probedAt.IsZero() && !probing {
+				return probedAt
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+
+		t.Fatalf("the regions were not probed")
+		return time.Time{}
+	}
+
+	probedAt := waitProbed()
+
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if got := waitProbed(); !got.Equal(probedAt) {
+		t.Errorf("the regions were probed again within the probe interval")
+	}
+
+	cfg.regions.mu.Lock()
+	defer cfg.regions.mu.Unlock()
+
+	if len(cfg.regions.latency) != 2 {
+		t.Errorf("latencies = %v, want both regions", cfg.regions.latency)
+	}
+}
@@ -0,0 +1,148 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/smithy-go"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// regionKMS records the region of the KMS calls and throttles the calls of the regions that are down.
+type regionKMS struct {
+	*mockkms.MockKMS
+
+	mu      sync.Mutex
+	down    map[string]bool
+	regions []string
+}
+
+func (c *regionKMS) call(optFns []func(*kms.Options)) error {
+	var o kms.Options
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.regions = append(c.regions, o.Region)
+	if c.down[o.Region] {
+		return &smithy.GenericAPIError{Code: "ThrottlingException", Message: "rate exceeded"}
+	}
+
+	return nil
+}
+
+// calls returns the regions of the calls since the last call of calls.
+func (c *regionKMS) calls() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	regions := c.regions
+	c.regions = nil
+
+	return regions
+}
+
+func (c *regionKMS) Sign(ctx context.Context, in *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error) {
+	if err := c.call(optFns); err != nil {
+		return nil, err
+	}
+
+	return c.MockKMS.Sign(ctx, in, optFns...)
+}
+
+func (c *regionKMS) Verify(ctx context.Context, in *kms.VerifyInput, optFns ...func(*kms.Options)) (*kms.VerifyOutput, error) {
+	if err := c.call(optFns); err != nil {
+		return nil, err
+	}
+
+	return c.MockKMS.Verify(ctx, in, optFns...)
+}
+
+func TestWithRegions(t *testing.T) {
+	registered(t)
+
+	client := &regionKMS{MockKMS: mockkms.NewMockKMS(), down: map[string]bool{"us-east-1": true}}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	now := time.Now()
+	cfg := NewConfig(client, id, WithRegions("us-east-1", "eu-west-1", "ap-southeast-2"), WithVerifyWithKMS(true))
+	cfg.regions.now = func() time.Time { return now }
+
+	sign := func(want ...string) string {
+		t.Helper()
+
+		signed, err := jwt.New(SigningMethodECDSA256).SignedString(cfg)
+		if err != nil {
+			t.Fatalf("Error signing token: %v", err)
+		}
+
+		if got := client.calls(); !slices.Equal(got, want) {
+			t.Errorf("Sign regions = %v, want %v", got, want)
+		}
+
+		return signed
+	}
+
+	sign("us-east-1", "eu-west-1")
+
+	// the failed primary is skipped until it recovers
+	signed := sign("eu-west-1")
+
+	client.mu.Lock()
+	client.down["us-east-1"] = false
+	client.mu.Unlock()
+
+	now = now.Add(defaultRegionCooldown)
+	sign("us-east-1")
+
+	// invalid signatures do not fail over
+	if _, err := jwt.Parse(tamperSignature(signed), func(*jwt.Token) (interface{}, error) { return cfg, nil }); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("err = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	if got := client.calls(); !slices.Equal(got, []string{"us-east-1"}) {
+		t.Errorf("Verify regions = %v, want [us-east-1]", got)
+	}
+
+	client.mu.Lock()
+	client.down = map[string]bool{"us-east-1": true, "eu-west-1": true, "ap-southeast-2": true}
+	client.mu.Unlock()
+
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); !errors.Is(err, ErrThrottled) {
+		t.Errorf("err = %v, want %v", err, ErrThrottled)
+	}
+
+	if got := client.calls(); len(got) != 3 {
+		t.Errorf("Sign regions = %v, want all regions", got)
+	}
+}
+
+func TestRegionKeyID(t *testing.T) {
+	tests := []struct {
+		keyID string
+		want  string
+	}{
+		{keyID: "arn:aws:kms:us-east-1:111122223333:key/mrk-1234", want: "arn:aws:kms:eu-west-1:111122223333:key/mrk-1234"},
+		{keyID: "arn:aws:kms:us-east-1:111122223333:alias/signing", want: "arn:aws:kms:eu-west-1:111122223333:alias/signing"},
+		{keyID: "mrk-1234", want: "mrk-1234"},
+		{keyID: "alias/signing", want: "alias/signing"},
+	}
+
+	for _, tt := range tests {
+		if got := regionKeyID(tt.keyID, "eu-west-1"); got != tt.want {
+			t.Errorf("regionKeyID(%q) = %q, want %q", tt.keyID, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package jwtkms
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrAlgorithmRegistered is returned by Register for algs a program registered its own signing method for.
+var ErrAlgorithmRegistered = errors.New("alg is registered with another signing method")
+
+var (
+	// signingMethods are the signing methods of this package, registered with golang-jwt or not.
+	signingMethods = make(map[string]jwt.SigningMethod)
+
+	// registerMu serializes Register, which checks then replaces the registered methods.
+	registerMu sync.Mutex
+)
+
+// golangJWTMethods are the signing methods of golang-jwt Register replaces.
+var golangJWTMethods = map[jwt.SigningMethod]bool{
+	jwt.SigningMethodES256: true,
+	jwt.SigningMethodES384: true,
+	jwt.SigningMethodES512: true,
+	jwt.SigningMethodRS256: true,
+	jwt.SigningMethodRS384: true,
+	jwt.SigningMethodRS512: true,
+	jwt.SigningMethodPS256: true,
+	jwt.SigningMethodPS384: true,
+	jwt.SigningMethodPS512: true,
+	jwt.SigningMethodHS256: true,
+	jwt.SigningMethodHS384: true,
+	jwt.SigningMethodHS512: true,
+}
+
+// addSigningMethod adds m to the signing methods of this package.
+func addSigningMethod(m jwt.SigningMethod) {
+	signingMethods[m.Alg()] = m
+}
+
+// Register registers the signing methods of this package with golang-jwt, so jwt.Parse verifies tokens with a *Config.
+// Importing the package registers nothing, and the parsers and verifiers of this package, like ParseWithConfig and
+// Verifier, need no registration. golang-jwt's methods of the same algs, e.g. ES256, are replaced; the methods of this
+// package fall back to them for golang-jwt's key types, so tokens keep verifying with e.g. an *ecdsa.PublicKey.
+//
+// Algs a program registered its own signing method for are left alone, and reported in an error matching
+// ErrAlgorithmRegistered after the other methods are registered. Register can be called more than once.
+func Register() error {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+
+	var conflicts []string
+	for _, alg := range Algorithms() {
+		m := signingMethods[alg]
+
+		if registered := jwt.GetSigningMethod(alg); registered != nil && registered != m && !golangJWTMethods[registered] {
+			conflicts = append(conflicts, alg)
+			continue
+		}
+
+		jwt.RegisterSigningMethod(alg, func() jwt.SigningMethod {
+			return m
+		})
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s", ErrAlgorithmRegistered, strings.Join(conflicts, ", "))
+	}
+
+	return nil
+}
+
+// MustRegister is Register panicking on error.
+func MustRegister() {
+	if err := Register(); err != nil {
+		panic(fmt.Sprintf("jwtkms: registering signing methods: %v", err))
+	}
+}
+
+// GetSigningMethod returns the signing method of this package for alg, whether registered with golang-jwt or not, nil
+// if there is none. Signing, e.g. with jwt.NewWithClaims, and the verifying methods of Config taking a signing method
+// need no registration.
+func GetSigningMethod(alg string) jwt.SigningMethod {
+	return signingMethods[alg]
+}
+
+// Algorithms returns the sorted algs of the signing methods of this package.
+func Algorithms() []string {
+	algs := make([]string, 0, len(signingMethods))
+	for alg := range signingMethods {
+		algs = append(algs, alg)
+	}
+
+	slices.Sort(algs)
+
+	return algs
+}
@@ -0,0 +1,142 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// registered registers the signing methods of this package with golang-jwt for the duration of the test, for tests
+// verifying tokens with jwt.Parse. The other tests run without registration.
+func registered(t *testing.T) {
+	t.Helper()
+
+	previous := make(map[string]jwt.SigningMethod)
+	for _, alg := range Algorithms() {
+		previous[alg] = jwt.GetSigningMethod(alg)
+	}
+
+	MustRegister()
+
+	t.Cleanup(func() {
+		// golang-jwt can not unregister, a nil method is the same as none
+		for alg, m := range previous {
+			jwt.RegisterSigningMethod(alg, func() jwt.SigningMethod { return m })
+		}
+	})
+}
+
+// foreignSigningMethod is a signing method a program registers itself.
+type foreignSigningMethod struct {
+	jwt.SigningMethod
+}
+
+func TestRegister(t *testing.T) {
+	registered(t)
+
+	if err := Register(); err != nil {
+		t.Fatalf("Error registering signing methods again: %v", err)
+	}
+
+	for _, alg := range Algorithms() {
+		if jwt.GetSigningMethod(alg) != GetSigningMethod(alg) {
+			t.Errorf("registered method of %s is not the method of this package", alg)
+		}
+	}
+
+	foreign := &foreignSigningMethod{SigningMethod: SigningMethodES256K}
+	jwt.RegisterSigningMethod("ES256K", func() jwt.SigningMethod { return foreign })
+
+	// golang-jwt's own methods are replaced, those of the program are not
+	jwt.RegisterSigningMethod("ES384", func() jwt.SigningMethod { return jwt.SigningMethodES384 })
+
+	if err := Register(); !errors.Is(err, ErrAlgorithmRegistered) {
+		t.Errorf("err = %v, want %v", err, ErrAlgorithmRegistered)
+	}
+
+	if jwt.GetSigningMethod("ES256K") != foreign {
+		t.Errorf("the signing method registered by the program was replaced")
+	}
+
+	if jwt.GetSigningMethod("ES384") != SigningMethodECDSA384 {
+		t.Errorf("the signing method of golang-jwt was not replaced")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected MustRegister to panic")
+		}
+	}()
+
+	MustRegister()
+}
+
+func TestGetSigningMethod(t *testing.T) {
+	if GetSigningMethod("PS384") != SigningMethodPS384 || GetSigningMethod("EdDSA") != nil {
+		t.Errorf("unexpected signing methods of PS384 and EdDSA")
+	}
+}
+
+func TestVerifyUnregistered(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		method  jwt.SigningMethod
+	}{
+		{name: "ES256", keyType: mockkms.KeyTypeECCNISTP256, method: SigningMethodECDSA256},
+		{name: "ES256K", keyType: mockkms.KeyTypeECCSECGP256K1, method: SigningMethodES256K},
+		{name: "PS256", keyType: mockkms.KeyTypeRSA2048, method: SigningMethodPS256},
+		{name: "HS256", keyType: mockkms.KeyTypeHMAC256, method: SigningMethodHS256},
+		{name: "SM2", keyType: mockkms.KeyTypeSM2, method: SigningMethodSM2},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if jwt.GetSigningMethod(tt.method.Alg()) == tt.method {
+				t.Fatalf("signing method of %s is registered", tt.method.Alg())
+			}
+
+			client := mockkms.NewMockKMS()
+			id, err := client.GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			cfg := NewConfig(client, id)
+
+			signed, err := cfg.SignContext(ctx, jwt.NewWithClaims(tt.method, jwt.MapClaims{
+				"exp": time.Now().Add(time.Minute).Unix(),
+			}))
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			if _, err := ParseWithConfig[jwt.MapClaims](ctx, signed, cfg, AllowAlgorithms(tt.method.Alg())); err != nil {
+				t.Errorf("Error parsing token: %v", err)
+			}
+
+			if _, err := cfg.VerifyContext(ctx, signed, jwt.MapClaims{}); err != nil {
+				t.Errorf("Error verifying token: %v", err)
+			}
+
+			if _, err := NewConfigVerifier(cfg, VerifierSettings{}).Verify(ctx, signed); err != nil {
+				t.Errorf("Error verifying token with a verifier: %v", err)
+			}
+
+			if _, err := cfg.VerifyContext(ctx, tamperSignature(signed), jwt.MapClaims{}); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+				t.Errorf("err = %v, want %v", err, jwt.ErrTokenSignatureInvalid)
+			}
+
+			_, err = ParseWithConfig[jwt.MapClaims](ctx, signed, cfg, AllowAlgorithms("EdDSA"))
+			if !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+				t.Errorf("err = %v, want %v", err, jwt.ErrTokenSignatureInvalid)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package jwtkms
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyProvider selects the Config a token is signed with when the token is signed, instead of when the key is
+// configured. Any KeyProvider can be passed as the key to token.SignedString in place of a *Config.
+type KeyProvider interface {
+	SigningConfig(alg string) (*Config, error)
+}
+
+// ProviderSignedString signs token with the Config provider selects for its alg and returns the complete, signed
+// token, setting the headers of the Config like Config.SignedString: the kid of an entry of a KeyRegistry is set unless
+// the token already has one, which token.SignedString(provider) can not do.
+func ProviderSignedString(provider KeyProvider, token *jwt.Token) (string, error) {
+	cfg, err := provider.SigningConfig(token.Method.Alg())
+	if err != nil {
+		return "", fmt.Errorf("selecting signing key: %w", err)
+	}
+
+	return cfg.SignedString(token)
+}
+
+// KeyEntry describes a KMS key held by a KeyRegistry.
+type KeyEntry struct {
+	// KID is the kid the key is looked up with, it must be unique within a registry.
+	KID string
+
+	// KeyID is the key id, key ARN, alias name or alias ARN of the KMS key.
+	KeyID string
+
+	// Algorithm is the JWT alg the key signs with. An entry without Algorithm is used for any alg its key supports,
+	// resolved from the key spec of the key when signing.
+	Algorithm string
+
+	// Region and Role are the AWS region the key lives in and the IAM role used to access it. They are passed to the
+	// registry's ClientFunc to build the client for the entry, or applied to the entry's Config by registries of
+	// NewKeyRegistryWithAssumeRole.
+	Region string
+	Role   string
+
+	// Credentials, if set, sign the KMS calls of the entry's Config instead of the client's, see WithCredentials.
+	Credentials aws.CredentialsProvider
+
+	// Tenant groups the keys of a tenant.
+	Tenant string
+
+	// VerifyWithKMS makes the entry's Config verify signatures with KMS instead of the cached public key.
+	VerifyWithKMS bool
+}
+
+// ClientFunc returns the KMS client used for a KeyEntry, for example one configured for the entry's Region and Role.
+type ClientFunc func(entry KeyEntry) (KMSClient, error)
+
+// KeyRegistry holds a set of KMS keys and looks them up by kid, key id or alias, or tenant.
+//
+// It is a KeySet for Keyfunc and a KeyProvider for signing: the first registered key for the signing method's alg is
+// used, or the first key without Algorithm whose key spec supports the alg, Tenant narrows the choice down to a
+// tenant's keys.
+type KeyRegistry struct {
+	clientFunc ClientFunc
+
+	// Options of the Config of an entry, none if nil
+	entryOptions func(entry KeyEntry) []Option
+
+	mu      sync.RWMutex
+	entries []*registryEntry
+	byKID   map[string]*registryEntry
+}
+
+type registryEntry struct {
+	KeyEntry
+	cfg *Config
+}
+
+// NewKeyRegistry creates a KeyRegistry whose keys all use client.
+func NewKeyRegistry(client KMSClient) *KeyRegistry {
+	return NewKeyRegistryWithClientFunc(func(KeyEntry) (KMSClient, error) {
+		return client, nil
+	})
+}
+
+// NewKeyRegistryWithClientFunc creates a KeyRegistry that builds the client of every key with clientFunc.
+func NewKeyRegistryWithClientFunc(clientFunc ClientFunc) *KeyRegistry {
+	return &KeyRegistry{
+		clientFunc: clientFunc,
+		byKID:      make(map[string]*registryEntry),
+	}
+}
+
+// NewKeyRegistryWithAssumeRole creates a KeyRegistry whose keys all use client, calling KMS with the credentials of
+// the Role of the entry assumed with stsClient, see WithAssumeRole, and in the Region of the entry, so one process
+// signs with keys of several AWS accounts and regions. Entries without Role or Region use those of client.
+func NewKeyRegistryWithAssumeRole(client KMSClient, stsClient stscreds.AssumeRoleAPIClient) *KeyRegistry {
+	r := NewKeyRegistry(client)
+	r.entryOptions = func(entry KeyEntry) []Option {
+		var opts []Option
+		if entry.Role != "" {
+			opts = append(opts, WithAssumeRole(stsClient, entry.Role))
+		}
+
+		if entry.Region != "" {
+			opts = append(opts, WithAPIOptions(func(o *kms.Options) {
+				o.Region = entry.Region
+			}))
+		}
+
+		return opts
+	}
+
+	return r
+}
+
+// Add registers a key.
+func (r *KeyRegistry) Add(entry KeyEntry) error {
+	if entry.KID == "" {
+		return errors.New("key entry has no kid")
+	}
+
+	if entry.KeyID == "" {
+		return fmt.Errorf("key entry %q has no key id", entry.KID)
+	}
+
+	client, err := r.clientFunc(entry)
+	if err != nil {
+		return fmt.Errorf("creating kms client for %q: %w", entry.KID, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byKID[entry.KID]; ok {
+		return fmt.Errorf("duplicate kid %q", entry.KID)
+	}
+
+	// the Config of an entry sets its kid, so tokens it signs with SignedString are verified with the same entry
+	opts := []Option{WithKID(entry.KID), WithVerifyWithKMS(entry.VerifyWithKMS)}
+	if r.entryOptions != nil {
+		opts = append(opts, r.entryOptions(entry)...)
+	}
+
+	// the entry's own credentials take precedence over those of an assumed role
+	if entry.Credentials != nil {
+		opts = append(opts, WithCredentials(entry.Credentials))
+	}
+
+	e := &registryEntry{
+		KeyEntry: entry,
+		cfg:      NewConfig(client, entry.KeyID, opts...),
+	}
+
+	r.entries = append(r.entries, e)
+	r.byKID[entry.KID] = e
+
+	return nil
+}
+
+// Remove unregisters the key with kid, reporting whether it was registered.
+func (r *KeyRegistry) Remove(kid string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byKID[kid]; !ok {
+		return false
+	}
+
+	delete(r.byKID, kid)
+	for i, e := range r.entries {
+		if e.KID == kid {
+			r.entries = append(r.entries[:i:i], r.entries[i+1:]...)
+			break
+		}
+	}
+
+	return true
+}
+
+// ByKID returns the entry registered with kid.
+func (r *KeyRegistry) ByKID(kid string) (KeyEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.byKID[kid]
+	if !ok {
+		return KeyEntry{}, false
+	}
+
+	return e.KeyEntry, true
+}
+
+// ByAlias returns the first entry whose KeyID is alias. Any form of KeyID can be looked up, not only alias names.
+func (r *KeyRegistry) ByAlias(alias string) (KeyEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, e := range r.entries {
+		if e.KeyID == alias {
+			return e.KeyEntry, true
+		}
+	}
+
+	return KeyEntry{}, false
+}
+
+// ByTenant returns the entries of tenant in registration order.
+func (r *KeyRegistry) ByTenant(tenant string) []KeyEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []KeyEntry
+	for _, e := range r.entries {
+		if e.Tenant == tenant {
+			entries = append(entries, e.KeyEntry)
+		}
+	}
+
+	return entries
+}
+
+// ConfigForKID returns the Config of the key registered with kid.
+func (r *KeyRegistry) ConfigForKID(kid string) (*Config, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.byKID[kid]
+	if !ok {
+		return nil, fmt.Errorf("no key for kid %q", kid)
+	}
+
+	return e.cfg, nil
+}
+
+// SigningConfig returns the Config of the first registered key for alg.
+func (r *KeyRegistry) SigningConfig(alg string) (*Config, error) {
+	return r.signingConfig(alg, func(*registryEntry) bool { return true })
+}
+
+// Tenant returns a KeyProvider that only selects keys of tenant.
+func (r *KeyRegistry) Tenant(tenant string) KeyProvider {
+	return tenantKeyProvider{registry: r, tenant: tenant}
+}
+
+func (r *KeyRegistry) signingConfig(alg string, match func(*registryEntry) bool) (*Config, error) {
+	r.mu.RLock()
+	var candidates []*registryEntry
+	for _, e := range r.entries {
+		if (e.Algorithm == "" || e.Algorithm == alg) && match(e) {
+			candidates = append(candidates, e)
+		}
+	}
+	r.mu.RUnlock()
+
+	// entries without Algorithm are routed by the key spec of their key, a key whose spec can not be fetched is used
+	// if no key is known to support alg, failing the signing with the KMS error
+	var unknown *Config
+	for _, e := range candidates {
+		if e.Algorithm == alg {
+			return e.cfg, nil
+		}
+
+		supported, err := e.cfg.supportsAlgorithm(alg)
+		if supported {
+			return e.cfg, nil
+		}
+
+		if err != nil && unknown == nil {
+			unknown = e.cfg
+		}
+	}
+
+	if unknown != nil {
+		return unknown, nil
+	}
+
+	return nil, fmt.Errorf("no key for alg %s", alg)
+}
+
+type tenantKeyProvider struct {
+	registry *KeyRegistry
+	tenant   string
+}
+
+func (p tenantKeyProvider) SigningConfig(alg string) (*Config, error) {
+	return p.registry.signingConfig(alg, func(e *registryEntry) bool { return e.Tenant == p.tenant })
+}
+
+// resolveSigningKey resolves a KeyProvider passed to Sign to the Config for alg, other keys are returned unchanged.
+func resolveSigningKey(keyConfig interface{}, alg string) (interface{}, error) {
+	provider, ok := keyConfig.(KeyProvider)
+	if !ok {
+		return keyConfig, nil
+	}
+
+	cfg, err := provider.SigningConfig(alg)
+	if err != nil {
+		return nil, fmt.Errorf("selecting signing key: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// supportsAlgorithm reports whether the KMS key of c signs with the signing method of this package for alg,
+// resolved from the key spec and signing algorithms of its cached metadata, see keyMetadata. HMAC keys support the HS*
+// method of their key spec, or every HS* method if their key spec can not be described.
+func (c *Config) supportsAlgorithm(alg string) (bool, error) {
+	method, ok := GetSigningMethod(alg).(kmsSigningMethod)
+	if !ok {
+		return false, nil
+	}
+
+	_, isHMAC := method.(*HMACSigningMethod)
+
+	key, err := c.keyMetadata()
+
+	var unsupported *types.UnsupportedOperationException
+	if errors.As(err, &unsupported) {
+		return isHMAC, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if isHMAC {
+		keyMethod, ok := SigningMethodForKeySpec(key.KeySpec)
+		return ok && keyMethod == method, nil
+	}
+
+	return checkSigningAlgorithm(c.kmsKeyID, key, method) == nil, nil
+}
@@ -0,0 +1,83 @@
+package jwtkms
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// NewKeyRegistryFromAWSConfig creates a KeyRegistry building the KMS client of every key from base, see
+// AWSConfigClientFunc.
+func NewKeyRegistryFromAWSConfig(base aws.Config) *KeyRegistry {
+	return NewKeyRegistryWithClientFunc(AWSConfigClientFunc(base))
+}
+
+// clientKey identifies the clients AWSConfigClientFunc shares between entries.
+type clientKey struct {
+	region string
+	role   string
+}
+
+// AWSConfigClientFunc returns a ClientFunc building a *kms.Client per KeyEntry from base with the entry's overrides:
+// the Region of the entry, else the region of a key or alias ARN KeyID, else the region of base, and the credentials
+// of the entry, else of its Role assumed with an STS client of base, else of base. Clients are cached and shared by the
+// entries with the same region and role; entries with their own Credentials get a client of their own.
+func AWSConfigClientFunc(base aws.Config) ClientFunc {
+	var (
+		mu        sync.Mutex
+		clients   = make(map[clientKey]*kms.Client)
+		stsClient *sts.Client
+	)
+
+	return func(entry KeyEntry) (KMSClient, error) {
+		key := clientKey{region: entry.Region, role: entry.Role}
+		if key.region == "" {
+			key.region = keyIDRegion(entry.KeyID)
+		}
+
+		cfg := base.Copy()
+		if key.region != "" {
+			cfg.Region = key.region
+		}
+
+		if entry.Credentials != nil {
+			return kms.NewFromConfig(cfg, func(o *kms.Options) {
+				o.Credentials = aws.NewCredentialsCache(entry.Credentials)
+			}), nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if client, ok := clients[key]; ok {
+			return client, nil
+		}
+
+		if entry.Role != "" {
+			if stsClient == nil {
+				stsClient = sts.NewFromConfig(base)
+			}
+
+			cfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(stsClient, entry.Role))
+		}
+
+		client := kms.NewFromConfig(cfg)
+		clients[key] = client
+
+		return client, nil
+	}
+}
+
+// keyIDRegion returns the region of a key or alias ARN, "" for other key ids.
+func keyIDRegion(keyID string) string {
+	parsed, err := arn.Parse(keyID)
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Region
+}
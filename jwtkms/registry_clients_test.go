@@ -0,0 +1,99 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAWSConfigClientFunc(t *testing.T) {
+	recorder := &hostRecorder{}
+	base := aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID-BASE", "secret", ""),
+		HTTPClient:  recorder,
+		Retryer:     func() aws.Retryer { return aws.NopRetryer{} },
+	}
+
+	clientFunc := AWSConfigClientFunc(base)
+	client := func(entry KeyEntry) KMSClient {
+		t.Helper()
+
+		c, err := clientFunc(entry)
+		if err != nil {
+			t.Fatalf("Error creating client: %v", err)
+		}
+
+		return c
+	}
+
+	keyARN := "arn:aws:kms:eu-central-1:444455556666:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+
+	tests := []struct {
+		name     string
+		entry    KeyEntry
+		wantHost string
+	}{
+		{name: "base region", entry: KeyEntry{KeyID: "alias/signing"}, wantHost: "kms.us-east-1.amazonaws.com"},
+		{name: "arn region", entry: KeyEntry{KeyID: keyARN}, wantHost: "kms.eu-central-1.amazonaws.com"},
+		{name: "entry region", entry: KeyEntry{KeyID: keyARN, Region: "ap-southeast-2"}, wantHost: "kms.ap-southeast-2.amazonaws.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig(client(tt.entry), tt.entry.KeyID).WithContext(context.Background())
+			if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); !errors.Is(err, errRequestCaptured) {
+				t.Fatalf("err = %v, want %v", err, errRequestCaptured)
+			}
+
+			if recorder.host != tt.wantHost {
+				t.Errorf("host = %s, want %s", recorder.host, tt.wantHost)
+			}
+		})
+	}
+
+	if client(KeyEntry{KeyID: keyARN}) != client(KeyEntry{KeyID: "alias/other", Region: "eu-central-1"}) {
+		t.Errorf("entries of the same region got different clients")
+	}
+
+	role := KeyEntry{KeyID: keyARN, Role: "arn:aws:iam::444455556666:role/signer"}
+	if client(role) == client(KeyEntry{KeyID: keyARN}) || client(role) != client(role) {
+		t.Errorf("the clients of a role are not cached separately")
+	}
+
+	own := KeyEntry{KeyID: keyARN, Credentials: credentials.NewStaticCredentialsProvider("AKID-ENTRY", "secret", "")}
+	if client(own) == client(KeyEntry{KeyID: keyARN}) {
+		t.Errorf("an entry with credentials got a shared client")
+	}
+}
+
+func TestNewKeyRegistryFromAWSConfig(t *testing.T) {
+	recorder := &hostRecorder{}
+	registry := NewKeyRegistryFromAWSConfig(aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID-BASE", "secret", ""),
+		HTTPClient:  recorder,
+		Retryer:     func() aws.Retryer { return aws.NopRetryer{} },
+	})
+
+	if err := registry.Add(KeyEntry{KID: "eu", KeyID: "alias/signing", Region: "eu-west-1"}); err != nil {
+		t.Fatalf("Error adding key: %v", err)
+	}
+
+	cfg, err := registry.ConfigForKID("eu")
+	if err != nil {
+		t.Fatalf("Error getting config: %v", err)
+	}
+
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg.WithContext(context.Background())); !errors.Is(err, errRequestCaptured) {
+		t.Fatalf("err = %v, want %v", err, errRequestCaptured)
+	}
+
+	if recorder.host != "kms.eu-west-1.amazonaws.com" {
+		t.Errorf("host = %s, want kms.eu-west-1.amazonaws.com", recorder.host)
+	}
+}
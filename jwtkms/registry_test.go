@@ -0,0 +1,227 @@
+package jwtkms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestKeyRegistry(t *testing.T) {
+	registered(t)
+
+	kms := mockkms.NewMockKMS()
+
+	keys := map[string]mockkms.KeyType{
+		"ec-a":  mockkms.KeyTypeECCNISTP256,
+		"ec-b":  mockkms.KeyTypeECCNISTP256,
+		"rsa-a": mockkms.KeyTypeRSA2048,
+	}
+
+	ids := make(map[string]string)
+	for kid, keyType := range keys {
+		id, err := kms.GenerateKey(keyType)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+		ids[kid] = id
+	}
+
+	registry := NewKeyRegistry(kms)
+	for _, entry := range []KeyEntry{
+		{KID: "ec-a", KeyID: ids["ec-a"], Algorithm: "ES256", Tenant: "a"},
+		{KID: "ec-b", KeyID: ids["ec-b"], Algorithm: "ES256", Tenant: "b"},
+		{KID: "rsa-a", KeyID: ids["rsa-a"], Tenant: "a"},
+	} {
+		if err := registry.Add(entry); err != nil {
+			t.Fatalf("Error adding key: %v", err)
+		}
+	}
+
+	if err := registry.Add(KeyEntry{KID: "ec-a", KeyID: ids["ec-a"]}); err == nil {
+		t.Errorf("expected error adding duplicate kid")
+	}
+
+	if e, ok := registry.ByAlias(ids["ec-b"]); !ok || e.KID != "ec-b" {
+		t.Errorf("ByAlias = %+v, %v", e, ok)
+	}
+
+	if entries := registry.ByTenant("a"); len(entries) != 2 {
+		t.Errorf("len(ByTenant(a)) = %d, want 2", len(entries))
+	}
+
+	tests := []struct {
+		name          string
+		signingMethod jwt.SigningMethod
+		provider      KeyProvider
+		wantKID       string
+	}{
+		{name: "first key for alg", signingMethod: SigningMethodECDSA256, provider: registry, wantKID: "ec-a"},
+		{name: "tenant key for alg", signingMethod: SigningMethodECDSA256, provider: registry.Tenant("b"), wantKID: "ec-b"},
+		{name: "key without alg", signingMethod: SigningMethodPS256, provider: registry.Tenant("a"), wantKID: "rsa-a"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signed, err := jwt.NewWithClaims(test.signingMethod, jwt.MapClaims{"claim": "value"}).SignedString(test.provider)
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			_, err = jwt.Parse(signed, func(*jwt.Token) (interface{}, error) {
+				return registry.ConfigForKID(test.wantKID)
+			})
+			if err != nil {
+				t.Errorf("Error verifying token with %s: %v", test.wantKID, err)
+			}
+		})
+	}
+
+	if _, err := jwt.New(SigningMethodES256K).SignedString(registry.Tenant("b")); err == nil {
+		t.Errorf("expected error signing without a matching key")
+	}
+
+	if !registry.Remove("ec-a") || registry.Remove("ec-a") {
+		t.Errorf("unexpected Remove result")
+	}
+}
+
+func TestKeyRegistryRoutesByKeySpec(t *testing.T) {
+	registered(t)
+
+	kms := mockkms.NewMockKMS()
+	registry := NewKeyRegistry(kms)
+
+	for _, key := range []struct {
+		kid     string
+		keyType mockkms.KeyType
+	}{
+		{kid: "hmac", keyType: mockkms.KeyTypeHMAC256},
+		{kid: "ec", keyType: mockkms.KeyTypeECCNISTP256},
+		{kid: "rsa", keyType: mockkms.KeyTypeRSA2048},
+	} {
+		id, err := kms.GenerateKey(key.keyType)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		if err := registry.Add(KeyEntry{KID: key.kid, KeyID: id}); err != nil {
+			t.Fatalf("Error adding key: %v", err)
+		}
+	}
+
+	tests := []struct {
+		signingMethod jwt.SigningMethod
+		wantKID       string
+	}{
+		{signingMethod: SigningMethodECDSA256, wantKID: "ec"},
+		{signingMethod: SigningMethodRS256, wantKID: "rsa"},
+		{signingMethod: SigningMethodPS384, wantKID: "rsa"},
+		{signingMethod: SigningMethodHS256, wantKID: "hmac"},
+	}
+	for _, test := range tests {
+		t.Run(test.signingMethod.Alg(), func(t *testing.T) {
+			signed, err := jwt.New(test.signingMethod).SignedString(registry)
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			_, err = jwt.Parse(signed, func(*jwt.Token) (interface{}, error) {
+				return registry.ConfigForKID(test.wantKID)
+			})
+			if err != nil {
+				t.Errorf("Error verifying token with %s: %v", test.wantKID, err)
+			}
+		})
+	}
+
+	if _, err := jwt.New(SigningMethodECDSA384).SignedString(registry); err == nil {
+		t.Errorf("expected error signing without a key supporting the alg")
+	}
+}
+
+func TestKeyRegistryKIDRoundTrip(t *testing.T) {
+	registered(t)
+
+	kms := mockkms.NewMockKMS()
+	registry := NewKeyRegistry(kms)
+
+	for _, kid := range []string{"a", "b"} {
+		id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		if err := registry.Add(KeyEntry{KID: kid, KeyID: id, Algorithm: "ES256", Tenant: kid}); err != nil {
+			t.Fatalf("Error adding key: %v", err)
+		}
+	}
+
+	for _, kid := range []string{"a", "b"} {
+		t.Run(kid, func(t *testing.T) {
+			token := jwt.New(SigningMethodECDSA256)
+			signed, err := ProviderSignedString(registry.Tenant(kid), token)
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			parsed, err := jwt.Parse(signed, Keyfunc(registry))
+			if err != nil {
+				t.Fatalf("Error verifying token: %v", err)
+			}
+
+			if got := parsed.Header["kid"]; got != kid {
+				t.Errorf("kid = %v, want %s", got, kid)
+			}
+		})
+	}
+
+	// a kid set by the caller is kept, here routing the verification to a key that did not sign the token
+	token := jwt.New(SigningMethodECDSA256)
+	token.Header["kid"] = "b"
+
+	signed, err := ProviderSignedString(registry.Tenant("a"), token)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if _, err := jwt.Parse(signed, Keyfunc(registry)); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Errorf("err = %v, want %v", err, jwt.ErrTokenSignatureInvalid)
+	}
+}
+
+func TestKeyRegistryRoutesHMACByKeySpec(t *testing.T) {
+	registered(t)
+
+	client := &metadataCallsKMS{deniedKMS: &deniedKMS{MockKMS: mockkms.NewMockKMS()}}
+	id, err := client.GenerateKey(mockkms.KeyTypeHMAC256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	registry := NewKeyRegistry(client)
+	if err := registry.Add(KeyEntry{KID: "hmac", KeyID: id}); err != nil {
+		t.Fatalf("Error adding key: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := jwt.New(SigningMethodHS256).SignedString(registry); err != nil {
+			t.Fatalf("Error signing token: %v", err)
+		}
+	}
+
+	// the key spec is described once, the GetPublicKey failure of the HMAC key is not repeated
+	if n := client.getPublicKey.Load(); n != 1 {
+		t.Errorf("GetPublicKey calls = %d, want 1", n)
+	}
+
+	if n := client.describeKey.Load(); n != 1 {
+		t.Errorf("DescribeKey calls = %d, want 1", n)
+	}
+
+	for _, method := range []jwt.SigningMethod{SigningMethodHS384, SigningMethodHS512} {
+		if _, err := jwt.New(method).SignedString(registry); err == nil {
+			t.Errorf("expected error signing %s with an HMAC_256 key", method.Alg())
+		}
+	}
+}
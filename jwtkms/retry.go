@@ -0,0 +1,94 @@
+package jwtkms
+
+import (
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy configures retries of KMS calls failing with ErrThrottled, and with ErrCallTimeout if RetryTimeouts is
+// set, on top of the retries of the AWS SDK.
+//
+// Attempts back off exponentially from BaseDelay up to MaxDelay with full jitter, and stop early when the context of
+// the call is done.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+
+	// BaseDelay is the upper bound of the delay before the first retry, 50ms when zero.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between attempts, 2s when zero.
+	MaxDelay time.Duration
+
+	// RetryTimeouts retries calls timing out with ErrCallTimeout too, e.g. of keys in external key stores.
+	RetryTimeouts bool
+}
+
+const (
+	defaultRetryBaseDelay = 50 * time.Millisecond
+	defaultRetryMaxDelay  = 2 * time.Second
+)
+
+// WithRetry makes the Config retry throttled KMS calls according to policy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Config) {
+		c.retryPolicy = &policy
+	}
+}
+
+// delay returns the jittered delay before retry number attempt, starting at 1.
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	base, maxDelay := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+
+	backoff := maxDelay
+	if shift := attempt - 1; shift < 32 && base<<shift > 0 && base<<shift < maxDelay {
+		backoff = base << shift
+	}
+
+	return rand.N(backoff) + 1
+}
+
+// retryable reports whether a call failing with err is retried.
+func (p *RetryPolicy) retryable(err error) bool {
+	return errors.Is(err, ErrThrottled) || (p.RetryTimeouts && errors.Is(err, ErrCallTimeout))
+}
+
+// retry runs call, retrying it while it fails with a retryable error and the retry policy of c allows.
+func retry[T any](c *Config, call func() (T, error)) (T, error) {
+	out, err := call()
+	if c.retryPolicy == nil {
+		return out, err
+	}
+
+	for attempt := 1; attempt < c.retryPolicy.MaxAttempts && c.retryPolicy.retryable(err); attempt++ {
+		delay := c.retryPolicy.delay(attempt)
+		msg := "retrying throttled KMS call"
+		if errors.Is(err, ErrCallTimeout) {
+			msg = "retrying timed out KMS call"
+		}
+
+		c.log(slog.LevelWarn, msg, slog.Int("attempt", attempt), slog.Duration("delay", delay))
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-c.ctx.Done():
+			timer.Stop()
+			return out, err
+		case <-timer.C:
+		}
+
+		out, err = call()
+	}
+
+	return out, err
+}
@@ -0,0 +1,88 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/smithy-go"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+// throttlingKMS throttles the first throttled Sign calls.
+type throttlingKMS struct {
+	*mockkms.MockKMS
+	throttled int32
+	calls     atomic.Int32
+}
+
+func (c *throttlingKMS) Sign(ctx context.Context, in *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error) {
+	if c.calls.Add(1) <= c.throttled {
+		return nil, &smithy.GenericAPIError{Code: "ThrottlingException"}
+	}
+
+	return c.MockKMS.Sign(ctx, in, optFns...)
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		throttled int32
+		policy    *RetryPolicy
+		wantCalls int32
+		wantErr   error
+	}{
+		{name: "no retry", throttled: 1, wantCalls: 1, wantErr: ErrThrottled},
+		{name: "retried", throttled: 2, policy: &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}, wantCalls: 3},
+		{name: "attempts exhausted", throttled: 5, policy: &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}, wantCalls: 3, wantErr: ErrThrottled},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &throttlingKMS{MockKMS: mockkms.NewMockKMS(), throttled: test.throttled}
+			id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			var opts []Option
+			if test.policy != nil {
+				opts = append(opts, WithRetry(*test.policy))
+			}
+
+			_, err = jwt.New(SigningMethodECDSA256).SignedString(NewConfig(client, id, opts...))
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("error = %v, want %v", err, test.wantErr)
+			}
+
+			if calls := client.calls.Load(); calls != test.wantCalls {
+				t.Errorf("Sign called %d times, want %d", calls, test.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	client := &throttlingKMS{MockKMS: mockkms.NewMockKMS(), throttled: 100}
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	cfg := NewConfig(client, id, WithRetry(RetryPolicy{MaxAttempts: 100, BaseDelay: time.Second, MaxDelay: time.Second}))
+
+	start := time.Now()
+	if _, err := cfg.SignContext(ctx, jwt.New(SigningMethodECDSA256)); !errors.Is(err, ErrThrottled) {
+		t.Errorf("error = %v, want %v", err, ErrThrottled)
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("retries took %v, not bounded by the context", elapsed)
+	}
+}
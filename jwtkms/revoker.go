@@ -0,0 +1,107 @@
+package jwtkms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrTokenRevoked is returned by the verification helpers for tokens their Revoker reports as revoked.
+var ErrTokenRevoked = errors.New("token is revoked")
+
+// Revoker reports whether a token is revoked. It is consulted by Verifier and ParseWithConfig after the signature and
+// claims of a token are validated, with the jti claim of the token, empty if it has none, and its claims.
+// Implementations must be safe for concurrent use.
+type Revoker interface {
+	IsRevoked(ctx context.Context, jti string, claims jwt.Claims) bool
+}
+
+// checkRevoked fails with ErrTokenRevoked if r reports the token with claims as revoked.
+func checkRevoked(ctx context.Context, r Revoker, claims jwt.Claims) error {
+	jti, err := claimsID(claims)
+	if err != nil {
+		return err
+	}
+
+	if r.IsRevoked(ctx, jti, claims) {
+		return fmt.Errorf("%w: jti %q", ErrTokenRevoked, jti)
+	}
+
+	return nil
+}
+
+// claimsID returns the jti claim of claims.
+func claimsID(claims jwt.Claims) (string, error) {
+	switch claims := claims.(type) {
+	case jwt.MapClaims:
+		jti, _ := claims["jti"].(string)
+		return jti, nil
+	case *jwt.RegisteredClaims:
+		return claims.ID, nil
+	}
+
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("marshalling claims: %w", err)
+	}
+
+	var registered jwt.RegisteredClaims
+	if err := json.Unmarshal(data, &registered); err != nil {
+		return "", fmt.Errorf("unmarshalling jti: %w", err)
+	}
+
+	return registered.ID, nil
+}
+
+// MemoryRevoker is an in-memory Revoker of jtis. Revocations are kept until the revoked token expires, so the deny
+// list stays as small as the short-lived tokens it is meant for.
+//
+// A MemoryRevoker is safe for concurrent use.
+type MemoryRevoker struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+var _ Revoker = &MemoryRevoker{}
+
+// NewMemoryRevoker creates an empty MemoryRevoker.
+func NewMemoryRevoker() *MemoryRevoker {
+	return &MemoryRevoker{now: time.Now, revoked: make(map[string]time.Time)}
+}
+
+// Revoke revokes the token with jti until expiresAt, usually its exp claim. Expired revocations are dropped.
+func (r *MemoryRevoker) Revoke(jti string, expiresAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for revokedJTI, until := range r.revoked {
+		if !now.Before(until) {
+			delete(r.revoked, revokedJTI)
+		}
+	}
+
+	if now.Before(expiresAt) {
+		r.revoked[jti] = expiresAt
+	}
+}
+
+// IsRevoked reports whether jti is revoked. Tokens without jti are never revoked.
+func (r *MemoryRevoker) IsRevoked(_ context.Context, jti string, _ jwt.Claims) bool {
+	if jti == "" {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until, ok := r.revoked[jti]
+	return ok && r.now().Before(until)
+}
@@ -0,0 +1,104 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestRevoker(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id)
+
+	exp := time.Now().Add(time.Minute)
+	sign := func(jti string) string {
+		t.Helper()
+
+		signed, err := cfg.SignContext(ctx, jwt.NewWithClaims(SigningMethodECDSA256, jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(exp),
+		}))
+		if err != nil {
+			t.Fatalf("Error signing token: %v", err)
+		}
+
+		return signed
+	}
+
+	revoker := NewMemoryRevoker()
+	revoker.Revoke("revoked", exp)
+
+	verifier := NewConfigVerifier(cfg, VerifierSettings{Revoker: revoker})
+
+	parse := map[string]func(string) error{
+		"verifier": func(token string) error {
+			_, err := verifier.Verify(ctx, token)
+			return err
+		},
+		"ParseWithConfig": func(token string) error {
+			_, err := ParseWithConfig[*jwt.RegisteredClaims](ctx, token, cfg, CheckRevocation(revoker))
+			return err
+		},
+		"ParseWithConfig map claims": func(token string) error {
+			_, err := ParseWithConfig[jwt.MapClaims](ctx, token, cfg, CheckRevocation(revoker))
+			return err
+		},
+	}
+
+	for name, parse := range parse {
+		t.Run(name, func(t *testing.T) {
+			if err := parse(sign("valid")); err != nil {
+				t.Errorf("Error verifying token: %v", err)
+			}
+
+			if err := parse(sign("revoked")); !errors.Is(err, ErrTokenRevoked) {
+				t.Errorf("err = %v, want %v", err, ErrTokenRevoked)
+			}
+
+			if err := parse(sign("")); err != nil {
+				t.Errorf("Error verifying token without jti: %v", err)
+			}
+		})
+	}
+}
+
+func TestMemoryRevoker(t *testing.T) {
+	now := time.Now()
+
+	revoker := NewMemoryRevoker()
+	revoker.now = func() time.Time { return now }
+
+	revoker.Revoke("a", now.Add(time.Minute))
+	revoker.Revoke("b", now.Add(2*time.Minute))
+	revoker.Revoke("expired", now.Add(-time.Second))
+
+	if !revoker.IsRevoked(context.Background(), "a", nil) || !revoker.IsRevoked(context.Background(), "b", nil) {
+		t.Errorf("revoked jtis are not revoked")
+	}
+
+	if revoker.IsRevoked(context.Background(), "expired", nil) {
+		t.Errorf("revocation of an expired token was kept")
+	}
+
+	// once a expired, its revocation is no longer needed and dropped with the next Revoke
+	now = now.Add(90 * time.Second)
+	revoker.Revoke("c", now.Add(time.Minute))
+
+	if revoker.IsRevoked(context.Background(), "a", nil) {
+		t.Errorf("a is revoked after it expired")
+	}
+
+	if len(revoker.revoked) != 2 {
+		t.Errorf("len(revoked) = %d, want 2", len(revoker.revoked))
+	}
+}
@@ -0,0 +1,36 @@
+package jwtkms
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WithVerificationKeyIDs returns a copy of Config that also accepts signatures made by any of keyIDs.
+//
+// It is meant for key rotation: tokens are signed with the Config's key while tokens signed with previous keys are
+// still in flight. Verification tries the Config's key first and then keyIDs in order, every key's public key is
+// cached separately.
+func (c *Config) WithVerificationKeyIDs(keyIDs ...string) *Config {
+	return c.with(WithVerificationKeyIDs(keyIDs...))
+}
+
+// verifyWithRotation calls verify with a Config for every verification key until one succeeds.
+func (c *Config) verifyWithRotation(verify func(cfg *Config) error) error {
+	keyIDs := append([]string{c.kmsKeyID}, c.verificationKeyIDs...)
+
+	errs := make([]error, 0, len(keyIDs))
+	for _, keyID := range keyIDs {
+		keyCfg := *c
+		keyCfg.kmsKeyID = keyID
+		keyCfg.verificationKeyIDs = nil
+
+		err := verify(&keyCfg)
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, fmt.Errorf("verifying with key %s: %w", keyID, err))
+	}
+
+	return errors.Join(errs...)
+}
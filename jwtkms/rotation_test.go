@@ -0,0 +1,62 @@
+package jwtkms
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestVerificationKeyIDs(t *testing.T) {
+	registered(t)
+
+	tests := []struct {
+		name          string
+		keyType       mockkms.KeyType
+		signingMethod jwt.SigningMethod
+	}{
+		{name: "ES256", keyType: mockkms.KeyTypeECCNISTP256, signingMethod: SigningMethodECDSA256},
+		{name: "RS256", keyType: mockkms.KeyTypeRSA2048, signingMethod: SigningMethodRS256},
+		{name: "PS256", keyType: mockkms.KeyTypeRSA2048, signingMethod: SigningMethodPS256},
+		{name: "HS256", keyType: mockkms.KeyTypeHMAC256, signingMethod: SigningMethodHS256},
+		{name: "SM2", keyType: mockkms.KeyTypeSM2, signingMethod: SigningMethodSM2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kms := mockkms.NewMockKMS()
+
+			var ids []string
+			for i := 0; i < 3; i++ {
+				id, err := kms.GenerateKey(test.keyType)
+				if err != nil {
+					t.Fatalf("Error generating key: %v", err)
+				}
+				ids = append(ids, id)
+			}
+
+			newKey, oldKey, unknownKey := ids[0], ids[1], ids[2]
+
+			for _, verifyWithKMS := range []bool{false, true} {
+				cfg := NewKMSConfig(kms, newKey, verifyWithKMS).WithVerificationKeyIDs(oldKey)
+
+				for keyID, wantValid := range map[string]bool{newKey: true, oldKey: true, unknownKey: false} {
+					signed, err := jwt.New(test.signingMethod).SignedString(NewKMSConfig(kms, keyID, false))
+					if err != nil {
+						t.Fatalf("Error signing token: %v", err)
+					}
+
+					_, err = jwt.Parse(signed, func(*jwt.Token) (interface{}, error) {
+						return cfg, nil
+					})
+					if wantValid && err != nil {
+						t.Errorf("Error verifying token of %s (verifyWithKMS %v): %v", keyID, verifyWithKMS, err)
+					}
+
+					if !wantValid && err == nil {
+						t.Errorf("expected error verifying token of %s (verifyWithKMS %v)", keyID, verifyWithKMS)
+					}
+				}
+			}
+		})
+	}
+}
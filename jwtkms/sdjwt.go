@@ -0,0 +1,138 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SDJWT is a selective disclosure JWT, RFC 9901, issued with IssueSDJWT: the issuer-signed JWT and the disclosures
+// of its selectively disclosable claims.
+type SDJWT struct {
+	Token       string
+	Disclosures []string
+}
+
+// String returns the SD-JWT in the combined format the holder receives, the token and every disclosure separated
+// by ~, with a trailing ~.
+func (s *SDJWT) String() string {
+	var b strings.Builder
+
+	b.WriteString(s.Token)
+	b.WriteByte('~')
+
+	for _, disclosure := range s.Disclosures {
+		b.WriteString(disclosure)
+		b.WriteByte('~')
+	}
+
+	return b.String()
+}
+
+// IssueSDJWT signs token, whose claims must be jwt.MapClaims, into an SD-JWT with the Config, using ctx for the KMS
+// calls, for verifiable credential style issuance. The claims named in disclosable, top-level claims or properties of
+// nested objects with a dot separated path like "address.street_address", are replaced by the SHA-256 digests of
+// their salted disclosures in the _sd claims, so the holder chooses which ones to reveal.
+//
+// The claims of token are not modified. The disclosures are in the order of disclosable.
+func (c *Config) IssueSDJWT(ctx context.Context, token *jwt.Token, disclosable []string) (*SDJWT, error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("sd-jwt claims must be jwt.MapClaims, got %T", token.Claims)
+	}
+
+	payload := maps.Clone(claims)
+	if _, ok := payload["_sd_alg"]; ok {
+		return nil, errors.New("claims already have an _sd_alg claim")
+	}
+
+	sdJWT := &SDJWT{Disclosures: make([]string, 0, len(disclosable))}
+
+	for _, path := range disclosable {
+		disclosure, err := disclose(payload, strings.Split(path, "."))
+		if err != nil {
+			return nil, fmt.Errorf("disclosing %q: %w", path, err)
+		}
+
+		sdJWT.Disclosures = append(sdJWT.Disclosures, disclosure)
+	}
+
+	payload["_sd_alg"] = "sha-256"
+
+	signed := &jwt.Token{Header: token.Header, Claims: payload, Method: token.Method}
+
+	var err error
+	if sdJWT.Token, err = c.SignContext(ctx, signed); err != nil {
+		return nil, err
+	}
+
+	return sdJWT, nil
+}
+
+// disclose replaces the claim at path in object, cloning the nested objects on the way, by the digest of its
+// disclosure in the _sd claim of its parent, and returns the disclosure.
+func disclose(object map[string]interface{}, path []string) (string, error) {
+	name := path[0]
+
+	value, ok := object[name]
+	if !ok {
+		return "", fmt.Errorf("claim %q not found", name)
+	}
+
+	if len(path) > 1 {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("claim %q is not an object", name)
+		}
+
+		nested = maps.Clone(nested)
+		object[name] = nested
+
+		return disclose(nested, path[1:])
+	}
+
+	if name == "_sd" || name == "_sd_alg" || name == "..." {
+		return "", fmt.Errorf("claim %q can not be disclosed", name)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generating salt: %w", err)
+	}
+
+	disclosureJSON, err := json.Marshal([]interface{}{base64.RawURLEncoding.EncodeToString(salt), name, value})
+	if err != nil {
+		return "", fmt.Errorf("marshalling disclosure: %w", err)
+	}
+
+	disclosure := base64.RawURLEncoding.EncodeToString(disclosureJSON)
+	digest := sha256.Sum256([]byte(disclosure))
+
+	var digests []string
+	if existing, ok := object["_sd"]; ok {
+		// only the digests of earlier disclosures are extended
+		if digests, ok = existing.([]string); !ok {
+			return "", errors.New("claims already have an _sd claim")
+		}
+
+		digests = slices.Clone(digests)
+	}
+
+	// sorted, so the digests do not reveal the order of the claims
+	digests = append(digests, base64.RawURLEncoding.EncodeToString(digest[:]))
+	slices.Sort(digests)
+
+	delete(object, name)
+	object["_sd"] = digests
+
+	return disclosure, nil
+}
@@ -0,0 +1,138 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestIssueSDJWT(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := NewConfig(client, id)
+
+	claims := jwt.MapClaims{
+		"iss":         "https://issuer.example.com",
+		"given_name":  "Erika",
+		"family_name": "Mustermann",
+		"address":     map[string]interface{}{"street_address": "Heidestrasse 17", "locality": "Koeln"},
+	}
+
+	token := jwt.NewWithClaims(SigningMethodECDSA256, claims)
+	token.Header["typ"] = "dc+sd-jwt"
+
+	sdJWT, err := cfg.IssueSDJWT(ctx, token, []string{"given_name", "family_name", "address.street_address"})
+	if err != nil {
+		t.Fatalf("Error issuing sd-jwt: %v", err)
+	}
+
+	if _, ok := claims["_sd"]; ok || claims["address"].(map[string]interface{})["street_address"] == nil {
+		t.Errorf("claims were modified: %v", claims)
+	}
+
+	if combined := sdJWT.String(); !strings.HasPrefix(combined, sdJWT.Token+"~") || strings.Count(combined, "~") != 4 {
+		t.Errorf("combined format = %s", combined)
+	}
+
+	payload := jwt.MapClaims{}
+	parsed, err := cfg.VerifyContext(ctx, sdJWT.Token, payload)
+	if err != nil {
+		t.Fatalf("Error verifying sd-jwt: %v", err)
+	}
+
+	if parsed.Header["typ"] != "dc+sd-jwt" || payload["_sd_alg"] != "sha-256" {
+		t.Errorf("header = %v, claims = %v", parsed.Header, payload)
+	}
+
+	for _, name := range []string{"given_name", "family_name"} {
+		if _, ok := payload[name]; ok {
+			t.Errorf("claim %s is not hidden", name)
+		}
+	}
+
+	address := payload["address"].(map[string]interface{})
+	if _, ok := address["street_address"]; ok || address["locality"] != "Koeln" {
+		t.Errorf("address = %v", address)
+	}
+
+	digests := func(object map[string]interface{}) []string {
+		var out []string
+		for _, digest := range object["_sd"].([]interface{}) {
+			out = append(out, digest.(string))
+		}
+
+		return out
+	}
+
+	topLevel := digests(payload)
+	nested := digests(address)
+
+	if !slices.IsSorted(topLevel) || len(topLevel) != 2 || len(nested) != 1 {
+		t.Fatalf("digests = %v and %v", topLevel, nested)
+	}
+
+	for i, disclosure := range sdJWT.Disclosures {
+		digest := sha256.Sum256([]byte(disclosure))
+		encoded := base64.RawURLEncoding.EncodeToString(digest[:])
+
+		want := topLevel
+		if i == 2 {
+			want = nested
+		}
+
+		if !slices.Contains(want, encoded) {
+			t.Errorf("digest of disclosure %d not found", i)
+		}
+
+		decoded, err := base64.RawURLEncoding.DecodeString(disclosure)
+		if err != nil {
+			t.Fatalf("Error decoding disclosure: %v", err)
+		}
+
+		var parts []interface{}
+		if err := json.Unmarshal(decoded, &parts); err != nil || len(parts) != 3 {
+			t.Fatalf("disclosure = %s", decoded)
+		}
+	}
+}
+
+func TestIssueSDJWTInvalid(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id)
+
+	tests := []struct {
+		name        string
+		claims      jwt.Claims
+		disclosable []string
+	}{
+		{name: "missing claim", claims: jwt.MapClaims{"sub": "x"}, disclosable: []string{"email"}},
+		{name: "not an object", claims: jwt.MapClaims{"sub": "x"}, disclosable: []string{"sub.id"}},
+		{name: "reserved claim", claims: jwt.MapClaims{"_sd": "x"}, disclosable: []string{"_sd"}},
+		{name: "registered claims", claims: &jwt.RegisteredClaims{Subject: "x"}, disclosable: []string{"sub"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := cfg.IssueSDJWT(context.Background(), jwt.NewWithClaims(SigningMethodECDSA256, tt.claims), tt.disclosable); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// digestHashes are the hash functions of digests, by their size.
+var digestHashes = map[int]crypto.Hash{
+	crypto.SHA256.Size(): crypto.SHA256,
+	crypto.SHA384.Size(): crypto.SHA384,
+	crypto.SHA512.Size(): crypto.SHA512,
+}
+
+// SignDigest signs a digest the caller already computed, e.g. while streaming a large payload, with the key of cfg,
+// using ctx for the KMS calls. The hash function is inferred from the size of digest: SHA-256, SHA-384 or SHA-512,
+// and the SM3 digest for SM2 keys.
+//
+// The signature is encoded as in a JWS with the alg matching the key and the digest: R || S for ECDSA and SM2, like
+// ES256 or ES256K, honouring WithLowS, and PKCS #1 v1.5 for RSA, like RS256. ML-DSA keys sign messages rather than
+// digests and are not supported; KMSSigner signs digests for other algorithms and encodings.
+func SignDigest(ctx context.Context, cfg *Config, digest []byte) ([]byte, error) {
+	cfg = cfg.WithContext(ctx)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	hash, ok := digestHashes[len(digest)]
+	if !ok {
+		return nil, fmt.Errorf("digest is %d bytes, expected the size of a SHA-256, SHA-384 or SHA-512 digest", len(digest))
+	}
+
+	pub, err := getPublicKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, ok := mldsaSignerAlgorithm(pub, hash); ok {
+		return nil, errors.New("ML-DSA keys do not sign digests")
+	}
+
+	algo, messageType, err := signerAlgorithm(pub, digest, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := (&KMSSigner{cfg: cfg}).sign(algo, messageType, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	ecdsaPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return signature, nil
+	}
+
+	jose, err := DERToJOSE(signature, ecdsaPub.Curve.Params().BitSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.lowS && algo != types.SigningAlgorithmSpecSm2dsa {
+		normalizeLowS(jose, ecdsaPub.Curve.Params().N)
+	}
+
+	return jose, nil
+}
@@ -0,0 +1,67 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestSignDigest(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		method  jwt.SigningMethod
+		hash    crypto.Hash
+	}{
+		{name: "ES256", keyType: mockkms.KeyTypeECCNISTP256, method: SigningMethodECDSA256, hash: crypto.SHA256},
+		{name: "ES384", keyType: mockkms.KeyTypeECCNISTP384, method: SigningMethodECDSA384, hash: crypto.SHA384},
+		{name: "ES512", keyType: mockkms.KeyTypeECCNISTP521, method: SigningMethodECDSA512, hash: crypto.SHA512},
+		{name: "ES256K", keyType: mockkms.KeyTypeECCSECGP256K1, method: SigningMethodES256K, hash: crypto.SHA256},
+		{name: "RS256", keyType: mockkms.KeyTypeRSA2048, method: SigningMethodRS256, hash: crypto.SHA256},
+		{name: "RS512", keyType: mockkms.KeyTypeRSA2048, method: SigningMethodRS512, hash: crypto.SHA512},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := mockkms.NewMockKMS()
+			id, err := client.GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			cfg := NewConfig(client, id)
+			signingString := "header.payload"
+
+			hasher := tt.hash.New()
+			hasher.Write([]byte(signingString))
+
+			sig, err := SignDigest(context.Background(), cfg, hasher.Sum(nil))
+			if err != nil {
+				t.Fatalf("Error signing digest: %v", err)
+			}
+
+			if err := tt.method.Verify(signingString, sig, cfg); err != nil {
+				t.Errorf("Error verifying signature: %v", err)
+			}
+		})
+	}
+}
+
+func TestSignDigestInvalid(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	if _, err := SignDigest(context.Background(), NewConfig(client, id), make([]byte, 20)); err == nil {
+		t.Errorf("expected error for a SHA-1 sized digest")
+	}
+
+	if _, err := SignDigest(context.Background(), NewConfig(nil, id), make([]byte, 32)); err == nil {
+		t.Errorf("expected error for a config without kms client")
+	}
+}
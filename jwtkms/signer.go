@@ -0,0 +1,152 @@
+package jwtkms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/emmansun/gmsm/sm2"
+)
+
+// KMSSigner is a crypto.Signer backed by a KMS asymmetric key, usable wherever the standard library accepts one,
+// e.g. x509.CreateCertificateRequest or tls.Certificate.
+//
+// The signing algorithm is derived from the public key and the SignerOpts: ECDSA keys sign with the SHA-2 variant
+// matching opts.HashFunc(), RSA keys use PSS when opts is an *rsa.PSSOptions and PKCS #1 v1.5 otherwise. Signatures
+// are returned in the format the standard library produces, i.e. ASN.1 DER for ECDSA and SM2.
+type KMSSigner struct {
+	cfg *Config
+}
+
+// NewKMSSigner creates a KMSSigner for the key in cfg. The public key is fetched (and cached) right away so
+// configuration problems surface here instead of in Public.
+func NewKMSSigner(cfg *Config) (*KMSSigner, error) {
+	if _, err := getPublicKey(cfg); err != nil {
+		return nil, err
+	}
+
+	return &KMSSigner{cfg: cfg}, nil
+}
+
+// Public returns the public key of the KMS key, or nil if it can not be retrieved.
+func (s *KMSSigner) Public() crypto.PublicKey {
+	pub, err := getPublicKey(s.cfg)
+	if err != nil {
+		return nil
+	}
+
+	return pub
+}
+
+// Sign signs digest with the KMS key. The rand argument is ignored, randomness is provided by KMS.
+func (s *KMSSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	pub, err := getPublicKey(s.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	algo, messageType, err := signerAlgorithm(pub, digest, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sign(algo, messageType, digest)
+}
+
+// sign calls KMS Sign for message, returning the signature as KMS encodes it.
+func (s *KMSSigner) sign(algo types.SigningAlgorithmSpec, messageType types.MessageType, message []byte) ([]byte, error) {
+	signInput := &kms.SignInput{
+		KeyId:            aws.String(s.cfg.kmsKeyID),
+		Message:          message,
+		MessageType:      messageType,
+		SigningAlgorithm: algo,
+	}
+
+	signOutput, err := s.cfg.kmsSign(signInput)
+	if err != nil {
+		return nil, fmt.Errorf("signing digest: %w", err)
+	}
+
+	return signOutput.Signature, nil
+}
+
+var (
+	ecdsaSigningAlgorithms = map[crypto.Hash]types.SigningAlgorithmSpec{
+		crypto.SHA256: types.SigningAlgorithmSpecEcdsaSha256,
+		crypto.SHA384: types.SigningAlgorithmSpecEcdsaSha384,
+		crypto.SHA512: types.SigningAlgorithmSpecEcdsaSha512,
+	}
+
+	rsaSigningAlgorithms = map[crypto.Hash]types.SigningAlgorithmSpec{
+		crypto.SHA256: types.SigningAlgorithmSpecRsassaPkcs1V15Sha256,
+		crypto.SHA384: types.SigningAlgorithmSpecRsassaPkcs1V15Sha384,
+		crypto.SHA512: types.SigningAlgorithmSpecRsassaPkcs1V15Sha512,
+	}
+
+	pssSigningAlgorithms = map[crypto.Hash]types.SigningAlgorithmSpec{
+		crypto.SHA256: types.SigningAlgorithmSpecRsassaPssSha256,
+		crypto.SHA384: types.SigningAlgorithmSpecRsassaPssSha384,
+		crypto.SHA512: types.SigningAlgorithmSpecRsassaPssSha512,
+	}
+)
+
+// signerAlgorithm picks the KMS signing algorithm for a crypto.Signer call.
+func signerAlgorithm(pub crypto.PublicKey, digest []byte, opts crypto.SignerOpts) (types.SigningAlgorithmSpec, types.MessageType, error) {
+	if opts == nil {
+		opts = crypto.Hash(0)
+	}
+
+	if algo, messageType, ok := mldsaSignerAlgorithm(pub, opts); ok {
+		return algo, messageType, nil
+	}
+
+	hash := opts.HashFunc()
+	if hash != 0 && len(digest) != hash.Size() {
+		return "", "", fmt.Errorf("digest is %d bytes, expected %d for %v", len(digest), hash.Size(), hash)
+	}
+
+	var algorithms map[crypto.Hash]types.SigningAlgorithmSpec
+
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		switch {
+		case sm2.IsSM2PublicKey(pub):
+			return types.SigningAlgorithmSpecSm2dsa, types.MessageTypeDigest, nil
+
+		case pub.Curve == secp256k1.S256() && hash != crypto.SHA256:
+			return "", "", fmt.Errorf("secp256k1 keys only support %v", crypto.SHA256)
+		}
+
+		algorithms = ecdsaSigningAlgorithms
+
+	case *rsa.PublicKey:
+		algorithms = rsaSigningAlgorithms
+
+		if pssOpts, ok := opts.(*rsa.PSSOptions); ok {
+			switch pssOpts.SaltLength {
+			case rsa.PSSSaltLengthAuto, rsa.PSSSaltLengthEqualsHash, hash.Size():
+			default:
+				return "", "", errors.New("KMS only supports a PSS salt length equal to the hash length")
+			}
+
+			algorithms = pssSigningAlgorithms
+		}
+
+	default:
+		return "", "", fmt.Errorf("unsupported key type %T", pub)
+	}
+
+	algo, ok := algorithms[hash]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported hash function %v", hash)
+	}
+
+	return algo, types.MessageTypeDigest, nil
+}
@@ -0,0 +1,80 @@
+package jwtkms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestKMSSigner(t *testing.T) {
+	digest := sha256.Sum256([]byte("message"))
+
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		opts    crypto.SignerOpts
+		verify  func(pub crypto.PublicKey, sig []byte) bool
+	}{
+		{
+			name:    "ECDSA",
+			keyType: mockkms.KeyTypeECCNISTP256,
+			opts:    crypto.SHA256,
+			verify: func(pub crypto.PublicKey, sig []byte) bool {
+				return ecdsa.VerifyASN1(pub.(*ecdsa.PublicKey), digest[:], sig)
+			},
+		},
+		{
+			name:    "RSA",
+			keyType: mockkms.KeyTypeRSA2048,
+			opts:    crypto.SHA256,
+			verify: func(pub crypto.PublicKey, sig []byte) bool {
+				return rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA256, digest[:], sig) == nil
+			},
+		},
+		{
+			name:    "PSS",
+			keyType: mockkms.KeyTypeRSA2048,
+			opts:    &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256},
+			verify: func(pub crypto.PublicKey, sig []byte) bool {
+				return rsa.VerifyPSS(pub.(*rsa.PublicKey), crypto.SHA256, digest[:], sig, nil) == nil
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kms := mockkms.NewMockKMS()
+			id, err := kms.GenerateKey(test.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			signer, err := NewKMSSigner(NewKMSConfig(kms, id, false))
+			if err != nil {
+				t.Fatalf("Error creating signer: %v", err)
+			}
+
+			sig, err := signer.Sign(rand.Reader, digest[:], test.opts)
+			if err != nil {
+				t.Fatalf("Error signing digest: %v", err)
+			}
+
+			if !test.verify(signer.Public(), sig) {
+				t.Fatal("Signature does not verify against the public key")
+			}
+
+			_, err = x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+				Subject: pkix.Name{CommonName: "example.com"},
+			}, signer)
+			if err != nil {
+				t.Fatalf("Error creating certificate request: %v", err)
+			}
+		})
+	}
+}
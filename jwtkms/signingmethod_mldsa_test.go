@@ -0,0 +1,145 @@
+//go:build go1.27
+
+package jwtkms
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestMLDSASigningMethod(t *testing.T) {
+	registered(t)
+
+	tests := []struct {
+		name          string
+		keyType       mockkms.KeyType
+		signingMethod jwt.SigningMethod
+	}{
+		{
+			name:          "ML-DSA-44",
+			keyType:       mockkms.KeyTypeMLDSA44,
+			signingMethod: SigningMethodMLDSA44,
+		},
+		{
+			name:          "ML-DSA-65",
+			keyType:       mockkms.KeyTypeMLDSA65,
+			signingMethod: SigningMethodMLDSA65,
+		},
+		{
+			name:          "ML-DSA-87",
+			keyType:       mockkms.KeyTypeMLDSA87,
+			signingMethod: SigningMethodMLDSA87,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			token := jwt.NewWithClaims(test.signingMethod, &jwt.MapClaims{
+				"claim": "value",
+			})
+
+			kms := mockkms.NewMockKMS()
+			id, err := kms.GenerateKey(test.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			config := NewKMSConfig(kms, id, false)
+			signed, err := token.SignedString(config)
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			var claims jwt.MapClaims
+			_, err = jwt.ParseWithClaims(signed, &claims, func(*jwt.Token) (interface{}, error) {
+				return NewKMSConfig(kms, id, false), nil
+			})
+			if err != nil {
+				t.Fatalf("Error validating token offline: %v", err)
+			}
+
+			_, err = jwt.ParseWithClaims(signed, &claims, func(*jwt.Token) (interface{}, error) {
+				return NewKMSConfig(kms, id, true), nil
+			})
+			if err != nil {
+				t.Fatalf("Error validating token online: %v", err)
+			}
+		})
+	}
+}
+
+func TestMLDSAParameterSetMismatch(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeMLDSA65)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	signingString, sig := "header.payload", []byte("signature")
+
+	err = SigningMethodMLDSA44.Verify(signingString, sig, NewConfig(client, id))
+	if !errors.Is(err, ErrIncompatibleKeySpec) {
+		t.Errorf("verify err = %v, want %v", err, ErrIncompatibleKeySpec)
+	}
+
+	pub, err := getPublicKey(NewConfig(client, id))
+	if err != nil {
+		t.Fatalf("Error getting public key: %v", err)
+	}
+
+	err = SigningMethodMLDSA44.Verify(signingString, sig, pub)
+	if !errors.Is(err, ErrIncompatibleKeySpec) {
+		t.Errorf("verify with key err = %v, want %v", err, ErrIncompatibleKeySpec)
+	}
+
+	_, err = SigningMethodMLDSA44.Sign(signingString, NewConfig(client, id))
+	if !errors.Is(err, ErrIncompatibleKeySpec) {
+		t.Errorf("sign err = %v, want %v", err, ErrIncompatibleKeySpec)
+	}
+}
+
+func TestMLDSAExternalMu(t *testing.T) {
+	registered(t)
+
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeMLDSA65)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	token := jwt.NewWithClaims(SigningMethodMLDSA65, &jwt.MapClaims{
+		"claim": strings.Repeat("a", 2*maxRawMessageSize),
+	})
+
+	signed, err := token.SignedString(NewKMSConfig(kms, id, false))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	_, err = jwt.Parse(signed, func(*jwt.Token) (interface{}, error) {
+		return NewKMSConfig(kms, id, false), nil
+	})
+	if err != nil {
+		t.Fatalf("Error validating token offline: %v", err)
+	}
+}
+
+func TestMLDSADefaultSigningMethod(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeMLDSA65)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	method, err := NewConfig(client, id).SigningMethod()
+	if err != nil {
+		t.Fatalf("Error resolving signing method: %v", err)
+	}
+
+	if method != SigningMethodMLDSA65 {
+		t.Errorf("method = %s, want %s", method.Alg(), SigningMethodMLDSA65.Alg())
+	}
+}
@@ -1,13 +1,27 @@
 package jwtkms
 
 import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"math/big"
+	"strings"
+	"sync/atomic"
 	"testing"
 
-	"github.com/golang-jwt/jwt/v4"
-	"github.com/matelang/jwt-go-aws-kms/v2/jwtkms/internal/mockkms"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
 )
 
 func TestSigningMethod(t *testing.T) {
+	registered(t)
+
 	tests := []struct {
 		name          string
 		keyType       mockkms.KeyType
@@ -28,6 +42,11 @@ func TestSigningMethod(t *testing.T) {
 			keyType:       mockkms.KeyTypeECCNISTP521,
 			signingMethod: SigningMethodECDSA512,
 		},
+		{
+			name:          "ES256K",
+			keyType:       mockkms.KeyTypeECCSECGP256K1,
+			signingMethod: SigningMethodES256K,
+		},
 		{
 			name:          "RS256",
 			keyType:       mockkms.KeyTypeRSA2048,
@@ -57,6 +76,26 @@ func TestSigningMethod(t *testing.T) {
 			keyType:       mockkms.KeyTypeRSA2048,
 			signingMethod: SigningMethodPS512,
 		},
+		{
+			name:          "HS256",
+			keyType:       mockkms.KeyTypeHMAC256,
+			signingMethod: SigningMethodHS256,
+		},
+		{
+			name:          "HS384",
+			keyType:       mockkms.KeyTypeHMAC384,
+			signingMethod: SigningMethodHS384,
+		},
+		{
+			name:          "HS512",
+			keyType:       mockkms.KeyTypeHMAC512,
+			signingMethod: SigningMethodHS512,
+		},
+		{
+			name:          "SM2",
+			keyType:       mockkms.KeyTypeSM2,
+			signingMethod: SigningMethodSM2,
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -93,3 +132,346 @@ func TestSigningMethod(t *testing.T) {
 		})
 	}
 }
+
+func TestPSSSaltLength(t *testing.T) {
+	registered(t)
+
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	kmsSigned, err := jwt.New(SigningMethodPS256).SignedString(NewConfig(client, id))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	// a token of another stack, signed with a salt of maximum length
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	maxSaltMethod := &jwt.SigningMethodRSAPSS{
+		SigningMethodRSA: jwt.SigningMethodPS256.SigningMethodRSA,
+		Options:          &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto, Hash: crypto.SHA256},
+	}
+
+	maxSaltSigned, err := jwt.New(maxSaltMethod).SignedString(otherKey)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		keyID      string
+		saltLength int
+		wantErr    string
+	}{
+		{name: "KMS auto", token: kmsSigned, keyID: id, saltLength: rsa.PSSSaltLengthAuto},
+		{name: "KMS equals hash", token: kmsSigned, keyID: id, saltLength: rsa.PSSSaltLengthEqualsHash},
+		{name: "KMS explicit", token: kmsSigned, keyID: id, saltLength: 32},
+		{name: "KMS wrong explicit", token: kmsSigned, keyID: id, saltLength: 20, wantErr: "salt length is not 20 bytes"},
+		{name: "max salt auto", token: maxSaltSigned, keyID: "other", saltLength: rsa.PSSSaltLengthAuto},
+		{
+			name:       "max salt equals hash",
+			token:      maxSaltSigned,
+			keyID:      "other",
+			saltLength: rsa.PSSSaltLengthEqualsHash,
+			wantErr:    "salt length is not the hash length of 32 bytes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig(client, tt.keyID, WithPublicKey("other", &otherKey.PublicKey), WithPSSSaltLength(tt.saltLength))
+
+			// the jwt package parses the PS256 alg with the jwtkms signing method
+			_, err := jwt.Parse(tt.token, func(*jwt.Token) (interface{}, error) { return cfg, nil })
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Error verifying token: %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidSignature) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %v with %q", err, ErrInvalidSignature, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPSSInterop(t *testing.T) {
+	registered(t)
+
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	kmsSigned, err := jwt.New(SigningMethodPS256).SignedString(NewConfig(client, id))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	signWithSalt := func(saltLength int) string {
+		t.Helper()
+
+		method := &jwt.SigningMethodRSAPSS{
+			SigningMethodRSA: jwt.SigningMethodPS256.SigningMethodRSA,
+			Options:          &rsa.PSSOptions{SaltLength: saltLength, Hash: crypto.SHA256},
+		}
+
+		signed, err := jwt.New(method).SignedString(otherKey)
+		if err != nil {
+			t.Fatalf("Error signing token: %v", err)
+		}
+
+		return signed
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		keyID   string
+		wantErr bool
+	}{
+		{name: "KMS", token: kmsSigned, keyID: id},
+		{name: "hash length salt", token: signWithSalt(rsa.PSSSaltLengthEqualsHash), keyID: "other"},
+		{name: "max salt", token: signWithSalt(rsa.PSSSaltLengthAuto), keyID: "other"},
+		{name: "other salt", token: signWithSalt(20), keyID: "other", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// WithPSSInterop takes precedence over the salt length
+			cfg := NewConfig(client, tt.keyID, WithPublicKey("other", &otherKey.PublicKey),
+				WithPSSSaltLength(rsa.PSSSaltLengthEqualsHash), WithPSSInterop())
+
+			_, err := jwt.Parse(tt.token, func(*jwt.Token) (interface{}, error) { return cfg, nil })
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Error verifying token: %v", err)
+				}
+
+				return
+			}
+
+			want := "salt length is not the hash length of 32 bytes or the maximum length of 222 bytes"
+			if !errors.Is(err, ErrInvalidSignature) || !strings.Contains(err.Error(), want) {
+				t.Errorf("err = %v, want %v with %q", err, ErrInvalidSignature, want)
+			}
+		})
+	}
+}
+
+type verifyCountingKMS struct {
+	*mockkms.MockKMS
+	calls atomic.Int32
+}
+
+func (c *verifyCountingKMS) Verify(ctx context.Context, in *kms.VerifyInput, optFns ...func(*kms.Options)) (*kms.VerifyOutput, error) {
+	c.calls.Add(1)
+	return c.MockKMS.Verify(ctx, in, optFns...)
+}
+
+func TestECDSALocalVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		method  *ECDSASigningMethod
+	}{
+		{name: "ES256", keyType: mockkms.KeyTypeECCNISTP256, method: SigningMethodECDSA256},
+		{name: "ES384", keyType: mockkms.KeyTypeECCNISTP384, method: SigningMethodECDSA384},
+		{name: "ES512", keyType: mockkms.KeyTypeECCNISTP521, method: SigningMethodECDSA512},
+		{name: "ES256K", keyType: mockkms.KeyTypeECCSECGP256K1, method: SigningMethodES256K},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &verifyCountingKMS{MockKMS: mockkms.NewMockKMS()}
+			id, err := client.GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			cfg := NewConfig(client, id)
+
+			signingString := "header.payload"
+			sig, err := tt.method.Sign(signingString, cfg)
+			if err != nil {
+				t.Fatalf("Error signing: %v", err)
+			}
+
+			if err := tt.method.Verify(signingString, sig, cfg); err != nil {
+				t.Fatalf("Error verifying signature: %v", err)
+			}
+
+			if client.calls.Load() != 0 {
+				t.Errorf("KMS Verify calls = %d, want 0", client.calls.Load())
+			}
+
+			if err := tt.method.Verify(signingString, sig[:len(sig)-1], cfg); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("err = %v for a truncated signature, want %v", err, ErrInvalidSignature)
+			}
+
+			tampered := append([]byte(nil), sig...)
+			tampered[0] ^= 0xff
+			if err := tt.method.Verify(signingString, tampered, cfg); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("err = %v for a tampered signature, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestECDSALocalVerifyWrongCurve(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP384)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	// a 64 byte signature, as long as an ES256 one
+	err = SigningMethodECDSA256.Verify("header.payload", make([]byte, 64), NewConfig(client, id))
+	if !errors.Is(err, ErrIncompatibleKeySpec) {
+		t.Errorf("err = %v, want %v", err, ErrIncompatibleKeySpec)
+	}
+}
+
+// tamperSignature changes the first character of the signature of a signed token, keeping its length.
+func tamperSignature(signed string) string {
+	i := strings.LastIndex(signed, ".") + 1
+
+	c := byte('A')
+	if signed[i] == c {
+		c = 'B'
+	}
+
+	return signed[:i] + string(c) + signed[i+1:]
+}
+
+func TestFallbackSigningMethod(t *testing.T) {
+	registered(t)
+
+	generateECDSA := func(curve elliptic.Curve) (crypto.Signer, crypto.PublicKey) {
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatalf("Error generating key: %v", err)
+		}
+
+		return key, &key.PublicKey
+	}
+
+	secp256k1Key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	p256Key, p256Pub := generateECDSA(elliptic.P256())
+	p384Key, p384Pub := generateECDSA(elliptic.P384())
+	p521Key, p521Pub := generateECDSA(elliptic.P521())
+
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		key    crypto.Signer
+		pub    crypto.PublicKey
+	}{
+		{name: "ES256", method: SigningMethodECDSA256, key: p256Key, pub: p256Pub},
+		{name: "ES384", method: SigningMethodECDSA384, key: p384Key, pub: p384Pub},
+		{name: "ES512", method: SigningMethodECDSA512, key: p521Key, pub: p521Pub},
+		{name: "ES256K", method: SigningMethodES256K, key: secp256k1Key.ToECDSA(), pub: &secp256k1Key.ToECDSA().PublicKey},
+		{name: "RS256", method: SigningMethodRS256, key: rsaKey, pub: &rsaKey.PublicKey},
+		{name: "PS256", method: SigningMethodPS256, key: rsaKey, pub: &rsaKey.PublicKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, err := jwt.New(tt.method).SignedString(tt.key)
+			if err != nil {
+				t.Fatalf("Error signing token with a local key: %v", err)
+			}
+
+			// the jwtkms signing method is registered for the alg
+			if _, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return tt.pub, nil }); err != nil {
+				t.Fatalf("Error verifying token with a local key: %v", err)
+			}
+
+			if _, err := jwt.Parse(tamperSignature(signed), func(*jwt.Token) (interface{}, error) { return tt.pub, nil }); err == nil {
+				t.Errorf("expected error verifying a tampered token")
+			}
+
+			if _, err := jwt.New(tt.method).SignedString(tt.pub); !errors.Is(err, jwt.ErrInvalidKeyType) {
+				t.Errorf("err = %v signing with a public key, want %v", err, jwt.ErrInvalidKeyType)
+			}
+		})
+	}
+}
+
+func TestLowS(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		method  *ECDSASigningMethod
+	}{
+		{name: "ES256K", keyType: mockkms.KeyTypeECCSECGP256K1, method: SigningMethodES256K},
+		{name: "ES256", keyType: mockkms.KeyTypeECCNISTP256, method: SigningMethodECDSA256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := mockkms.NewMockKMS()
+			id, err := client.GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			cfg := NewConfig(client, id, WithLowS())
+			halfOrder := new(big.Int).Rsh(tt.method.curve.Params().N, 1)
+
+			// a signature is high-S half of the time
+			for i := 0; i < 16; i++ {
+				sig, err := tt.method.Sign("header.payload", cfg)
+				if err != nil {
+					t.Fatalf("Error signing: %v", err)
+				}
+
+				if s := new(big.Int).SetBytes(sig[tt.method.keySize:]); s.Cmp(halfOrder) > 0 {
+					t.Fatalf("signature is high-S")
+				}
+
+				if err := tt.method.Verify("header.payload", sig, cfg); err != nil {
+					t.Fatalf("Error verifying low-S signature: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeLowS(t *testing.T) {
+	n := secp256k1.S256().Params().N
+
+	sig := make([]byte, 64)
+	new(big.Int).Sub(n, big.NewInt(1)).FillBytes(sig[32:])
+
+	normalizeLowS(sig, n)
+
+	if s := new(big.Int).SetBytes(sig[32:]); s.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("s = %v, want 1", s)
+	}
+}
@@ -0,0 +1,70 @@
+package jwtkms
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// TokenTypeJWT is the typ of generic JWTs, e.g. OpenID Connect ID tokens.
+	TokenTypeJWT = "JWT"
+
+	// TokenTypeAccessToken is the typ of JWT access tokens, RFC 9068 section 2.1.
+	TokenTypeAccessToken = "at+jwt"
+)
+
+// ErrInvalidTokenType is returned when verifying a token without the typ header required by WithTokenType.
+var ErrInvalidTokenType = errors.New("token has an invalid typ header")
+
+// WithTokenType makes the Config set and require the typ header typ, see Config.WithTokenType.
+func WithTokenType(typ string) Option {
+	return func(c *Config) {
+		c.tokenType = typ
+	}
+}
+
+// WithTokenType returns a copy of Config whose SignedString sets the typ header of tokens to typ, e.g.
+// TokenTypeAccessToken, and that verifies only tokens with that typ through VerifyContext, Keyfunc, Verifier and
+// ParseWithConfig; tokens with another or no typ fail with ErrInvalidTokenType. Types are compared like RFC 7515
+// section 4.1.9 does, case-insensitively and with an optional "application/" prefix.
+//
+// Tokens verified with a jwt.Keyfunc returning the Config directly are not checked, the signing methods do not see
+// the header.
+func (c *Config) WithTokenType(typ string) *Config {
+	return c.with(WithTokenType(typ))
+}
+
+// checkTokenType fails if the Config requires a typ header token does not have.
+func (c *Config) checkTokenType(token *jwt.Token) error {
+	if c.tokenType == "" {
+		return nil
+	}
+
+	return requireTokenType(token, c.tokenType)
+}
+
+// requireTokenType fails if token does not have the typ header typ.
+func requireTokenType(token *jwt.Token, typ string) error {
+	got, _ := token.Header["typ"].(string)
+	if !sameTokenType(got, typ) {
+		return fmt.Errorf("%w: %q, want %q", ErrInvalidTokenType, got, typ)
+	}
+
+	return nil
+}
+
+// sameTokenType reports whether the typ headers a and b are the same type.
+func sameTokenType(a, b string) bool {
+	trim := func(typ string) string {
+		if len(typ) > len("application/") && strings.EqualFold(typ[:len("application/")], "application/") {
+			return typ[len("application/"):]
+		}
+
+		return typ
+	}
+
+	return a != "" && strings.EqualFold(trim(a), trim(b))
+}
@@ -0,0 +1,97 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestWithTokenType(t *testing.T) {
+	registered(t)
+
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	claims := jwt.MapClaims{"exp": time.Now().Add(time.Hour).Unix()}
+	cfg := NewConfig(client, id, WithKeyIDHeader(), WithTokenType(TokenTypeAccessToken))
+
+	accessToken, err := cfg.SignContext(ctx, jwt.NewWithClaims(SigningMethodECDSA256, claims))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	idToken, err := NewBuilder(NewConfig(client, id, WithKeyIDHeader())).WithType(TokenTypeJWT).WithClaims(claims).Sign(ctx)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	prefixed, err := NewBuilder(NewConfig(client, id, WithKeyIDHeader())).WithType("application/AT+JWT").WithClaims(claims).Sign(ctx)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	untyped, err := NewConfig(client, id, WithKeyIDHeader()).SignContext(ctx, jwt.NewWithClaims(SigningMethodECDSA256, claims))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	verifiers := map[string]func(token string) error{
+		"VerifyContext": func(token string) error {
+			_, err := cfg.VerifyContext(ctx, token, jwt.MapClaims{})
+			return err
+		},
+		"Keyfunc": func(token string) error {
+			_, err := jwt.Parse(token, Keyfunc(StaticKeySet{mockkms.KeyARN(id): cfg}))
+			return err
+		},
+		"Verifier": func(token string) error {
+			_, err := NewVerifier(StaticKeySet{mockkms.KeyARN(id): cfg}, VerifierSettings{}).Verify(ctx, token)
+			return err
+		},
+		"ParseWithConfig": func(token string) error {
+			_, err := ParseWithConfig[jwt.MapClaims](ctx, token, cfg)
+			return err
+		},
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "access token", token: accessToken},
+		{name: "media type", token: prefixed},
+		{name: "id token", token: idToken, wantErr: true},
+		{name: "no typ", token: untyped, wantErr: true},
+	}
+
+	for name, verify := range verifiers {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				err := verify(tt.token)
+				if !tt.wantErr {
+					if err != nil {
+						t.Errorf("Error verifying token: %v", err)
+					}
+					return
+				}
+
+				if !errors.Is(err, ErrInvalidTokenType) {
+					t.Errorf("err = %v, want %v", err, ErrInvalidTokenType)
+				}
+			})
+		}
+	}
+
+	if _, err := ParseWithConfig[jwt.MapClaims](ctx, idToken, NewConfig(client, id), RequireType(TokenTypeAccessToken)); !errors.Is(err, ErrInvalidTokenType) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidTokenType)
+	}
+}
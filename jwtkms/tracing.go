@@ -0,0 +1,57 @@
+package jwtkms
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the instrumentation scope of the spans of the package.
+const tracerName = "github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+
+// Span attribute keys.
+const (
+	attributeKeyID      = attribute.Key("aws.kms.key_id")
+	attributeKeyARN     = attribute.Key("aws.kms.key_arn")
+	attributeAlgorithm  = attribute.Key("jwtkms.algorithm")
+	attributeVerifyMode = attribute.Key("jwtkms.verify_mode")
+	attributeCacheHit   = attribute.Key("jwtkms.cache_hit")
+)
+
+// WithTracerProvider makes the Config create OpenTelemetry spans for signing, verification and public key lookups,
+// as children of the span in the Config's context, see WithContext and the *Context methods. KMS API calls are made
+// with the span's context, so spans of an instrumented AWS SDK client are nested below.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Config) {
+		c.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// startSpan starts a span named name if the Config has a tracer and returns a copy of c using the span's context.
+func (c *Config) startSpan(name string, attrs ...attribute.KeyValue) (*Config, trace.Span) {
+	if c.tracer == nil {
+		return c, noop.Span{}
+	}
+
+	attrs = append(attrs, attributeKeyID.String(c.kmsKeyID))
+	if known := c.knownPublicKey(); known != nil && known.KeyARN != "" {
+		attrs = append(attrs, attributeKeyARN.String(known.KeyARN))
+	}
+
+	ctx, span := c.tracer.Start(c.ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+
+	return c.with(func(c *Config) {
+		c.ctx = ctx
+	}), span
+}
+
+// endSpan ends span, recording err if not nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,119 @@
+package jwtkms
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+
+	return attribute.Value{}, false
+}
+
+func TestTracing(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	cfg := NewConfig(kms, id, WithTracerProvider(tp))
+
+	signed, err := cfg.SignContext(ctx, jwt.New(SigningMethodECDSA256))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if _, err := cfg.VerifyContext(ctx, signed, jwt.MapClaims{}); err != nil {
+		t.Fatalf("Error verifying token: %v", err)
+	}
+
+	if _, err := cfg.WithVerifyMode(VerifyWithKMS).VerifyContext(ctx, signed+"x", jwt.MapClaims{}); err == nil {
+		t.Fatalf("expected error verifying a tampered token")
+	}
+
+	parent.End()
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+
+	// signing fetches the key's metadata, local verification finds the public key cached
+	want := []string{"jwtkms.GetPublicKey", "jwtkms.Sign", "jwtkms.GetPublicKey", "jwtkms.Verify", "jwtkms.Verify", "request"}
+	if len(names) != len(want) {
+		t.Fatalf("spans = %v, want %v", names, want)
+	}
+
+	for i, name := range want {
+		if names[i] != name {
+			t.Fatalf("spans = %v, want %v", names, want)
+		}
+	}
+
+	spans := recorder.Ended()
+	for _, span := range spans[:5] {
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() && span.Name() != "jwtkms.GetPublicKey" {
+			t.Errorf("span %s is not a child of the caller's span", span.Name())
+		}
+
+		if v, _ := spanAttribute(span, attributeKeyID); v.AsString() != id {
+			t.Errorf("span %s key id = %q, want %q", span.Name(), v.AsString(), id)
+		}
+	}
+
+	if v, _ := spanAttribute(spans[1], attributeAlgorithm); v.AsString() != "ECDSA_SHA_256" {
+		t.Errorf("sign span algorithm = %q, want ECDSA_SHA_256", v.AsString())
+	}
+
+	getPublicKey := spans[0]
+	if v, ok := spanAttribute(getPublicKey, attributeCacheHit); !ok || v.AsBool() {
+		t.Errorf("GetPublicKey span cache hit = %v, want false", v.AsBool())
+	}
+
+	if v, _ := spanAttribute(getPublicKey, attributeKeyARN); v.AsString() != mockkms.KeyARN(id) {
+		t.Errorf("GetPublicKey span key ARN = %q, want %q", v.AsString(), mockkms.KeyARN(id))
+	}
+
+	cacheHit := spans[2]
+	if cacheHit.Parent().SpanID() != spans[3].SpanContext().SpanID() {
+		t.Errorf("GetPublicKey span is not a child of the Verify span")
+	}
+
+	if v, ok := spanAttribute(cacheHit, attributeCacheHit); !ok || !v.AsBool() {
+		t.Errorf("GetPublicKey span of the verification cache hit = %v, want true", v.AsBool())
+	}
+
+	if v, _ := spanAttribute(spans[4], attributeVerifyMode); v.AsString() != "kms" || spans[4].Status().Code != codes.Error {
+		t.Errorf("unexpected KMS verify span mode %q status %v", v.AsString(), spans[4].Status())
+	}
+}
+
+func TestTracingDisabled(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(kms, id)
+	if _, span := cfg.startSpan("jwtkms.Sign"); span.IsRecording() {
+		t.Errorf("expected a non recording span without a tracer provider")
+	}
+}
@@ -0,0 +1,43 @@
+package jwtkms
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Transport is an http.RoundTripper setting the Authorization header of requests to a bearer token of a
+// BearerTokenSource, signed with the context of the request when a new one is needed.
+//
+// A Transport is safe for concurrent use.
+type Transport struct {
+	source *BearerTokenSource
+	base   http.RoundTripper
+}
+
+var _ http.RoundTripper = &Transport{}
+
+// NewTransport creates a Transport sending requests with base, http.DefaultTransport when nil.
+func NewTransport(source *BearerTokenSource, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{source: source, base: base}
+}
+
+// RoundTrip sends a copy of req with the Authorization header set to the bearer token.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+
+		return nil, fmt.Errorf("signing bearer token: %w", err)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,394 @@
+package jwtkms
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/aws/smithy-go"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrInvalidConfig is matched by every ConfigError.
+	ErrInvalidConfig = errors.New("invalid config")
+
+	// ErrIncompatibleKeySpec is reported when the KMS key does not support the signing algorithm of a signing method.
+	ErrIncompatibleKeySpec = errors.New("key spec is incompatible with the signing method")
+)
+
+// ConfigError reports which part of a Config is invalid.
+type ConfigError struct {
+	Field string
+	Err   error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid config %s: %v", e.Field, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrInvalidConfig.
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+// kmsSigningMethod is implemented by the signing methods of this package.
+type kmsSigningMethod interface {
+	jwt.SigningMethod
+
+	// kmsSigningAlgorithm returns the KMS signing algorithm, or "" for methods not backed by an asymmetric key.
+	kmsSigningAlgorithm() types.SigningAlgorithmSpec
+
+	// supportsKeySpec reports whether the method signs with keys of keySpec.
+	supportsKeySpec(keySpec types.KeySpec) bool
+}
+
+func (m *ECDSASigningMethod) kmsSigningAlgorithm() types.SigningAlgorithmSpec {
+	return types.SigningAlgorithmSpec(m.algo)
+}
+
+func (m *RSASigningMethod) kmsSigningAlgorithm() types.SigningAlgorithmSpec {
+	return types.SigningAlgorithmSpec(m.algo)
+}
+
+func (m *SM2SigningMethod) kmsSigningAlgorithm() types.SigningAlgorithmSpec {
+	return types.SigningAlgorithmSpecSm2dsa
+}
+
+func (m *HMACSigningMethod) kmsSigningAlgorithm() types.SigningAlgorithmSpec {
+	return ""
+}
+
+// ecdsaKeySpecs are the key specs of the curves of the ECDSA signing methods.
+var ecdsaKeySpecs = map[string]types.KeySpec{
+	"P-256":     types.KeySpecEccNistP256,
+	"P-384":     types.KeySpecEccNistP384,
+	"P-521":     types.KeySpecEccNistP521,
+	"secp256k1": types.KeySpecEccSecgP256k1,
+}
+
+func (m *ECDSASigningMethod) supportsKeySpec(keySpec types.KeySpec) bool {
+	return ecdsaKeySpecs[m.curve.Params().Name] == keySpec
+}
+
+func (m *RSASigningMethod) supportsKeySpec(keySpec types.KeySpec) bool {
+	return strings.HasPrefix(string(keySpec), "RSA_")
+}
+
+func (m *SM2SigningMethod) supportsKeySpec(keySpec types.KeySpec) bool {
+	return keySpec == types.KeySpecSm2
+}
+
+// hmacKeySpecs are the key specs of the MAC algorithms of the HMAC signing methods.
+var hmacKeySpecs = map[string]types.KeySpec{
+	"HMAC_SHA_256": types.KeySpecHmac256,
+	"HMAC_SHA_384": types.KeySpecHmac384,
+	"HMAC_SHA_512": types.KeySpecHmac512,
+}
+
+func (m *HMACSigningMethod) supportsKeySpec(keySpec types.KeySpec) bool {
+	return hmacKeySpecs[m.algo] == keySpec
+}
+
+// Validate checks the Config for problems that would otherwise only surface inside a KMS call: a missing client or key
+// id, malformed key ARNs and reserved protected headers. The returned error is a *ConfigError.
+//
+// Signing methods validate the Config on every use, so calling it is only needed to fail early, e.g. at startup.
+func (c *Config) Validate() error {
+	if c.kmsClient == nil && !c.offline {
+		return &ConfigError{Field: "KMSClient", Err: errors.New("kms client is nil")}
+	}
+
+	if c.ctx == nil {
+		return &ConfigError{Field: "Context", Err: errors.New("context is nil")}
+	}
+
+	if err := validateKeyID(c.kmsKeyID); err != nil {
+		return &ConfigError{Field: "KeyID", Err: err}
+	}
+
+	for _, keyID := range c.verificationKeyIDs {
+		if err := validateKeyID(keyID); err != nil {
+			return &ConfigError{Field: "VerificationKeyIDs", Err: err}
+		}
+	}
+
+	if err := checkProtectedHeaders(c.protectedHeaders); err != nil {
+		return &ConfigError{Field: "ProtectedHeaders", Err: err}
+	}
+
+	if _, ok := c.signingMethod.(kmsSigningMethod); c.signingMethod != nil && !ok {
+		return &ConfigError{
+			Field: "SigningMethod",
+			Err:   fmt.Errorf("%s is not a jwtkms signing method", c.signingMethod.Alg()),
+		}
+	}
+
+	return nil
+}
+
+// ValidateFor validates the Config like Validate and checks that the KMS key supports the signing algorithm of
+// method, fetching the key's metadata with GetPublicKey.
+func (c *Config) ValidateFor(method jwt.SigningMethod) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	m, ok := method.(kmsSigningMethod)
+	if !ok {
+		return &ConfigError{Field: "SigningMethod", Err: fmt.Errorf("%s is not a jwtkms signing method", method.Alg())}
+	}
+
+	if m.kmsSigningAlgorithm() == "" {
+		return nil
+	}
+
+	cached, err := getCachedPublicKey(c)
+	if err != nil {
+		return err
+	}
+
+	return checkSigningAlgorithm(c.kmsKeyID, cached, m)
+}
+
+// prepareVerify runs the steps shared by the Verify of every signing method before the signature is checked: it rejects
+// algorithms the Config does not allow, reports the verification to the observers of the Config, verifies against every
+// key of a rotating Config, validates the Config, verifies locally while KMS is unavailable and retries local
+// verification with a refetched public key. When done is true the verification is complete and err is its result.
+func (c *Config) prepareVerify(m kmsSigningMethod, signingString string, sig []byte) (done bool, err error) {
+	if err := c.checkAllowedAlgorithm(m); err != nil {
+		return true, err
+	}
+
+	if c.observesVerify() {
+		return true, c.observeVerify(m, func(cfg *Config) error {
+			return m.Verify(signingString, sig, cfg)
+		})
+	}
+
+	if len(c.verificationKeyIDs) > 0 {
+		return true, c.verifyWithRotation(func(cfg *Config) error {
+			return m.Verify(signingString, sig, cfg)
+		})
+	}
+
+	validate := c.validateVerify
+	if !c.verifyWithKMS {
+		// local verification checks the public key it verifies with itself, e.g. its curve
+		validate = c.validateKnown
+	}
+
+	if err := validate(m); err != nil {
+		return true, err
+	}
+
+	if c.verifyWithKMS && c.localVerifyFallback && c.circuitBreaker != nil && c.circuitBreaker.Open() {
+		c.log(slog.LevelWarn, "circuit breaker open, verifying locally")
+
+		return true, m.Verify(signingString, sig, c.WithVerifyMode(VerifyLocally))
+	}
+
+	if c.refetchOnInvalidSignature && !c.verifyRefetching && !c.verifyWithKMS && m.kmsSigningAlgorithm() != "" &&
+		c.providedPublicKeys[c.kmsKeyID] == nil {
+		return true, c.verifyWithRefetch(func(cfg *Config) error {
+			return m.Verify(signingString, sig, cfg)
+		})
+	}
+
+	return false, nil
+}
+
+// validate is the check signing methods run before signing: Validate plus the signing algorithm check against the
+// key's metadata, see keyMetadata, so KMS never signs with a key of another key spec, e.g. ES256 with a secp256k1 key.
+// The signing algorithm is not checked when the metadata can not be fetched, leaving the key to KMS.
+func (c *Config) validate(m kmsSigningMethod) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	if m.kmsSigningAlgorithm() == "" {
+		return nil
+	}
+
+	metadata, err := c.keyMetadata()
+	if err != nil {
+		return nil
+	}
+
+	return checkSigningAlgorithm(c.kmsKeyID, metadata, m)
+}
+
+// validateVerify is validate for verification with KMS, failing with the error of keyMetadata when the metadata can
+// not be fetched, so a token is never verified with a key of another key spec.
+func (c *Config) validateVerify(m kmsSigningMethod) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	if m.kmsSigningAlgorithm() == "" {
+		return nil
+	}
+
+	metadata, err := c.keyMetadata()
+	if err != nil {
+		return err
+	}
+
+	return checkSigningAlgorithm(c.kmsKeyID, metadata, m)
+}
+
+// validateKnown is validate checking the signing algorithm only if the key's metadata is already known.
+func (c *Config) validateKnown(m kmsSigningMethod) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	if known := c.knownPublicKey(); known != nil && m.kmsSigningAlgorithm() != "" {
+		return checkSigningAlgorithm(c.kmsKeyID, known, m)
+	}
+
+	return nil
+}
+
+// KeySpecMismatchError reports a signing method used with a KMS key it can not sign with, detected from the key's
+// metadata instead of the opaque ValidationException KMS answers with. It matches ErrIncompatibleKeySpec.
+type KeySpecMismatchError struct {
+	KeyID string
+
+	// Alg is the alg of the signing method.
+	Alg string
+
+	// KeySpec and SigningAlgorithms are the key spec and signing algorithms of the key.
+	KeySpec           types.KeySpec
+	SigningAlgorithms []types.SigningAlgorithmSpec
+
+	// ExpectedKeySpecs are the key specs the signing method signs with, SigningAlgorithm its KMS signing algorithm.
+	ExpectedKeySpecs []types.KeySpec
+	SigningAlgorithm types.SigningAlgorithmSpec
+
+	// Err is the KMS error of the call the mismatch was detected for, nil if detected before calling KMS.
+	Err error
+}
+
+func (e *KeySpecMismatchError) Error() string {
+	if !slices.Contains(e.ExpectedKeySpecs, e.KeySpec) {
+		expected := make([]string, len(e.ExpectedKeySpecs))
+		for i, keySpec := range e.ExpectedKeySpecs {
+			expected[i] = string(keySpec)
+		}
+
+		return fmt.Sprintf("%s key %q can not sign %s, which needs a key of key spec %s", e.KeySpec, e.KeyID, e.Alg,
+			strings.Join(expected, ", "))
+	}
+
+	return fmt.Sprintf("%s key %q supports %v, %s needs %s", e.KeySpec, e.KeyID, e.SigningAlgorithms, e.Alg,
+		e.SigningAlgorithm)
+}
+
+func (e *KeySpecMismatchError) Is(target error) bool {
+	return target == ErrIncompatibleKeySpec
+}
+
+func (e *KeySpecMismatchError) Unwrap() error {
+	return e.Err
+}
+
+// checkSigningAlgorithm returns a *KeySpecMismatchError in a ConfigError if the key of keyID with the metadata cached
+// can not sign with m.
+func checkSigningAlgorithm(keyID string, cached *CachedPublicKey, m kmsSigningMethod) error {
+	if mismatch := keySpecMismatch(keyID, cached, m); mismatch != nil {
+		return &ConfigError{Field: "KeyID", Err: mismatch}
+	}
+
+	return nil
+}
+
+// keySpecMismatch returns the *KeySpecMismatchError of checkSigningAlgorithm, nil if the key can sign with m.
+func keySpecMismatch(keyID string, cached *CachedPublicKey, m kmsSigningMethod) *KeySpecMismatchError {
+	specMismatch := cached.KeySpec != "" && !m.supportsKeySpec(cached.KeySpec)
+	algorithmSupported := len(cached.SigningAlgorithms) == 0 ||
+		slices.Contains(cached.SigningAlgorithms, m.kmsSigningAlgorithm())
+
+	if !specMismatch && algorithmSupported {
+		return nil
+	}
+
+	var expected []types.KeySpec
+	for _, keySpec := range types.KeySpec("").Values() {
+		if m.supportsKeySpec(keySpec) {
+			expected = append(expected, keySpec)
+		}
+	}
+
+	return &KeySpecMismatchError{
+		KeyID:             keyID,
+		Alg:               m.Alg(),
+		KeySpec:           cached.KeySpec,
+		SigningAlgorithms: cached.SigningAlgorithms,
+		ExpectedKeySpecs:  expected,
+		SigningAlgorithm:  m.kmsSigningAlgorithm(),
+	}
+}
+
+// diagnoseSignError replaces the error of a KMS Sign or GenerateMac call rejecting the request, which KMS does for a
+// signing or MAC algorithm the key does not support, with a *KeySpecMismatchError if the key's metadata, see
+// keyMetadata, shows a mismatch.
+func (c *Config) diagnoseSignError(m kmsSigningMethod, err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	switch apiErr.ErrorCode() {
+	case "ValidationException", "InvalidKeyUsageException":
+	default:
+		return err
+	}
+
+	metadata, fetchErr := c.keyMetadata()
+	if fetchErr != nil {
+		return err
+	}
+
+	mismatch := keySpecMismatch(c.kmsKeyID, metadata, m)
+	if mismatch == nil {
+		return err
+	}
+
+	mismatch.Err = err
+
+	return &ConfigError{Field: "KeyID", Err: mismatch}
+}
+
+func validateKeyID(keyID string) error {
+	if keyID == "" {
+		return errors.New("key id is empty")
+	}
+
+	if !strings.HasPrefix(keyID, "arn:") {
+		return nil
+	}
+
+	parsed, err := arn.Parse(keyID)
+	if err != nil {
+		return fmt.Errorf("malformed arn %q: %w", keyID, err)
+	}
+
+	if parsed.Service != "kms" {
+		return fmt.Errorf("arn %q is not a kms arn", keyID)
+	}
+
+	if !strings.HasPrefix(parsed.Resource, "key/") && !strings.HasPrefix(parsed.Resource, "alias/") {
+		return fmt.Errorf("arn %q is neither a key nor an alias arn", keyID)
+	}
+
+	return nil
+}
@@ -0,0 +1,242 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestValidate(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+
+	tests := []struct {
+		name      string
+		cfg       *Config
+		wantField string
+	}{
+		{name: "valid key id", cfg: NewKMSConfig(kms, "1234abcd-12ab-34cd-56ef-1234567890ab", false)},
+		{name: "valid key arn", cfg: NewKMSConfig(kms, "arn:aws:kms:us-east-1:111122223333:key/1234abcd", false)},
+		{name: "valid alias arn", cfg: NewKMSConfig(kms, "arn:aws:kms:us-east-1:111122223333:alias/signing", false)},
+		{name: "nil client", cfg: NewKMSConfig(nil, "key", false), wantField: "KMSClient"},
+		{name: "empty key id", cfg: NewKMSConfig(kms, "", false), wantField: "KeyID"},
+		{name: "malformed arn", cfg: NewKMSConfig(kms, "arn:aws:kms", false), wantField: "KeyID"},
+		{name: "non kms arn", cfg: NewKMSConfig(kms, "arn:aws:s3:::bucket/key", false), wantField: "KeyID"},
+		{name: "non key arn", cfg: NewKMSConfig(kms, "arn:aws:kms:us-east-1:111122223333:grant/1", false), wantField: "KeyID"},
+		{
+			name:      "invalid verification key",
+			cfg:       NewKMSConfig(kms, "key", false).WithVerificationKeyIDs("arn:aws:kms"),
+			wantField: "VerificationKeyIDs",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.cfg.Validate()
+			if test.wantField == "" {
+				if err != nil {
+					t.Errorf("Error validating config: %v", err)
+				}
+				return
+			}
+
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) || !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("Validate error = %v, want a *ConfigError", err)
+			}
+
+			if configErr.Field != test.wantField {
+				t.Errorf("Field = %s, want %s", configErr.Field, test.wantField)
+			}
+		})
+	}
+}
+
+func TestValidateFor(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewKMSConfig(kms, id, false)
+
+	if err := cfg.ValidateFor(SigningMethodPS256); err != nil {
+		t.Errorf("Error validating config for PS256: %v", err)
+	}
+
+	if err := cfg.ValidateFor(SigningMethodECDSA256); !errors.Is(err, ErrIncompatibleKeySpec) {
+		t.Errorf("ValidateFor(ES256) error = %v, want %v", err, ErrIncompatibleKeySpec)
+	}
+
+	if err := cfg.ValidateFor(jwt.SigningMethodHS256); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("ValidateFor(jwt.HS256) error = %v, want %v", err, ErrInvalidConfig)
+	}
+
+	// the key metadata is cached now, so signing with an incompatible method fails before calling KMS
+	if _, err := jwt.New(SigningMethodECDSA256).SignedString(cfg); !errors.Is(err, ErrIncompatibleKeySpec) {
+		t.Errorf("signing error = %v, want %v", err, ErrIncompatibleKeySpec)
+	}
+
+	if _, err := jwt.New(SigningMethodRS256).SignedString(NewKMSConfig(nil, id, false)); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("signing error = %v, want %v", err, ErrInvalidConfig)
+	}
+}
+
+func TestKeySpecBinding(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCSECGP256K1)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+
+	// KMS signs and verifies ECDSA_SHA_256 with any 256 bit curve, ES256K signs an ES256 token with the secp256k1 key
+	token := jwt.New(SigningMethodECDSA256)
+
+	signingString, err := token.SigningString()
+	if err != nil {
+		t.Fatalf("Error encoding token: %v", err)
+	}
+
+	sig, err := SigningMethodES256K.Sign(signingString, NewConfig(client, id))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	signed := signingString + "." + token.EncodeSegment(sig)
+
+	preloaded := NewConfig(client, id, WithVerifyWithKMS(true))
+	if err := preloaded.PreloadKeys(ctx); err != nil {
+		t.Fatalf("Error preloading key: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{name: "verify locally", run: func() error {
+			_, err := NewConfig(client, id).VerifyContext(ctx, signed, jwt.MapClaims{})
+			return err
+		}},
+		{name: "verify with KMS", run: func() error {
+			_, err := NewConfig(client, id, WithVerifyWithKMS(true)).VerifyContext(ctx, signed, jwt.MapClaims{})
+			return err
+		}},
+		{name: "verify with KMS and known key spec", run: func() error {
+			_, err := preloaded.VerifyContext(ctx, signed, jwt.MapClaims{})
+			return err
+		}},
+		{name: "sign", run: func() error {
+			_, err := NewConfig(client, id).SignContext(ctx, jwt.New(SigningMethodECDSA256))
+			return err
+		}},
+		{name: "sign with known key spec", run: func() error {
+			_, err := preloaded.SignContext(ctx, jwt.New(SigningMethodECDSA256))
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+
+			var mismatch *KeySpecMismatchError
+			if !errors.Is(err, ErrIncompatibleKeySpec) {
+				t.Errorf("err = %v, want %v", err, ErrIncompatibleKeySpec)
+			} else if tt.name != "verify locally" && !errors.As(err, &mismatch) {
+				t.Errorf("err = %v, want a *KeySpecMismatchError", err)
+			}
+		})
+	}
+}
+
+func TestKeySpecMismatchError(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		cached bool
+		method jwt.SigningMethod
+	}{
+		// the metadata is fetched before KMS is asked to sign
+		{name: "uncached", method: SigningMethodPS256},
+		{name: "cached", cached: true, method: SigningMethodPS256},
+		{name: "cached wrong curve", cached: true, method: SigningMethodECDSA384},
+		{name: "uncached SM2", method: SigningMethodSM2},
+		{name: "cached SM2", cached: true, method: SigningMethodSM2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig(client, id)
+			if tt.cached {
+				if err := cfg.ValidateFor(SigningMethodECDSA256); err != nil {
+					t.Fatalf("Error validating config: %v", err)
+				}
+			}
+
+			_, err := jwt.New(tt.method).SignedString(cfg)
+
+			var mismatch *KeySpecMismatchError
+			if !errors.As(err, &mismatch) || !errors.Is(err, ErrIncompatibleKeySpec) || !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("err = %v, want a *KeySpecMismatchError", err)
+			}
+
+			if mismatch.KeySpec != types.KeySpecEccNistP256 || mismatch.Alg != tt.method.Alg() || mismatch.KeyID != id {
+				t.Errorf("KeySpec, Alg, KeyID = %s, %s, %s, want %s, %s, %s", mismatch.KeySpec, mismatch.Alg, mismatch.KeyID,
+					types.KeySpecEccNistP256, tt.method.Alg(), id)
+			}
+
+			if mismatch.Err != nil {
+				t.Errorf("Err = %v, want none, the mismatch is detected before calling KMS", mismatch.Err)
+			}
+		})
+	}
+
+	mismatch := &KeySpecMismatchError{
+		KeyID:            id,
+		Alg:              "PS256",
+		KeySpec:          types.KeySpecEccNistP256,
+		ExpectedKeySpecs: []types.KeySpec{types.KeySpecRsa2048, types.KeySpecRsa3072, types.KeySpecRsa4096},
+	}
+
+	want := `ECC_NIST_P256 key "` + id + `" can not sign PS256, which needs a key of key spec RSA_2048, RSA_3072, RSA_4096`
+	if mismatch.Error() != want {
+		t.Errorf("Error() = %q, want %q", mismatch.Error(), want)
+	}
+}
+
+func TestHMACKeySpecMismatchError(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeHMAC256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	_, err = jwt.New(SigningMethodHS384).SignedString(NewConfig(client, id))
+
+	var mismatch *KeySpecMismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, ErrIncompatibleKeySpec) {
+		t.Fatalf("err = %v, want a *KeySpecMismatchError", err)
+	}
+
+	if mismatch.KeySpec != types.KeySpecHmac256 || !slices.Equal(mismatch.ExpectedKeySpecs, []types.KeySpec{types.KeySpecHmac384}) {
+		t.Errorf("KeySpec, ExpectedKeySpecs = %s, %v, want %s, [%s]", mismatch.KeySpec, mismatch.ExpectedKeySpecs,
+			types.KeySpecHmac256, types.KeySpecHmac384)
+	}
+
+	// the key spec of an HMAC key is only looked up once KMS rejects the MAC algorithm
+	var invalidKeyUsage *types.InvalidKeyUsageException
+	if !errors.As(mismatch.Err, &invalidKeyUsage) {
+		t.Errorf("Err = %v, want the %T of GenerateMac", mismatch.Err, invalidKeyUsage)
+	}
+}
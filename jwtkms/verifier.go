@@ -0,0 +1,145 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrNoBearerToken is returned by Verifier.VerifyRequest for requests without a bearer token.
+var ErrNoBearerToken = errors.New("no bearer token")
+
+// VerifierSettings configures the checks of a Verifier.
+type VerifierSettings struct {
+	// Issuer, Audience and Subject are the required iss, aud and sub claims, not checked when empty.
+	Issuer   string
+	Audience string
+	Subject  string
+
+	// Leeway allows exp, nbf and iat claims to be off by up to Leeway, for clock skew between issuers and the verifier.
+	Leeway time.Duration
+
+	// Now returns the time the exp, nbf and iat claims are validated against, time.Now when nil.
+	Now func() time.Time
+
+	// Claims returns the claims tokens are parsed into, jwt.MapClaims when nil.
+	Claims func() jwt.Claims
+
+	// Revoker fails tokens it reports as revoked with ErrTokenRevoked, none are when nil.
+	Revoker Revoker
+
+	// ParserOptions are additional options of the jwt parser.
+	ParserOptions []jwt.ParserOption
+}
+
+// Verifier verifies KMS-signed tokens with a Config or KeySet, e.g. a KeyRegistry, requiring the exp claim and the
+// configured issuer, audience and subject. It is the implementation shared by Middleware and the framework adapters.
+//
+// A Verifier is safe for concurrent use.
+type Verifier struct {
+	keyfunc  func(ctx context.Context) jwt.Keyfunc
+	settings VerifierSettings
+	opts     []jwt.ParserOption
+}
+
+// NewVerifier creates a Verifier verifying tokens with the Config of ks for their kid header.
+func NewVerifier(ks KeySet, settings VerifierSettings) *Verifier {
+	return newVerifier(func(ctx context.Context) jwt.Keyfunc {
+		return KeyfuncContext(ctx, ks)
+	}, settings)
+}
+
+// NewConfigVerifier creates a Verifier verifying tokens with cfg.
+func NewConfigVerifier(cfg *Config, settings VerifierSettings) *Verifier {
+	return newVerifier(func(ctx context.Context) jwt.Keyfunc {
+		return func(token *jwt.Token) (interface{}, error) {
+			return cfg.WithContext(ctx), cfg.checkTokenType(token)
+		}
+	}, settings)
+}
+
+func newVerifier(keyfunc func(ctx context.Context) jwt.Keyfunc, settings VerifierSettings) *Verifier {
+	if settings.Claims == nil {
+		settings.Claims = func() jwt.Claims { return jwt.MapClaims{} }
+	}
+
+	opts := []jwt.ParserOption{jwt.WithExpirationRequired(), jwt.WithLeeway(settings.Leeway)}
+	if settings.Now != nil {
+		opts = append(opts, jwt.WithTimeFunc(settings.Now))
+	}
+
+	if settings.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(settings.Issuer))
+	}
+
+	if settings.Audience != "" {
+		opts = append(opts, jwt.WithAudience(settings.Audience))
+	}
+
+	if settings.Subject != "" {
+		opts = append(opts, jwt.WithSubject(settings.Subject))
+	}
+
+	return &Verifier{keyfunc: keyfunc, settings: settings, opts: append(opts, settings.ParserOptions...)}
+}
+
+// Verify parses and verifies tokenString, using ctx for the KMS calls.
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (*jwt.Token, error) {
+	token, err := parseWithClaims(tokenString, v.settings.Claims(), v.keyfunc(ctx), nil, v.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.settings.Revoker != nil {
+		if err := checkRevoked(ctx, v.settings.Revoker, token.Claims); err != nil {
+			return nil, err
+		}
+	}
+
+	return token, nil
+}
+
+// VerifyRequest verifies the bearer token of the Authorization header of r, using the context of r for the KMS
+// calls. Requests without one fail with ErrNoBearerToken.
+func (v *Verifier) VerifyRequest(r *http.Request) (*jwt.Token, error) {
+	token, err := BearerToken(r.Header.Get("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Verify(r.Context(), token)
+}
+
+// BearerToken returns the token of an Authorization header value of the Bearer scheme, RFC 6750 section 2.1, and
+// ErrNoBearerToken for other values.
+func BearerToken(authorization string) (string, error) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", ErrNoBearerToken
+	}
+
+	if token = strings.TrimSpace(token); token == "" {
+		return "", fmt.Errorf("empty token: %w", ErrNoBearerToken)
+	}
+
+	return token, nil
+}
+
+// claimsContextKey is the context key of the claims of verified tokens.
+type claimsContextKey struct{}
+
+// ContextWithClaims returns a copy of ctx carrying claims, see ClaimsFromContext.
+func ContextWithClaims(ctx context.Context, claims jwt.Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of the token verified by Middleware, or set with ContextWithClaims.
+func ClaimsFromContext(ctx context.Context) (jwt.Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(jwt.Claims)
+	return claims, ok
+}
@@ -0,0 +1,136 @@
+package jwtkms
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func TestVerifier(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id, WithKeyIDHeader())
+
+	sign := func(claims jwt.MapClaims) string {
+		t.Helper()
+
+		signed, err := cfg.SignContext(context.Background(), jwt.NewWithClaims(SigningMethodECDSA256, claims))
+		if err != nil {
+			t.Fatalf("Error signing token: %v", err)
+		}
+
+		return signed
+	}
+
+	exp := time.Now().Add(time.Minute).Unix()
+	settings := VerifierSettings{Issuer: "issuer", Audience: "audience", Subject: "user"}
+
+	verifiers := map[string]*Verifier{
+		"config":  NewConfigVerifier(cfg, settings),
+		"key set": NewVerifier(StaticKeySet{mockkms.KeyARN(id): cfg}, settings),
+	}
+
+	tests := []struct {
+		name    string
+		claims  jwt.MapClaims
+		wantErr error
+	}{
+		{name: "valid", claims: jwt.MapClaims{"iss": "issuer", "aud": "audience", "sub": "user", "exp": exp}},
+		{name: "no exp", claims: jwt.MapClaims{"iss": "issuer", "aud": "audience", "sub": "user"}, wantErr: jwt.ErrTokenRequiredClaimMissing},
+		{name: "expired", claims: jwt.MapClaims{"iss": "issuer", "aud": "audience", "sub": "user", "exp": time.Now().Add(-time.Minute).Unix()}, wantErr: jwt.ErrTokenExpired},
+		{name: "wrong issuer", claims: jwt.MapClaims{"iss": "other", "aud": "audience", "sub": "user", "exp": exp}, wantErr: jwt.ErrTokenInvalidIssuer},
+		{name: "wrong audience", claims: jwt.MapClaims{"iss": "issuer", "aud": "other", "sub": "user", "exp": exp}, wantErr: jwt.ErrTokenInvalidAudience},
+		{name: "wrong subject", claims: jwt.MapClaims{"iss": "issuer", "aud": "audience", "sub": "other", "exp": exp}, wantErr: jwt.ErrTokenInvalidSubject},
+	}
+
+	for name, v := range verifiers {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				token, err := v.Verify(context.Background(), sign(tt.claims))
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Errorf("err = %v, want %v", err, tt.wantErr)
+					}
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("Error verifying token: %v", err)
+				}
+
+				if iss, _ := token.Claims.GetIssuer(); iss != "issuer" {
+					t.Errorf("iss = %q, want %q", iss, "issuer")
+				}
+			})
+		}
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		want          string
+		wantErr       bool
+	}{
+		{name: "bearer", authorization: "Bearer abc", want: "abc"},
+		{name: "case insensitive scheme", authorization: "bearer abc", want: "abc"},
+		{name: "empty", authorization: "", wantErr: true},
+		{name: "basic", authorization: "Basic abc", wantErr: true},
+		{name: "no token", authorization: "Bearer ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BearerToken(tt.authorization)
+			if tt.wantErr {
+				if !errors.Is(err, ErrNoBearerToken) {
+					t.Errorf("err = %v, want %v", err, ErrNoBearerToken)
+				}
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Errorf("BearerToken() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifierClock(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewConfig(client, id)
+
+	// a token minted by an issuer whose clock is ahead
+	now := time.Now()
+	signed, err := cfg.SignContext(context.Background(), jwt.NewWithClaims(SigningMethodECDSA256, jwt.MapClaims{
+		"nbf": now.Add(5 * time.Second).Unix(),
+		"exp": now.Add(time.Minute).Unix(),
+	}))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	frozen := func() time.Time { return now }
+
+	if _, err := NewConfigVerifier(cfg, VerifierSettings{Now: frozen}).Verify(context.Background(), signed); !errors.Is(err, jwt.ErrTokenNotValidYet) {
+		t.Errorf("err = %v, want %v", err, jwt.ErrTokenNotValidYet)
+	}
+
+	if _, err := NewConfigVerifier(cfg, VerifierSettings{Now: frozen, Leeway: 10 * time.Second}).Verify(context.Background(), signed); err != nil {
+		t.Errorf("Error verifying token within leeway: %v", err)
+	}
+}
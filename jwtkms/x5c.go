@@ -0,0 +1,127 @@
+package jwtkms
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type publicKeyEqualer interface {
+	Equal(x crypto.PublicKey) bool
+}
+
+// WithCertificateChain returns a copy of Config whose SignedString sets the x5c and x5t#S256 headers of tokens to chain,
+// RFC 7515 sections 4.1.6 and 4.1.8. The first certificate must be the one of the KMS key, followed by the certificates
+// that certify it.
+func (c *Config) WithCertificateChain(chain ...*x509.Certificate) *Config {
+	return c.with(WithCertificateChain(chain...))
+}
+
+func (c *Config) setCertificateHeaders(token *jwt.Token) error {
+	if len(c.certificateChain) == 0 {
+		return nil
+	}
+
+	leaf := c.certificateChain[0]
+
+	pub, err := getPublicKey(c)
+	if err != nil {
+		return err
+	}
+
+	if leafPub, ok := leaf.PublicKey.(publicKeyEqualer); !ok || !leafPub.Equal(pub) {
+		return errors.New("certificate does not match the kms public key")
+	}
+
+	if _, ok := token.Header["x5c"]; !ok {
+		x5c := make([]string, 0, len(c.certificateChain))
+		for _, cert := range c.certificateChain {
+			x5c = append(x5c, base64.StdEncoding.EncodeToString(cert.Raw))
+		}
+
+		token.Header["x5c"] = x5c
+	}
+
+	if _, ok := token.Header["x5t#S256"]; !ok {
+		sum := sha256.Sum256(leaf.Raw)
+		token.Header["x5t#S256"] = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+
+	return nil
+}
+
+// X5CKeyfunc returns a jwt.Keyfunc that verifies the certificate chain in a token's x5c header with opts and returns
+// the public key of its first certificate. opts.Roots holds the trust anchors and opts.Intermediates is extended with
+// the rest of the chain. Without opts.KeyUsages the certificates may have any extended key usage, rather than the
+// server authentication x509 defaults to. A x5t#S256 header, when present, must match the first certificate.
+//
+// The returned key is a standard library public key, so tokens are verified locally by the fallback signing methods.
+func X5CKeyfunc(opts x509.VerifyOptions) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		chain, err := parseX5C(token.Header["x5c"])
+		if err != nil {
+			return nil, err
+		}
+
+		if x5t, ok := token.Header["x5t#S256"]; ok {
+			sum := sha256.Sum256(chain[0].Raw)
+			if x5t != base64.RawURLEncoding.EncodeToString(sum[:]) {
+				return nil, errors.New("x5t#S256 header does not match the certificate")
+			}
+		}
+
+		verifyOpts := opts
+		verifyOpts.Intermediates = x509.NewCertPool()
+		if opts.Intermediates != nil {
+			verifyOpts.Intermediates = opts.Intermediates.Clone()
+		}
+
+		for _, cert := range chain[1:] {
+			verifyOpts.Intermediates.AddCert(cert)
+		}
+
+		if len(opts.KeyUsages) == 0 {
+			verifyOpts.KeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageAny}
+		}
+
+		if _, err := chain[0].Verify(verifyOpts); err != nil {
+			return nil, fmt.Errorf("verifying certificate chain: %w", err)
+		}
+
+		return chain[0].PublicKey, nil
+	}
+}
+
+func parseX5C(header interface{}) ([]*x509.Certificate, error) {
+	values, ok := header.([]interface{})
+	if !ok || len(values) == 0 {
+		return nil, errors.New("token has no x5c header")
+	}
+
+	chain := make([]*x509.Certificate, 0, len(values))
+	for i, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("x5c entry %d is not a string", i)
+		}
+
+		der, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("decoding x5c entry %d: %w", i, err)
+		}
+
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("parsing x5c entry %d: %w", i, err)
+		}
+
+		chain = append(chain, cert)
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,165 @@
+package jwtkms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+)
+
+func createCertificate(t *testing.T, serial int64, pub crypto.PublicKey, parent *x509.Certificate, parentKey crypto.Signer,
+	extKeyUsage ...x509.ExtKeyUsage) *x509.Certificate {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "jwtkms test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           extKeyUsage,
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+
+	if parent == nil {
+		parent = template
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, parentKey)
+	if err != nil {
+		t.Fatalf("Error creating certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Error parsing certificate: %v", err)
+	}
+
+	return cert
+}
+
+func TestCertificateChain(t *testing.T) {
+	registered(t)
+
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewKMSConfig(kms, id, false)
+
+	pub, err := getPublicKey(cfg)
+	if err != nil {
+		t.Fatalf("Error getting public key: %v", err)
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating CA key: %v", err)
+	}
+
+	ca := createCertificate(t, 1, &caKey.PublicKey, nil, caKey)
+	leaf := createCertificate(t, 2, pub, ca, caKey)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	signed, err := cfg.WithCertificateChain(leaf, ca).SignedString(jwt.New(SigningMethodECDSA256))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if _, err := jwt.Parse(signed, X5CKeyfunc(x509.VerifyOptions{Roots: roots})); err != nil {
+		t.Errorf("Error verifying token: %v", err)
+	}
+
+	if _, err := jwt.Parse(signed, X5CKeyfunc(x509.VerifyOptions{Roots: x509.NewCertPool()})); err == nil {
+		t.Errorf("expected error verifying token with an untrusted chain")
+	}
+
+	if _, err := cfg.WithCertificateChain(ca).SignedString(jwt.New(SigningMethodECDSA256)); err == nil {
+		t.Errorf("expected error signing with a certificate of another key")
+	}
+
+	unsigned, err := cfg.SignedString(jwt.New(SigningMethodECDSA256))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	if _, err := jwt.Parse(unsigned, X5CKeyfunc(x509.VerifyOptions{Roots: roots})); err == nil {
+		t.Errorf("expected error verifying token without x5c header")
+	}
+}
+
+func TestX5CKeyfuncKeyUsages(t *testing.T) {
+	registered(t)
+
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := NewKMSConfig(kms, id, false)
+
+	pub, err := getPublicKey(cfg)
+	if err != nil {
+		t.Fatalf("Error getting public key: %v", err)
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating CA key: %v", err)
+	}
+
+	ca := createCertificate(t, 1, &caKey.PublicKey, nil, caKey)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	tests := []struct {
+		name        string
+		extKeyUsage []x509.ExtKeyUsage
+		keyUsages   []x509.ExtKeyUsage
+		wantErr     bool
+	}{
+		{name: "no extended key usage"},
+		{name: "code signing", extKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}},
+		{
+			name:        "code signing certificate for server authentication",
+			extKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+			keyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			wantErr:     true,
+		},
+		{
+			name:        "code signing certificate for code signing",
+			extKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+			keyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaf := createCertificate(t, int64(i+2), pub, ca, caKey, tt.extKeyUsage...)
+
+			signed, err := cfg.WithCertificateChain(leaf, ca).SignedString(jwt.New(SigningMethodECDSA256))
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			_, err = jwt.Parse(signed, X5CKeyfunc(x509.VerifyOptions{Roots: roots, KeyUsages: tt.keyUsages}))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package jwtkmscose
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// The subset of CBOR, RFC 8949, used by COSE_Sign1 and CWTs: integers, byte and text strings, arrays, maps, tags and
+// the simple values false, true and null. Values are decoded to int64, []byte, string, []interface{},
+// map[interface{}]interface{}, cborTag, bool and nil, and encoded from the same types and int.
+
+const (
+	majorUnsigned = 0
+	majorNegative = 1
+	majorBytes    = 2
+	majorText     = 3
+	majorArray    = 4
+	majorMap      = 5
+	majorTag      = 6
+	majorSimple   = 7
+)
+
+// maxDepth is the deepest nesting of arrays, maps and tags decoded.
+const maxDepth = 16
+
+// cborTag is a tagged data item.
+type cborTag struct {
+	Number  uint64
+	Content interface{}
+}
+
+// marshalCBOR encodes v, with map keys in the bytewise order of their encoding, RFC 8949 section 4.2.1.
+func marshalCBOR(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeCBOR(&buf, v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encodeCBOR(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case int:
+		encodeInt(buf, int64(v))
+
+	case int64:
+		encodeInt(buf, v)
+
+	case []byte:
+		encodeHead(buf, majorBytes, uint64(len(v)))
+		buf.Write(v)
+
+	case string:
+		encodeHead(buf, majorText, uint64(len(v)))
+		buf.WriteString(v)
+
+	case []interface{}:
+		encodeHead(buf, majorArray, uint64(len(v)))
+		for _, item := range v {
+			if err := encodeCBOR(buf, item); err != nil {
+				return err
+			}
+		}
+
+	case map[interface{}]interface{}:
+		type entry struct{ key, value []byte }
+
+		entries := make([]entry, 0, len(v))
+		for key, value := range v {
+			encodedKey, err := marshalCBOR(key)
+			if err != nil {
+				return err
+			}
+
+			encodedValue, err := marshalCBOR(value)
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, entry{encodedKey, encodedValue})
+		}
+
+		slices.SortFunc(entries, func(a, b entry) int { return bytes.Compare(a.key, b.key) })
+
+		encodeHead(buf, majorMap, uint64(len(entries)))
+		for _, e := range entries {
+			buf.Write(e.key)
+			buf.Write(e.value)
+		}
+
+	case cborTag:
+		encodeHead(buf, majorTag, v.Number)
+		return encodeCBOR(buf, v.Content)
+
+	case bool:
+		if v {
+			buf.WriteByte(majorSimple<<5 | 21)
+		} else {
+			buf.WriteByte(majorSimple<<5 | 20)
+		}
+
+	case nil:
+		buf.WriteByte(majorSimple<<5 | 22)
+
+	default:
+		return fmt.Errorf("cbor: unsupported type %T", v)
+	}
+
+	return nil
+}
+
+func encodeInt(buf *bytes.Buffer, v int64) {
+	if v < 0 {
+		encodeHead(buf, majorNegative, uint64(-(v + 1)))
+		return
+	}
+
+	encodeHead(buf, majorUnsigned, uint64(v))
+}
+
+// encodeHead writes the initial byte and argument of a data item, in the shortest form.
+func encodeHead(buf *bytes.Buffer, major byte, arg uint64) {
+	switch {
+	case arg < 24:
+		buf.WriteByte(major<<5 | byte(arg))
+	case arg <= math.MaxUint8:
+		buf.Write([]byte{major<<5 | 24, byte(arg)})
+	case arg <= math.MaxUint16:
+		buf.WriteByte(major<<5 | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(arg)))
+	case arg <= math.MaxUint32:
+		buf.WriteByte(major<<5 | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(arg)))
+	default:
+		buf.WriteByte(major<<5 | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, arg))
+	}
+}
+
+var errCBORTruncated = errors.New("cbor: unexpected end of data")
+
+// unmarshalCBOR decodes data, which must hold exactly one data item.
+func unmarshalCBOR(data []byte) (interface{}, error) {
+	d := &cborDecoder{data: data}
+
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.off != len(d.data) {
+		return nil, errors.New("cbor: trailing data")
+	}
+
+	return v, nil
+}
+
+type cborDecoder struct {
+	data []byte
+	off  int
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errors.New("cbor: nested too deeply")
+	}
+
+	major, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUnsigned:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflows int64")
+		}
+
+		return int64(arg), nil
+
+	case majorNegative:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflows int64")
+		}
+
+		return -int64(arg) - 1, nil
+
+	case majorBytes:
+		return d.bytes(arg)
+
+	case majorText:
+		b, err := d.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		return string(b), nil
+
+	case majorArray:
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, errCBORTruncated
+		}
+
+		items := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+
+		return items, nil
+
+	case majorMap:
+		if arg > uint64(len(d.data)-d.off)/2 {
+			return nil, errCBORTruncated
+		}
+
+		m := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, fmt.Errorf("cbor: unsupported map key type %T", key)
+			}
+
+			if _, ok := m[key]; ok {
+				return nil, fmt.Errorf("cbor: duplicate map key %v", key)
+			}
+
+			if m[key], err = d.decode(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+
+		return m, nil
+
+	case majorTag:
+		content, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		return cborTag{Number: arg, Content: content}, nil
+
+	default:
+		switch arg {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22:
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("cbor: unsupported simple value or float %d", arg)
+	}
+}
+
+// head reads the initial byte and argument of a data item.
+func (d *cborDecoder) head() (byte, uint64, error) {
+	if d.off >= len(d.data) {
+		return 0, 0, errCBORTruncated
+	}
+
+	initial := d.data[d.off]
+	d.off++
+
+	major, info := initial>>5, initial&0x1f
+
+	var size int
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, errors.New("cbor: indefinite lengths are not supported")
+	}
+
+	if major == majorSimple && size > 1 {
+		return 0, 0, errors.New("cbor: floats are not supported")
+	}
+
+	if len(d.data)-d.off < size {
+		return 0, 0, errCBORTruncated
+	}
+
+	var arg uint64
+	for _, b := range d.data[d.off : d.off+size] {
+		arg = arg<<8 | uint64(b)
+	}
+	d.off += size
+
+	return major, arg, nil
+}
+
+func (d *cborDecoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, errCBORTruncated
+	}
+
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+
+	return b, nil
+}
@@ -0,0 +1,89 @@
+package jwtkmscose
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestCBOR(t *testing.T) {
+	// RFC 8949 appendix A
+	tests := []struct {
+		value interface{}
+		hex   string
+	}{
+		{value: int64(0), hex: "00"},
+		{value: int64(23), hex: "17"},
+		{value: int64(24), hex: "1818"},
+		{value: int64(1000), hex: "1903e8"},
+		{value: int64(1000000), hex: "1a000f4240"},
+		{value: int64(1000000000000), hex: "1b000000e8d4a51000"},
+		{value: int64(-1), hex: "20"},
+		{value: int64(-1000), hex: "3903e7"},
+		{value: []byte{}, hex: "40"},
+		{value: []byte{1, 2, 3, 4}, hex: "4401020304"},
+		{value: "", hex: "60"},
+		{value: "IETF", hex: "6449455446"},
+		{value: []interface{}{int64(1), []interface{}{int64(2), int64(3)}}, hex: "8201820203"},
+		{value: map[interface{}]interface{}{int64(1): int64(2), int64(3): int64(4)}, hex: "a201020304"},
+		{value: map[interface{}]interface{}{"a": int64(1), "b": []interface{}{int64(2)}}, hex: "a261610161628102"},
+		{value: cborTag{Number: 1, Content: int64(1363896240)}, hex: "c11a514b67b0"},
+		{value: false, hex: "f4"},
+		{value: true, hex: "f5"},
+		{value: nil, hex: "f6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hex, func(t *testing.T) {
+			encoded, err := marshalCBOR(tt.value)
+			if err != nil {
+				t.Fatalf("Error encoding: %v", err)
+			}
+
+			if got := hex.EncodeToString(encoded); got != tt.hex {
+				t.Errorf("encoded = %s, want %s", got, tt.hex)
+			}
+
+			decoded, err := unmarshalCBOR(encoded)
+			if err != nil {
+				t.Fatalf("Error decoding: %v", err)
+			}
+
+			if !reflect.DeepEqual(decoded, tt.value) {
+				t.Errorf("decoded = %#v, want %#v", decoded, tt.value)
+			}
+		})
+	}
+}
+
+func TestCBORInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		hex  string
+	}{
+		{name: "empty", hex: ""},
+		{name: "truncated argument", hex: "19e8"},
+		{name: "truncated string", hex: "6449"},
+		{name: "truncated array", hex: "8301"},
+		{name: "trailing data", hex: "0000"},
+		{name: "indefinite length", hex: "5f42010243030405ff"},
+		{name: "float", hex: "f93c00"},
+		{name: "duplicate key", hex: "a201020103"},
+		{name: "array key", hex: "a1800102"},
+		{name: "integer overflow", hex: "1bffffffffffffffff"},
+		{name: "huge array", hex: "9bffffffffffffffff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.hex)
+			if err != nil {
+				t.Fatalf("Error decoding hex: %v", err)
+			}
+
+			if _, err := unmarshalCBOR(data); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
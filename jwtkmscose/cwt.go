@@ -0,0 +1,180 @@
+package jwtkmscose
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+// CWT claim keys, RFC 8392 section 3.1.
+const (
+	claimIssuer     = 1
+	claimSubject    = 2
+	claimAudience   = 3
+	claimExpiration = 4
+	claimNotBefore  = 5
+	claimIssuedAt   = 6
+	claimCWTID      = 7
+)
+
+// Claims are the claims of a CWT. Zero values are omitted. Extra holds other claims by key, of the types int,
+// int64, string, []byte and bool; it must not repeat the registered claims.
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	CWTID     []byte
+	Extra     map[int64]interface{}
+}
+
+// SignCWT signs claims into a CWT, a tagged COSE_Sign1 message, with the Config and method like Sign1.
+func SignCWT(ctx context.Context, cfg *jwtkms.Config, method jwt.SigningMethod, kid []byte, claims *Claims) ([]byte, error) {
+	m := make(map[interface{}]interface{}, len(claims.Extra)+7)
+	for key, value := range claims.Extra {
+		if key >= claimIssuer && key <= claimCWTID {
+			return nil, fmt.Errorf("extra claim %d is a registered claim", key)
+		}
+
+		m[key] = value
+	}
+
+	setString := func(key int64, value string) {
+		if value != "" {
+			m[key] = value
+		}
+	}
+
+	setTime := func(key int64, value time.Time) {
+		if !value.IsZero() {
+			m[key] = value.Unix()
+		}
+	}
+
+	setString(claimIssuer, claims.Issuer)
+	setString(claimSubject, claims.Subject)
+	setString(claimAudience, claims.Audience)
+	setTime(claimExpiration, claims.ExpiresAt)
+	setTime(claimNotBefore, claims.NotBefore)
+	setTime(claimIssuedAt, claims.IssuedAt)
+
+	if len(claims.CWTID) > 0 {
+		m[int64(claimCWTID)] = claims.CWTID
+	}
+
+	payload, err := marshalCBOR(m)
+	if err != nil {
+		return nil, fmt.Errorf("encoding claims: %w", err)
+	}
+
+	return Sign1(ctx, cfg, method, kid, payload)
+}
+
+// VerifyCWT verifies token, a CWT made of a COSE_Sign1 message, optionally tagged as CWT, with the Config and method
+// like Verify1 and returns its claims. Expired and not yet valid tokens are rejected like by the jwt parser.
+func VerifyCWT(ctx context.Context, cfg *jwtkms.Config, method jwt.SigningMethod, token []byte) (*Claims, error) {
+	v, err := unmarshalCBOR(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", jwt.ErrTokenMalformed, err)
+	}
+
+	if tag, ok := v.(cborTag); ok && tag.Number == tagCWT {
+		v = tag.Content
+	}
+
+	payload, _, err := verify1(ctx, cfg, method, v)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := parseClaims(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", jwt.ErrTokenMalformed, err)
+	}
+
+	now := time.Now()
+
+	if !claims.ExpiresAt.IsZero() && !now.Before(claims.ExpiresAt) {
+		return nil, jwt.ErrTokenExpired
+	}
+
+	if !claims.NotBefore.IsZero() && now.Before(claims.NotBefore) {
+		return nil, jwt.ErrTokenNotValidYet
+	}
+
+	return claims, nil
+}
+
+// parseClaims decodes the claims map of a CWT.
+func parseClaims(payload []byte) (*Claims, error) {
+	v, err := unmarshalCBOR(payload)
+	if err != nil {
+		return nil, fmt.Errorf("decoding claims: %w", err)
+	}
+
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("claims are not a map")
+	}
+
+	claims := &Claims{}
+	for key, value := range m {
+		label, ok := key.(int64)
+		if !ok {
+			// text claim keys are not supported by Claims
+			continue
+		}
+
+		ok = true
+		switch label {
+		case claimIssuer:
+			claims.Issuer, ok = value.(string)
+		case claimSubject:
+			claims.Subject, ok = value.(string)
+		case claimAudience:
+			claims.Audience, ok = value.(string)
+		case claimExpiration:
+			claims.ExpiresAt, ok = numericDate(value)
+		case claimNotBefore:
+			claims.NotBefore, ok = numericDate(value)
+		case claimIssuedAt:
+			claims.IssuedAt, ok = numericDate(value)
+		case claimCWTID:
+			var cti []byte
+			cti, ok = value.([]byte)
+			claims.CWTID = bytes.Clone(cti)
+		default:
+			if claims.Extra == nil {
+				claims.Extra = make(map[int64]interface{})
+			}
+
+			if b, isBytes := value.([]byte); isBytes {
+				value = bytes.Clone(b)
+			}
+
+			claims.Extra[label] = value
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("claim %d has type %T", label, value)
+		}
+	}
+
+	return claims, nil
+}
+
+func numericDate(v interface{}) (time.Time, bool) {
+	seconds, ok := v.(int64)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return time.Unix(seconds, 0), true
+}
@@ -0,0 +1,152 @@
+// Package jwtkmscose signs and verifies COSE_Sign1 messages, RFC 9052, and CBOR Web Tokens, RFC 8392, with the KMS
+// ECDSA keys of jwtkms Configs, so constrained-device and mDL tokens can be minted from the same key material as JWTs.
+//
+// Signing and verification are delegated to the jwtkms ECDSA signing methods, whose R || S signatures are the COSE
+// ECDSA signatures, so local verification with the cached public key and the other Config options apply.
+package jwtkmscose
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+// COSE algorithm identifiers of the ECDSA signing methods, RFC 9053 section 2.1 and RFC 8812 section 3.2.
+var algorithms = map[string]int64{
+	jwtkms.SigningMethodECDSA256.Alg(): -7,
+	jwtkms.SigningMethodECDSA384.Alg(): -35,
+	jwtkms.SigningMethodECDSA512.Alg(): -36,
+	jwtkms.SigningMethodES256K.Alg():   -47,
+}
+
+// COSE header labels, RFC 9052 section 3.1.
+const (
+	headerAlgorithm = 1
+	headerKeyID     = 4
+)
+
+// CBOR tags of COSE_Sign1 and CWT, RFC 9052 section 2 and RFC 8392 section 6.
+const (
+	tagSign1 = 18
+	tagCWT   = 61
+)
+
+// Sign1 signs payload with the Config and method, an ECDSA signing method of jwtkms, into a tagged COSE_Sign1
+// message, using ctx for the KMS calls. The protected header holds the algorithm and kid, omitted when empty.
+func Sign1(ctx context.Context, cfg *jwtkms.Config, method jwt.SigningMethod, kid, payload []byte) ([]byte, error) {
+	alg, ok := algorithms[method.Alg()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing method %s", method.Alg())
+	}
+
+	header := map[interface{}]interface{}{headerAlgorithm: alg}
+	if len(kid) > 0 {
+		header[headerKeyID] = kid
+	}
+
+	protected, err := marshalCBOR(header)
+	if err != nil {
+		return nil, fmt.Errorf("encoding protected header: %w", err)
+	}
+
+	toBeSigned, err := sigStructure(protected, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := cfg.SignPayload(ctx, method, toBeSigned)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalCBOR(cborTag{Number: tagSign1, Content: []interface{}{
+		protected,
+		map[interface{}]interface{}{},
+		payload,
+		sig,
+	}})
+}
+
+// Verify1 verifies msg, a COSE_Sign1 message, tagged or not, with the Config and method, using ctx for the KMS calls,
+// and returns its payload and kid. The algorithm of the protected header must be the one of method.
+func Verify1(ctx context.Context, cfg *jwtkms.Config, method jwt.SigningMethod, msg []byte) (payload, kid []byte, err error) {
+	v, err := unmarshalCBOR(msg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", jwt.ErrTokenMalformed, err)
+	}
+
+	return verify1(ctx, cfg, method, v)
+}
+
+func verify1(ctx context.Context, cfg *jwtkms.Config, method jwt.SigningMethod, v interface{}) (payload, kid []byte, err error) {
+	if tag, ok := v.(cborTag); ok {
+		if tag.Number != tagSign1 {
+			return nil, nil, fmt.Errorf("%w: cbor tag %d is not COSE_Sign1", jwt.ErrTokenMalformed, tag.Number)
+		}
+
+		v = tag.Content
+	}
+
+	items, ok := v.([]interface{})
+	if !ok || len(items) != 4 {
+		return nil, nil, fmt.Errorf("%w: not a COSE_Sign1 message", jwt.ErrTokenMalformed)
+	}
+
+	protected, ok1 := items[0].([]byte)
+	unprotected, ok2 := items[1].(map[interface{}]interface{})
+	payload, ok3 := items[2].([]byte)
+	sig, ok4 := items[3].([]byte)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return nil, nil, fmt.Errorf("%w: malformed COSE_Sign1 message, detached payloads are not supported", jwt.ErrTokenMalformed)
+	}
+
+	header := map[interface{}]interface{}{}
+	if len(protected) > 0 {
+		decoded, err := unmarshalCBOR(protected)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: decoding protected header: %w", jwt.ErrTokenMalformed, err)
+		}
+
+		if header, ok = decoded.(map[interface{}]interface{}); !ok {
+			return nil, nil, fmt.Errorf("%w: protected header is not a map", jwt.ErrTokenMalformed)
+		}
+	}
+
+	if alg, ok := header[int64(headerAlgorithm)].(int64); !ok || alg != algorithms[method.Alg()] {
+		return nil, nil, fmt.Errorf("%w: algorithm %v, expected %d", jwt.ErrTokenSignatureInvalid, header[int64(headerAlgorithm)],
+			algorithms[method.Alg()])
+	}
+
+	if _, ok := header[int64(2)]; ok {
+		return nil, nil, fmt.Errorf("%w: critical headers are not supported", jwt.ErrTokenUnverifiable)
+	}
+
+	toBeSigned, err := sigStructure(protected, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := cfg.VerifyPayload(ctx, method, toBeSigned, sig); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", jwt.ErrTokenSignatureInvalid, err)
+	}
+
+	kid, _ = header[int64(headerKeyID)].([]byte)
+	if kid == nil {
+		kid, _ = unprotected[int64(headerKeyID)].([]byte)
+	}
+
+	return bytes.Clone(payload), bytes.Clone(kid), nil
+}
+
+// sigStructure returns the Sig_structure of a COSE_Sign1 message without external data, RFC 9052 section 4.4.
+func sigStructure(protected, payload []byte) ([]byte, error) {
+	if payload == nil {
+		return nil, errors.New("payload is nil")
+	}
+
+	return marshalCBOR([]interface{}{"Signature1", protected, []byte{}, payload})
+}
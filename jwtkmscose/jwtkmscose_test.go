@@ -0,0 +1,202 @@
+package jwtkmscose
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+func TestSign1(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		method  jwt.SigningMethod
+	}{
+		{name: "ES256", keyType: mockkms.KeyTypeECCNISTP256, method: jwtkms.SigningMethodECDSA256},
+		{name: "ES384", keyType: mockkms.KeyTypeECCNISTP384, method: jwtkms.SigningMethodECDSA384},
+		{name: "ES512", keyType: mockkms.KeyTypeECCNISTP521, method: jwtkms.SigningMethodECDSA512},
+		{name: "ES256K", keyType: mockkms.KeyTypeECCSECGP256K1, method: jwtkms.SigningMethodES256K},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := mockkms.NewMockKMS()
+			id, err := client.GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			cfg := jwtkms.NewConfig(client, id)
+
+			msg, err := Sign1(ctx, cfg, tt.method, []byte("device-key"), []byte("sensor reading"))
+			if err != nil {
+				t.Fatalf("Error signing message: %v", err)
+			}
+
+			payload, kid, err := Verify1(ctx, cfg, tt.method, msg)
+			if err != nil {
+				t.Fatalf("Error verifying message: %v", err)
+			}
+
+			if string(payload) != "sensor reading" || string(kid) != "device-key" {
+				t.Errorf("payload = %q, kid = %q", payload, kid)
+			}
+
+			tampered := tamperPayload(t, msg)
+			if _, _, err := Verify1(ctx, cfg, tt.method, tampered); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+				t.Errorf("err = %v, want %v", err, jwt.ErrTokenSignatureInvalid)
+			}
+		})
+	}
+}
+
+// tamperPayload replaces the payload of the COSE_Sign1 message msg.
+func tamperPayload(t *testing.T, msg []byte) []byte {
+	t.Helper()
+
+	v, err := unmarshalCBOR(msg)
+	if err != nil {
+		t.Fatalf("Error decoding message: %v", err)
+	}
+
+	v.(cborTag).Content.([]interface{})[2] = []byte("tampered")
+
+	tampered, err := marshalCBOR(v)
+	if err != nil {
+		t.Fatalf("Error encoding message: %v", err)
+	}
+
+	return tampered
+}
+
+func TestSign1Invalid(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := jwtkms.NewConfig(client, id)
+
+	if _, err := Sign1(ctx, cfg, jwtkms.SigningMethodRS256, nil, []byte("payload")); err == nil {
+		t.Errorf("expected error for a signing method without COSE support")
+	}
+
+	msg, err := Sign1(ctx, cfg, jwtkms.SigningMethodECDSA256, nil, []byte("payload"))
+	if err != nil {
+		t.Fatalf("Error signing message: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		msg    []byte
+		want   error
+	}{
+		{name: "other algorithm", method: jwtkms.SigningMethodECDSA384, msg: msg, want: jwt.ErrTokenSignatureInvalid},
+		{name: "not cbor", method: jwtkms.SigningMethodECDSA256, msg: []byte{0xff}, want: jwt.ErrTokenMalformed},
+		{name: "not COSE_Sign1", method: jwtkms.SigningMethodECDSA256, msg: []byte{0x83, 0x01, 0x02, 0x03}, want: jwt.ErrTokenMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Verify1(ctx, cfg, tt.method, tt.msg); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignCWT(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := jwtkms.NewConfig(client, id)
+
+	// RFC 8392 appendix A.1
+	claims := &Claims{
+		Issuer:    "coap://as.example.com",
+		Subject:   "erikw",
+		Audience:  "coap://light.example.com",
+		ExpiresAt: time.Unix(1444064944, 0),
+		NotBefore: time.Unix(1443944944, 0),
+		IssuedAt:  time.Unix(1443944944, 0),
+		CWTID:     []byte{0x0b, 0x71},
+	}
+
+	token, err := SignCWT(ctx, cfg, jwtkms.SigningMethodECDSA256, nil, claims)
+	if err != nil {
+		t.Fatalf("Error signing cwt: %v", err)
+	}
+
+	v, err := unmarshalCBOR(token)
+	if err != nil {
+		t.Fatalf("Error decoding cwt: %v", err)
+	}
+
+	want := "a70175636f61703a2f2f61732e6578616d706c652e636f6d02656572696b77037818636f61703a2f2f6c696768742e6578616d706c652e636f6d041a5612aeb0051a5610d9f0061a5610d9f007420b71"
+	if got := hex.EncodeToString(v.(cborTag).Content.([]interface{})[2].([]byte)); got != want {
+		t.Errorf("claims = %s, want %s", got, want)
+	}
+
+	if _, err := VerifyCWT(ctx, cfg, jwtkms.SigningMethodECDSA256, token); !errors.Is(err, jwt.ErrTokenExpired) {
+		t.Errorf("err = %v, want %v", err, jwt.ErrTokenExpired)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	claims.ExpiresAt = now.Add(time.Hour)
+	claims.Extra = map[int64]interface{}{-70000: "device", 8: true}
+
+	if token, err = SignCWT(ctx, cfg, jwtkms.SigningMethodECDSA256, []byte("kid"), claims); err != nil {
+		t.Fatalf("Error signing cwt: %v", err)
+	}
+
+	got, err := VerifyCWT(ctx, cfg, jwtkms.SigningMethodECDSA256, token)
+	if err != nil {
+		t.Fatalf("Error verifying cwt: %v", err)
+	}
+
+	if got.Subject != "erikw" || !got.ExpiresAt.Equal(claims.ExpiresAt) || got.Extra[-70000] != "device" || got.Extra[8] != true {
+		t.Errorf("claims = %+v", got)
+	}
+
+	v, err = unmarshalCBOR(token)
+	if err != nil {
+		t.Fatalf("Error decoding cwt: %v", err)
+	}
+
+	tagged, err := marshalCBOR(cborTag{Number: tagCWT, Content: v})
+	if err != nil {
+		t.Fatalf("Error encoding cwt: %v", err)
+	}
+
+	if _, err := VerifyCWT(ctx, cfg, jwtkms.SigningMethodECDSA256, tagged); err != nil {
+		t.Errorf("Error verifying cwt tagged as CWT: %v", err)
+	}
+
+	claims.NotBefore = now.Add(time.Minute)
+	if token, err = SignCWT(ctx, cfg, jwtkms.SigningMethodECDSA256, nil, claims); err != nil {
+		t.Fatalf("Error signing cwt: %v", err)
+	}
+
+	if _, err := VerifyCWT(ctx, cfg, jwtkms.SigningMethodECDSA256, token); !errors.Is(err, jwt.ErrTokenNotValidYet) {
+		t.Errorf("err = %v, want %v", err, jwt.ErrTokenNotValidYet)
+	}
+
+	if _, err := SignCWT(ctx, cfg, jwtkms.SigningMethodECDSA256, nil, &Claims{Extra: map[int64]interface{}{claimSubject: "x"}}); err == nil {
+		t.Errorf("expected error for an extra registered claim")
+	}
+}
@@ -0,0 +1,41 @@
+// Package jwtkmsecho adapts the jwtkms.Verifier middleware to Echo.
+package jwtkmsecho
+
+import (
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+// ClaimsKey is the key of the claims of verified tokens in the echo.Context.
+const ClaimsKey = "jwtkms.claims"
+
+// Middleware verifies the bearer token of requests with v, storing its claims in the echo.Context, see Claims, and in
+// the request context, see jwtkms.ClaimsFromContext. Requests failing verification fail with a 401 Unauthorized
+// echo.HTTPError.
+func Middleware(v *jwtkms.Verifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			token, err := v.VerifyRequest(req)
+			if err != nil {
+				c.Response().Header().Set("WWW-Authenticate", jwtkms.BearerChallenge(err))
+				return echo.NewHTTPError(http.StatusUnauthorized).SetInternal(err)
+			}
+
+			c.Set(ClaimsKey, token.Claims)
+			c.SetRequest(req.WithContext(jwtkms.ContextWithClaims(req.Context(), token.Claims)))
+
+			return next(c)
+		}
+	}
+}
+
+// Claims returns the claims of the token verified by Middleware.
+func Claims(c echo.Context) (jwt.Claims, bool) {
+	claims, ok := c.Get(ClaimsKey).(jwt.Claims)
+	return claims, ok
+}
@@ -0,0 +1,45 @@
+// Package jwtkmsfiber adapts the jwtkms.Verifier middleware to Fiber.
+package jwtkmsfiber
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+// ClaimsKey is the key of the claims of verified tokens in the locals of the fiber.Ctx.
+const ClaimsKey = "jwtkms.claims"
+
+// Middleware verifies the bearer token of requests with v, using the user context for the KMS calls. The claims are
+// stored in the locals, see Claims, and in the user context, see jwtkms.ClaimsFromContext. Requests failing
+// verification fail with a 401 Unauthorized fiber.Error.
+func Middleware(v *jwtkms.Verifier) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token, err := jwtkms.BearerToken(c.Get(fiber.HeaderAuthorization))
+		if err != nil {
+			return unauthorized(c, err)
+		}
+
+		verified, err := v.Verify(c.UserContext(), token)
+		if err != nil {
+			return unauthorized(c, err)
+		}
+
+		c.Locals(ClaimsKey, verified.Claims)
+		c.SetUserContext(jwtkms.ContextWithClaims(c.UserContext(), verified.Claims))
+
+		return c.Next()
+	}
+}
+
+// unauthorized sets the WWW-Authenticate header for err and returns fiber.ErrUnauthorized.
+func unauthorized(c *fiber.Ctx, err error) error {
+	c.Set(fiber.HeaderWWWAuthenticate, jwtkms.BearerChallenge(err))
+	return fiber.ErrUnauthorized
+}
+
+// Claims returns the claims of the token verified by Middleware.
+func Claims(c *fiber.Ctx) (jwt.Claims, bool) {
+	claims, ok := c.Locals(ClaimsKey).(jwt.Claims)
+	return claims, ok
+}
@@ -0,0 +1,88 @@
+package jwtkmsfiber
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+func TestMiddleware(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := jwtkms.NewConfig(client, id)
+
+	signed, err := cfg.SignContext(context.Background(), jwt.NewWithClaims(jwtkms.SigningMethodECDSA256, jwt.MapClaims{
+		"sub": "user",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	app := fiber.New()
+	app.Use(Middleware(jwtkms.NewConfigVerifier(cfg, jwtkms.VerifierSettings{})))
+	app.Get("/", func(c *fiber.Ctx) error {
+		claims, ok := Claims(c)
+		if _, inUserContext := jwtkms.ClaimsFromContext(c.UserContext()); !ok || !inUserContext {
+			t.Errorf("no claims in the context")
+			return nil
+		}
+
+		sub, _ := claims.GetSubject()
+		return c.SendString(sub)
+	})
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "valid", authorization: "Bearer " + signed, wantStatus: http.StatusOK},
+		{name: "no token", wantStatus: http.StatusUnauthorized},
+		{name: "invalid token", authorization: "Bearer invalid", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error sending request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Error reading body: %v", err)
+			}
+
+			if tt.wantStatus == http.StatusOK && string(body) != "user" {
+				t.Errorf("body = %q, want %q", body, "user")
+			}
+
+			if tt.wantStatus == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+				t.Errorf("no WWW-Authenticate header")
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// Package jwtkmsgin adapts the jwtkms.Verifier middleware to Gin.
+package jwtkmsgin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+// ClaimsKey is the key of the claims of verified tokens in the gin.Context.
+const ClaimsKey = "jwtkms.claims"
+
+// Middleware verifies the bearer token of requests with v, storing its claims in the gin.Context, see Claims, and in
+// the request context, see jwtkms.ClaimsFromContext. Requests failing verification are aborted with
+// 401 Unauthorized.
+func Middleware(v *jwtkms.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, err := v.VerifyRequest(c.Request)
+		if err != nil {
+			c.Header("WWW-Authenticate", jwtkms.BearerChallenge(err))
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		c.Set(ClaimsKey, token.Claims)
+		c.Request = c.Request.WithContext(jwtkms.ContextWithClaims(c.Request.Context(), token.Claims))
+		c.Next()
+	}
+}
+
+// Claims returns the claims of the token verified by Middleware.
+func Claims(c *gin.Context) (jwt.Claims, bool) {
+	value, ok := c.Get(ClaimsKey)
+	if !ok {
+		return nil, false
+	}
+
+	claims, ok := value.(jwt.Claims)
+	return claims, ok
+}
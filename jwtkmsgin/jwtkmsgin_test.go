@@ -0,0 +1,80 @@
+package jwtkmsgin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+func TestMiddleware(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := jwtkms.NewConfig(client, id)
+
+	signed, err := cfg.SignContext(context.Background(), jwt.NewWithClaims(jwtkms.SigningMethodECDSA256, jwt.MapClaims{
+		"sub": "user",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware(jwtkms.NewConfigVerifier(cfg, jwtkms.VerifierSettings{})))
+	router.GET("/", func(c *gin.Context) {
+		claims, ok := Claims(c)
+		if _, inRequest := jwtkms.ClaimsFromContext(c.Request.Context()); !ok || !inRequest {
+			t.Errorf("no claims in the context")
+			return
+		}
+
+		sub, _ := claims.GetSubject()
+		c.String(http.StatusOK, sub)
+	})
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "valid", authorization: "Bearer " + signed, wantStatus: http.StatusOK},
+		{name: "no token", wantStatus: http.StatusUnauthorized},
+		{name: "invalid token", authorization: "Bearer invalid", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			if tt.wantStatus == http.StatusOK && rec.Body.String() != "user" {
+				t.Errorf("body = %q, want %q", rec.Body.String(), "user")
+			}
+
+			if tt.wantStatus == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("no WWW-Authenticate header")
+			}
+		})
+	}
+}
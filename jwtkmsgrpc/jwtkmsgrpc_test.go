@@ -0,0 +1,119 @@
+package jwtkmsgrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeServerStream is a grpc.ServerStream with a fixed context.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestInterceptors(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cfg := jwtkms.NewConfig(client, id)
+	source := jwtkms.NewBearerTokenSource(cfg, jwtkms.SigningMethodECDSA256, jwtkms.BearerTokenSettings{
+		Subject:  "service-a",
+		Audience: []string{"service-b"},
+	})
+	verifier := jwtkms.NewConfigVerifier(cfg, jwtkms.VerifierSettings{Audience: "service-b"})
+
+	// incoming returns the server context of a call with the outgoing metadata attached by the client interceptor
+	incoming := func(t *testing.T) context.Context {
+		t.Helper()
+
+		var ctx context.Context
+		invoker := func(outgoing context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(outgoing)
+			ctx = metadata.NewIncomingContext(context.Background(), md)
+			return nil
+		}
+
+		if err := UnaryClientInterceptor(source)(context.Background(), "/svc/Method", nil, nil, nil, invoker); err != nil {
+			t.Fatalf("Error invoking client interceptor: %v", err)
+		}
+
+		return ctx
+	}
+
+	subject := func(t *testing.T, ctx context.Context) {
+		t.Helper()
+
+		claims, ok := jwtkms.ClaimsFromContext(ctx)
+		if !ok {
+			t.Fatalf("no claims in the handler context")
+		}
+
+		if sub, _ := claims.GetSubject(); sub != "service-a" {
+			t.Errorf("sub = %q, want %q", sub, "service-a")
+		}
+	}
+
+	t.Run("unary", func(t *testing.T) {
+		_, err := UnaryServerInterceptor(verifier)(incoming(t), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+			subject(t, ctx)
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatalf("Error verifying call: %v", err)
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		err := StreamServerInterceptor(verifier)(nil, &fakeServerStream{ctx: incoming(t)}, &grpc.StreamServerInfo{}, func(_ interface{}, ss grpc.ServerStream) error {
+			subject(t, ss.Context())
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Error verifying stream: %v", err)
+		}
+	})
+
+	other, err := cfg.SignContext(context.Background(), jwt.NewWithClaims(jwtkms.SigningMethodECDSA256, jwt.MapClaims{"aud": "other"}))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		md   metadata.MD
+	}{
+		{name: "no token", md: metadata.MD{}},
+		{name: "not bearer", md: metadata.Pairs(authorizationKey, "Basic abc")},
+		{name: "wrong audience", md: metadata.Pairs(authorizationKey, "Bearer "+other)},
+		{name: "two tokens", md: metadata.Pairs(authorizationKey, "Bearer a", authorizationKey, "Bearer b")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			_, err := UnaryServerInterceptor(verifier)(ctx, nil, &grpc.UnaryServerInfo{}, func(context.Context, interface{}) (interface{}, error) {
+				t.Errorf("handler called")
+				return nil, nil
+			})
+
+			if status.Code(err) != codes.Unauthenticated {
+				t.Errorf("code = %v, want %v", status.Code(err), codes.Unauthenticated)
+			}
+		})
+	}
+}
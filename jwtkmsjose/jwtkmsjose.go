@@ -0,0 +1,142 @@
+// Package jwtkmsjose adapts jwtkms Configs to the go-jose OpaqueSigner and OpaqueVerifier interfaces, so JWS/JWE
+// built with github.com/go-jose/go-jose can be signed and verified with KMS keys.
+//
+// Signing and verification are delegated to the jwtkms signing methods, so a Signer or Verifier behaves exactly
+// like the equivalent golang-jwt token operation, including local verification with the cached public key.
+package jwtkmsjose
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+var signingMethods = map[jose.SignatureAlgorithm]jwt.SigningMethod{
+	jose.ES256: jwtkms.SigningMethodECDSA256,
+	jose.ES384: jwtkms.SigningMethodECDSA384,
+	jose.ES512: jwtkms.SigningMethodECDSA512,
+	jose.RS256: jwtkms.SigningMethodRS256,
+	jose.RS384: jwtkms.SigningMethodRS384,
+	jose.RS512: jwtkms.SigningMethodRS512,
+	jose.PS256: jwtkms.SigningMethodPS256,
+	jose.PS384: jwtkms.SigningMethodPS384,
+	jose.PS512: jwtkms.SigningMethodPS512,
+}
+
+// Signer implements jose.OpaqueSigner with a KMS key.
+type Signer struct {
+	cfg  *jwtkms.Config
+	jwk  *jose.JSONWebKey
+	algs []jose.SignatureAlgorithm
+}
+
+var _ jose.OpaqueSigner = &Signer{}
+
+// NewSigner creates a Signer for the key in cfg, fetching its public key to determine the supported algorithms.
+// keyID is reported as the kid of the public JWK; go-jose copies it into the JWS header. An empty keyID omits it.
+func NewSigner(cfg *jwtkms.Config, keyID string) (*Signer, error) {
+	kmsSigner, err := jwtkms.NewKMSSigner(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pub := kmsSigner.Public()
+
+	algs, err := supportedAlgs(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{
+		cfg: cfg,
+		jwk: &jose.JSONWebKey{
+			Key:   pub,
+			KeyID: keyID,
+			Use:   "sig",
+		},
+		algs: algs,
+	}, nil
+}
+
+// Public returns the public JWK of the KMS key.
+func (s *Signer) Public() *jose.JSONWebKey {
+	return s.jwk
+}
+
+// Algs returns the signature algorithms the KMS key supports.
+func (s *Signer) Algs() []jose.SignatureAlgorithm {
+	return s.algs
+}
+
+// SignPayload signs payload with the KMS key using alg.
+func (s *Signer) SignPayload(payload []byte, alg jose.SignatureAlgorithm) ([]byte, error) {
+	method, err := signingMethod(s.algs, alg)
+	if err != nil {
+		return nil, err
+	}
+
+	return method.Sign(string(payload), s.cfg)
+}
+
+// Verifier implements jose.OpaqueVerifier with a KMS key.
+type Verifier struct {
+	cfg *jwtkms.Config
+}
+
+var _ jose.OpaqueVerifier = &Verifier{}
+
+// NewVerifier creates a Verifier for the key in cfg. Whether signatures are verified locally or by KMS follows cfg.
+func NewVerifier(cfg *jwtkms.Config) *Verifier {
+	return &Verifier{cfg: cfg}
+}
+
+// VerifyPayload verifies signature over payload with the KMS key using alg.
+func (v *Verifier) VerifyPayload(payload []byte, signature []byte, alg jose.SignatureAlgorithm) error {
+	method, ok := signingMethods[alg]
+	if !ok {
+		return fmt.Errorf("unsupported signature algorithm %q", alg)
+	}
+
+	return method.Verify(string(payload), signature, v.cfg)
+}
+
+func signingMethod(algs []jose.SignatureAlgorithm, alg jose.SignatureAlgorithm) (jwt.SigningMethod, error) {
+	for _, supported := range algs {
+		if supported == alg {
+			return signingMethods[alg], nil
+		}
+	}
+
+	return nil, fmt.Errorf("signature algorithm %q is not supported by the key", alg)
+}
+
+func supportedAlgs(pub interface{}) ([]jose.SignatureAlgorithm, error) {
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			return []jose.SignatureAlgorithm{jose.ES256}, nil
+		case elliptic.P384():
+			return []jose.SignatureAlgorithm{jose.ES384}, nil
+		case elliptic.P521():
+			return []jose.SignatureAlgorithm{jose.ES512}, nil
+		}
+
+		return nil, errors.New("unsupported elliptic curve")
+
+	case *rsa.PublicKey:
+		return []jose.SignatureAlgorithm{
+			jose.RS256, jose.RS384, jose.RS512,
+			jose.PS256, jose.PS384, jose.PS512,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", pub)
+	}
+}
@@ -0,0 +1,72 @@
+package jwtkmsjose
+
+import (
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+func TestOpaqueSigner(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		alg     jose.SignatureAlgorithm
+	}{
+		{name: "ES256", keyType: mockkms.KeyTypeECCNISTP256, alg: jose.ES256},
+		{name: "ES384", keyType: mockkms.KeyTypeECCNISTP384, alg: jose.ES384},
+		{name: "ES512", keyType: mockkms.KeyTypeECCNISTP521, alg: jose.ES512},
+		{name: "RS256", keyType: mockkms.KeyTypeRSA2048, alg: jose.RS256},
+		{name: "PS256", keyType: mockkms.KeyTypeRSA2048, alg: jose.PS256},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kms := mockkms.NewMockKMS()
+			id, err := kms.GenerateKey(test.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			cfg := jwtkms.NewKMSConfig(kms, id, false)
+
+			opaqueSigner, err := NewSigner(cfg, "key-1")
+			if err != nil {
+				t.Fatalf("Error creating signer: %v", err)
+			}
+
+			signer, err := jose.NewSigner(jose.SigningKey{Algorithm: test.alg, Key: opaqueSigner}, nil)
+			if err != nil {
+				t.Fatalf("Error creating jose signer: %v", err)
+			}
+
+			jws, err := signer.Sign([]byte("payload"))
+			if err != nil {
+				t.Fatalf("Error signing payload: %v", err)
+			}
+
+			compact, err := jws.CompactSerialize()
+			if err != nil {
+				t.Fatalf("Error serializing JWS: %v", err)
+			}
+
+			parsed, err := jose.ParseSigned(compact, []jose.SignatureAlgorithm{test.alg})
+			if err != nil {
+				t.Fatalf("Error parsing JWS: %v", err)
+			}
+
+			if kid := parsed.Signatures[0].Header.KeyID; kid != "key-1" {
+				t.Errorf("kid = %q, want %q", kid, "key-1")
+			}
+
+			// verify both with the public JWK and the KMS backed verifier
+			if _, err := parsed.Verify(opaqueSigner.Public()); err != nil {
+				t.Fatalf("Error verifying with public key: %v", err)
+			}
+
+			if _, err := parsed.Verify(NewVerifier(jwtkms.NewKMSConfig(kms, id, true))); err != nil {
+				t.Fatalf("Error verifying with KMS: %v", err)
+			}
+		})
+	}
+}
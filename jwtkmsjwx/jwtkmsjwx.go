@@ -0,0 +1,124 @@
+// Package jwtkmsjwx integrates jwtkms with github.com/lestrrat-go/jwx, so JWS and JWT produced with jwx can be
+// signed and verified with KMS keys by passing a *jwtkms.Config as the key:
+//
+//	jwtkmsjwx.Register()
+//	signed, err := jws.Sign(payload, jws.WithKey(jwa.ES256(), cfg))
+//
+// The Signer and Verifier delegate to the jwtkms signing methods, so they share the public key cache and KMS
+// client plumbing with the golang-jwt integration. Keys other than *jwtkms.Config are handed to whatever
+// implementation jwx had registered for the algorithm before, the same way the jwtkms signing methods fall back to
+// the stock golang-jwt methods.
+package jwtkmsjwx
+
+import (
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jws"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+// Signer implements jws.Signer2 with a jwtkms signing method.
+type Signer struct {
+	alg      jwa.SignatureAlgorithm
+	method   jwt.SigningMethod
+	fallback jws.Signer2
+}
+
+var _ jws.Signer2 = &Signer{}
+
+func (s *Signer) Algorithm() jwa.SignatureAlgorithm {
+	return s.alg
+}
+
+func (s *Signer) Sign(key any, payload []byte) ([]byte, error) {
+	cfg, ok := key.(*jwtkms.Config)
+	if !ok {
+		if s.fallback == nil {
+			return nil, fmt.Errorf("invalid key type %T for %s", key, s.alg)
+		}
+
+		return s.fallback.Sign(key, payload)
+	}
+
+	return s.method.Sign(string(payload), cfg)
+}
+
+// Verifier implements jws.Verifier2 with a jwtkms signing method.
+type Verifier struct {
+	method   jwt.SigningMethod
+	fallback jws.Verifier2
+}
+
+var _ jws.Verifier2 = &Verifier{}
+
+func (v *Verifier) Verify(key any, payload, signature []byte) error {
+	cfg, ok := key.(*jwtkms.Config)
+	if !ok {
+		if v.fallback == nil {
+			return fmt.Errorf("invalid key type %T for %s", key, v.method.Alg())
+		}
+
+		return v.fallback.Verify(key, payload, signature)
+	}
+
+	return v.method.Verify(string(payload), signature, cfg)
+}
+
+func signingMethods() map[jwa.SignatureAlgorithm]jwt.SigningMethod {
+	return map[jwa.SignatureAlgorithm]jwt.SigningMethod{
+		jwa.ES256(): jwtkms.SigningMethodECDSA256,
+		jwa.ES384(): jwtkms.SigningMethodECDSA384,
+		jwa.ES512(): jwtkms.SigningMethodECDSA512,
+		jwa.RS256(): jwtkms.SigningMethodRS256,
+		jwa.RS384(): jwtkms.SigningMethodRS384,
+		jwa.RS512(): jwtkms.SigningMethodRS512,
+		jwa.PS256(): jwtkms.SigningMethodPS256,
+		jwa.PS384(): jwtkms.SigningMethodPS384,
+		jwa.PS512(): jwtkms.SigningMethodPS512,
+		jwa.HS256(): jwtkms.SigningMethodHS256,
+		jwa.HS384(): jwtkms.SigningMethodHS384,
+		jwa.HS512(): jwtkms.SigningMethodHS512,
+	}
+}
+
+// Register registers a Signer and Verifier with jwx for every algorithm jwtkms and jwx have in common.
+// It is not safe to call concurrently with jwx signing or verification, call it during program initialization.
+func Register() error {
+	for alg, method := range signingMethods() {
+		if _, ok := signerOf(alg).(*Signer); ok {
+			continue
+		}
+
+		signer := &Signer{alg: alg, method: method, fallback: signerOf(alg)}
+		if err := jws.RegisterSigner(alg, signer); err != nil {
+			return fmt.Errorf("registering signer for %s: %w", alg, err)
+		}
+
+		verifier := &Verifier{method: method, fallback: verifierOf(alg)}
+		if err := jws.RegisterVerifier(alg, verifier); err != nil {
+			return fmt.Errorf("registering verifier for %s: %w", alg, err)
+		}
+	}
+
+	return nil
+}
+
+func signerOf(alg jwa.SignatureAlgorithm) jws.Signer2 {
+	signer, err := jws.SignerFor(alg)
+	if err != nil {
+		return nil
+	}
+
+	return signer
+}
+
+func verifierOf(alg jwa.SignatureAlgorithm) jws.Verifier2 {
+	verifier, err := jws.VerifierFor(alg)
+	if err != nil {
+		return nil
+	}
+
+	return verifier
+}
@@ -0,0 +1,73 @@
+package jwtkmsjwx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jws"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+func TestSignVerify(t *testing.T) {
+	if err := Register(); err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		alg     jwa.SignatureAlgorithm
+	}{
+		{name: "ES256", keyType: mockkms.KeyTypeECCNISTP256, alg: jwa.ES256()},
+		{name: "RS256", keyType: mockkms.KeyTypeRSA2048, alg: jwa.RS256()},
+		{name: "PS384", keyType: mockkms.KeyTypeRSA2048, alg: jwa.PS384()},
+		{name: "HS256", keyType: mockkms.KeyTypeHMAC256, alg: jwa.HS256()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kms := mockkms.NewMockKMS()
+			id, err := kms.GenerateKey(test.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			signed, err := jws.Sign([]byte("payload"), jws.WithKey(test.alg, jwtkms.NewKMSConfig(kms, id, false)))
+			if err != nil {
+				t.Fatalf("Error signing payload: %v", err)
+			}
+
+			payload, err := jws.Verify(signed, jws.WithKey(test.alg, jwtkms.NewKMSConfig(kms, id, false)))
+			if err != nil {
+				t.Fatalf("Error verifying payload: %v", err)
+			}
+
+			if string(payload) != "payload" {
+				t.Errorf("payload = %q, want %q", payload, "payload")
+			}
+		})
+	}
+}
+
+func TestFallback(t *testing.T) {
+	if err := Register(); err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	signed, err := jws.Sign([]byte("payload"), jws.WithKey(jwa.ES256(), key))
+	if err != nil {
+		t.Fatalf("Error signing payload: %v", err)
+	}
+
+	if _, err := jws.Verify(signed, jws.WithKey(jwa.ES256(), &key.PublicKey)); err != nil {
+		t.Fatalf("Error verifying payload: %v", err)
+	}
+}
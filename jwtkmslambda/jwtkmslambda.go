@@ -0,0 +1,128 @@
+// Package jwtkmslambda provides API Gateway Lambda authorizers verifying KMS-signed bearer tokens.
+//
+// Authorizers should verify locally with public keys fetched once per execution environment, not with KMS per
+// invocation: build the jwtkms.Verifier outside the handler from a Config with the default verify mode, preloaded
+// with Config.PreloadKeys, or from jwtkms.NewOfflineConfig or jwtkms.NewOfflineKeySet, which need no KMS access at all.
+package jwtkmslambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+// ErrUnauthorized is the error API Gateway answers with 401 Unauthorized when a Lambda authorizer returns it.
+var ErrUnauthorized = errors.New("Unauthorized")
+
+// Authorizer verifies the bearer tokens of API Gateway authorizer requests with a jwtkms.Verifier. The claims of
+// verified tokens are returned in the authorizer context, the sub claim as principal id.
+type Authorizer struct {
+	verifier *jwtkms.Verifier
+}
+
+// NewAuthorizer creates an Authorizer verifying tokens with v.
+func NewAuthorizer(v *jwtkms.Verifier) *Authorizer {
+	return &Authorizer{verifier: v}
+}
+
+// AuthorizeToken is the handler of REST API TOKEN authorizers. Valid tokens are allowed to invoke the method,
+// requests with other tokens fail with ErrUnauthorized.
+func (a *Authorizer) AuthorizeToken(ctx context.Context, req events.APIGatewayCustomAuthorizerRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	principal, authContext, err := a.authorize(ctx, req.AuthorizationToken)
+	if err != nil {
+		return events.APIGatewayCustomAuthorizerResponse{}, ErrUnauthorized
+	}
+
+	return events.APIGatewayCustomAuthorizerResponse{
+		PrincipalID: principal,
+		PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+			Version: "2012-10-17",
+			Statement: []events.IAMPolicyStatement{{
+				Action:   []string{"execute-api:Invoke"},
+				Effect:   "Allow",
+				Resource: []string{req.MethodArn},
+			}},
+		},
+		Context: authContext,
+	}, nil
+}
+
+// AuthorizeHTTP is the handler of HTTP API authorizers with simple responses. The token is taken from the first
+// identity source, the Authorization header when there is none.
+func (a *Authorizer) AuthorizeHTTP(ctx context.Context, req events.APIGatewayV2CustomAuthorizerV2Request) (events.APIGatewayV2CustomAuthorizerSimpleResponse, error) {
+	authorization := req.Headers["authorization"]
+	if len(req.IdentitySource) > 0 {
+		authorization = req.IdentitySource[0]
+	}
+
+	_, authContext, err := a.authorize(ctx, authorization)
+	if err != nil {
+		return events.APIGatewayV2CustomAuthorizerSimpleResponse{IsAuthorized: false}, nil
+	}
+
+	return events.APIGatewayV2CustomAuthorizerSimpleResponse{IsAuthorized: true, Context: authContext}, nil
+}
+
+// authorize verifies the bearer token of an Authorization header value, returning its subject and claims.
+func (a *Authorizer) authorize(ctx context.Context, authorization string) (string, map[string]interface{}, error) {
+	token, err := jwtkms.BearerToken(authorization)
+	if err != nil {
+		return "", nil, err
+	}
+
+	verified, err := a.verifier.Verify(ctx, token)
+	if err != nil {
+		return "", nil, err
+	}
+
+	subject, err := verified.Claims.GetSubject()
+	if err != nil {
+		return "", nil, err
+	}
+
+	authContext, err := contextClaims(verified.Claims)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return subject, authContext, nil
+}
+
+// contextClaims converts claims to an authorizer context, whose values API Gateway restricts to strings, numbers and
+// booleans: other values, e.g. an aud array, are JSON encoded.
+func contextClaims(claims jwt.Claims) (map[string]interface{}, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling claims: %w", err)
+	}
+
+	var values map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("unmarshalling claims: %w", err)
+	}
+
+	authContext := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		switch value.(type) {
+		case string, bool, json.Number:
+			authContext[name] = value
+		default:
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("marshalling claim %q: %w", name, err)
+			}
+
+			authContext[name] = string(encoded)
+		}
+	}
+
+	return authContext, nil
+}
@@ -0,0 +1,115 @@
+package jwtkmslambda
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+)
+
+func TestAuthorizer(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+
+	out, err := client.GetPublicKey(ctx, &kms.GetPublicKeyInput{KeyId: aws.String(id)})
+	if err != nil {
+		t.Fatalf("Error getting public key: %v", err)
+	}
+
+	pub, err := jwtkms.ParsePublicKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: out.PublicKey}))
+	if err != nil {
+		t.Fatalf("Error parsing public key: %v", err)
+	}
+
+	// the authorizer verifies without KMS
+	authorizer := NewAuthorizer(jwtkms.NewConfigVerifier(jwtkms.NewOfflineConfig(id, pub), jwtkms.VerifierSettings{Audience: "api"}))
+
+	signed, err := jwtkms.NewConfig(client, id).SignContext(ctx, jwt.NewWithClaims(jwtkms.SigningMethodECDSA256, jwt.MapClaims{
+		"sub":   "user",
+		"aud":   []string{"api"},
+		"admin": true,
+		"exp":   time.Now().Add(time.Minute).Unix(),
+	}))
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	methodARN := "arn:aws:execute-api:eu-west-1:123456789012:api/prod/GET/items"
+
+	t.Run("token", func(t *testing.T) {
+		resp, err := authorizer.AuthorizeToken(ctx, events.APIGatewayCustomAuthorizerRequest{
+			Type:               "TOKEN",
+			AuthorizationToken: "Bearer " + signed,
+			MethodArn:          methodARN,
+		})
+		if err != nil {
+			t.Fatalf("Error authorizing request: %v", err)
+		}
+
+		if resp.PrincipalID != "user" {
+			t.Errorf("PrincipalID = %q, want %q", resp.PrincipalID, "user")
+		}
+
+		statement := resp.PolicyDocument.Statement[0]
+		if statement.Effect != "Allow" || statement.Resource[0] != methodARN {
+			t.Errorf("statement = %+v, want Allow for %s", statement, methodARN)
+		}
+
+		if resp.Context["aud"] != `["api"]` || resp.Context["admin"] != true {
+			t.Errorf("Context = %v", resp.Context)
+		}
+
+		if _, ok := resp.Context["exp"].(json.Number); !ok {
+			t.Errorf("exp = %T, want a number", resp.Context["exp"])
+		}
+	})
+
+	t.Run("token invalid", func(t *testing.T) {
+		_, err := authorizer.AuthorizeToken(ctx, events.APIGatewayCustomAuthorizerRequest{AuthorizationToken: "Bearer invalid", MethodArn: methodARN})
+		if !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("err = %v, want %v", err, ErrUnauthorized)
+		}
+	})
+
+	tests := []struct {
+		name           string
+		req            events.APIGatewayV2CustomAuthorizerV2Request
+		wantAuthorized bool
+	}{
+		{name: "identity source", req: events.APIGatewayV2CustomAuthorizerV2Request{IdentitySource: []string{"Bearer " + signed}}, wantAuthorized: true},
+		{name: "header", req: events.APIGatewayV2CustomAuthorizerV2Request{Headers: map[string]string{"authorization": "Bearer " + signed}}, wantAuthorized: true},
+		{name: "no token", req: events.APIGatewayV2CustomAuthorizerV2Request{}},
+		{name: "invalid token", req: events.APIGatewayV2CustomAuthorizerV2Request{IdentitySource: []string{"Bearer invalid"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run("http "+tt.name, func(t *testing.T) {
+			resp, err := authorizer.AuthorizeHTTP(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("Error authorizing request: %v", err)
+			}
+
+			if resp.IsAuthorized != tt.wantAuthorized {
+				t.Errorf("IsAuthorized = %v, want %v", resp.IsAuthorized, tt.wantAuthorized)
+			}
+
+			if tt.wantAuthorized && resp.Context["sub"] != "user" {
+				t.Errorf("sub = %v, want user", resp.Context["sub"])
+			}
+		})
+	}
+}
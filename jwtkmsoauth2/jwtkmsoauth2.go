@@ -0,0 +1,195 @@
+// Package jwtkmsoauth2 provides an oauth2.TokenSource exchanging JWT bearer assertions signed with a KMS key for
+// access tokens, RFC 7523 section 2.1, so services authenticate to external APIs with nothing but a KMS key.
+package jwtkmsoauth2
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+	"golang.org/x/oauth2"
+)
+
+// GrantType is the grant_type of JWT bearer assertion grants, RFC 7523 section 2.1.
+const GrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+
+const defaultAssertionLifetime = 5 * time.Minute
+
+// Config describes the assertions and the token endpoint they are exchanged at.
+type Config struct {
+	// TokenURL is the token endpoint of the authorization server.
+	TokenURL string
+
+	// Issuer is the iss claim of the assertions, usually the client id.
+	Issuer string
+
+	// Subject is the sub claim of the assertions, the Issuer when empty.
+	Subject string
+
+	// Audience is the aud claim of the assertions, the TokenURL when empty.
+	Audience string
+
+	// Scopes are the scopes requested, none when empty.
+	Scopes []string
+
+	// Lifetime is the lifetime of the assertions, five minutes when zero.
+	Lifetime time.Duration
+
+	// Claims holds additional claims of the assertions.
+	Claims map[string]interface{}
+
+	// HTTPClient is the client of the token requests, http.DefaultClient when nil.
+	HTTPClient *http.Client
+}
+
+// TokenSource returns an oauth2.TokenSource exchanging assertions signed with cfg and method for access tokens at
+// conf.TokenURL, using ctx for the KMS calls and token requests. Access tokens are cached and a new assertion is
+// exchanged shortly before they expire.
+func TokenSource(ctx context.Context, cfg *jwtkms.Config, method jwt.SigningMethod, conf Config) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(nil, &assertionSource{ctx: ctx, cfg: cfg, method: method, conf: conf})
+}
+
+type assertionSource struct {
+	ctx    context.Context
+	cfg    *jwtkms.Config
+	method jwt.SigningMethod
+	conf   Config
+}
+
+func (s *assertionSource) Token() (*oauth2.Token, error) {
+	assertion, err := s.assertion()
+	if err != nil {
+		return nil, fmt.Errorf("signing assertion: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {GrantType},
+		"assertion":  {assertion},
+	}
+
+	if len(s.conf.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.conf.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, s.conf.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("creating token request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := s.conf.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("reading token response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		retrieveErr := &oauth2.RetrieveError{Response: resp, Body: body}
+
+		var errResp struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+			ErrorURI         string `json:"error_uri"`
+		}
+
+		if json.Unmarshal(body, &errResp) == nil {
+			retrieveErr.ErrorCode = errResp.Error
+			retrieveErr.ErrorDescription = errResp.ErrorDescription
+			retrieveErr.ErrorURI = errResp.ErrorURI
+		}
+
+		return nil, retrieveErr
+	}
+
+	var tokenResp struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, fmt.Errorf("parsing token response: %w", err)
+	}
+
+	if tokenResp.AccessToken == "" {
+		return nil, errors.New("token response has no access_token")
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  tokenResp.AccessToken,
+		TokenType:    tokenResp.TokenType,
+		RefreshToken: tokenResp.RefreshToken,
+		ExpiresIn:    tokenResp.ExpiresIn,
+	}
+
+	if tokenResp.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	}
+
+	var extra map[string]interface{}
+	if err := json.Unmarshal(body, &extra); err != nil {
+		return nil, fmt.Errorf("parsing token response: %w", err)
+	}
+
+	return token.WithExtra(extra), nil
+}
+
+// assertion signs a new assertion for the token request.
+func (s *assertionSource) assertion() (string, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", fmt.Errorf("generating jti: %w", err)
+	}
+
+	subject := s.conf.Subject
+	if subject == "" {
+		subject = s.conf.Issuer
+	}
+
+	audience := s.conf.Audience
+	if audience == "" {
+		audience = s.conf.TokenURL
+	}
+
+	lifetime := s.conf.Lifetime
+	if lifetime <= 0 {
+		lifetime = defaultAssertionLifetime
+	}
+
+	now := time.Now()
+
+	claims := jwt.MapClaims{}
+	maps.Copy(claims, s.conf.Claims)
+
+	claims["iss"] = s.conf.Issuer
+	claims["sub"] = subject
+	claims["aud"] = audience
+	claims["exp"] = now.Add(lifetime).Unix()
+	claims["iat"] = now.Unix()
+	claims["jti"] = base64.RawURLEncoding.EncodeToString(jti)
+
+	return s.cfg.SignContext(s.ctx, jwt.NewWithClaims(s.method, claims))
+}
@@ -0,0 +1,90 @@
+package jwtkmsoauth2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+	"golang.org/x/oauth2"
+)
+
+func TestTokenSource(t *testing.T) {
+	client := mockkms.NewMockKMS()
+	id, err := client.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	ctx := context.Background()
+	cfg := jwtkms.NewConfig(client, id)
+
+	var requests atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if r.PostFormValue("grant_type") != GrantType || r.PostFormValue("scope") != "read write" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		claims := jwt.MapClaims{}
+		if _, err := cfg.VerifyContext(r.Context(), r.PostFormValue("assertion"), claims,
+			jwt.WithIssuer("service-a"), jwt.WithSubject("service-a"), jwt.WithAudience(server.URL),
+			jwt.WithExpirationRequired()); err != nil || claims["tenant"] != "acme" || claims["jti"] == nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"scope":        "read write",
+		})
+	}))
+	defer server.Close()
+
+	ts := TokenSource(ctx, cfg, jwtkms.SigningMethodECDSA256, Config{
+		TokenURL: server.URL,
+		Issuer:   "service-a",
+		Scopes:   []string{"read", "write"},
+		Claims:   map[string]interface{}{"tenant": "acme"},
+	})
+
+	for i := 0; i < 2; i++ {
+		token, err := ts.Token()
+		if err != nil {
+			t.Fatalf("Error getting token: %v", err)
+		}
+
+		if token.AccessToken != "access-token" || !token.Valid() || token.Extra("scope") != "read write" {
+			t.Errorf("token = %+v", token)
+		}
+	}
+
+	if requests.Load() != 1 {
+		t.Errorf("token requests = %d, want 1, the token is cached", requests.Load())
+	}
+
+	_, err = TokenSource(ctx, cfg, jwtkms.SigningMethodECDSA256, Config{
+		TokenURL: server.URL,
+		Issuer:   "service-b",
+		Scopes:   []string{"read", "write"},
+	}).Token()
+
+	var retrieveErr *oauth2.RetrieveError
+	if !errors.As(err, &retrieveErr) || retrieveErr.ErrorCode != "invalid_grant" {
+		t.Errorf("err = %v, want an invalid_grant oauth2.RetrieveError", err)
+	}
+}
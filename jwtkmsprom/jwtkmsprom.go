@@ -0,0 +1,189 @@
+// Package jwtkmsprom exports the jwtkms metrics hooks as Prometheus metrics.
+//
+// A Collector is both a jwtkms.MetricsRecorder, set on Configs with jwtkms.WithMetricsRecorder, and a
+// prometheus.Collector, registered with a Prometheus registry:
+//
+//	collector := jwtkmsprom.NewCollector("")
+//	prometheus.MustRegister(collector)
+//	cfg := jwtkms.NewConfig(kmsClient, keyID, jwtkms.WithMetricsRecorder(collector))
+//
+// Key ids are not used as labels, to keep the cardinality of the metrics independent of the number of keys.
+package jwtkmsprom
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unknownErrorCode is the code label of errors that did not come with a KMS error code, like timeouts.
+const unknownErrorCode = "unknown"
+
+// Collector records jwtkms operations as Prometheus metrics:
+//
+//   - kms_sign_duration_seconds, histogram of KMS Sign and GenerateMac calls by operation and algorithm
+//   - kms_verify_duration_seconds, histogram of signature verifications by alg and mode (kms or local)
+//   - kms_get_public_key_duration_seconds, histogram of KMS GetPublicKey calls
+//   - pubkey_cache_hits_total and pubkey_cache_misses_total, public key lookups served from the cache or not
+//   - kms_errors_total, failed KMS calls by operation and KMS error code
+//   - pubkey_cache_entries and pubkey_cache_evictions_total, the size and evictions of the public key cache, as last
+//     reported with jwtkms.CacheStats; Configs reporting to the same Collector should share their cache
+type Collector struct {
+	signDuration         *prometheus.HistogramVec
+	verifyDuration       *prometheus.HistogramVec
+	getPublicKeyDuration prometheus.Histogram
+	cacheHits            prometheus.Counter
+	cacheMisses          prometheus.Counter
+	errors               *prometheus.CounterVec
+	cacheEntries         prometheus.Gauge
+	cacheEvictions       *prometheus.Desc
+
+	mutex     sync.Mutex
+	evictions uint64
+}
+
+var (
+	_ jwtkms.MetricsRecorder      = (*Collector)(nil)
+	_ jwtkms.CacheMetricsRecorder = (*Collector)(nil)
+	_ prometheus.Collector        = (*Collector)(nil)
+)
+
+// NewCollector returns a Collector with its metric names prefixed by namespace, if not empty.
+func NewCollector(namespace string) *Collector {
+	return &Collector{
+		signDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "kms_sign_duration_seconds",
+			Help:      "Duration of KMS Sign and GenerateMac calls, including retries and rate limiting.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "algorithm"}),
+		verifyDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "kms_verify_duration_seconds",
+			Help:      "Duration of token signature verifications, with KMS or locally.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"algorithm", "mode"}),
+		getPublicKeyDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "kms_get_public_key_duration_seconds",
+			Help:      "Duration of KMS GetPublicKey calls.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		cacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pubkey_cache_hits_total",
+			Help:      "Public key lookups served from the cache.",
+		}),
+		cacheMisses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pubkey_cache_misses_total",
+			Help:      "Public key lookups not served from the cache.",
+		}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "kms_errors_total",
+			Help:      "Failed KMS calls by operation and KMS error code.",
+		}, []string{"operation", "code"}),
+		cacheEntries: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "pubkey_cache_entries",
+			Help:      "Public keys in the cache.",
+		}),
+		cacheEvictions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "pubkey_cache_evictions_total"),
+			"Public keys evicted from the cache to stay under its maximum number of entries.",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.signDuration.Describe(ch)
+	c.verifyDuration.Describe(ch)
+	c.getPublicKeyDuration.Describe(ch)
+	c.cacheHits.Describe(ch)
+	c.cacheMisses.Describe(ch)
+	c.errors.Describe(ch)
+	c.cacheEntries.Describe(ch)
+	ch <- c.cacheEvictions
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.signDuration.Collect(ch)
+	c.verifyDuration.Collect(ch)
+	c.getPublicKeyDuration.Collect(ch)
+	c.cacheHits.Collect(ch)
+	c.cacheMisses.Collect(ch)
+	c.errors.Collect(ch)
+	c.cacheEntries.Collect(ch)
+
+	c.mutex.Lock()
+	evictions := c.evictions
+	c.mutex.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(c.cacheEvictions, prometheus.CounterValue, float64(evictions))
+}
+
+// OnSign implements jwtkms.MetricsRecorder.
+func (c *Collector) OnSign(m jwtkms.SignMetrics) {
+	c.signDuration.WithLabelValues(string(m.Operation), m.Algorithm).Observe(m.Duration.Seconds())
+
+	if m.Err != nil {
+		c.recordError(m.Operation, m.ErrorCode)
+	}
+}
+
+// OnVerify implements jwtkms.MetricsRecorder. Only verifications with KMS count as KMS errors, a local verification
+// failing is an invalid token, and so is KMS rejecting the signature with jwtkms.ErrInvalidSignature.
+func (c *Collector) OnVerify(m jwtkms.VerifyMetrics) {
+	mode := "local"
+	if m.WithKMS {
+		mode = "kms"
+	}
+
+	c.verifyDuration.WithLabelValues(m.Algorithm, mode).Observe(m.Duration.Seconds())
+
+	if m.Err != nil && m.WithKMS && !errors.Is(m.Err, jwtkms.ErrInvalidSignature) {
+		c.recordError(jwtkms.OperationVerify, m.ErrorCode)
+	}
+}
+
+// OnGetPublicKey implements jwtkms.MetricsRecorder.
+func (c *Collector) OnGetPublicKey(m jwtkms.GetPublicKeyMetrics) {
+	if m.CacheHit {
+		c.cacheHits.Inc()
+		return
+	}
+
+	c.cacheMisses.Inc()
+	if m.NegativeCacheHit {
+		return
+	}
+
+	c.getPublicKeyDuration.Observe(m.Duration.Seconds())
+
+	if m.Err != nil {
+		c.recordError(jwtkms.OperationGetPublicKey, m.ErrorCode)
+	}
+}
+
+// OnCacheStats implements jwtkms.CacheMetricsRecorder.
+func (c *Collector) OnCacheStats(stats jwtkms.CacheStats) {
+	c.cacheEntries.Set(float64(stats.Entries))
+
+	c.mutex.Lock()
+	c.evictions = stats.Evictions
+	c.mutex.Unlock()
+}
+
+func (c *Collector) recordError(operation jwtkms.Operation, code string) {
+	if code == "" {
+		code = unknownErrorCode
+	}
+
+	c.errors.WithLabelValues(string(operation), code).Inc()
+}
@@ -0,0 +1,161 @@
+package jwtkmsprom
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	collector := NewCollector("")
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatalf("Error registering collector: %v", err)
+	}
+
+	cfg := jwtkms.NewConfig(kms, id, jwtkms.WithMetricsRecorder(collector))
+
+	signed, err := jwt.New(jwtkms.SigningMethodECDSA256).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cfg.VerifyContext(context.Background(), signed, jwt.MapClaims{}); err != nil {
+			t.Fatalf("Error verifying token: %v", err)
+		}
+	}
+
+	kms.DisableKey(id)
+
+	if _, err := jwt.New(jwtkms.SigningMethodECDSA256).SignedString(cfg); err == nil {
+		t.Fatalf("expected error signing with a disabled key")
+	}
+
+	if n := testutil.CollectAndCount(collector, "kms_sign_duration_seconds"); n != 1 {
+		t.Errorf("kms_sign_duration_seconds series = %d, want 1", n)
+	}
+
+	// signing fetches the public key, both verifications find it cached
+	if n := testutil.ToFloat64(collector.cacheMisses); n != 1 {
+		t.Errorf("pubkey_cache_misses_total = %v, want 1", n)
+	}
+
+	if n := testutil.ToFloat64(collector.cacheHits); n != 2 {
+		t.Errorf("pubkey_cache_hits_total = %v, want 2", n)
+	}
+
+	if n := testutil.ToFloat64(collector.errors.WithLabelValues("Sign", "DisabledException")); n != 1 {
+		t.Errorf("kms_errors_total{operation=Sign,code=DisabledException} = %v, want 1", n)
+	}
+
+	if n := testutil.ToFloat64(collector.cacheEntries); n != 1 {
+		t.Errorf("pubkey_cache_entries = %v, want 1", n)
+	}
+
+	if _, err := registry.Gather(); err != nil {
+		t.Fatalf("Error gathering metrics: %v", err)
+	}
+}
+
+func TestCollectorNamespace(t *testing.T) {
+	collector := NewCollector("auth")
+	collector.OnGetPublicKey(jwtkms.GetPublicKeyMetrics{CacheHit: true})
+
+	if n := testutil.CollectAndCount(collector, "auth_pubkey_cache_hits_total"); n != 1 {
+		t.Errorf("auth_pubkey_cache_hits_total series = %d, want 1", n)
+	}
+}
+
+func TestCollectorNegativeCacheHit(t *testing.T) {
+	collector := NewCollector("")
+	cfg := jwtkms.NewConfig(mockkms.NewMockKMS(), "unknown-key", jwtkms.WithNegativeCacheTTL(time.Minute),
+		jwtkms.WithMetricsRecorder(collector))
+
+	for i := 0; i < 2; i++ {
+		if err := cfg.PreloadKeys(context.Background()); err == nil {
+			t.Fatalf("expected error fetching an unknown key")
+		}
+	}
+
+	// the second lookup is answered by the negative cache without calling KMS
+	if n := testutil.ToFloat64(collector.cacheMisses); n != 2 {
+		t.Errorf("pubkey_cache_misses_total = %v, want 2", n)
+	}
+
+	if n := testutil.ToFloat64(collector.cacheHits); n != 0 {
+		t.Errorf("pubkey_cache_hits_total = %v, want 0", n)
+	}
+
+	if n := testutil.ToFloat64(collector.errors.WithLabelValues("GetPublicKey", "NotFoundException")); n != 1 {
+		t.Errorf("kms_errors_total{operation=GetPublicKey,code=NotFoundException} = %v, want 1", n)
+	}
+
+	stats := cfg.CacheStats()
+	if stats.Hits != 0 || stats.Misses != 2 {
+		t.Errorf("cache stats hits, misses = %d, %d, want 0, 2", stats.Hits, stats.Misses)
+	}
+}
+
+func TestCollectorCacheEvictions(t *testing.T) {
+	collector := NewCollector("")
+	collector.OnCacheStats(jwtkms.CacheStats{Entries: 10, Evictions: 3})
+
+	expected := `
+# HELP pubkey_cache_evictions_total Public keys evicted from the cache to stay under its maximum number of entries.
+# TYPE pubkey_cache_evictions_total counter
+pubkey_cache_evictions_total 3
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "pubkey_cache_evictions_total"); err != nil {
+		t.Errorf("Error comparing metrics: %v", err)
+	}
+}
+
+func TestCollectorInvalidSignature(t *testing.T) {
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	collector := NewCollector("")
+	cfg := jwtkms.NewConfig(kms, id, jwtkms.WithVerifyWithKMS(true), jwtkms.WithMetricsRecorder(collector))
+
+	signed, err := jwt.New(jwtkms.SigningMethodECDSA256).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	other, err := jwt.NewWithClaims(jwtkms.SigningMethodECDSA256, jwt.MapClaims{"sub": "other"}).SignedString(cfg)
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	// the signature of another token, rejected by KMS Verify with KMSInvalidSignatureException
+	tampered := signed[:strings.LastIndex(signed, ".")] + other[strings.LastIndex(other, "."):]
+	if _, err := cfg.VerifyContext(context.Background(), tampered, jwt.MapClaims{}); !errors.Is(err, jwtkms.ErrInvalidSignature) {
+		t.Fatalf("err = %v, want %v", err, jwtkms.ErrInvalidSignature)
+	}
+
+	if n := testutil.CollectAndCount(collector, "kms_errors_total"); n != 0 {
+		t.Errorf("kms_errors_total series = %d, want 0, an invalid signature is not a KMS error", n)
+	}
+
+	if n := testutil.CollectAndCount(collector, "kms_verify_duration_seconds"); n != 1 {
+		t.Errorf("kms_verify_duration_seconds series = %d, want 1", n)
+	}
+}
@@ -0,0 +1,233 @@
+// Package jwtkmsredis provides a jwtkms.PublicKeyCache backed by Redis, so the public keys fetched by one instance
+// of a service are reused by the whole fleet instead of every instance calling KMS GetPublicKey.
+//
+// Public keys are stored as their PKIX DER encoding together with the key metadata, and kept in a local in-memory
+// cache in front of Redis:
+//
+//	cache := jwtkmsredis.New(redisClient)
+//	cfg := jwtkms.NewConfig(kmsClient, keyID, jwtkms.WithPublicKeyCache(cache))
+//
+// Deleting a key removes it from Redis and the local cache of the instance, other instances keep their local copy
+// until it expires, see jwtkms.WithPublicKeyTTL.
+package jwtkmsredis
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultKeyPrefix is the prefix of the Redis keys of cached public keys.
+	DefaultKeyPrefix = "jwtkms:pubkey:"
+
+	defaultTimeout = time.Second
+)
+
+// Cache is a jwtkms.PublicKeyCache storing public keys in Redis, with a local in-memory cache in front.
+//
+// The PublicKeyCache interface does not return errors, so Redis failures make Get report a miss and Add and Delete
+// only update the local cache; they are passed to the error handler set with WithErrorHandler.
+type Cache struct {
+	client       redis.Cmdable
+	prefix       string
+	ttl          time.Duration
+	timeout      time.Duration
+	local        jwtkms.PublicKeyCache
+	errorHandler func(error)
+}
+
+var (
+	_ jwtkms.PublicKeyCache              = (*Cache)(nil)
+	_ jwtkms.PublicKeyCacheStatsReporter = (*Cache)(nil)
+)
+
+// Option configures a Cache created with New.
+type Option func(*Cache)
+
+// WithKeyPrefix sets the prefix of the Redis keys, DefaultKeyPrefix by default.
+func WithKeyPrefix(prefix string) Option {
+	return func(c *Cache) {
+		c.prefix = prefix
+	}
+}
+
+// WithTTL sets the Redis expiry of public keys without an ExpiresAt, none by default.
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithTimeout sets the timeout of Redis commands, one second by default.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Cache) {
+		c.timeout = timeout
+	}
+}
+
+// WithLocalCache sets the local cache in front of Redis, a jwtkms.NewPublicKeyCache by default.
+func WithLocalCache(local jwtkms.PublicKeyCache) Option {
+	return func(c *Cache) {
+		c.local = local
+	}
+}
+
+// WithErrorHandler sets a function called with the errors of Redis commands, which are ignored by default.
+func WithErrorHandler(f func(error)) Option {
+	return func(c *Cache) {
+		c.errorHandler = f
+	}
+}
+
+// New creates a Cache storing public keys with client.
+func New(client redis.Cmdable, opts ...Option) *Cache {
+	c := &Cache{
+		client:       client,
+		prefix:       DefaultKeyPrefix,
+		timeout:      defaultTimeout,
+		local:        jwtkms.NewPublicKeyCache(jwtkms.PublicKeyCacheSettings{}),
+		errorHandler: func(error) {},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// entry is the JSON encoding of a jwtkms.CachedPublicKey stored in Redis.
+type entry struct {
+	DER               []byte                       `json:"der"`
+	KeyARN            string                       `json:"keyArn,omitempty"`
+	KeySpec           types.KeySpec                `json:"keySpec,omitempty"`
+	SigningAlgorithms []types.SigningAlgorithmSpec `json:"signingAlgorithms,omitempty"`
+	FetchedAt         time.Time                    `json:"fetchedAt,omitzero"`
+	ExpiresAt         time.Time                    `json:"expiresAt,omitzero"`
+}
+
+// Get returns the public key of keyID from the local cache, or from Redis on a local miss.
+func (c *Cache) Get(keyID string) *jwtkms.CachedPublicKey {
+	if key := c.local.Get(keyID); key != nil {
+		return key
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, c.prefix+keyID).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			c.errorHandler(fmt.Errorf("getting public key of %s from redis: %w", keyID, err))
+		}
+
+		return nil
+	}
+
+	key, err := decode(data)
+	if err != nil {
+		c.errorHandler(fmt.Errorf("decoding public key of %s: %w", keyID, err))
+		return nil
+	}
+
+	c.local.Add(keyID, key)
+
+	return key
+}
+
+// Add stores the public key of keyID in the local cache and Redis.
+func (c *Cache) Add(keyID string, key *jwtkms.CachedPublicKey) {
+	c.local.Add(keyID, key)
+
+	data, err := encode(key)
+	if err != nil {
+		c.errorHandler(fmt.Errorf("encoding public key of %s: %w", keyID, err))
+		return
+	}
+
+	ttl := c.ttl
+	if !key.ExpiresAt.IsZero() {
+		ttl = time.Until(key.ExpiresAt)
+		if ttl <= 0 {
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if err := c.client.Set(ctx, c.prefix+keyID, data, ttl).Err(); err != nil {
+		c.errorHandler(fmt.Errorf("storing public key of %s in redis: %w", keyID, err))
+	}
+}
+
+// Delete removes the public key of keyID from the local cache and Redis.
+func (c *Cache) Delete(keyID string) {
+	c.local.Delete(keyID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if err := c.client.Del(ctx, c.prefix+keyID).Err(); err != nil {
+		c.errorHandler(fmt.Errorf("deleting public key of %s from redis: %w", keyID, err))
+	}
+}
+
+// CacheStats implements jwtkms.PublicKeyCacheStatsReporter with the entries and evictions of the local cache, zero
+// if it does not report them.
+func (c *Cache) CacheStats() jwtkms.CacheStats {
+	if reporter, ok := c.local.(jwtkms.PublicKeyCacheStatsReporter); ok {
+		return reporter.CacheStats()
+	}
+
+	return jwtkms.CacheStats{}
+}
+
+func encode(key *jwtkms.CachedPublicKey) ([]byte, error) {
+	der := key.DER
+	if der == nil {
+		var err error
+		if der, err = x509.MarshalPKIXPublicKey(key.PublicKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(entry{
+		DER:               der,
+		KeyARN:            key.KeyARN,
+		KeySpec:           key.KeySpec,
+		SigningAlgorithms: key.SigningAlgorithms,
+		FetchedAt:         key.FetchedAt,
+		ExpiresAt:         key.ExpiresAt,
+	})
+}
+
+func decode(data []byte) (*jwtkms.CachedPublicKey, error) {
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+
+	pub, err := jwtkms.ParsePublicKey(e.DER)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jwtkms.CachedPublicKey{
+		PublicKey:         pub,
+		DER:               e.DER,
+		KeyARN:            e.KeyARN,
+		KeySpec:           e.KeySpec,
+		SigningAlgorithms: e.SigningAlgorithms,
+		FetchedAt:         e.FetchedAt,
+		ExpiresAt:         e.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,135 @@
+package jwtkmsredis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matelang/jwt-go-aws-kms/v3/internal/mockkms"
+	"github.com/matelang/jwt-go-aws-kms/v3/jwtkms"
+	"github.com/redis/go-redis/v9"
+)
+
+func newRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return mr, client
+}
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType mockkms.KeyType
+		method  jwt.SigningMethod
+	}{
+		{name: "ES256", keyType: mockkms.KeyTypeECCNISTP256, method: jwtkms.SigningMethodECDSA256},
+		{name: "ES256K", keyType: mockkms.KeyTypeECCSECGP256K1, method: jwtkms.SigningMethodES256K},
+		{name: "RS256", keyType: mockkms.KeyTypeRSA2048, method: jwtkms.SigningMethodRS256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr, client := newRedis(t)
+
+			kms := mockkms.NewMockKMS()
+			id, err := kms.GenerateKey(tt.keyType)
+			if err != nil {
+				t.Fatalf("Error generating key: %v", err)
+			}
+
+			errorHandler := func(err error) { t.Errorf("unexpected redis error: %v", err) }
+
+			// the first instance fetches the public key from KMS and stores it in redis
+			cfg := jwtkms.NewConfig(kms, id, jwtkms.WithPublicKeyCache(New(client, WithErrorHandler(errorHandler))))
+
+			signed, err := jwt.New(tt.method).SignedString(cfg)
+			if err != nil {
+				t.Fatalf("Error signing token: %v", err)
+			}
+
+			if _, err := cfg.VerifyContext(context.Background(), signed, jwt.MapClaims{}); err != nil {
+				t.Fatalf("Error verifying token: %v", err)
+			}
+
+			if !mr.Exists(DefaultKeyPrefix + id) {
+				t.Fatalf("public key not stored in redis")
+			}
+
+			// a second instance verifies with the public key from redis, without KMS
+			kms.DisableKey(id)
+			other := jwtkms.NewConfig(kms, id, jwtkms.WithPublicKeyCache(New(client, WithErrorHandler(errorHandler))))
+
+			if _, err := other.VerifyContext(context.Background(), signed, jwt.MapClaims{}); err != nil {
+				t.Fatalf("Error verifying token with the public key from redis: %v", err)
+			}
+		})
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	mr, client := newRedis(t)
+	cache := New(client, WithTTL(time.Minute))
+
+	cache.Add("no-expiry", &jwtkms.CachedPublicKey{DER: mustGenerateDER(t)})
+	cache.Add("expiry", &jwtkms.CachedPublicKey{DER: mustGenerateDER(t), ExpiresAt: time.Now().Add(time.Hour)})
+
+	if ttl := mr.TTL(DefaultKeyPrefix + "no-expiry"); ttl != time.Minute {
+		t.Errorf("ttl = %v, want %v", ttl, time.Minute)
+	}
+
+	if ttl := mr.TTL(DefaultKeyPrefix + "expiry"); ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("ttl = %v, want about an hour", ttl)
+	}
+
+	cache.Delete("expiry")
+
+	if mr.Exists(DefaultKeyPrefix+"expiry") || cache.Get("expiry") != nil {
+		t.Errorf("public key not deleted")
+	}
+}
+
+func TestCacheRedisDown(t *testing.T) {
+	mr, client := newRedis(t)
+
+	var errs []error
+	cache := New(client, WithTimeout(100*time.Millisecond), WithErrorHandler(func(err error) { errs = append(errs, err) }))
+
+	mr.Close()
+
+	if cache.Get("key") != nil {
+		t.Errorf("expected a miss")
+	}
+
+	cache.Add("key", &jwtkms.CachedPublicKey{DER: mustGenerateDER(t)})
+
+	if cache.Get("key") == nil {
+		t.Errorf("public key not cached locally")
+	}
+
+	if len(errs) != 2 {
+		t.Errorf("errors = %v, want 2", errs)
+	}
+}
+
+func mustGenerateDER(t *testing.T) []byte {
+	t.Helper()
+
+	kms := mockkms.NewMockKMS()
+	id, err := kms.GenerateKey(mockkms.KeyTypeECCNISTP256)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	cache := jwtkms.NewPublicKeyCache(jwtkms.PublicKeyCacheSettings{})
+	if err := jwtkms.NewConfig(kms, id, jwtkms.WithPublicKeyCache(cache)).PreloadKeys(t.Context()); err != nil {
+		t.Fatalf("Error preloading key: %v", err)
+	}
+
+	return cache.Get(id).DER
+}